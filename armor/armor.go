@@ -3,9 +3,12 @@
 package armor
 
 import (
-	"bytes"
+	"encoding/base64"
 	"io"
 	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/ProtonMail/go-crypto/openpgp/armor"
 	"github.com/ProtonMail/gopenpgp/v2/constants"
@@ -51,19 +54,125 @@ func Unarmor(input string) ([]byte, error) {
 	return ioutil.ReadAll(b.Body)
 }
 
+// ArmorStream armors the binary data read from src with the given armorType,
+// and writes the armored data to dst, without loading the whole data in memory.
+// Returns the number of binary bytes read.
+func ArmorStream(dst io.Writer, src io.Reader, armorType string) (int64, error) {
+	w, err := armor.Encode(dst, armorType, internal.ArmorHeaders)
+	if err != nil {
+		return 0, errors.Wrap(err, "gopengp: unable to encode armoring")
+	}
+	n, err := io.Copy(w, src)
+	if err != nil {
+		return n, errors.Wrap(err, "gopengp: unable to armor stream")
+	}
+	if err = w.Close(); err != nil {
+		return n, errors.Wrap(err, "gopengp: unable to close armor writer")
+	}
+	return n, nil
+}
+
+// UnarmorStream returns a reader for the binary data of the armored data read from src,
+// along with the armor type, e.g. constants.PGPMessageHeader.
+// The checksum, if any, is verified when the end of the data is read.
+func UnarmorStream(src io.Reader) (binary io.Reader, armorType string, err error) {
+	block, err := armor.Decode(src)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "gopengp: unable to unarmor")
+	}
+	return block.Body, block.Type, nil
+}
+
+// armorChunkLines is the number of base64 lines encoded at once in a pooled buffer.
+const armorChunkLines = 64
+
+// armorChunks holds the buffers reused to encode the base64 lines of armored data.
+var armorChunks = sync.Pool{
+	New: func() interface{} {
+		chunk := make([]byte, armorChunkLines*(armorLineLength+1))
+		return &chunk
+	},
+}
+
+const (
+	// armorLineLength is the length of the base64 lines, encoding armorLineInput bytes each.
+	armorLineLength = 64
+	armorLineInput  = 48
+)
+
 func armorWithTypeAndHeaders(input []byte, armorType string, headers map[string]string) (string, error) {
-	var b bytes.Buffer
+	return encodeArmor(input, armorType, headers, true), nil
+}
 
-	w, err := armor.Encode(&b, armorType, headers)
+// encodeArmor armors input as the go-crypto armor encoder, with the headers sorted by key,
+// and without the checksum line if checksum is false. The base64 lines are encoded in place
+// in a pooled buffer, and written to a builder pre-sized to the armored length,
+// so that the returned string is the only allocation proportional to the input.
+func encodeArmor(input []byte, armorType string, headers map[string]string, checksum bool) string {
+	var b strings.Builder
+	b.Grow(armoredLength(len(input), armorType, headers))
 
-	if err != nil {
-		return "", errors.Wrap(err, "gopengp: unable to encode armoring")
+	b.WriteString("-----BEGIN " + armorType + "-----\n")
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(k + ": " + headers[k] + "\n")
 	}
-	if _, err = w.Write(input); err != nil {
-		return "", errors.Wrap(err, "gopengp: unable to write armored to buffer")
+	b.WriteByte('\n')
+
+	chunkPointer := armorChunks.Get().(*[]byte)
+	chunk := *chunkPointer
+	for offset := 0; offset < len(input); {
+		used := 0
+		for line := 0; line < armorChunkLines && offset < len(input); line++ {
+			next := offset + armorLineInput
+			if next > len(input) {
+				next = len(input)
+			}
+			encodedLength := base64.StdEncoding.EncodedLen(next - offset)
+			base64.StdEncoding.Encode(chunk[used:used+encodedLength], input[offset:next])
+			used += encodedLength
+			chunk[used] = '\n'
+			used++
+			offset = next
+		}
+		b.Write(chunk[:used])
+	}
+	armorChunks.Put(chunkPointer)
+	if len(input) == 0 {
+		b.WriteByte('\n')
+	}
+
+	if checksum {
+		crc := crc24(crc24Init, input)
+		var checksumBytes [4]byte
+		base64.StdEncoding.Encode(checksumBytes[:], []byte{byte(crc >> 16), byte(crc >> 8), byte(crc)})
+		b.WriteByte('=')
+		b.Write(checksumBytes[:])
+		b.WriteByte('\n')
 	}
-	if err := w.Close(); err != nil {
-		return "", errors.Wrap(err, "gopengp: unable to close armor buffer")
+	b.WriteString("-----END " + armorType + "-----")
+	return b.String()
+}
+
+// armoredLength returns an upper bound of the length of the armored data,
+// for an input of inputLength bytes.
+func armoredLength(inputLength int, armorType string, headers map[string]string) int {
+	// "-----BEGIN <type>-----\n" and "-----END <type>-----\n"
+	length := 2*len(armorType) + 32
+	for k, v := range headers {
+		// "<key>: <value>\n"
+		length += len(k) + len(v) + 3
 	}
-	return b.String(), nil
+	// Empty line after the headers
+	length++
+	// Base64 data in lines of 64 characters
+	encodedLength := base64.StdEncoding.EncodedLen(inputLength)
+	length += encodedLength + encodedLength/64 + 1
+	// "\n=<checksum>\n"
+	length += 7
+	return length
 }
@@ -0,0 +1,136 @@
+package armor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArmorWithType(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 47, 48, 49, 1000, 1 << 16} {
+		input := bytes.Repeat([]byte{0x42}, size)
+
+		var expected bytes.Buffer
+		w, err := armor.Encode(&expected, constants.PGPMessageHeader, nil)
+		if err != nil {
+			t.Fatal("Expected no error while encoding, got:", err)
+		}
+		if _, err = w.Write(input); err != nil {
+			t.Fatal("Expected no error while encoding, got:", err)
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal("Expected no error while encoding, got:", err)
+		}
+
+		armoredWithoutHeaders, err := armorWithTypeAndHeaders(input, constants.PGPMessageHeader, nil)
+		if err != nil {
+			t.Fatal("Expected no error while armoring, got:", err)
+		}
+		assert.Exactly(t, expected.String(), armoredWithoutHeaders)
+
+		headers := map[string]string{"Comment": "test"}
+		expected.Reset()
+		w, err = armor.Encode(&expected, constants.PGPMessageHeader, headers)
+		if err != nil {
+			t.Fatal("Expected no error while encoding, got:", err)
+		}
+		if _, err = w.Write(input); err != nil {
+			t.Fatal("Expected no error while encoding, got:", err)
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal("Expected no error while encoding, got:", err)
+		}
+		assert.Exactly(t, expected.String(), encodeArmor(input, constants.PGPMessageHeader, headers, true))
+		assert.Exactly(
+			t,
+			checksumLine.ReplaceAllString(expected.String(), ""),
+			encodeArmor(input, constants.PGPMessageHeader, headers, false),
+		)
+
+		armored, err := ArmorWithType(input, constants.PGPMessageHeader)
+		if err != nil {
+			t.Fatal("Expected no error while armoring, got:", err)
+		}
+		assert.LessOrEqual(t, len(armored), armoredLength(size, constants.PGPMessageHeader, internal.ArmorHeaders))
+
+		unarmored, err := Unarmor(armored)
+		if err != nil {
+			t.Fatal("Expected no error while unarmoring, got:", err)
+		}
+		assert.Exactly(t, input, unarmored)
+	}
+}
+
+func TestArmorWithTypeAllocations(t *testing.T) {
+	input := bytes.Repeat([]byte{0x42}, 1<<20)
+	allocations := testing.AllocsPerRun(10, func() {
+		_, _ = ArmorWithType(input, constants.PGPMessageHeader)
+	})
+	// The output string and the sorted header keys, the chunk buffer is pooled
+	assert.LessOrEqual(t, allocations, float64(3))
+}
+
+func BenchmarkArmorWithType(b *testing.B) {
+	input := bytes.Repeat([]byte{0x42}, 1<<20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ArmorWithType(input, constants.PGPMessageHeader); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkArmorEncoder armors with the go-crypto encoder, for comparison with BenchmarkArmorWithType.
+func BenchmarkArmorEncoder(b *testing.B) {
+	input := bytes.Repeat([]byte{0x42}, 1<<20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var buffer bytes.Buffer
+		w, err := armor.Encode(&buffer, constants.PGPMessageHeader, internal.ArmorHeaders)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err = w.Write(input); err != nil {
+			b.Fatal(err)
+		}
+		if err = w.Close(); err != nil {
+			b.Fatal(err)
+		}
+		_ = buffer.String()
+	}
+}
+
+func TestArmorStream(t *testing.T) {
+	input := bytes.Repeat([]byte{0x42, 0x43, 0x44}, 100000)
+
+	var armored bytes.Buffer
+	n, err := ArmorStream(&armored, bytes.NewReader(input), constants.PGPMessageHeader)
+	if err != nil {
+		t.Fatal("Expected no error while armoring the stream, got:", err)
+	}
+	assert.Exactly(t, int64(len(input)), n)
+
+	unarmored, err := Unarmor(armored.String())
+	if err != nil {
+		t.Fatal("Expected no error while unarmoring, got:", err)
+	}
+	assert.Exactly(t, input, unarmored)
+
+	binary, armorType, err := UnarmorStream(&armored)
+	if err != nil {
+		t.Fatal("Expected no error while unarmoring the stream, got:", err)
+	}
+	assert.Exactly(t, constants.PGPMessageHeader, armorType)
+	var output bytes.Buffer
+	if _, err = output.ReadFrom(binary); err != nil {
+		t.Fatal("Expected no error while reading the unarmored stream, got:", err)
+	}
+	assert.Exactly(t, input, output.Bytes())
+
+	_, _, err = UnarmorStream(bytes.NewReader(input))
+	assert.Error(t, err)
+}
@@ -0,0 +1,209 @@
+package armor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/internal"
+	"github.com/pkg/errors"
+)
+
+// ErrChecksumMismatch is returned when the CRC24 checksum of armored data does not match the data.
+var ErrChecksumMismatch = errors.New("gopenpgp: armor checksum mismatch")
+
+const (
+	crc24Init = 0xb704ce
+	crc24Poly = 0x1864cfb
+	crc24Mask = 0xffffff
+)
+
+// crc24 updates the OpenPGP checksum, see RFC 4880, section 6.1.
+func crc24(crc uint32, data []byte) uint32 {
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+	return crc
+}
+
+// UnarmorDetails reports how the armored data was unarmored.
+type UnarmorDetails struct {
+	// ArmorType is the type of the armored data, e.g. constants.PGPMessageHeader.
+	ArmorType string
+	// ChecksumPresent is true if the armored data had a CRC24 checksum.
+	ChecksumPresent bool
+	// ChecksumValid is true if the checksum was present and matched the data.
+	ChecksumValid bool
+}
+
+// HasChecksumMismatch returns true if the armored data had a checksum that did not match the data.
+func (details *UnarmorDetails) HasChecksumMismatch() bool {
+	return details.ChecksumPresent && !details.ChecksumValid
+}
+
+// UnarmorReader reads the binary data of armored data, see UnarmorStreamWithChecksumPolicy.
+type UnarmorReader struct {
+	body           io.Reader
+	lines          *checksumLineReader
+	checksumPolicy int
+	crc            uint32
+	details        *UnarmorDetails
+}
+
+// Read reads the binary data. At the end of the data, the checksum is checked
+// according to the checksum policy.
+func (reader *UnarmorReader) Read(b []byte) (n int, err error) {
+	n, err = reader.body.Read(b)
+	reader.crc = crc24(reader.crc, b[:n])
+	if errors.Is(err, io.EOF) && reader.lines.checksumSet {
+		reader.details.ChecksumPresent = true
+		reader.details.ChecksumValid = reader.lines.checksum == reader.crc&crc24Mask
+		if !reader.details.ChecksumValid && reader.checksumPolicy != constants.ARMOR_CHECKSUM_IGNORE {
+			return n, ErrChecksumMismatch
+		}
+	}
+	return n, err
+}
+
+// GetDetails returns the details of the unarmoring.
+// The checksum is only reported once the data has been read entirely.
+func (reader *UnarmorReader) GetDetails() *UnarmorDetails {
+	return reader.details
+}
+
+// UnarmorStreamWithChecksumPolicy returns a reader for the binary data of the armored data read from src.
+// A mismatched CRC24 checksum is handled according to checksumPolicy, one of
+// constants.ARMOR_CHECKSUM_VERIFY or ARMOR_CHECKSUM_IGNORE, when the end of the data is read.
+// The checksum is reported in UnarmorReader.GetDetails().
+func UnarmorStreamWithChecksumPolicy(src io.Reader, checksumPolicy int) (*UnarmorReader, error) {
+	lines := &checksumLineReader{in: bufio.NewReader(src)}
+	block, err := armor.Decode(lines)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopengp: unable to unarmor")
+	}
+	return &UnarmorReader{
+		body:           block.Body,
+		lines:          lines,
+		checksumPolicy: checksumPolicy,
+		crc:            crc24Init,
+		details:        &UnarmorDetails{ArmorType: block.Type},
+	}, nil
+}
+
+// UnarmorWithChecksumPolicy unarmors an armored input into a byte array,
+// handling a mismatched CRC24 checksum according to checksumPolicy, one of
+// constants.ARMOR_CHECKSUM_VERIFY or ARMOR_CHECKSUM_IGNORE.
+func UnarmorWithChecksumPolicy(input string, checksumPolicy int) ([]byte, *UnarmorDetails, error) {
+	reader, err := UnarmorStreamWithChecksumPolicy(strings.NewReader(input), checksumPolicy)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, reader.GetDetails(), errors.Wrap(err, "gopengp: unable to unarmor")
+	}
+	return data, reader.GetDetails(), nil
+}
+
+// checksumLineReader removes the checksum line from armored data and records the checksum,
+// so that it is checked by UnarmorReader rather than by the armor decoder.
+type checksumLineReader struct {
+	in          *bufio.Reader
+	line        []byte
+	inBlock     bool
+	checksum    uint32
+	checksumSet bool
+}
+
+func (reader *checksumLineReader) Read(b []byte) (n int, err error) {
+	for len(reader.line) == 0 {
+		line, readErr := reader.in.ReadBytes('\n')
+		if len(line) == 0 {
+			return 0, readErr
+		}
+		trimmed := bytes.TrimSpace(line)
+		switch {
+		case bytes.HasPrefix(trimmed, []byte("-----BEGIN ")):
+			reader.inBlock = true
+		case bytes.HasPrefix(trimmed, []byte("-----END ")):
+			reader.inBlock = false
+		case reader.inBlock && len(trimmed) == 5 && trimmed[0] == '=':
+			var checksum [3]byte
+			if m, decodeErr := base64.StdEncoding.Decode(checksum[:], trimmed[1:]); decodeErr == nil && m == 3 {
+				reader.checksum = uint32(checksum[0])<<16 | uint32(checksum[1])<<8 | uint32(checksum[2])
+				reader.checksumSet = true
+				continue
+			}
+		}
+		reader.line = line
+	}
+	n = copy(b, reader.line)
+	reader.line = reader.line[n:]
+	return n, nil
+}
+
+// ArmorWithTypeWithoutChecksum armors input with the given armorType,
+// without the CRC24 checksum line, as recommended by RFC 9580 for new data.
+func ArmorWithTypeWithoutChecksum(input []byte, armorType string) (string, error) {
+	return encodeArmor(input, armorType, internal.ArmorHeaders, false), nil
+}
+
+// ArmorStreamWithoutChecksum armors the binary data read from src with the given armorType,
+// and writes the armored data to dst without the CRC24 checksum line.
+// Returns the number of binary bytes read.
+func ArmorStreamWithoutChecksum(dst io.Writer, src io.Reader, armorType string) (int64, error) {
+	trailer := &checksumOmittingWriter{out: dst}
+	w, err := armor.Encode(trailer, armorType, internal.ArmorHeaders)
+	if err != nil {
+		return 0, errors.Wrap(err, "gopengp: unable to encode armoring")
+	}
+	n, err := io.Copy(w, src)
+	if err != nil {
+		return n, errors.Wrap(err, "gopengp: unable to armor stream")
+	}
+	trailer.closing = true
+	if err = w.Close(); err != nil {
+		return n, errors.Wrap(err, "gopengp: unable to close armor writer")
+	}
+	if err = trailer.flush(); err != nil {
+		return n, errors.Wrap(err, "gopengp: unable to close armor writer")
+	}
+	return n, nil
+}
+
+// checksumOmittingWriter forwards the armored data, and buffers the trailer written when
+// the armor writer is closed, in order to remove the checksum line from it.
+type checksumOmittingWriter struct {
+	out     io.Writer
+	closing bool
+	trailer bytes.Buffer
+}
+
+func (w *checksumOmittingWriter) Write(b []byte) (int, error) {
+	if w.closing {
+		return w.trailer.Write(b)
+	}
+	return w.out.Write(b)
+}
+
+func (w *checksumOmittingWriter) flush() error {
+	trailer := w.trailer.Bytes()
+	// The trailer ends with "\n=<checksum>\n-----END <type>-----",
+	// and base64 lines can't start with padding.
+	if i := bytes.LastIndex(trailer, []byte("\n=")); i >= 0 && len(trailer) >= i+7 {
+		trailer = append(trailer[:i+1:i+1], trailer[i+7:]...)
+	}
+	_, err := w.out.Write(trailer)
+	return err
+}
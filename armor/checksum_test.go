@@ -0,0 +1,80 @@
+package armor
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+var checksumLine = regexp.MustCompile(`(?m)^=[A-Za-z0-9+/]{4}\n`)
+
+func TestArmorWithoutChecksum(t *testing.T) {
+	for _, size := range []int{0, 1, 48, 49, 1000} {
+		input := bytes.Repeat([]byte{0x42}, size)
+
+		armored, err := ArmorWithTypeWithoutChecksum(input, constants.PGPMessageHeader)
+		if err != nil {
+			t.Fatal("Expected no error while armoring, got:", err)
+		}
+		assert.False(t, checksumLine.MatchString(armored))
+		assert.True(t, strings.HasSuffix(armored, "\n-----END PGP MESSAGE-----"))
+
+		unarmored, details, err := UnarmorWithChecksumPolicy(armored, constants.ARMOR_CHECKSUM_VERIFY)
+		if err != nil {
+			t.Fatal("Expected no error while unarmoring, got:", err)
+		}
+		assert.Exactly(t, input, unarmored)
+		assert.False(t, details.ChecksumPresent)
+		assert.False(t, details.HasChecksumMismatch())
+
+		unarmored, err = Unarmor(armored)
+		if err != nil {
+			t.Fatal("Expected no error while unarmoring, got:", err)
+		}
+		assert.Exactly(t, input, unarmored)
+	}
+}
+
+func TestUnarmorWithChecksumPolicy(t *testing.T) {
+	input := bytes.Repeat([]byte{0x42, 0x43, 0x44}, 100)
+	armored, err := ArmorWithType(input, constants.PGPMessageHeader)
+	if err != nil {
+		t.Fatal("Expected no error while armoring, got:", err)
+	}
+
+	unarmored, details, err := UnarmorWithChecksumPolicy(armored, constants.ARMOR_CHECKSUM_VERIFY)
+	if err != nil {
+		t.Fatal("Expected no error while unarmoring, got:", err)
+	}
+	assert.Exactly(t, input, unarmored)
+	assert.Exactly(t, constants.PGPMessageHeader, details.ArmorType)
+	assert.True(t, details.ChecksumPresent)
+	assert.True(t, details.ChecksumValid)
+
+	mismatched := checksumLine.ReplaceAllString(armored, "=AAAA\n")
+	_, details, err = UnarmorWithChecksumPolicy(mismatched, constants.ARMOR_CHECKSUM_VERIFY)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.True(t, details.HasChecksumMismatch())
+
+	unarmored, details, err = UnarmorWithChecksumPolicy(mismatched, constants.ARMOR_CHECKSUM_IGNORE)
+	if err != nil {
+		t.Fatal("Expected no error while unarmoring, got:", err)
+	}
+	assert.Exactly(t, input, unarmored)
+	assert.True(t, details.HasChecksumMismatch())
+
+	reader, err := UnarmorStreamWithChecksumPolicy(strings.NewReader(mismatched), constants.ARMOR_CHECKSUM_IGNORE)
+	if err != nil {
+		t.Fatal("Expected no error while unarmoring the stream, got:", err)
+	}
+	var output bytes.Buffer
+	if _, err = output.ReadFrom(reader); err != nil {
+		t.Fatal("Expected no error while reading the unarmored stream, got:", err)
+	}
+	assert.Exactly(t, input, output.Bytes())
+	assert.True(t, reader.GetDetails().HasChecksumMismatch())
+}
@@ -10,6 +10,12 @@ const (
 	AES256    = "aes256"
 )
 
+// Legacy cipher names, only used to report the cipher of decrypted messages.
+const (
+	IDEA     = "idea"
+	Blowfish = "blowfish"
+)
+
 const (
 	SIGNATURE_OK          int = 0
 	SIGNATURE_NOT_SIGNED  int = 1
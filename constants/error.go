@@ -0,0 +1,13 @@
+package constants
+
+// Error codes returned by helper.GetErrorCode,
+// for the mobile callers which can't inspect the errors.
+const (
+	ERROR_NONE             int = 0
+	ERROR_UNKNOWN          int = 1
+	ERROR_WRONG_PASSPHRASE int = 2
+	ERROR_NO_MATCHING_KEY  int = 3
+	ERROR_BAD_SIGNATURE    int = 4
+	ERROR_CORRUPTED_DATA   int = 5
+	ERROR_CANCELED         int = 6
+)
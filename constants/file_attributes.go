@@ -0,0 +1,9 @@
+package constants
+
+// Names of the signature notations recording the attributes of an encrypted file.
+const (
+	FileModeNotationName    = "file-mode@gopenpgp.org"
+	FileUIDNotationName     = "file-uid@gopenpgp.org"
+	FileGIDNotationName     = "file-gid@gopenpgp.org"
+	FileModTimeNotationName = "file-mtime@gopenpgp.org"
+)
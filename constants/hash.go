@@ -0,0 +1,9 @@
+package constants
+
+// Hash algorithm names.
+const (
+	SHA224 = "sha224"
+	SHA256 = "sha256"
+	SHA384 = "sha384"
+	SHA512 = "sha512"
+)
@@ -0,0 +1,16 @@
+package constants
+
+// Key flags, as defined in RFC 4880 section 5.2.3.21.
+// They can be combined to describe the capabilities of a key.
+const (
+	// KEY_FLAG_CERTIFY means that the key can certify other keys.
+	KEY_FLAG_CERTIFY int = 0x01
+	// KEY_FLAG_SIGN means that the key can sign data.
+	KEY_FLAG_SIGN int = 0x02
+	// KEY_FLAG_ENCRYPT_COMMUNICATIONS means that the key can encrypt communications.
+	KEY_FLAG_ENCRYPT_COMMUNICATIONS int = 0x04
+	// KEY_FLAG_ENCRYPT_STORAGE means that the key can encrypt storage.
+	KEY_FLAG_ENCRYPT_STORAGE int = 0x08
+	// KEY_FLAG_AUTHENTICATE means that the key can be used for authentication.
+	KEY_FLAG_AUTHENTICATE int = 0x20
+)
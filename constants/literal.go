@@ -0,0 +1,5 @@
+package constants
+
+// ForYourEyesOnlyFilename is the special file name of the literal data packet
+// marking a message as for your eyes only: it should be displayed, but not saved to disk.
+const ForYourEyesOnlyFilename = "_CONSOLE"
@@ -0,0 +1,9 @@
+package constants
+
+// Operation names reported to metrics collectors.
+const (
+	OperationEncrypt = "encrypt"
+	OperationDecrypt = "decrypt"
+	OperationSign    = "sign"
+	OperationVerify  = "verify"
+)
@@ -0,0 +1,59 @@
+package constants
+
+// Policies for messages without integrity protection,
+// i.e. encrypted with a Symmetrically Encrypted Data packet instead of a SEIPD packet.
+const (
+	// MDC_POLICY_FAIL rejects messages without integrity protection.
+	MDC_POLICY_FAIL int = 0
+	// MDC_POLICY_WARN decrypts messages without integrity protection,
+	// and reports a warning in the decryption details.
+	MDC_POLICY_WARN int = 1
+	// MDC_POLICY_ACCEPT decrypts messages without integrity protection.
+	MDC_POLICY_ACCEPT int = 2
+)
+
+// Policies for the release of the plaintext of signed messages decrypted as a stream,
+// before the signature, which can only be checked once the message has been read entirely, is verified.
+const (
+	// PLAINTEXT_RELEASE_SILENT releases the plaintext while it is being decrypted.
+	PLAINTEXT_RELEASE_SILENT int = 0
+	// PLAINTEXT_RELEASE_WARN releases the plaintext while it is being decrypted,
+	// and reports a warning in the decryption details.
+	PLAINTEXT_RELEASE_WARN int = 1
+	// PLAINTEXT_RELEASE_BUFFER buffers the plaintext until the signature is verified,
+	// and releases it only if the signature is valid.
+	PLAINTEXT_RELEASE_BUFFER int = 2
+)
+
+// Policies for armored data whose CRC24 checksum does not match the data.
+// Armored data without checksum, as recommended by RFC 9580, is always accepted.
+const (
+	// ARMOR_CHECKSUM_VERIFY rejects armored data with a mismatched checksum.
+	ARMOR_CHECKSUM_VERIFY int = 0
+	// ARMOR_CHECKSUM_IGNORE accepts armored data with a mismatched checksum,
+	// and reports the mismatch in the unarmoring details.
+	ARMOR_CHECKSUM_IGNORE int = 1
+)
+
+// Policies for the packets of keys with unknown tags or unsupported, e.g. experimental or private, algorithms.
+const (
+	// UNKNOWN_PACKET_FAIL rejects keys with unknown packets.
+	UNKNOWN_PACKET_FAIL int = 0
+	// UNKNOWN_PACKET_WARN skips the unknown packets, and reports a warning for each of them.
+	UNKNOWN_PACKET_WARN int = 1
+	// UNKNOWN_PACKET_PRESERVE skips the unknown packets, reports a warning for each of them,
+	// and preserves them verbatim when serializing the key.
+	UNKNOWN_PACKET_PRESERVE int = 2
+)
+
+// Strictness levels of the decryption and verification policies.
+const (
+	// STRICTNESS_STANDARD tolerates none of the legacy message properties,
+	// and skips the unexpected packets of messages.
+	STRICTNESS_STANDARD int = 0
+	// STRICTNESS_STRICT additionally rejects the unexpected packets of messages,
+	// i.e. packets out of order before the encrypted data, and detached signatures made of several packets.
+	STRICTNESS_STRICT int = 1
+	// STRICTNESS_INTEROP tolerates all the legacy message properties, reporting them as warnings.
+	STRICTNESS_INTEROP int = 2
+)
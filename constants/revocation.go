@@ -0,0 +1,16 @@
+package constants
+
+// Reasons for revocation, as defined in RFC 4880 section 5.2.3.23.
+const (
+	// REVOCATION_REASON_NONE is used when no reason was specified.
+	REVOCATION_REASON_NONE int = 0
+	// REVOCATION_REASON_SUPERSEDED means that the key was replaced by a new one.
+	REVOCATION_REASON_SUPERSEDED int = 1
+	// REVOCATION_REASON_COMPROMISED means that the key material was compromised:
+	// signatures made before the revocation can't be trusted either.
+	REVOCATION_REASON_COMPROMISED int = 2
+	// REVOCATION_REASON_RETIRED means that the key is no longer used.
+	REVOCATION_REASON_RETIRED int = 3
+	// REVOCATION_REASON_USER_ID_INVALID means that the user ID is no longer valid.
+	REVOCATION_REASON_USER_ID_INVALID int = 32
+)
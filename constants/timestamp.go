@@ -0,0 +1,5 @@
+package constants
+
+// TimestampNotationName is the name of the unhashed signature notation
+// holding an RFC 3161 timestamp token over the signature.
+const TimestampNotationName = "rfc3161-timestamp@gopenpgp.org"
@@ -0,0 +1,113 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"os"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sys/cpu"
+)
+
+// startupGODEBUG is the GODEBUG environment variable at startup, when the Go runtime
+// reads the CPU options: changing it afterwards has no effect on the instructions in use.
+var startupGODEBUG = os.Getenv("GODEBUG")
+
+// HardwareAcceleration reports the hardware acceleration used by the cryptographic primitives.
+// The AES and SHA instructions are used by the Go standard library, for all the ciphers and modes,
+// when the CPU supports them, unless they are disabled at startup, e.g. with GODEBUG=cpu.aes=off.
+type HardwareAcceleration struct {
+	// Architecture is the architecture of the running program, e.g. "amd64".
+	Architecture string
+	// AES is true if AES instructions are used: AES-NI on amd64, ARMv8 AES on arm64.
+	AES bool
+	// SHA is true if SHA-1 and SHA-256 instructions are used, as reported on arm64.
+	SHA bool
+	// CarrylessMultiplication is true if the CPU has carry-less multiplication instructions,
+	// PCLMULQDQ on amd64 and PMULL on arm64, used by the vectorized GCM implementation.
+	CarrylessMultiplication bool
+	// VectorizedGCM is true if AEAD encryption in GCM mode, e.g. with SessionKey.EncryptAEAD,
+	// uses the vectorized implementation. OCB and EAX encrypt block by block,
+	// with the AES instructions if available.
+	VectorizedGCM bool
+	// VectorizedGCMDisabled is true if the vectorized GCM implementation was disabled with DisableVectorizedGCM.
+	VectorizedGCMDisabled bool
+}
+
+// GetHardwareAcceleration returns the hardware acceleration used by the cryptographic primitives,
+// to diagnose performance regressions or constant-time requirements.
+// The CPU features are reported as enabled at startup.
+func GetHardwareAcceleration() *HardwareAcceleration {
+	pgp.lock.RLock()
+	disabled := pgp.vectorizedGCMDisabled
+	pgp.lock.RUnlock()
+
+	acceleration := &HardwareAcceleration{Architecture: runtime.GOARCH, VectorizedGCMDisabled: disabled}
+	switch runtime.GOARCH {
+	case "amd64", "386":
+		acceleration.AES = cpu.X86.HasAES && !isCPUFeatureOff(startupGODEBUG, "aes")
+		acceleration.CarrylessMultiplication = cpu.X86.HasPCLMULQDQ && !isCPUFeatureOff(startupGODEBUG, "pclmulqdq")
+	case "arm64":
+		acceleration.AES = cpu.ARM64.HasAES && !isCPUFeatureOff(startupGODEBUG, "aes")
+		acceleration.SHA = cpu.ARM64.HasSHA1 && cpu.ARM64.HasSHA2 &&
+			!isCPUFeatureOff(startupGODEBUG, "sha1") && !isCPUFeatureOff(startupGODEBUG, "sha2")
+		acceleration.CarrylessMultiplication = cpu.ARM64.HasPMULL && !isCPUFeatureOff(startupGODEBUG, "pmull")
+	}
+	acceleration.VectorizedGCM = acceleration.AES && acceleration.CarrylessMultiplication && !disabled
+	return acceleration
+}
+
+// DisableVectorizedGCM makes AEAD encryption in GCM mode with the session key API,
+// e.g. SessionKey.EncryptAEAD, use the generic GCM implementation of the Go standard library,
+// e.g. to compare performance or to rule out hardware issues.
+// It only affects GCM: the AES instructions, used by all the ciphers and modes, and the SHA instructions
+// can't be disabled at runtime, the program needs to be started with e.g. GODEBUG=cpu.aes=off.
+func DisableVectorizedGCM() {
+	pgp.lock.Lock()
+	defer pgp.lock.Unlock()
+
+	pgp.vectorizedGCMDisabled = true
+}
+
+// EnableVectorizedGCM restores the vectorized GCM implementation, if the CPU supports it.
+func EnableVectorizedGCM() {
+	pgp.lock.Lock()
+	defer pgp.lock.Unlock()
+
+	pgp.vectorizedGCMDisabled = false
+}
+
+// isCPUFeatureOff returns true if the CPU feature is disabled in the GODEBUG value.
+func isCPUFeatureOff(godebug, feature string) bool {
+	off := false
+	for _, option := range strings.Split(godebug, ",") {
+		switch option {
+		case "cpu.all=off", "cpu." + feature + "=off":
+			off = true
+		case "cpu." + feature + "=on":
+			off = false
+		}
+	}
+	return off
+}
+
+// genericBlock hides the optimized interfaces of a block cipher,
+// so that the modes built on it use their generic implementation.
+type genericBlock struct {
+	block cipher.Block
+}
+
+func (b genericBlock) BlockSize() int          { return b.block.BlockSize() }
+func (b genericBlock) Encrypt(dst, src []byte) { b.block.Encrypt(dst, src) }
+func (b genericBlock) Decrypt(dst, src []byte) { b.block.Decrypt(dst, src) }
+
+func newGCM(block cipher.Block) (cipher.AEAD, error) {
+	pgp.lock.RLock()
+	disabled := pgp.vectorizedGCMDisabled
+	pgp.lock.RUnlock()
+
+	if disabled {
+		return cipher.NewGCM(genericBlock{block})
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHardwareAcceleration(t *testing.T) {
+	acceleration := GetHardwareAcceleration()
+	assert.Exactly(t, runtime.GOARCH, acceleration.Architecture)
+	assert.False(t, acceleration.VectorizedGCMDisabled)
+	assert.Exactly(t, acceleration.AES && acceleration.CarrylessMultiplication, acceleration.VectorizedGCM)
+
+	key := make([]byte, 32)
+	nonce := make([]byte, 12)
+	aead, err := newAEAD(packet.AEADModeGCM, key)
+	if err != nil {
+		t.Fatal("Expected no error while initializing GCM, got:", err)
+	}
+	sealed := aead.Seal(nil, nonce, []byte(testMessage), nil)
+
+	DisableVectorizedGCM()
+	defer EnableVectorizedGCM()
+	acceleration = GetHardwareAcceleration()
+	assert.True(t, acceleration.VectorizedGCMDisabled)
+	assert.False(t, acceleration.VectorizedGCM)
+
+	aead, err = newAEAD(packet.AEADModeGCM, key)
+	if err != nil {
+		t.Fatal("Expected no error while initializing GCM, got:", err)
+	}
+	assert.Exactly(t, sealed, aead.Seal(nil, nonce, []byte(testMessage), nil))
+}
+
+func TestIsCPUFeatureOff(t *testing.T) {
+	assert.True(t, isCPUFeatureOff("cpu.aes=off,madvdontneed=1", "aes"))
+	assert.False(t, isCPUFeatureOff("cpu.aes=off,madvdontneed=1", "pclmulqdq"))
+
+	assert.False(t, isCPUFeatureOff("cpu.all=off,cpu.aes=on", "aes"))
+	assert.True(t, isCPUFeatureOff("cpu.all=off,cpu.aes=on", "pclmulqdq"))
+}
+
+func TestHardwareAccelerationStartupState(t *testing.T) {
+	defer os.Setenv("GODEBUG", os.Getenv("GODEBUG"))
+
+	before := GetHardwareAcceleration()
+	_ = os.Setenv("GODEBUG", "cpu.all=off")
+	assert.Exactly(t, before, GetHardwareAcceleration())
+}
@@ -70,7 +70,7 @@ func (ap *AttachmentProcessor) Finish() (*PGPSplitMessage, error) {
 // newAttachmentProcessor creates an AttachmentProcessor which can be used to encrypt
 // a file. It takes an estimatedSize and fileName as hints about the file.
 func (keyRing *KeyRing) newAttachmentProcessor(
-	estimatedSize int, filename string, isBinary bool, modTime uint32, garbageCollector int, //nolint:unparam
+	estimatedSize int, filename string, isBinary bool, modTime uint32, garbageCollector int, aead bool, //nolint:unparam
 ) (*AttachmentProcessor, error) {
 	attachmentProc := &AttachmentProcessor{}
 	// You could also add these one at a time if needed.
@@ -86,6 +86,7 @@ func (keyRing *KeyRing) newAttachmentProcessor(
 	config := &packet.Config{
 		DefaultCipher: packet.CipherAES256,
 		Time:          getTimeGenerator(),
+		Rand:          getRandomSource(),
 	}
 
 	reader, writer := io.Pipe()
@@ -105,7 +106,11 @@ func (keyRing *KeyRing) newAttachmentProcessor(
 
 	var ew io.WriteCloser
 	var encryptErr error
-	ew, encryptErr = openpgp.Encrypt(writer, keyRing.entities, nil, hints, config)
+	if aead {
+		ew, encryptErr = keyRing.encryptSplitAEAD(writer, writer, hints, config)
+	} else {
+		ew, encryptErr = openpgp.Encrypt(writer, keyRing.entities, nil, hints, config)
+	}
 	if encryptErr != nil {
 		return nil, errors.Wrap(encryptErr, "gopengpp: unable to encrypt attachment")
 	}
@@ -130,6 +135,7 @@ func (keyRing *KeyRing) EncryptAttachment(message *PlainMessage, filename string
 		message.IsBinary(),
 		message.Time,
 		-1,
+		false,
 	)
 	if err != nil {
 		return nil, err
@@ -149,7 +155,70 @@ func (keyRing *KeyRing) EncryptAttachment(message *PlainMessage, filename string
 func (keyRing *KeyRing) NewLowMemoryAttachmentProcessor(
 	estimatedSize int, filename string,
 ) (*AttachmentProcessor, error) {
-	return keyRing.newAttachmentProcessor(estimatedSize, filename, true, uint32(GetUnixTime()), 1<<20)
+	return keyRing.newAttachmentProcessor(estimatedSize, filename, true, uint32(GetUnixTime()), 1<<20, false)
+}
+
+// NewLowMemoryAttachmentProcessorWithNonStandardAEAD creates an AttachmentProcessor as NewLowMemoryAttachmentProcessor,
+// but the data packet is a Symmetrically Encrypted Integrity Protected Data packet version 2 (AEAD with OCB).
+// All the keys of the keyring must advertise the SEIPDv2 feature, otherwise an error is returned.
+//
+// The output is NOT conformant with RFC 9580: the session key is encrypted with version 3 key packets,
+// as go-crypto can't write version 6 ones, while section 5.1 requires version 6 key packets
+// before SEIPDv2 packets. The attachment can only be decrypted by DecryptAttachment
+// and by implementations accepting this pairing, and it must only be used between such peers.
+func (keyRing *KeyRing) NewLowMemoryAttachmentProcessorWithNonStandardAEAD(
+	estimatedSize int, filename string,
+) (*AttachmentProcessor, error) {
+	return keyRing.newAttachmentProcessor(estimatedSize, filename, true, uint32(GetUnixTime()), 1<<20, true)
+}
+
+// encryptSplitAEAD works as openpgp.EncryptSplit, but always encrypts the data
+// with a Symmetrically Encrypted Integrity Protected Data packet version 2 and the cipher of the config,
+// if all the keys support it. The key packets are version 3 ones, which is not conformant.
+// The key packets are written to keyPacketWriter before returning.
+func (keyRing *KeyRing) encryptSplitAEAD(
+	keyPacketWriter, dataPacketWriter io.Writer, hints *openpgp.FileHints, config *packet.Config,
+) (io.WriteCloser, error) {
+	if err := checkSEIPDv2Support(keyRing.entities); err != nil {
+		return nil, err
+	}
+	sk, err := GenerateSessionKeyAlgo(getAlgo(config.Cipher()))
+	if err != nil {
+		return nil, err
+	}
+	keyPacket, err := keyRing.EncryptSessionKey(sk)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = keyPacketWriter.Write(keyPacket); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to write key packet")
+	}
+
+	aeadConfig := *config
+	aeadConfig.AEADConfig = &packet.AEADConfig{DefaultMode: packet.AEADModeOCB}
+	encryptWriter, _, err := encryptStreamWithSessionKeyAndConfig(
+		hints.IsBinary,
+		hints.FileName,
+		uint32(hints.ModTime.Unix()),
+		dataPacketWriter,
+		sk,
+		nil,
+		&aeadConfig,
+	)
+	return encryptWriter, err
+}
+
+// checkSEIPDv2Support returns an error if a key doesn't advertise the SEIPDv2 feature
+// in the self-signature of its primary identity.
+func checkSEIPDv2Support(entities openpgp.EntityList) error {
+	for _, entity := range entities {
+		identity := entity.PrimaryIdentity()
+		if identity == nil || identity.SelfSignature == nil || !identity.SelfSignature.SEIPDv2 {
+			return errors.New("gopenpgp: the key " + keyIDToHex(entity.PrimaryKey.KeyId) +
+				" doesn't support SEIPDv2 packets")
+		}
+	}
+	return nil
 }
 
 // DecryptAttachment takes a PGPSplitMessage, containing a session key packet and symmetrically encrypted data
@@ -171,7 +240,7 @@ func (keyRing *KeyRing) DecryptAttachment(message *PGPSplitMessage) (*PlainMessa
 	}
 
 	decrypted := md.UnverifiedBody
-	b, err := ioutil.ReadAll(decrypted)
+	b, err := readAllPlaintext(decrypted)
 	if err != nil {
 		return nil, errors.Wrap(err, "gopengpp: unable to read attachment body")
 	}
@@ -73,6 +73,21 @@ func (ap *ManualAttachmentProcessor) Finish() error {
 // otherwise Finish() will return an error.
 func (keyRing *KeyRing) NewManualAttachmentProcessor(
 	estimatedSize int, filename string, dataBuffer []byte,
+) (*ManualAttachmentProcessor, error) {
+	return keyRing.newManualAttachmentProcessor(estimatedSize, filename, dataBuffer, false)
+}
+
+// NewManualAttachmentProcessorWithNonStandardAEAD creates an AttachmentProcessor as NewManualAttachmentProcessor,
+// but the data packet is a Symmetrically Encrypted Integrity Protected Data packet version 2 (AEAD with OCB),
+// with the same requirements and non-conformant key packets as NewLowMemoryAttachmentProcessorWithNonStandardAEAD.
+func (keyRing *KeyRing) NewManualAttachmentProcessorWithNonStandardAEAD(
+	estimatedSize int, filename string, dataBuffer []byte,
+) (*ManualAttachmentProcessor, error) {
+	return keyRing.newManualAttachmentProcessor(estimatedSize, filename, dataBuffer, true)
+}
+
+func (keyRing *KeyRing) newManualAttachmentProcessor(
+	estimatedSize int, filename string, dataBuffer []byte, aead bool, //nolint:unparam
 ) (*ManualAttachmentProcessor, error) {
 	if len(dataBuffer) == 0 {
 		return nil, errors.New("gopenpgp: can't give a nil or empty buffer to process the attachment")
@@ -96,6 +111,7 @@ func (keyRing *KeyRing) NewManualAttachmentProcessor(
 	config := &packet.Config{
 		DefaultCipher: packet.CipherAES256,
 		Time:          getTimeGenerator(),
+		Rand:          getRandomSource(),
 	}
 
 	// goroutine that reads the key packet
@@ -128,7 +144,11 @@ func (keyRing *KeyRing) NewManualAttachmentProcessor(
 	// We generate the encrypting writer
 	var ew io.WriteCloser
 	var encryptErr error
-	ew, encryptErr = openpgp.EncryptSplit(keyWriter, dataWriter, keyRing.entities, nil, hints, config)
+	if aead {
+		ew, encryptErr = keyRing.encryptSplitAEAD(keyWriter, dataWriter, hints, config)
+	} else {
+		ew, encryptErr = openpgp.EncryptSplit(keyWriter, dataWriter, keyRing.entities, nil, hints, config)
+	}
 	if encryptErr != nil {
 		return nil, errors.Wrap(encryptErr, "gopengpp: unable to encrypt attachment")
 	}
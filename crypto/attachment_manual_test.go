@@ -5,6 +5,7 @@ import (
 	"io"
 	"testing"
 
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
 	"github.com/pkg/errors"
 )
 
@@ -174,3 +175,52 @@ func TestManualAttachmentProcessorEmptyBuffer(t *testing.T) {
 		t.Error("Expected an error while building the attachment processor with an empty buffer got nil")
 	}
 }
+
+func TestManualAttachmentProcessorWithAEAD(t *testing.T) {
+	plaintextBytes := []byte(readTestFile("att_cleartext", false))
+	dataPacket := make([]byte, 2*len(plaintextBytes))
+	_, err := keyRingTestPublic.NewManualAttachmentProcessorWithNonStandardAEAD(
+		len(plaintextBytes),
+		"test.txt",
+		dataPacket,
+	)
+	if err == nil {
+		t.Error("Expected an error while building the attachment processor for a key without the SEIPDv2 feature got nil")
+	}
+
+	privateKeyRing, publicKeyRing := newSEIPDv2KeyRings(t)
+	ap, err := publicKeyRing.NewManualAttachmentProcessorWithNonStandardAEAD(
+		len(plaintextBytes),
+		"test.txt",
+		dataPacket,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while building the attachment processor, got:", err)
+	}
+	if err = ap.Process(plaintextBytes); err != nil {
+		t.Fatal("Expected no error while writing plain data, got:", err)
+	}
+	if err = ap.Finish(); err != nil {
+		t.Fatal("Expected no error while calling finish, got:", err)
+	}
+
+	split := NewPGPSplitMessage(ap.GetKeyPacket(), dataPacket[:ap.GetDataLength()])
+	p, err := packet.NewReader(bytes.NewReader(split.GetBinaryDataPacket())).Next()
+	if err != nil {
+		t.Fatal("Expected no error while parsing the data packet, got:", err)
+	}
+	if seipd, ok := p.(*packet.SymmetricallyEncrypted); !ok || seipd.Version != 2 {
+		t.Fatal("Expected a symmetrically encrypted integrity protected data packet version 2")
+	}
+	sk, err := privateKeyRing.DecryptSessionKey(split.GetBinaryKeyPacket())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the key packet, got:", err)
+	}
+	plainMsg, err := sk.Decrypt(split.GetBinaryDataPacket())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	if !bytes.Equal(plainMsg.GetBinary(), plaintextBytes) {
+		t.Error("Expected the decrypted data to match the plaintext")
+	}
+}
@@ -1,9 +1,11 @@
 package crypto
 
 import (
+	"bytes"
 	"encoding/base64"
 	"testing"
 
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -144,3 +146,72 @@ func TestAttachmentDecryptStatic(t *testing.T) {
 
 	assert.Exactly(t, []byte("PNG"), dec.GetBinary()[1:4])
 }
+
+// newSEIPDv2KeyRings generates a key advertising the SEIPDv2 feature,
+// and returns the keyrings of the private and public keys.
+func newSEIPDv2KeyRings(t *testing.T) (privateKeyRing, publicKeyRing *KeyRing) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating the key, got:", err)
+	}
+	for name, identity := range key.entity.Identities {
+		identity.SelfSignature.SEIPDv2 = true
+		err = identity.SelfSignature.SignUserId(name, key.entity.PrimaryKey, key.entity.PrivateKey, nil)
+		if err != nil {
+			t.Fatal("Expected no error while signing the user ID, got:", err)
+		}
+	}
+	publicKey, err := key.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while extracting the public key, got:", err)
+	}
+	if privateKeyRing, err = NewKeyRing(key); err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	if publicKeyRing, err = NewKeyRing(publicKey); err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	return privateKeyRing, publicKeyRing
+}
+
+func TestAttachmentProcessorWithAEAD(t *testing.T) {
+	plainData := []byte(readTestFile("att_cleartext", false))
+
+	_, err := keyRingTestPublic.NewLowMemoryAttachmentProcessorWithNonStandardAEAD(len(plainData), "test.txt")
+	assert.Error(t, err, "Expected an error for a key without the SEIPDv2 feature")
+
+	privateKeyRing, publicKeyRing := newSEIPDv2KeyRings(t)
+	ap, err := publicKeyRing.NewLowMemoryAttachmentProcessorWithNonStandardAEAD(len(plainData), "test.txt")
+	if err != nil {
+		t.Fatal("Expected no error while building the attachment processor, got:", err)
+	}
+	chunkSize := 1 << 10
+	for offset := 0; offset < len(plainData); offset += chunkSize {
+		end := offset + chunkSize
+		if end > len(plainData) {
+			end = len(plainData)
+		}
+		ap.Process(plainData[offset:end])
+	}
+	encSplit, err := ap.Finish()
+	if err != nil {
+		t.Fatal("Expected no error while finishing the attachment, got:", err)
+	}
+
+	dataPacket, err := packet.NewReader(bytes.NewReader(encSplit.GetBinaryDataPacket())).Next()
+	if err != nil {
+		t.Fatal("Expected no error while parsing the data packet, got:", err)
+	}
+	seipd, ok := dataPacket.(*packet.SymmetricallyEncrypted)
+	if !ok {
+		t.Fatalf("Expected a symmetrically encrypted data packet, got: %T", dataPacket)
+	}
+	assert.Exactly(t, 2, seipd.Version)
+
+	redecData, err := privateKeyRing.DecryptAttachment(encSplit)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting attachment, got:", err)
+	}
+	assert.Exactly(t, plainData, redecData.GetBinary())
+	assert.Exactly(t, "test.txt", redecData.GetFilename())
+}
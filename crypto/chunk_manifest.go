@@ -0,0 +1,194 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"hash"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// chunkManifestHash is the name of the hash function of the chunk digests.
+const chunkManifestHash = "SHA256"
+
+// ChunkDigest is the digest of a chunk of a file, in a ChunkManifest.
+type ChunkDigest struct {
+	// Offset of the chunk in the file.
+	Offset int64 `json:"offset"`
+	// Length of the chunk, the chunk size except for the last chunk.
+	Length int64 `json:"length"`
+	// Hash of the chunk.
+	Hash []byte `json:"hash"`
+}
+
+// ChunkManifest lists the digests of the fixed-size chunks of a file, usually the encrypted file,
+// so that downloaders can verify ranges of the file without downloading it whole.
+// The manifest is signed with KeyRing.SignChunkManifest, and its signature is verified
+// with KeyRing.VerifyChunkManifest before use.
+type ChunkManifest struct {
+	// HashAlgorithm of the chunk digests, SHA256.
+	HashAlgorithm string `json:"hashAlgorithm"`
+	// ChunkSize is the length of the chunks.
+	ChunkSize int64 `json:"chunkSize"`
+	// Chunks are the digests of the chunks, in order.
+	Chunks []*ChunkDigest `json:"chunks"`
+}
+
+// ChunkManifestWriter writes data to an underlying writer, e.g. the ciphertext
+// of an encryption, and computes the ChunkManifest of the data written.
+type ChunkManifestWriter struct {
+	writer    Writer
+	manifest  *ChunkManifest
+	hash      hash.Hash
+	offset    int64
+	chunkFill int64
+}
+
+// NewChunkManifestWriter returns a ChunkManifestWriter for the writer,
+// with chunks of the given size.
+func NewChunkManifestWriter(writer Writer, chunkSize int64) (*ChunkManifestWriter, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("gopenpgp: invalid chunk size")
+	}
+	return &ChunkManifestWriter{
+		writer: writer,
+		manifest: &ChunkManifest{
+			HashAlgorithm: chunkManifestHash,
+			ChunkSize:     chunkSize,
+		},
+		hash: sha256.New(),
+	}, nil
+}
+
+// Write writes the data to the underlying writer, and hashes it.
+func (w *ChunkManifestWriter) Write(b []byte) (int, error) {
+	n, err := w.writer.Write(b)
+	for written := b[:n]; len(written) > 0; {
+		length := len(written)
+		if int64(length) > w.manifest.ChunkSize-w.chunkFill {
+			length = int(w.manifest.ChunkSize - w.chunkFill)
+		}
+		_, _ = w.hash.Write(written[:length])
+		w.chunkFill += int64(length)
+		written = written[length:]
+		if w.chunkFill == w.manifest.ChunkSize {
+			w.appendChunk()
+		}
+	}
+	return n, err
+}
+
+// GetManifest returns the manifest of the data written so far, including the last, partial, chunk.
+func (w *ChunkManifestWriter) GetManifest() *ChunkManifest {
+	manifest := &ChunkManifest{
+		HashAlgorithm: w.manifest.HashAlgorithm,
+		ChunkSize:     w.manifest.ChunkSize,
+		Chunks:        append([]*ChunkDigest(nil), w.manifest.Chunks...),
+	}
+	if w.chunkFill > 0 {
+		manifest.Chunks = append(manifest.Chunks, &ChunkDigest{
+			Offset: w.offset,
+			Length: w.chunkFill,
+			Hash:   w.hash.Sum(nil),
+		})
+	}
+	return manifest
+}
+
+func (w *ChunkManifestWriter) appendChunk() {
+	w.manifest.Chunks = append(w.manifest.Chunks, &ChunkDigest{
+		Offset: w.offset,
+		Length: w.chunkFill,
+		Hash:   w.hash.Sum(nil),
+	})
+	w.offset += w.chunkFill
+	w.chunkFill = 0
+	w.hash.Reset()
+}
+
+// NewChunkManifestFromJSON parses a manifest serialized by ChunkManifest.GetJSON.
+func NewChunkManifestFromJSON(data []byte) (*ChunkManifest, error) {
+	manifest := &ChunkManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to parse the chunk manifest")
+	}
+	if manifest.HashAlgorithm != chunkManifestHash {
+		return nil, errors.New("gopenpgp: unsupported chunk manifest hash algorithm")
+	}
+	if manifest.ChunkSize <= 0 {
+		return nil, errors.New("gopenpgp: invalid chunk size")
+	}
+	for i, chunk := range manifest.Chunks {
+		if chunk == nil || chunk.Offset != int64(i)*manifest.ChunkSize || chunk.Length <= 0 ||
+			chunk.Length > manifest.ChunkSize || (chunk.Length < manifest.ChunkSize && i != len(manifest.Chunks)-1) {
+			return nil, errors.New("gopenpgp: invalid chunk " + strconv.Itoa(i) + " in the chunk manifest")
+		}
+	}
+	return manifest, nil
+}
+
+// GetJSON serializes the manifest in JSON.
+func (manifest *ChunkManifest) GetJSON() ([]byte, error) {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to serialize the chunk manifest")
+	}
+	return data, nil
+}
+
+// GetLength returns the length of the file described by the manifest.
+func (manifest *ChunkManifest) GetLength() int64 {
+	if len(manifest.Chunks) == 0 {
+		return 0
+	}
+	last := manifest.Chunks[len(manifest.Chunks)-1]
+	return last.Offset + last.Length
+}
+
+// VerifyRange verifies a range of the file against the manifest.
+// The offset needs to be the start of a chunk, and the data needs to contain complete chunks.
+func (manifest *ChunkManifest) VerifyRange(offset int64, data []byte) error {
+	if offset < 0 || offset%manifest.ChunkSize != 0 {
+		return errors.New("gopenpgp: the offset is not the start of a chunk")
+	}
+	for index := offset / manifest.ChunkSize; len(data) > 0; index++ {
+		if index >= int64(len(manifest.Chunks)) {
+			return errors.New("gopenpgp: the range exceeds the file length")
+		}
+		chunk := manifest.Chunks[index]
+		if int64(len(data)) < chunk.Length {
+			return errors.New("gopenpgp: the range ends with a partial chunk")
+		}
+		digest := sha256.Sum256(data[:chunk.Length])
+		if !bytes.Equal(digest[:], chunk.Hash) {
+			return errors.New("gopenpgp: chunk " + strconv.FormatInt(index, 10) + " does not match the manifest")
+		}
+		data = data[chunk.Length:]
+	}
+	return nil
+}
+
+// SignChunkManifest signs the JSON serialization of the manifest with the keyring,
+// and returns the serialized manifest and its detached signature.
+func (keyRing *KeyRing) SignChunkManifest(manifest *ChunkManifest) ([]byte, *PGPSignature, error) {
+	data, err := manifest.GetJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := keyRing.SignDetached(NewPlainMessage(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, signature, nil
+}
+
+// VerifyChunkManifest verifies the detached signature of the serialized manifest
+// with the keyring, and parses the manifest if the signature is valid.
+func (keyRing *KeyRing) VerifyChunkManifest(data []byte, signature *PGPSignature, verifyTime int64) (*ChunkManifest, error) {
+	if err := keyRing.VerifyDetached(NewPlainMessage(data), signature, verifyTime); err != nil {
+		return nil, err
+	}
+	return NewChunkManifestFromJSON(data)
+}
@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkManifest(t *testing.T) {
+	var ciphertext bytes.Buffer
+	manifestWriter, err := NewChunkManifestWriter(&ciphertext, 1024)
+	if err != nil {
+		t.Fatal("Expected no error while creating the manifest writer, got:", err)
+	}
+	encryptWriter, err := keyRingTestPublic.EncryptStream(manifestWriter, nil, nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if _, err = encryptWriter.Write(bytes.Repeat([]byte(testMessage), 1000)); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if err = encryptWriter.Close(); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	manifest := manifestWriter.GetManifest()
+	assert.Exactly(t, int64(ciphertext.Len()), manifest.GetLength())
+	assert.Len(t, manifest.Chunks, (ciphertext.Len()+1023)/1024)
+
+	data, signature, err := keyRingTestPrivate.SignChunkManifest(manifest)
+	if err != nil {
+		t.Fatal("Expected no error while signing the manifest, got:", err)
+	}
+	verified, err := keyRingTestPublic.VerifyChunkManifest(data, signature, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while verifying the manifest, got:", err)
+	}
+	assert.Exactly(t, manifest, verified)
+
+	encrypted := ciphertext.Bytes()
+	assert.NoError(t, verified.VerifyRange(0, encrypted))
+	assert.NoError(t, verified.VerifyRange(1024, encrypted[1024:2048]))
+	assert.NoError(t, verified.VerifyRange(1024, encrypted[1024:]))
+	assert.Error(t, verified.VerifyRange(1000, encrypted[1000:2048]))
+	assert.Error(t, verified.VerifyRange(1024, encrypted[1024:2000]))
+
+	corrupted := clone(encrypted[1024:2048])
+	corrupted[10] ^= 1
+	assert.Error(t, verified.VerifyRange(1024, corrupted))
+
+	tampered := bytes.Replace(data, []byte(`"offset":0`), []byte(`"offset":1`), 1)
+	_, err = keyRingTestPublic.VerifyChunkManifest(tampered, signature, GetUnixTime())
+	assert.Error(t, err)
+}
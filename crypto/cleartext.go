@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/internal"
+	"github.com/pkg/errors"
+)
+
+// SignClearText signs the text as a cleartext signed message, see RFC 4880, section 7:
+// the trailing whitespace of each line is trimmed and the line endings are canonicalized,
+// and ClearTextMessage.GetArmored returns the "-----BEGIN PGP SIGNED MESSAGE-----" block.
+func (keyRing *KeyRing) SignClearText(text string) (*ClearTextMessage, error) {
+	message := NewPlainMessageFromString(internal.TrimEachLine(text))
+	signature, err := keyRing.SignDetached(message)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in signing cleartext message")
+	}
+	return NewClearTextMessage(message.GetBinary(), signature.GetBinary()), nil
+}
+
+// VerifyClearText verifies a cleartext signed message, e.g. read with NewClearTextMessageFromArmored,
+// and returns the signed text as a PlainMessage, along with a SignatureVerificationError
+// if the verification fails.
+func (keyRing *KeyRing) VerifyClearText(message *ClearTextMessage, verifyTime int64) (*PlainMessage, error) {
+	plainMessage := NewPlainMessageFromString(internal.TrimEachLine(message.GetString()))
+	signature := NewPGPSignature(message.GetBinarySignature())
+	return plainMessage, keyRing.VerifyDetached(plainMessage, signature, verifyTime)
+}
+
+// dashEscape escapes the lines of the text starting with a dash, see RFC 4880, section 7.1.
+func dashEscape(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "-") {
+			lines[i] = "- " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getClearTextHashHeader returns the value of the Hash armor header for the signature packets,
+// with the default SHA512 if they can't be parsed.
+func getClearTextHashHeader(signature []byte) string {
+	var names []string
+	packets := packet.NewReader(bytes.NewReader(signature))
+	for {
+		p, err := packets.Next()
+		if err != nil {
+			break
+		}
+		sig, ok := p.(*packet.Signature)
+		if !ok {
+			continue
+		}
+		name := strings.ToUpper(hashName(sig.Hash))
+		if !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return strings.ToUpper(constants.SHA512)
+	}
+	return strings.Join(names, ",")
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
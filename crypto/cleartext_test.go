@@ -0,0 +1,41 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerifyClearText(t *testing.T) {
+	text := "-----BEGIN PGP MESSAGE-----\nHello   \n- World!\n"
+
+	clearTextMessage, err := keyRingTestPrivate.SignClearText(text)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	armored, err := clearTextMessage.GetArmored()
+	if err != nil {
+		t.Fatal("Expected no error while armoring, got:", err)
+	}
+	assert.Contains(t, armored, "Hash: SHA512\r\n")
+	assert.Contains(t, armored, "\r\n- -----BEGIN PGP MESSAGE-----\r\n")
+	assert.Contains(t, armored, "\r\n- - World!\r\n")
+
+	parsed, err := NewClearTextMessageFromArmored(armored)
+	if err != nil {
+		t.Fatal("Expected no error while parsing, got:", err)
+	}
+	verified, err := keyRingTestPublic.VerifyClearText(parsed, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while verifying, got:", err)
+	}
+	assert.Exactly(t, "-----BEGIN PGP MESSAGE-----\nHello\n- World!\n", verified.GetString())
+
+	tampered, err := NewClearTextMessageFromArmored(strings.Replace(armored, "Hello", "Hallo", 1))
+	if err != nil {
+		t.Fatal("Expected no error while parsing, got:", err)
+	}
+	_, err = keyRingTestPublic.VerifyClearText(tampered, GetUnixTime())
+	assert.Error(t, err)
+}
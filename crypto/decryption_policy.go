@@ -0,0 +1,643 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	goerrors "errors"
+	"io"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgpErrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+// DecryptionPolicy controls which legacy or insecure message properties
+// are tolerated when decrypting with the ...WithPolicy functions.
+// The default policy, which is also used when the policy is nil, is the strictest one.
+// It is stricter than the functions without a policy, e.g. KeyRing.Decrypt: in particular,
+// signatures made with keys certified by SHA-1 self-signatures, which KeyRing.Decrypt accepts,
+// are reported as insecure unless AllowSHA1SelfSignatures is set.
+// A policy holds no per-message state: along with the decryption keyring, it can be configured once
+// and shared by concurrent decryptions, as long as it is not modified meanwhile.
+type DecryptionPolicy struct {
+	strictness         int
+	mdcPolicy          int
+	hasMDCPolicy       bool
+	allowLegacyCiphers bool
+	allowElGamal       bool
+	allowDSA           bool
+	allowV3Signatures  bool
+	allowLibrePGP      bool
+
+	plaintextReleasePolicy int
+
+	allowSHA1SelfSignatures  bool
+	sha1SelfSignaturesBefore int64
+}
+
+// NewDecryptionPolicy creates a decryption policy with the default, strict, settings.
+func NewDecryptionPolicy() *DecryptionPolicy {
+	return &DecryptionPolicy{
+		strictness: constants.STRICTNESS_STANDARD,
+	}
+}
+
+// WithMDCPolicy sets how messages without integrity protection are handled,
+// one of constants.MDC_POLICY_FAIL (default), MDC_POLICY_WARN or MDC_POLICY_ACCEPT,
+// and returns the policy.
+func (policy *DecryptionPolicy) WithMDCPolicy(mdcPolicy int) *DecryptionPolicy {
+	policy.mdcPolicy = mdcPolicy
+	policy.hasMDCPolicy = true
+	return policy
+}
+
+// WithStrictness sets the strictness level of the policy, one of constants.STRICTNESS_STANDARD
+// (default), STRICTNESS_STRICT or STRICTNESS_INTEROP, and returns the policy.
+// The level is the base of the policy: the other options loosen it, whether they are set
+// before or after it, and they are kept when the level changes.
+// The strict level rejects the packets of messages that are otherwise skipped, i.e. packets
+// before the encrypted data other than key packets, and detached signatures made of several packets,
+// for compliance testing. The interop level tolerates all the legacy properties of messages,
+// as AllowLegacyCiphers, AllowElGamal, AllowDSA, AllowV3Signatures, AllowLibrePGP,
+// AllowSHA1SelfSignatures(0) and WithMDCPolicy(constants.MDC_POLICY_WARN), unless another MDC policy is set.
+// NOTE: no level detects the malformed or unsupported packets and subpackets, which are skipped
+// by the parser. The decryptions and verifications fail if the level is unknown.
+func (policy *DecryptionPolicy) WithStrictness(strictness int) *DecryptionPolicy {
+	policy.strictness = strictness
+	return policy
+}
+
+// WithPlaintextReleasePolicy sets when the plaintext of a signed message decrypted with
+// KeyRing.DecryptStreamWithPolicy is released, and returns the policy.
+// The policy is one of constants.PLAINTEXT_RELEASE_SILENT (default), PLAINTEXT_RELEASE_WARN
+// or PLAINTEXT_RELEASE_BUFFER. With the latter, the first Read of the PlainMessageReader decrypts
+// the whole message in memory, within the memory budget, and fails if the signature is invalid.
+// It only applies if a verification keyring is given.
+func (policy *DecryptionPolicy) WithPlaintextReleasePolicy(plaintextReleasePolicy int) *DecryptionPolicy {
+	policy.plaintextReleasePolicy = plaintextReleasePolicy
+	return policy
+}
+
+// AllowLegacyCiphers allows the decryption of messages encrypted with the legacy
+// CAST5 and 3DES ciphers, and returns the policy.
+// A warning is reported in the decryption details when such a message is decrypted.
+// NOTE: messages encrypted with IDEA or Blowfish can't be decrypted, as these ciphers are not supported.
+func (policy *DecryptionPolicy) AllowLegacyCiphers() *DecryptionPolicy {
+	policy.allowLegacyCiphers = true
+	return policy
+}
+
+// AllowElGamal allows the decryption of messages encrypted to legacy ElGamal keys,
+// and returns the policy. This is insecure, and only meant for the recovery of archived messages.
+// A warning is reported in the decryption details when such a message is decrypted.
+func (policy *DecryptionPolicy) AllowElGamal() *DecryptionPolicy {
+	policy.allowElGamal = true
+	return policy
+}
+
+// AllowDSA allows the verification of embedded signatures made with legacy DSA keys,
+// and returns the policy. Otherwise, such signatures are reported as insecure.
+// A warning is reported in the decryption details when such a signature is verified.
+func (policy *DecryptionPolicy) AllowDSA() *DecryptionPolicy {
+	policy.allowDSA = true
+	return policy
+}
+
+// AllowV3Signatures allows the verification of legacy version 3 signatures
+// with KeyRing.VerifyDetachedWithPolicy, and returns the policy.
+// Version 3 signatures are insecure, and only meant for the verification of archived data:
+// they are accepted with MD5 and SHA-1 hashes.
+// A warning is reported in the verification details when such a signature is verified.
+// NOTE: version 3 keys are not supported, the signatures need to be verified with newer keys.
+func (policy *DecryptionPolicy) AllowV3Signatures() *DecryptionPolicy {
+	policy.allowV3Signatures = true
+	return policy
+}
+
+// AllowLibrePGP allows reading data produced in the LibrePGP formats, e.g. by GnuPG 2.4,
+// and returns the policy: messages encrypted with an AEAD Encrypted Data packet (OCB or EAX),
+// and version 5 keys used for decryption or signing.
+// These formats are not part of the OpenPGP standard, and are reported in the details.
+func (policy *DecryptionPolicy) AllowLibrePGP() *DecryptionPolicy {
+	policy.allowLibrePGP = true
+	return policy
+}
+
+// AllowSHA1SelfSignatures allows SHA-1 in the self-signatures of the signing keys,
+// i.e. the user ID and subkey binding signatures, and returns the policy.
+// If before is not 0, SHA-1 is only allowed in self-signatures created before the given unix time.
+// SHA-1 is always rejected in data signatures, and other hash algorithms weaker than SHA-224
+// are always rejected in self-signatures.
+// A warning is reported in the details when such a key is used.
+func (policy *DecryptionPolicy) AllowSHA1SelfSignatures(before int64) *DecryptionPolicy {
+	policy.allowSHA1SelfSignatures = true
+	policy.sha1SelfSignaturesBefore = before
+	return policy
+}
+
+// check returns an error if the strictness level is unknown.
+func (policy *DecryptionPolicy) check() error {
+	switch policy.strictness {
+	case constants.STRICTNESS_STANDARD, constants.STRICTNESS_STRICT, constants.STRICTNESS_INTEROP:
+		return nil
+	}
+	return errors.New("gopenpgp: unknown strictness level in the decryption policy")
+}
+
+func (policy *DecryptionPolicy) isStrict() bool {
+	return policy.strictness == constants.STRICTNESS_STRICT
+}
+
+func (policy *DecryptionPolicy) isInterop() bool {
+	return policy.strictness == constants.STRICTNESS_INTEROP
+}
+
+func (policy *DecryptionPolicy) getMDCPolicy() int {
+	if policy.hasMDCPolicy {
+		return policy.mdcPolicy
+	}
+	if policy.isInterop() {
+		return constants.MDC_POLICY_WARN
+	}
+	return constants.MDC_POLICY_FAIL
+}
+
+// DecryptionDetails reports the properties of a message decrypted with a DecryptionPolicy,
+// and the insecure properties that were tolerated because of the policy.
+type DecryptionDetails struct {
+	// IntegrityProtected is false if the message had no integrity protection.
+	IntegrityProtected bool
+	// LibrePGP is true if the message was in a LibrePGP format, i.e. encrypted with an
+	// AEAD Encrypted Data packet or to a version 5 key.
+	LibrePGP bool
+	// DecryptionKeyFingerprint is the hex fingerprint of the key, or subkey, used to decrypt the session key.
+	DecryptionKeyFingerprint string
+	// KeyRingIndex is the index of the keyring that decrypted the session key,
+	// when decrypting with OrderedKeyRings, and 0 otherwise.
+	KeyRingIndex int
+	// CachedSessionKey is true if the message was decrypted with a cached session key,
+	// and false if the session key was decrypted from a key packet.
+	CachedSessionKey bool
+	// Cipher is the name of the symmetric cipher the message was encrypted with, e.g. constants.AES256.
+	Cipher string
+	// Warnings describes the insecure properties of the message that were tolerated.
+	Warnings []string
+}
+
+// IsIntegrityProtected returns false if the message had no integrity protection.
+func (details *DecryptionDetails) IsIntegrityProtected() bool {
+	return details.IntegrityProtected
+}
+
+// IsLibrePGP returns true if the message was in a LibrePGP format.
+func (details *DecryptionDetails) IsLibrePGP() bool {
+	return details.LibrePGP
+}
+
+// GetDecryptionKeyFingerprint returns the hex fingerprint of the key, or subkey,
+// used to decrypt the session key.
+func (details *DecryptionDetails) GetDecryptionKeyFingerprint() string {
+	return details.DecryptionKeyFingerprint
+}
+
+// GetKeyRingIndex returns the index of the keyring that decrypted the session key,
+// when decrypting with OrderedKeyRings.
+func (details *DecryptionDetails) GetKeyRingIndex() int {
+	return details.KeyRingIndex
+}
+
+// IsCachedSessionKey returns true if the message was decrypted with a cached session key.
+func (details *DecryptionDetails) IsCachedSessionKey() bool {
+	return details.CachedSessionKey
+}
+
+// GetCipher returns the name of the symmetric cipher the message was encrypted with.
+func (details *DecryptionDetails) GetCipher() string {
+	return details.Cipher
+}
+
+// HasWarnings returns true if insecure properties of the message were tolerated.
+func (details *DecryptionDetails) HasWarnings() bool {
+	return len(details.Warnings) > 0
+}
+
+func (details *DecryptionDetails) addWarning(warning string) {
+	details.Warnings = append(details.Warnings, warning)
+}
+
+// GetDecryptionDetails returns the details of the decryption,
+// if the message was decrypted with a DecryptionPolicy, or nil otherwise.
+func (msg *PlainMessage) GetDecryptionDetails() *DecryptionDetails {
+	return msg.decryptionDetails
+}
+
+// GetDecryptionDetails returns the details of the decryption,
+// if the message was decrypted with a DecryptionPolicy, or nil otherwise.
+func (msg *PlainMessageReader) GetDecryptionDetails() *DecryptionDetails {
+	return msg.decryptionDetails
+}
+
+// DecryptWithPolicy decrypts encrypted string using pgp keys, returning a PlainMessage.
+// The decryption is restricted by the given policy, and the tolerated insecure properties of the message
+// are reported in PlainMessage.GetDecryptionDetails().
+// * message    : The encrypted input as a PGPMessage
+// * verifyKey  : Public key for signature verification (optional)
+// * verifyTime : Time at verification (necessary only if verifyKey is not nil)
+// * policy     : The decryption policy, the default policy is used if nil.
+func (keyRing *KeyRing) DecryptWithPolicy(
+	message *PGPMessage, verifyKey *KeyRing, verifyTime int64, policy *DecryptionPolicy,
+) (*PlainMessage, error) {
+	if policy == nil {
+		policy = NewDecryptionPolicy()
+	}
+	return decryptWithPolicy(message, policy.keyRingDecrypter(keyRing), verifyKey, verifyTime, policy)
+}
+
+// DecryptStreamWithPolicy is used to decrypt a pgp message as a Reader.
+// It takes a reader for the message data
+// and returns a PlainMessageReader for the plaintext data.
+// The decryption is restricted by the given policy, and the tolerated insecure properties of the message
+// are reported in PlainMessageReader.GetDecryptionDetails().
+// If verifyKeyRing is not nil, PlainMessageReader.VerifySignature() will
+// verify the embedded signature with the given key ring and verification time.
+func (keyRing *KeyRing) DecryptStreamWithPolicy(
+	message Reader,
+	verifyKeyRing *KeyRing,
+	verifyTime int64,
+	policy *DecryptionPolicy,
+) (plainMessage *PlainMessageReader, err error) {
+	if policy == nil {
+		policy = NewDecryptionPolicy()
+	}
+	return decryptStreamReaderWithPolicy(message, policy.keyRingDecrypter(keyRing), verifyKeyRing, verifyTime, policy)
+}
+
+// keyPacketDecrypter decrypts one of the key packets of a message,
+// and returns it along with the key used. It may report how the key was found in the details.
+// The key is nil if the session key was not decrypted from a key packet.
+type keyPacketDecrypter func(
+	keyPackets []*packet.EncryptedKey, details *DecryptionDetails,
+) (*packet.EncryptedKey, *openpgp.Key, error)
+
+func (policy *DecryptionPolicy) keyRingDecrypter(keyRing *KeyRing) keyPacketDecrypter {
+	return func(keyPackets []*packet.EncryptedKey, _ *DecryptionDetails) (*packet.EncryptedKey, *openpgp.Key, error) {
+		return policy.decryptKeyPackets(keyPackets, keyRing)
+	}
+}
+
+func decryptWithPolicy(
+	message *PGPMessage,
+	decryptKeyPackets keyPacketDecrypter,
+	verifyKey *KeyRing,
+	verifyTime int64,
+	policy *DecryptionPolicy,
+) (*PlainMessage, error) {
+	messageDetails, details, err := decryptStreamWithPolicy(
+		bytes.NewReader(message.GetBinary()),
+		decryptKeyPackets,
+		verifyKey,
+		verifyTime,
+		policy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := readAllPlaintext(messageDetails.UnverifiedBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in reading message body")
+	}
+
+	if verifyKey != nil {
+		processSignatureExpiration(messageDetails, verifyTime)
+		err = verifyDetailsSignature(messageDetails, verifyKey, nil)
+		if err == nil {
+			err = policy.checkSignature(messageDetails, details)
+		}
+	}
+
+	return &PlainMessage{
+		Data:              body,
+		TextType:          !messageDetails.LiteralData.IsBinary,
+		Filename:          messageDetails.LiteralData.FileName,
+		Time:              messageDetails.LiteralData.Time,
+		decryptionDetails: details,
+	}, err
+}
+
+func decryptStreamReaderWithPolicy(
+	message Reader,
+	decryptKeyPackets keyPacketDecrypter,
+	verifyKeyRing *KeyRing,
+	verifyTime int64,
+	policy *DecryptionPolicy,
+) (*PlainMessageReader, error) {
+	messageDetails, details, err := decryptStreamWithPolicy(
+		message,
+		decryptKeyPackets,
+		verifyKeyRing,
+		verifyTime,
+		policy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PlainMessageReader{
+		details:           messageDetails,
+		verifyKeyRing:     verifyKeyRing,
+		verifyTime:        verifyTime,
+		decryptionDetails: details,
+		decryptionPolicy:  policy,
+	}, nil
+}
+
+// decryptStreamWithPolicy reads the key packets and the encrypted data packet of the message,
+// checks them against the policy, and decrypts the data packet with the session key
+// of the first key packet that can be decrypted.
+func decryptStreamWithPolicy(
+	messageReader io.Reader,
+	decryptKeyPackets keyPacketDecrypter,
+	verifyKey *KeyRing,
+	verifyTime int64,
+	policy *DecryptionPolicy,
+) (*openpgp.MessageDetails, *DecryptionDetails, error) {
+	if err := policy.check(); err != nil {
+		return nil, nil, err
+	}
+	var keyPackets []*packet.EncryptedKey
+	var dataPacket packet.EncryptedDataPacket
+	packets := packet.NewReader(messageReader)
+
+Loop:
+	for {
+		p, err := packets.Next()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "gopenpgp: error in reading message")
+		}
+		switch p := p.(type) {
+		case *packet.EncryptedKey:
+			keyPackets = append(keyPackets, p)
+		case *packet.SymmetricallyEncrypted:
+			dataPacket = p
+			break Loop
+		case *packet.AEADEncrypted:
+			dataPacket = p
+			break Loop
+		case *packet.Compressed, *packet.LiteralData, *packet.OnePassSignature:
+			return nil, nil, errors.New("gopenpgp: message is not encrypted")
+		case *packet.SymmetricKeyEncrypted:
+		default:
+			if policy.isStrict() {
+				return nil, nil, errors.New("gopenpgp: unexpected packet before the encrypted data")
+			}
+		}
+	}
+
+	details, err := policy.checkDataPacket(dataPacket)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ek, key, err := decryptKeyPackets(keyPackets, details)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key != nil {
+		details.DecryptionKeyFingerprint = hex.EncodeToString(key.PublicKey.Fingerprint)
+		if key.PublicKey.PubKeyAlgo == packet.PubKeyAlgoElGamal {
+			details.addWarning("message is encrypted to a legacy ElGamal key")
+		}
+		if key.PublicKey.Version == 5 {
+			details.LibrePGP = true
+			details.addWarning("message is encrypted to a LibrePGP version 5 key")
+		}
+	}
+
+	if err = policy.checkCipher(ek.CipherFunc, details); err != nil {
+		return nil, nil, err
+	}
+
+	decrypted, err := dataPacket.Decrypt(ek.CipherFunc, ek.Key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "gopenpgp: unable to decrypt data packet")
+	}
+
+	config := &packet.Config{
+		Time: func() time.Time {
+			if verifyTime == 0 {
+				return getNow()
+			}
+			return time.Unix(verifyTime, 0)
+		},
+	}
+
+	var verifyEntities openpgp.EntityList
+	if verifyKey != nil {
+		verifyEntities = verifyKey.entities
+	}
+
+	md, err := openpgp.ReadMessage(decrypted, verifyEntities, nil, config)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "gopenpgp: error in reading message")
+	}
+
+	md.IsEncrypted = true
+	if key != nil {
+		md.DecryptedWith = *key
+	}
+	for _, ek := range keyPackets {
+		md.EncryptedToKeyIds = append(md.EncryptedToKeyIds, ek.KeyId)
+	}
+	md.UnverifiedBody = checkReader{decrypted, md.UnverifiedBody}
+	return md, details, nil
+}
+
+// checkDataPacket checks the encrypted data packet against the policy,
+// and returns the corresponding decryption details.
+func (policy *DecryptionPolicy) checkDataPacket(dataPacket packet.EncryptedDataPacket) (*DecryptionDetails, error) {
+	details := &DecryptionDetails{IntegrityProtected: true}
+	if se, ok := dataPacket.(*packet.SymmetricallyEncrypted); ok && !se.IntegrityProtected {
+		details.IntegrityProtected = false
+		switch policy.getMDCPolicy() {
+		case constants.MDC_POLICY_ACCEPT:
+		case constants.MDC_POLICY_WARN:
+			details.addWarning("message is not integrity protected")
+		default:
+			return nil, errors.New("gopenpgp: message is not integrity protected")
+		}
+	}
+	if _, ok := dataPacket.(*packet.AEADEncrypted); ok {
+		if !policy.allowLibrePGP && !policy.isInterop() {
+			return nil, errors.New("gopenpgp: LibrePGP AEAD encrypted data packets are not allowed by the decryption policy")
+		}
+		details.LibrePGP = true
+		details.addWarning("message is encrypted with a LibrePGP AEAD encrypted data packet")
+	}
+	return details, nil
+}
+
+// checkCipher checks the cipher of the session key against the policy,
+// and reports it in the decryption details.
+func (policy *DecryptionPolicy) checkCipher(cipherFunc packet.CipherFunction, details *DecryptionDetails) error {
+	details.Cipher = cipherName(cipherFunc)
+	switch cipherFunc {
+	case packet.CipherCAST5, packet.Cipher3DES:
+		if !policy.allowLegacyCiphers && !policy.isInterop() {
+			return errors.New("gopenpgp: legacy cipher not allowed by the decryption policy: " + details.Cipher)
+		}
+		details.addWarning("message is encrypted with the legacy cipher " + details.Cipher)
+	}
+	return nil
+}
+
+// checkSignature checks the key of a valid signature against the policy,
+// and reports the tolerated weaknesses in the decryption details.
+func (policy *DecryptionPolicy) checkSignature(md *openpgp.MessageDetails, details *DecryptionDetails) error {
+	if md.SignedBy == nil {
+		return nil
+	}
+	warnings, err := policy.checkSigningKey(md.SignedBy)
+	if err != nil {
+		return err
+	}
+	details.Warnings = append(details.Warnings, warnings...)
+	return nil
+}
+
+// checkSigningKey checks the key of a valid signature against the policy,
+// and returns warnings if the key is only tolerated by the policy.
+func (policy *DecryptionPolicy) checkSigningKey(key *openpgp.Key) ([]string, error) {
+	var warnings []string
+	if key.PublicKey.PubKeyAlgo == packet.PubKeyAlgoDSA {
+		if !policy.allowDSA && !policy.isInterop() {
+			return nil, newSignatureInsecure()
+		}
+		warnings = append(warnings, "signature is made with a legacy DSA key")
+	}
+	if key.PublicKey.Version == 5 {
+		if !policy.allowLibrePGP && !policy.isInterop() {
+			return nil, newSignatureInsecure()
+		}
+		warnings = append(warnings, "signature is made with a LibrePGP version 5 key")
+	}
+	warning, err := policy.checkSelfSignatures(key)
+	if err != nil {
+		return nil, err
+	}
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+	return warnings, nil
+}
+
+// checkSelfSignatures checks the hash algorithms of the self-signatures of the signing key.
+func (policy *DecryptionPolicy) checkSelfSignatures(key *openpgp.Key) (string, error) {
+	var selfSignatures []*packet.Signature
+	if identity := key.Entity.PrimaryIdentity(); identity != nil {
+		selfSignatures = append(selfSignatures, identity.SelfSignature)
+	}
+	if key.PublicKey != key.Entity.PrimaryKey {
+		selfSignatures = append(selfSignatures, key.SelfSignature)
+		if key.SelfSignature != nil {
+			selfSignatures = append(selfSignatures, key.SelfSignature.EmbeddedSignature)
+		}
+	}
+
+	var warning string
+	for _, sig := range selfSignatures {
+		if sig == nil || isAllowedHash(sig.Hash) {
+			continue
+		}
+		if sig.Hash != crypto.SHA1 || !policy.allowSHA1SelfSignatures && !policy.isInterop() ||
+			(policy.allowSHA1SelfSignatures && policy.sha1SelfSignaturesBefore != 0 &&
+				sig.CreationTime.Unix() >= policy.sha1SelfSignaturesBefore) {
+			return "", newSignatureInsecure()
+		}
+		warning = "signing key is certified with a SHA-1 self-signature"
+	}
+	return warning, nil
+}
+
+func isAllowedHash(hash crypto.Hash) bool {
+	for _, allowedHash := range allowedHashes {
+		if hash == allowedHash {
+			return true
+		}
+	}
+	return false
+}
+
+// cipherName returns the name of the cipher, including the legacy ciphers not in symKeyAlgos.
+func cipherName(cipherFunc packet.CipherFunction) string {
+	switch cipherFunc {
+	case packet.CipherAES128:
+		return constants.AES128
+	case packet.CipherAES192:
+		return constants.AES192
+	case packet.CipherAES256:
+		return constants.AES256
+	case packet.CipherCAST5:
+		return constants.CAST5
+	case packet.Cipher3DES:
+		return constants.ThreeDES
+	case packet.CipherFunction(1):
+		return constants.IDEA
+	case packet.CipherFunction(4):
+		return constants.Blowfish
+	}
+	return "unknown"
+}
+
+// decryptKeyPackets decrypts the first key packet that can be decrypted with the keyring
+// and is allowed by the policy, and returns it along with the key used.
+func (policy *DecryptionPolicy) decryptKeyPackets(
+	keyPackets []*packet.EncryptedKey, privateKey *KeyRing,
+) (*packet.EncryptedKey, *openpgp.Key, error) {
+	if len(keyPackets) == 0 {
+		return nil, nil, errors.New("gopenpgp: couldn't find a session key packet")
+	}
+	var decryptErr error
+	var skippedElGamal, skippedV5 bool
+	decryptionKeys := privateKey.entities.DecryptionKeys()
+	for _, ek := range keyPackets {
+		for i := range decryptionKeys {
+			key := &decryptionKeys[i]
+			if ek.KeyId != 0 && ek.KeyId != key.PublicKey.KeyId {
+				continue
+			}
+			if key.PrivateKey == nil || key.PrivateKey.Encrypted {
+				continue
+			}
+			if key.PublicKey.PubKeyAlgo == packet.PubKeyAlgoElGamal && !policy.allowElGamal && !policy.isInterop() {
+				skippedElGamal = true
+				continue
+			}
+			if key.PublicKey.Version == 5 && !policy.allowLibrePGP && !policy.isInterop() {
+				skippedV5 = true
+				continue
+			}
+			if decryptErr = ek.Decrypt(key.PrivateKey, nil); decryptErr == nil {
+				return ek, key, nil
+			}
+			var unsupported pgpErrors.UnsupportedError
+			if goerrors.As(decryptErr, &unsupported) {
+				return nil, nil, errors.New("gopenpgp: unsupported cipher: " + cipherName(ek.CipherFunc))
+			}
+		}
+	}
+	if decryptErr != nil {
+		return nil, nil, errors.Wrap(decryptErr, "gopenpgp: error in decrypting")
+	}
+	if skippedElGamal {
+		return nil, nil, errors.New("gopenpgp: ElGamal decryption not allowed by the decryption policy")
+	}
+	if skippedV5 {
+		return nil, nil, errors.New("gopenpgp: LibrePGP version 5 keys are not allowed by the decryption policy")
+	}
+	return nil, nil, ErrNoDecryptionKey
+}
@@ -0,0 +1,612 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/dsa" //nolint:staticcheck
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/ocb"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/elgamal"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/cast5"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// encryptWithoutIntegrityProtection builds a message with a legacy Symmetrically Encrypted Data packet.
+func encryptWithoutIntegrityProtection(t *testing.T, keyRing *KeyRing, algo string, data []byte) *PGPMessage {
+	sk, err := GenerateSessionKeyAlgo(algo)
+	if err != nil {
+		t.Fatal("Expected no error while generating the session key, got:", err)
+	}
+	keyPacket, err := keyRing.EncryptSessionKey(sk)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting the session key, got:", err)
+	}
+
+	var literal bytes.Buffer
+	literalWriter, err := packet.SerializeLiteral(nopWriteCloser{&literal}, true, "", 0)
+	if err != nil {
+		t.Fatal("Expected no error while serializing the literal packet, got:", err)
+	}
+	if _, err = literalWriter.Write(data); err != nil {
+		t.Fatal("Expected no error while serializing the literal packet, got:", err)
+	}
+	if err = literalWriter.Close(); err != nil {
+		t.Fatal("Expected no error while serializing the literal packet, got:", err)
+	}
+
+	var block cipher.Block
+	if algo == constants.CAST5 {
+		block, err = cast5.NewCipher(sk.Key)
+	} else {
+		block, err = aes.NewCipher(sk.Key)
+	}
+	if err != nil {
+		t.Fatal("Expected no error while creating the cipher, got:", err)
+	}
+	iv := make([]byte, block.BlockSize())
+	stream, prefix := packet.NewOCFBEncrypter(block, iv, packet.OCFBResync)
+	ciphertext := make([]byte, literal.Len())
+	stream.XORKeyStream(ciphertext, literal.Bytes())
+	body := append(prefix, ciphertext...)
+	if len(body) >= 192 {
+		t.Fatal("Test data is too long for a one-octet packet length")
+	}
+
+	dataPacket := append([]byte{0xC9, byte(len(body))}, body...)
+	return NewPGPMessage(append(keyPacket, dataPacket...))
+}
+
+func TestDecryptWithPolicyMDC(t *testing.T) {
+	message := encryptWithoutIntegrityProtection(t, keyRingTestPublic, constants.AES256, []byte(testMessage))
+
+	if _, err := keyRingTestPrivate.Decrypt(message, nil, 0); err == nil {
+		t.Fatal("Expected an error while decrypting a message without integrity protection, got nil")
+	}
+	if _, err := keyRingTestPrivate.DecryptWithPolicy(message, nil, 0, nil); err == nil {
+		t.Fatal("Expected an error while decrypting with the default policy, got nil")
+	}
+
+	decrypted, err := keyRingTestPrivate.DecryptWithPolicy(
+		message, nil, 0,
+		NewDecryptionPolicy().WithMDCPolicy(constants.MDC_POLICY_WARN),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with a warning policy, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.False(t, decrypted.GetDecryptionDetails().IsIntegrityProtected())
+	assert.True(t, decrypted.GetDecryptionDetails().HasWarnings())
+
+	decrypted, err = keyRingTestPrivate.DecryptWithPolicy(
+		message, nil, 0,
+		NewDecryptionPolicy().WithMDCPolicy(constants.MDC_POLICY_ACCEPT),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with an accepting policy, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.False(t, decrypted.GetDecryptionDetails().IsIntegrityProtected())
+	assert.False(t, decrypted.GetDecryptionDetails().HasWarnings())
+}
+
+func TestDecryptWithPolicyIntegrityProtected(t *testing.T) {
+	message, err := keyRingTestPublic.Encrypt(NewPlainMessageFromString(testMessage), keyRingTestPrivate)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	decrypted, err := keyRingTestPrivate.DecryptWithPolicy(message, keyRingTestPublic, GetUnixTime(), nil)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.True(t, decrypted.GetDecryptionDetails().IsIntegrityProtected())
+	assert.Exactly(t, constants.AES256, decrypted.GetDecryptionDetails().GetCipher())
+	assert.False(t, decrypted.GetDecryptionDetails().HasWarnings())
+
+	plainMessageReader, err := keyRingTestPrivate.DecryptStreamWithPolicy(
+		bytes.NewReader(message.GetBinary()),
+		keyRingTestPublic,
+		GetUnixTime(),
+		nil,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	decryptedBytes, err := ioutil.ReadAll(plainMessageReader)
+	if err != nil {
+		t.Fatal("Expected no error while reading the decrypted data, got:", err)
+	}
+	assert.Exactly(t, testMessage, string(decryptedBytes))
+	if err = plainMessageReader.VerifySignature(); err != nil {
+		t.Fatal("Expected no error while verifying the signature, got:", err)
+	}
+	assert.True(t, plainMessageReader.GetDecryptionDetails().IsIntegrityProtected())
+}
+
+func TestDecryptWithPolicyPlaintextRelease(t *testing.T) {
+	message, err := keyRingTestPublic.Encrypt(NewPlainMessageFromString(testMessage), keyRingTestPrivate)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	otherKey, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	otherKeyRing, err := NewKeyRing(otherKey)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+
+	decryptStream := func(verifyKeyRing *KeyRing, releasePolicy int) *PlainMessageReader {
+		plainMessageReader, err := keyRingTestPrivate.DecryptStreamWithPolicy(
+			bytes.NewReader(message.GetBinary()),
+			verifyKeyRing,
+			GetUnixTime(),
+			NewDecryptionPolicy().WithPlaintextReleasePolicy(releasePolicy),
+		)
+		if err != nil {
+			t.Fatal("Expected no error while decrypting, got:", err)
+		}
+		return plainMessageReader
+	}
+
+	plainMessageReader := decryptStream(keyRingTestPublic, constants.PLAINTEXT_RELEASE_WARN)
+	decryptedBytes, err := ioutil.ReadAll(plainMessageReader)
+	if err != nil {
+		t.Fatal("Expected no error while reading the decrypted data, got:", err)
+	}
+	assert.Exactly(t, testMessage, string(decryptedBytes))
+	assert.NoError(t, plainMessageReader.VerifySignature())
+	assert.Exactly(
+		t,
+		[]string{"plaintext released before signature verification"},
+		plainMessageReader.GetDecryptionDetails().Warnings,
+	)
+
+	plainMessageReader = decryptStream(keyRingTestPublic, constants.PLAINTEXT_RELEASE_BUFFER)
+	decryptedBytes, err = ioutil.ReadAll(plainMessageReader)
+	if err != nil {
+		t.Fatal("Expected no error while reading the decrypted data, got:", err)
+	}
+	assert.Exactly(t, testMessage, string(decryptedBytes))
+	assert.NoError(t, plainMessageReader.VerifySignature())
+	assert.False(t, plainMessageReader.GetDecryptionDetails().HasWarnings())
+
+	plainMessageReader = decryptStream(otherKeyRing, constants.PLAINTEXT_RELEASE_BUFFER)
+	decryptedBytes, err = ioutil.ReadAll(plainMessageReader)
+	assert.Empty(t, decryptedBytes)
+	var sigErr SignatureVerificationError
+	if !errors.As(err, &sigErr) {
+		t.Fatal("Expected a signature verification error, got:", err)
+	}
+	assert.Exactly(t, constants.SIGNATURE_NO_VERIFIER, sigErr.Status)
+	assert.Exactly(t, err, plainMessageReader.VerifySignature())
+}
+
+func TestDecryptWithPolicyStrictness(t *testing.T) {
+	plainMessage := NewPlainMessageFromString(testMessage)
+	message, err := keyRingTestPublic.Encrypt(plainMessage, nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	signature, err := keyRingTestPrivate.SignDetached(plainMessage)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	strict := NewDecryptionPolicy().WithStrictness(constants.STRICTNESS_STRICT)
+	interop := NewDecryptionPolicy().WithStrictness(constants.STRICTNESS_INTEROP)
+
+	// Signature packet before the key packets
+	outOfOrder := NewPGPMessage(append(clone(signature.GetBinary()), message.GetBinary()...))
+	decrypted, err := keyRingTestPrivate.DecryptWithPolicy(outOfOrder, nil, 0, nil)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with the standard policy, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	_, err = keyRingTestPrivate.DecryptWithPolicy(outOfOrder, nil, 0, strict)
+	assert.Error(t, err)
+	decrypted, err = keyRingTestPrivate.DecryptWithPolicy(message, nil, 0, strict)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with the strict policy, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+
+	// Detached signature made of two signature packets
+	doubleSignature := NewPGPSignature(append(clone(signature.GetBinary()), signature.GetBinary()...))
+	_, err = keyRingTestPublic.VerifyDetachedWithPolicy(plainMessage, doubleSignature, GetUnixTime(), nil)
+	assert.NoError(t, err)
+	_, err = keyRingTestPublic.VerifyDetachedWithPolicy(plainMessage, doubleSignature, GetUnixTime(), strict)
+	assert.Error(t, err)
+	_, err = keyRingTestPublic.VerifyDetachedWithPolicy(plainMessage, signature, GetUnixTime(), strict)
+	assert.NoError(t, err)
+
+	withoutMDC := encryptWithoutIntegrityProtection(t, keyRingTestPublic, constants.CAST5, []byte(testMessage))
+	_, err = keyRingTestPrivate.DecryptWithPolicy(withoutMDC, nil, 0, strict)
+	assert.Error(t, err)
+	decrypted, err = keyRingTestPrivate.DecryptWithPolicy(withoutMDC, nil, 0, interop)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with the interop policy, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.Len(t, decrypted.GetDecryptionDetails().Warnings, 2)
+
+	// Resetting the strictness level
+	_, err = keyRingTestPrivate.DecryptWithPolicy(withoutMDC, nil, 0, interop.WithStrictness(constants.STRICTNESS_STANDARD))
+	assert.Error(t, err)
+
+	// The options set before or after the level are kept
+	policy := NewDecryptionPolicy().
+		AllowLegacyCiphers().
+		WithStrictness(constants.STRICTNESS_STRICT).
+		WithMDCPolicy(constants.MDC_POLICY_ACCEPT)
+	decrypted, err = keyRingTestPrivate.DecryptWithPolicy(withoutMDC, nil, 0, policy)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with the strict policy and options, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	_, err = keyRingTestPrivate.DecryptWithPolicy(outOfOrder, nil, 0, policy)
+	assert.Error(t, err)
+	policy = NewDecryptionPolicy().WithMDCPolicy(constants.MDC_POLICY_FAIL).WithStrictness(constants.STRICTNESS_INTEROP)
+	_, err = keyRingTestPrivate.DecryptWithPolicy(withoutMDC, nil, 0, policy)
+	assert.Error(t, err)
+
+	// Unknown levels are rejected
+	unknown := NewDecryptionPolicy().WithStrictness(42)
+	_, err = keyRingTestPrivate.DecryptWithPolicy(message, nil, 0, unknown)
+	assert.Error(t, err)
+	_, err = keyRingTestPublic.VerifyDetachedWithPolicy(plainMessage, signature, GetUnixTime(), unknown)
+	assert.Error(t, err)
+}
+
+func TestDecryptWithPolicyLegacyCipher(t *testing.T) {
+	message := encryptWithoutIntegrityProtection(t, keyRingTestPublic, constants.CAST5, []byte(testMessage))
+	policy := NewDecryptionPolicy().WithMDCPolicy(constants.MDC_POLICY_ACCEPT)
+
+	if _, err := keyRingTestPrivate.DecryptWithPolicy(message, nil, 0, policy); err == nil {
+		t.Fatal("Expected an error while decrypting a legacy cipher without allowing it, got nil")
+	}
+
+	decrypted, err := keyRingTestPrivate.DecryptWithPolicy(message, nil, 0, policy.AllowLegacyCiphers())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with legacy ciphers allowed, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.Exactly(t, constants.CAST5, decrypted.GetDecryptionDetails().GetCipher())
+	assert.True(t, decrypted.GetDecryptionDetails().HasWarnings())
+}
+
+func TestDecryptWithPolicyUnsupportedLegacyCipher(t *testing.T) {
+	var keyPacket bytes.Buffer
+	encryptionKey, ok := keyRingTestPublic.entities[0].EncryptionKey(getNow())
+	if !ok {
+		t.Fatal("Expected an encryption key in the test keyring")
+	}
+	// IDEA session key
+	err := packet.SerializeEncryptedKey(&keyPacket, encryptionKey.PublicKey, packet.CipherFunction(1), make([]byte, 16), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting the session key, got:", err)
+	}
+	sk, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating the session key, got:", err)
+	}
+	dataPacket, err := sk.Encrypt(NewPlainMessageFromString(testMessage))
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	message := NewPGPMessage(append(keyPacket.Bytes(), dataPacket...))
+
+	_, err = keyRingTestPrivate.DecryptWithPolicy(message, nil, 0, NewDecryptionPolicy().AllowLegacyCiphers())
+	assert.EqualError(t, err, "gopenpgp: unsupported cipher: "+constants.IDEA)
+}
+
+// 2048-bit MODP group from RFC 3526.
+const testElGamalPrime = "FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B139B22514A08798E3404DD" +
+	"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED" +
+	"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3DC2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F" +
+	"83655D23DCA3AD961C62F356208552BB9ED529077096966D670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B" +
+	"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9DE2BCBF6955817183995497CEA956AE515D2261898FA0510" +
+	"15728E5A8AACAA68FFFFFFFFFFFFFFFF"
+
+// addElGamalSubkey adds a legacy ElGamal encryption subkey to an unlocked private key.
+func addElGamalSubkey(t *testing.T, key *Key) *packet.PublicKey {
+	p, ok := new(big.Int).SetString(testElGamalPrime, 16)
+	if !ok {
+		t.Fatal("Expected to parse the ElGamal prime")
+	}
+	x, err := rand.Int(rand.Reader, p)
+	if err != nil {
+		t.Fatal("Expected no error while generating the ElGamal key, got:", err)
+	}
+	g := big.NewInt(2)
+	elGamalKey := &elgamal.PrivateKey{
+		PublicKey: elgamal.PublicKey{G: g, P: p, Y: new(big.Int).Exp(g, x, p)},
+		X:         x,
+	}
+	return addTestSubkey(
+		t, key,
+		packet.NewElGamalPublicKey(getNow(), &elGamalKey.PublicKey),
+		packet.NewElGamalPrivateKey(getNow(), elGamalKey),
+		false,
+	)
+}
+
+// addDSASubkey adds a legacy DSA signing subkey to an unlocked private key.
+func addDSASubkey(t *testing.T, key *Key) *packet.PublicKey {
+	dsaKey := new(dsa.PrivateKey)
+	if err := dsa.GenerateParameters(&dsaKey.Parameters, rand.Reader, dsa.L1024N160); err != nil {
+		t.Fatal("Expected no error while generating the DSA parameters, got:", err)
+	}
+	if err := dsa.GenerateKey(dsaKey, rand.Reader); err != nil {
+		t.Fatal("Expected no error while generating the DSA key, got:", err)
+	}
+	return addTestSubkey(
+		t, key,
+		packet.NewDSAPublicKey(getNow(), &dsaKey.PublicKey),
+		packet.NewDSAPrivateKey(getNow(), dsaKey),
+		true,
+	)
+}
+
+func addTestSubkey(t *testing.T, key *Key, pub *packet.PublicKey, priv *packet.PrivateKey, sign bool) *packet.PublicKey {
+	subkey := openpgp.Subkey{
+		PublicKey:  pub,
+		PrivateKey: priv,
+		Sig: &packet.Signature{
+			Version:                   4,
+			CreationTime:              getNow(),
+			SigType:                   packet.SigTypeSubkeyBinding,
+			PubKeyAlgo:                key.entity.PrimaryKey.PubKeyAlgo,
+			Hash:                      crypto.SHA256,
+			FlagsValid:                true,
+			FlagSign:                  sign,
+			FlagEncryptStorage:        !sign,
+			FlagEncryptCommunications: !sign,
+			IssuerKeyId:               &key.entity.PrimaryKey.KeyId,
+		},
+	}
+	subkey.PublicKey.IsSubkey = true
+	subkey.PrivateKey.IsSubkey = true
+	if sign {
+		subkey.Sig.EmbeddedSignature = &packet.Signature{
+			Version:      4,
+			CreationTime: getNow(),
+			SigType:      packet.SigTypePrimaryKeyBinding,
+			PubKeyAlgo:   pub.PubKeyAlgo,
+			Hash:         crypto.SHA256,
+			IssuerKeyId:  &pub.KeyId,
+		}
+		err := subkey.Sig.EmbeddedSignature.CrossSignKey(pub, key.entity.PrimaryKey, priv, nil)
+		if err != nil {
+			t.Fatal("Expected no error while cross-signing the subkey, got:", err)
+		}
+	}
+	if err := subkey.Sig.SignKey(subkey.PublicKey, key.entity.PrivateKey, nil); err != nil {
+		t.Fatal("Expected no error while signing the subkey, got:", err)
+	}
+	key.entity.Subkeys = append(key.entity.Subkeys, subkey)
+	return subkey.PublicKey
+}
+
+func TestDecryptWithPolicyElGamal(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating the key, got:", err)
+	}
+	elGamalPublicKey := addElGamalSubkey(t, key)
+	keyRing, err := NewKeyRing(key)
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+
+	sk, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating the session key, got:", err)
+	}
+	var keyPacket bytes.Buffer
+	if err = packet.SerializeEncryptedKey(&keyPacket, elGamalPublicKey, packet.CipherAES256, sk.Key, nil); err != nil {
+		t.Fatal("Expected no error while encrypting the session key, got:", err)
+	}
+	dataPacket, err := sk.Encrypt(NewPlainMessageFromString(testMessage))
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	message := NewPGPMessage(append(keyPacket.Bytes(), dataPacket...))
+
+	_, err = keyRing.DecryptWithPolicy(message, nil, 0, nil)
+	assert.EqualError(t, err, "gopenpgp: ElGamal decryption not allowed by the decryption policy")
+
+	decrypted, err := keyRing.DecryptWithPolicy(message, nil, 0, NewDecryptionPolicy().AllowElGamal())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with ElGamal allowed, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.True(t, decrypted.GetDecryptionDetails().HasWarnings())
+}
+
+func TestDecryptWithPolicyDSA(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating the key, got:", err)
+	}
+	addDSASubkey(t, key)
+	signKeyRing, err := NewKeyRing(key)
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	message, err := keyRingTestPublic.Encrypt(NewPlainMessageFromString(testMessage), signKeyRing)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	if _, err = keyRingTestPrivate.Decrypt(message, signKeyRing, GetUnixTime()); err != nil {
+		t.Fatal("Expected no error while decrypting with the legacy API, got:", err)
+	}
+
+	_, err = keyRingTestPrivate.DecryptWithPolicy(message, signKeyRing, GetUnixTime(), nil)
+	var sigErr SignatureVerificationError
+	if !errors.As(err, &sigErr) {
+		t.Fatal("Expected a signature verification error with the default policy, got:", err)
+	}
+	assert.Exactly(t, constants.SIGNATURE_FAILED, sigErr.Status)
+
+	decrypted, err := keyRingTestPrivate.DecryptWithPolicy(
+		message, signKeyRing, GetUnixTime(),
+		NewDecryptionPolicy().AllowDSA(),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with DSA allowed, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.True(t, decrypted.GetDecryptionDetails().HasWarnings())
+}
+
+func TestCheckSigningKeyDSAPrimaryKey(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating the key, got:", err)
+	}
+	// Only the algorithm of the key which made the signature matters, not the one of the primary key.
+	entity := *key.entity
+	primaryKey := *entity.PrimaryKey
+	primaryKey.PubKeyAlgo = packet.PubKeyAlgoDSA
+	entity.PrimaryKey = &primaryKey
+	subkey := entity.Subkeys[0]
+	signingKey := &openpgp.Key{
+		Entity:        &entity,
+		PublicKey:     subkey.PublicKey,
+		PrivateKey:    subkey.PrivateKey,
+		SelfSignature: subkey.Sig,
+	}
+
+	warnings, err := NewDecryptionPolicy().checkSigningKey(signingKey)
+	if err != nil {
+		t.Fatal("Expected no error while checking a non-DSA subkey of a DSA primary key, got:", err)
+	}
+	assert.Empty(t, warnings)
+}
+
+// encryptAEADEncrypted builds a message with a LibrePGP AEAD Encrypted Data packet, using OCB.
+func encryptAEADEncrypted(t *testing.T, keyRing *KeyRing, data []byte) *PGPMessage {
+	sk, err := GenerateSessionKeyAlgo(constants.AES256)
+	if err != nil {
+		t.Fatal("Expected no error while generating the session key, got:", err)
+	}
+	keyPacket, err := keyRing.EncryptSessionKey(sk)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting the session key, got:", err)
+	}
+
+	var literal bytes.Buffer
+	literalWriter, err := packet.SerializeLiteral(nopWriteCloser{&literal}, true, "", 0)
+	if err != nil {
+		t.Fatal("Expected no error while serializing the literal packet, got:", err)
+	}
+	if _, err = literalWriter.Write(data); err != nil {
+		t.Fatal("Expected no error while serializing the literal packet, got:", err)
+	}
+	if err = literalWriter.Close(); err != nil {
+		t.Fatal("Expected no error while serializing the literal packet, got:", err)
+	}
+
+	block, err := aes.NewCipher(sk.Key)
+	if err != nil {
+		t.Fatal("Expected no error while creating the cipher, got:", err)
+	}
+	aead, err := ocb.NewOCB(block)
+	if err != nil {
+		t.Fatal("Expected no error while creating the cipher, got:", err)
+	}
+	header := []byte{1, byte(packet.CipherAES256), byte(packet.AEADModeOCB), 12}
+	iv := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(iv); err != nil {
+		t.Fatal("Expected no error while generating the IV, got:", err)
+	}
+
+	// One chunk with index 0, then the final tag with index 1
+	associatedData := append([]byte{0xD4}, header...)
+	chunkData := append(append([]byte{}, associatedData...), make([]byte, 8)...)
+	body := append(append([]byte{}, header...), iv...)
+	body = aead.Seal(body, iv, literal.Bytes(), chunkData)
+
+	finalNonce := append([]byte{}, iv...)
+	finalNonce[len(finalNonce)-1] ^= 1
+	finalData := append(append([]byte{}, associatedData...), 0, 0, 0, 0, 0, 0, 0, 1)
+	finalData = append(finalData, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(finalData[len(finalData)-8:], uint64(literal.Len()))
+	body = aead.Seal(body, finalNonce, nil, finalData)
+	if len(body) >= 192 {
+		t.Fatal("Test data is too long for a one-octet packet length")
+	}
+
+	dataPacket := append([]byte{0xD4, byte(len(body))}, body...)
+	return NewPGPMessage(append(keyPacket, dataPacket...))
+}
+
+func TestDecryptWithPolicyLibrePGP(t *testing.T) {
+	message := encryptAEADEncrypted(t, keyRingTestPublic, []byte(testMessage))
+
+	if _, err := keyRingTestPrivate.DecryptWithPolicy(message, nil, 0, nil); err == nil {
+		t.Fatal("Expected an error while decrypting an AEAD encrypted data packet with the default policy, got nil")
+	}
+
+	decrypted, err := keyRingTestPrivate.DecryptWithPolicy(message, nil, 0, NewDecryptionPolicy().AllowLibrePGP())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with LibrePGP allowed, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.True(t, decrypted.GetDecryptionDetails().IsLibrePGP())
+	assert.True(t, decrypted.GetDecryptionDetails().HasWarnings())
+}
+
+func TestDecryptWithPolicyV5Key(t *testing.T) {
+	entity, err := openpgp.NewEntity(keyTestName, "", keyTestDomain, &packet.Config{
+		Algorithm: packet.PubKeyAlgoEdDSA,
+		V5Keys:    true,
+		Time:      getNow,
+	})
+	if err != nil {
+		t.Fatal("Expected no error while generating the key, got:", err)
+	}
+	key, err := NewKeyFromEntity(entity)
+	if err != nil {
+		t.Fatal("Expected no error while creating the key, got:", err)
+	}
+	keyRing, err := NewKeyRing(key)
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	message, err := keyRing.Encrypt(NewPlainMessageFromString(testMessage), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	_, err = keyRing.DecryptWithPolicy(message, nil, 0, nil)
+	assert.EqualError(t, err, "gopenpgp: LibrePGP version 5 keys are not allowed by the decryption policy")
+
+	decrypted, err := keyRing.DecryptWithPolicy(message, nil, 0, NewDecryptionPolicy().AllowLibrePGP())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with LibrePGP allowed, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.True(t, decrypted.GetDecryptionDetails().IsLibrePGP())
+}
@@ -0,0 +1,244 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	goerrors "errors"
+	"strconv"
+
+	pgpErrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+var packetTypeNames = map[int]string{
+	1:  "PKESK",
+	2:  "Signature",
+	3:  "SKESK",
+	4:  "One-Pass Signature",
+	5:  "Secret-Key",
+	6:  "Public-Key",
+	7:  "Secret-Subkey",
+	8:  "Compressed Data",
+	9:  "SED",
+	10: "Marker",
+	11: "Literal Data",
+	12: "Trust",
+	13: "User ID",
+	14: "Public-Subkey",
+	17: "User Attribute",
+	18: "SEIPD",
+	19: "MDC",
+	20: "AEAD Encrypted Data",
+	21: "Padding",
+}
+
+// PacketDiagnostic describes where and how binary OpenPGP data is malformed,
+// to triage corrupted data without inspecting it by hand.
+type PacketDiagnostic struct {
+	// Offset is the byte offset of the malformed packet in the data.
+	Offset int `json:"offset"`
+	// PacketIndex is the index of the malformed packet in the data.
+	PacketIndex int `json:"packetIndex"`
+	// PacketTag is the tag of the malformed packet, -1 if the packet header is invalid
+	// or if the data ends unexpectedly.
+	PacketTag int `json:"packetTag"`
+	// PacketType is the name of the packet type, e.g. "SEIPD", empty if the tag is unknown.
+	PacketType string `json:"packetType,omitempty"`
+	// Expected describes what was expected at the offset.
+	Expected string `json:"expected"`
+	// Found describes what was found instead.
+	Found string `json:"found"`
+}
+
+// Error returns a description of the diagnostic, making PacketDiagnostic an error.
+func (diagnostic *PacketDiagnostic) Error() string {
+	description := "gopenpgp: malformed packet " + strconv.Itoa(diagnostic.PacketIndex) +
+		" at offset " + strconv.Itoa(diagnostic.Offset)
+	if diagnostic.PacketType != "" {
+		description += " (" + diagnostic.PacketType + ")"
+	}
+	return description + ": expected " + diagnostic.Expected + ", found " + diagnostic.Found
+}
+
+// DiagnoseMessage checks the packets of a binary message, e.g. after a parsing failure,
+// and returns a diagnostic of the first malformed packet, or nil if none is found.
+func DiagnoseMessage(data []byte) *PacketDiagnostic {
+	encrypted, dataPacketRead, signed := false, false, false
+	diagnostic := diagnosePackets(data, func(tag int) string {
+		switch {
+		case dataPacketRead:
+			return "end of message"
+		case signed:
+			return ""
+		}
+		switch tag {
+		case 1, 3:
+			encrypted = true
+		case 9, 18, 20:
+			dataPacketRead = true
+		case 2, 4, 8, 11:
+			if encrypted {
+				return "key packet or encrypted data packet"
+			}
+			signed = true
+		case 10:
+		default:
+			return "key packet, encrypted data packet or unencrypted message packet"
+		}
+		return ""
+	})
+	if diagnostic == nil && !dataPacketRead && !signed {
+		diagnostic = &PacketDiagnostic{
+			Offset:      len(data),
+			PacketIndex: countPackets(data),
+			PacketTag:   -1,
+			Expected:    "encrypted data packet",
+			Found:       "end of data",
+		}
+	}
+	return diagnostic
+}
+
+// DiagnoseKey checks the packets of a binary key or keyring, e.g. after a parsing failure,
+// and returns a diagnostic of the first malformed packet, or nil if none is found.
+func DiagnoseKey(data []byte) *PacketDiagnostic {
+	first := true
+	diagnostic := diagnosePackets(data, func(tag int) string {
+		switch {
+		case tag == 5 || tag == 6:
+		case first:
+			return "Public-Key or Secret-Key packet"
+		case tag != 2 && tag != 7 && tag != 12 && tag != 13 && tag != 14 && tag != 17:
+			return "key component packet"
+		}
+		first = false
+		return ""
+	})
+	if diagnostic == nil && first {
+		diagnostic = &PacketDiagnostic{
+			Offset:    len(data),
+			PacketTag: -1,
+			Expected:  "Public-Key or Secret-Key packet",
+			Found:     "end of data",
+		}
+	}
+	return diagnostic
+}
+
+// diagnosePackets checks the headers and the contents of the packets of the data,
+// and their sequence with checkTag, which returns what was expected if the tag is not.
+func diagnosePackets(data []byte, checkTag func(tag int) string) *PacketDiagnostic {
+	for offset, index := 0, 0; offset < len(data); index++ {
+		diagnostic := &PacketDiagnostic{Offset: offset, PacketIndex: index}
+		tag, length, expected, found := readPacketLayout(data[offset:])
+		diagnostic.PacketTag = tag
+		diagnostic.PacketType = packetTypeNames[tag]
+		if expected != "" {
+			diagnostic.Expected, diagnostic.Found = expected, found
+			return diagnostic
+		}
+		if expected = checkTag(tag); expected != "" {
+			diagnostic.Expected = expected
+			diagnostic.Found = "packet with tag " + strconv.Itoa(tag)
+			return diagnostic
+		}
+		if _, err := packet.Read(bytes.NewReader(data[offset : offset+length])); err != nil {
+			var unknownPacketTypeError pgpErrors.UnknownPacketTypeError
+			if goerrors.As(err, &unknownPacketTypeError) {
+				diagnostic.Expected = "known packet type"
+			} else {
+				diagnostic.Expected = "valid packet contents"
+			}
+			diagnostic.Found = err.Error()
+			return diagnostic
+		}
+		offset += length
+	}
+	return nil
+}
+
+// readPacketLayout reads the header of the first packet of the data, and returns the packet tag
+// and length, including the header, or a description of what was expected and found if malformed.
+func readPacketLayout(data []byte) (tag, length int, expected, found string) {
+	if data[0]&0x80 == 0 {
+		return -1, 0, "packet header", "byte 0x" + strconv.FormatUint(uint64(data[0]), 16)
+	}
+	var bodyOffset, bodyLength int
+	if data[0]&0x40 == 0 {
+		// Old format packet
+		tag = int(data[0]&0x3f) >> 2
+		bodyOffset, bodyLength, expected, found = readOldFormatLength(data)
+	} else {
+		tag = int(data[0] & 0x3f)
+		bodyOffset, bodyLength, expected, found = readNewFormatLength(data)
+	}
+	if expected != "" {
+		return tag, 0, expected, found
+	}
+	if bodyLength < 0 || len(data)-bodyOffset < bodyLength {
+		return tag, 0, strconv.Itoa(bodyLength) + " bytes of packet body", strconv.Itoa(len(data)-bodyOffset) + " bytes"
+	}
+	return tag, bodyOffset + bodyLength, "", ""
+}
+
+// readOldFormatLength reads the length of an old format packet.
+func readOldFormatLength(data []byte) (bodyOffset, bodyLength int, expected, found string) {
+	lengthType := data[0] & 3
+	if lengthType == 3 {
+		// Indeterminate length, until the end of the data
+		return 1, len(data) - 1, "", ""
+	}
+	bodyOffset = 1 + 1<<lengthType
+	if len(data) < bodyOffset {
+		return 0, 0, strconv.Itoa(bodyOffset-1) + " bytes of packet length", "end of data"
+	}
+	for _, b := range data[1:bodyOffset] {
+		bodyLength = bodyLength<<8 | int(b)
+	}
+	return bodyOffset, bodyLength, "", ""
+}
+
+// readNewFormatLength reads the length of a new format packet, skipping the partial body lengths
+// and the partial bodies, and returns the offset and the length of the last part of the body.
+func readNewFormatLength(data []byte) (bodyOffset, bodyLength int, expected, found string) {
+	offset := 1
+	for {
+		if offset >= len(data) {
+			return 0, 0, "packet length", "end of data"
+		}
+		lengthByte := data[offset]
+		switch {
+		case lengthByte < 192:
+			return offset + 1, int(lengthByte), "", ""
+		case lengthByte < 224:
+			if offset+2 > len(data) {
+				return 0, 0, "2 bytes of packet length", "end of data"
+			}
+			return offset + 2, (int(lengthByte)-192)<<8 + int(data[offset+1]) + 192, "", ""
+		case lengthByte == 255:
+			if offset+5 > len(data) {
+				return 0, 0, "5 bytes of packet length", "end of data"
+			}
+			return offset + 5, int(binary.BigEndian.Uint32(data[offset+1 : offset+5])), "", ""
+		default:
+			partialLength := 1 << (lengthByte & 0x1f)
+			if offset+1+partialLength > len(data) {
+				return 0, 0, strconv.Itoa(partialLength) + " bytes of partial packet body", "end of data"
+			}
+			offset += 1 + partialLength
+		}
+	}
+}
+
+// countPackets returns the number of well-formed packets at the start of the data.
+func countPackets(data []byte) int {
+	count := 0
+	for offset := 0; offset < len(data); count++ {
+		_, length, expected, _ := readPacketLayout(data[offset:])
+		if expected != "" {
+			break
+		}
+		offset += length
+	}
+	return count
+}
@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnoseMessage(t *testing.T) {
+	message, err := keyRingTestPublic.Encrypt(NewPlainMessageFromString(testMessage), keyRingTestPrivate)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	split, err := message.SplitMessage()
+	if err != nil {
+		t.Fatal("Expected no error while splitting, got:", err)
+	}
+	data := message.GetBinary()
+	dataOffset := len(split.GetBinaryKeyPacket())
+	assert.Nil(t, DiagnoseMessage(data))
+
+	truncated := DiagnoseMessage(data[:len(data)-10])
+	if truncated == nil {
+		t.Fatal("Expected a diagnostic for a truncated message, got nil")
+	}
+	assert.Exactly(t, dataOffset, truncated.Offset)
+	assert.Exactly(t, 1, truncated.PacketIndex)
+	assert.Exactly(t, 18, truncated.PacketTag)
+	assert.Exactly(t, "SEIPD", truncated.PacketType)
+	assert.Contains(t, truncated.Expected, "bytes of packet body")
+
+	corrupted := clone(data)
+	corrupted[dataOffset] = 0x42
+	diagnostic := DiagnoseMessage(corrupted)
+	if diagnostic == nil {
+		t.Fatal("Expected a diagnostic for a corrupted message, got nil")
+	}
+	assert.Exactly(t, dataOffset, diagnostic.Offset)
+	assert.Exactly(t, -1, diagnostic.PacketTag)
+	assert.Exactly(t, "packet header", diagnostic.Expected)
+	assert.Exactly(t, "byte 0x42", diagnostic.Found)
+
+	outOfOrder := append(clone(data), split.GetBinaryKeyPacket()...)
+	diagnostic = DiagnoseMessage(outOfOrder)
+	if diagnostic == nil {
+		t.Fatal("Expected a diagnostic for packets out of order, got nil")
+	}
+	assert.Exactly(t, 2, diagnostic.PacketIndex)
+	assert.Exactly(t, "end of message", diagnostic.Expected)
+
+	diagnostic = DiagnoseMessage(split.GetBinaryKeyPacket())
+	if diagnostic == nil {
+		t.Fatal("Expected a diagnostic for a message without data packet, got nil")
+	}
+	assert.Exactly(t, "encrypted data packet", diagnostic.Expected)
+	assert.Exactly(t, "end of data", diagnostic.Found)
+
+	serialized, err := json.Marshal(truncated)
+	if err != nil {
+		t.Fatal("Expected no error while serializing diagnostic, got:", err)
+	}
+	assert.Contains(t, string(serialized), `"packetType":"SEIPD"`)
+	assert.Contains(t, truncated.Error(), "malformed packet 1")
+}
+
+func TestDiagnoseKey(t *testing.T) {
+	key, err := keyRingTestPrivate.GetKey(0)
+	if err != nil {
+		t.Fatal("Expected no error while getting key, got:", err)
+	}
+	data, err := key.GetPublicKey()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+	assert.Nil(t, DiagnoseKey(data))
+
+	message, err := keyRingTestPublic.Encrypt(NewPlainMessageFromString(testMessage), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	diagnostic := DiagnoseKey(message.GetBinary())
+	if diagnostic == nil {
+		t.Fatal("Expected a diagnostic for a message, got nil")
+	}
+	assert.Exactly(t, 0, diagnostic.PacketIndex)
+	assert.Exactly(t, "PKESK", diagnostic.PacketType)
+	assert.Exactly(t, "Public-Key or Secret-Key packet", diagnostic.Expected)
+
+	diagnostic = DiagnoseKey(data[:len(data)-1])
+	if diagnostic == nil {
+		t.Fatal("Expected a diagnostic for a truncated key, got nil")
+	}
+	assert.Contains(t, diagnostic.Expected, "bytes of packet body")
+}
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"database/sql/driver"
+
+	"github.com/pkg/errors"
+)
+
+// The messages and session keys implement driver.Valuer and sql.Scanner, but not
+// encoding.BinaryMarshaler, which would change their gob encoding.
+
+// Value implements driver.Valuer, storing the binary message.
+func (msg *PGPMessage) Value() (driver.Value, error) {
+	return clone(msg.Data), nil
+}
+
+// Scan implements sql.Scanner, reading a binary message. A NULL value resets the message.
+func (msg *PGPMessage) Scan(src interface{}) error {
+	data, err := scanBytes(src)
+	if err != nil {
+		return err
+	}
+	msg.Data = data
+	return nil
+}
+
+// Value implements driver.Valuer, storing the joined key and data packets.
+func (msg *PGPSplitMessage) Value() (driver.Value, error) {
+	data := make([]byte, 0, len(msg.KeyPacket)+len(msg.DataPacket))
+	data = append(data, msg.KeyPacket...)
+	return append(data, msg.DataPacket...), nil
+}
+
+// Scan implements sql.Scanner, reading a binary message split into key and data packets.
+// A NULL value resets the message.
+func (msg *PGPSplitMessage) Scan(src interface{}) error {
+	data, err := scanBytes(src)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		msg.KeyPacket, msg.DataPacket = nil, nil
+		return nil
+	}
+	split, err := NewPGPMessage(data).SplitMessage()
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to split the message")
+	}
+	msg.KeyPacket, msg.DataPacket = split.KeyPacket, split.DataPacket
+	return nil
+}
+
+// Value implements driver.Valuer, storing the session key serialized with SessionKey.Serialize.
+// Warning: the key is stored in the clear in the database.
+// Wrap it first, e.g. with SessionKey.Wrap, and store the wrapped key instead.
+func (sk *SessionKey) Value() (driver.Value, error) {
+	return sk.Serialize()
+}
+
+// Scan implements sql.Scanner, reading a session key serialized with SessionKey.Serialize.
+// A NULL value resets the session key.
+func (sk *SessionKey) Scan(src interface{}) error {
+	data, err := scanBytes(src)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		sk.Key, sk.Algo = nil, ""
+		return nil
+	}
+	parsed, err := NewSessionKeyFromSerialized(data)
+	if err != nil {
+		return err
+	}
+	sk.Key, sk.Algo = clone(parsed.Key), parsed.Algo
+	return nil
+}
+
+// scanBytes returns the bytes of a database value, which the driver may reuse,
+// or nil for a NULL value.
+func scanBytes(src interface{}) ([]byte, error) {
+	switch value := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return clone(value), nil
+	case string:
+		return []byte(value), nil
+	}
+	return nil, errors.Errorf("gopenpgp: unable to scan a value of type %T", src)
+}
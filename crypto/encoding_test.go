@@ -0,0 +1,92 @@
+package crypto
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ driver.Valuer = (*PGPMessage)(nil)
+	_ sql.Scanner   = (*PGPSplitMessage)(nil)
+	_ driver.Valuer = (*SessionKey)(nil)
+	_ sql.Scanner   = (*SessionKey)(nil)
+)
+
+func TestMessageEncodingInterfaces(t *testing.T) {
+	message, err := keyRingTestPublic.Encrypt(NewPlainMessageFromString(testMessage), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	value, err := message.Value()
+	if err != nil {
+		t.Fatal("Expected no error while getting the message value, got:", err)
+	}
+	var scannedMessage PGPMessage
+	if err = scannedMessage.Scan(value); err != nil {
+		t.Fatal("Expected no error while scanning the message, got:", err)
+	}
+	assert.Exactly(t, message.GetBinary(), scannedMessage.GetBinary())
+
+	var splitMessage PGPSplitMessage
+	if err = splitMessage.Scan(message.GetBinary()); err != nil {
+		t.Fatal("Expected no error while scanning the split message, got:", err)
+	}
+	expectedSplit, err := message.SplitMessage()
+	if err != nil {
+		t.Fatal("Expected no error while splitting the message, got:", err)
+	}
+	assert.Exactly(t, expectedSplit.GetBinaryKeyPacket(), splitMessage.GetBinaryKeyPacket())
+	assert.Exactly(t, expectedSplit.GetBinaryDataPacket(), splitMessage.GetBinaryDataPacket())
+
+	value, err = splitMessage.Value()
+	if err != nil {
+		t.Fatal("Expected no error while getting the split message value, got:", err)
+	}
+	assert.Exactly(t, message.GetBinary(), value)
+
+	assert.Error(t, scannedMessage.Scan(42))
+	assert.NoError(t, splitMessage.Scan(nil))
+	assert.Empty(t, splitMessage.GetBinary())
+}
+
+func TestSessionKeyEncodingInterfaces(t *testing.T) {
+	value, err := testSessionKey.Value()
+	if err != nil {
+		t.Fatal("Expected no error while getting the session key value, got:", err)
+	}
+	var scannedKey SessionKey
+	if err = scannedKey.Scan(value); err != nil {
+		t.Fatal("Expected no error while scanning the session key, got:", err)
+	}
+	assert.Exactly(t, testSessionKey.Key, scannedKey.Key)
+	assert.Exactly(t, testSessionKey.Algo, scannedKey.Algo)
+
+	assert.Error(t, scannedKey.Scan([]byte{1}))
+	assert.NoError(t, scannedKey.Scan(nil))
+	assert.Nil(t, scannedKey.Key)
+}
+
+func TestEncodingKeepsGobFormat(t *testing.T) {
+	for _, value := range []interface{}{&PGPMessage{}, &PGPSplitMessage{}, &SessionKey{}} {
+		_, isMarshaler := value.(encoding.BinaryMarshaler)
+		assert.False(t, isMarshaler)
+	}
+
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(testSessionKey); err != nil {
+		t.Fatal("Expected no error while encoding the session key, got:", err)
+	}
+	var sessionKey SessionKey
+	if err := gob.NewDecoder(&buffer).Decode(&sessionKey); err != nil {
+		t.Fatal("Expected no error while decoding the session key, got:", err)
+	}
+	assert.Exactly(t, testSessionKey.Key, sessionKey.Key)
+	assert.Exactly(t, testSessionKey.Algo, sessionKey.Algo)
+}
@@ -0,0 +1,108 @@
+package crypto
+
+import (
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+// EncryptionOptions overrides the default parameters of the ...WithOptions encryption functions,
+// so that the occasional messages needing different parameters can be encrypted with the
+// usual keyrings, without changing the defaults of the other encryptions.
+// The default options, which are also used when the options are nil, are those of Encrypt.
+// The options can be shared by concurrent encryptions, as long as they are not modified meanwhile:
+// use Copy to derive different options from shared ones.
+type EncryptionOptions struct {
+	cipher   string
+	hash     string
+	compress bool
+}
+
+// NewEncryptionOptions creates encryption options with the default settings:
+// AES-256, the default signature hash, and no compression.
+func NewEncryptionOptions() *EncryptionOptions {
+	return &EncryptionOptions{cipher: constants.AES256}
+}
+
+// Copy returns a copy of the options, which can be modified independently.
+func (options *EncryptionOptions) Copy() *EncryptionOptions {
+	optionsCopy := *options
+	return &optionsCopy
+}
+
+// WithCipher sets the cipher of the message, one of constants.AES128, AES192 or AES256 (default),
+// and returns the options. The cipher is only used if all the recipients support it,
+// otherwise the preferred cipher of the recipients is used.
+func (options *EncryptionOptions) WithCipher(cipher string) *EncryptionOptions {
+	options.cipher = cipher
+	return options
+}
+
+// WithHash sets the hash of the embedded signature, one of constants.SHA256, SHA384, SHA512 or SHA224,
+// and returns the options. The hash is only used if all the recipients support it,
+// otherwise the preferred hash of the recipients is used.
+func (options *EncryptionOptions) WithHash(hash string) *EncryptionOptions {
+	options.hash = hash
+	return options
+}
+
+// WithCompression compresses the plaintext before encrypting it, as EncryptWithCompression,
+// and returns the options.
+func (options *EncryptionOptions) WithCompression() *EncryptionOptions {
+	options.compress = true
+	return options
+}
+
+// EncryptWithOptions encrypts a PlainMessage as Encrypt, with the given options.
+// * message    : The plaintext input as a PlainMessage.
+// * privateKey : (optional) an unlocked private keyring to include signature in the message.
+// * options    : The encryption options, the default options are used if nil.
+func (keyRing *KeyRing) EncryptWithOptions(message *PlainMessage, privateKey *KeyRing, options *EncryptionOptions) (*PGPMessage, error) {
+	return asymmetricEncrypt(message, keyRing, privateKey, options, nil)
+}
+
+// EncryptStreamWithOptions is used to encrypt data as a Writer, as EncryptStream, with the given options.
+// The default options are used if options is nil.
+func (keyRing *KeyRing) EncryptStreamWithOptions(
+	pgpMessageWriter Writer,
+	plainMessageMetadata *PlainMessageMetadata,
+	signKeyRing *KeyRing,
+	options *EncryptionOptions,
+) (plainMessageWriter WriteCloser, err error) {
+	return encryptStream(
+		keyRing,
+		pgpMessageWriter,
+		pgpMessageWriter,
+		plainMessageMetadata,
+		signKeyRing,
+		options,
+		nil,
+	)
+}
+
+// getConfig returns the go-crypto configuration of the options.
+func (options *EncryptionOptions) getConfig() (*packet.Config, error) {
+	cipher, ok := symKeyAlgos[options.cipher]
+	if !ok || cipher == packet.CipherCAST5 || cipher == packet.Cipher3DES {
+		return nil, errors.New("gopenpgp: unsupported encryption cipher: " + options.cipher)
+	}
+	config := &packet.Config{
+		DefaultCipher: cipher,
+		Time:          getTimeGenerator(),
+		Rand:          getRandomSource(),
+	}
+
+	if options.hash != "" {
+		hash, ok := plaintextHashAlgos[options.hash]
+		if !ok {
+			return nil, errors.New("gopenpgp: unsupported signature hash: " + options.hash)
+		}
+		config.DefaultHash = hash
+	}
+
+	if options.compress {
+		config.DefaultCompressionAlgo = constants.DefaultCompression
+		config.CompressionConfig = &packet.CompressionConfig{Level: constants.DefaultCompressionLevel}
+	}
+	return config, nil
+}
@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptWithOptions(t *testing.T) {
+	message := NewPlainMessageFromString("options")
+	options := NewEncryptionOptions().WithCipher(constants.AES128).WithHash(constants.SHA512)
+
+	ciphertext, err := keyRingTestPublic.EncryptWithOptions(message, keyRingTestPrivate, options)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	split, err := ciphertext.SplitMessage()
+	if err != nil {
+		t.Fatal("Expected no error while splitting message, got:", err)
+	}
+	sessionKey, err := keyRingTestPrivate.DecryptSessionKey(split.GetBinaryKeyPacket())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting session key, got:", err)
+	}
+	assert.Exactly(t, constants.AES128, sessionKey.Algo)
+
+	reader, err := keyRingTestPrivate.DecryptStream(bytes.NewReader(ciphertext.GetBinary()), keyRingTestPublic, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	decrypted, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal("Expected no error while reading plaintext, got:", err)
+	}
+	assert.Exactly(t, "options", string(decrypted))
+	details, err := reader.VerifySignatureDetails()
+	if err != nil {
+		t.Fatal("Expected no error while verifying, got:", err)
+	}
+	assert.Exactly(t, constants.SHA512, details.HashAlgorithm)
+
+	var buffer bytes.Buffer
+	writer, err := keyRingTestPublic.EncryptStreamWithOptions(&buffer, nil, nil, NewEncryptionOptions().WithCompression())
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if _, err = writer.Write(message.GetBinary()); err != nil {
+		t.Fatal("Expected no error while writing plaintext, got:", err)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal("Expected no error while closing writer, got:", err)
+	}
+	decryptedMessage, err := keyRingTestPrivate.Decrypt(NewPGPMessage(buffer.Bytes()), nil, 0)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, "options", decryptedMessage.GetString())
+
+	_, err = keyRingTestPublic.EncryptWithOptions(message, nil, NewEncryptionOptions().WithCipher(constants.CAST5))
+	assert.Error(t, err)
+	_, err = keyRingTestPublic.EncryptWithOptions(message, nil, NewEncryptionOptions().WithHash("md5"))
+	assert.Error(t, err)
+}
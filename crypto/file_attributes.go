@@ -0,0 +1,114 @@
+package crypto
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+// FileAttributes contains the POSIX attributes of a file, which are recorded
+// as notations of the embedded signature when encrypting the file, so that
+// they are authenticated along with the data.
+type FileAttributes struct {
+	// Mode contains the permission bits and the file type bits, as st_mode.
+	Mode int64
+	// UID and GID are the numeric owner and group of the file.
+	UID int64
+	GID int64
+	// ModTime is the unix modification time of the file.
+	ModTime int64
+	// ModTimeNanoseconds is the sub-second part of the modification time,
+	// which the literal data packet can't carry.
+	ModTimeNanoseconds int64
+}
+
+// NewFileAttributes creates a new FileAttributes from the given values.
+func NewFileAttributes(mode, uid, gid, modTime, modTimeNanoseconds int64) *FileAttributes {
+	return &FileAttributes{
+		Mode:               mode,
+		UID:                uid,
+		GID:                gid,
+		ModTime:            modTime,
+		ModTimeNanoseconds: modTimeNanoseconds,
+	}
+}
+
+// GetModTime returns the full modification time of the file.
+func (attributes *FileAttributes) GetModTime() time.Time {
+	return time.Unix(attributes.ModTime, attributes.ModTimeNanoseconds)
+}
+
+// GetFileAttributes returns the file attributes recorded in the embedded signature,
+// or nil if the message doesn't carry any.
+// The attributes are only returned once the signature has been verified,
+// hence this method needs to be called once all the data has been read.
+func (msg *PlainMessageReader) GetFileAttributes() (*FileAttributes, error) {
+	if err := msg.VerifySignature(); err != nil {
+		return nil, err
+	}
+	return parseFileAttributes(msg.details)
+}
+
+func (attributes *FileAttributes) getNotations() []*packet.Notation {
+	return []*packet.Notation{
+		fileAttributeNotation(constants.FileModeNotationName, "0"+strconv.FormatInt(attributes.Mode, 8)),
+		fileAttributeNotation(constants.FileUIDNotationName, strconv.FormatInt(attributes.UID, 10)),
+		fileAttributeNotation(constants.FileGIDNotationName, strconv.FormatInt(attributes.GID, 10)),
+		fileAttributeNotation(constants.FileModTimeNotationName, attributes.GetModTime().UTC().Format(time.RFC3339Nano)),
+	}
+}
+
+func fileAttributeNotation(name, value string) *packet.Notation {
+	return &packet.Notation{
+		Name:            name,
+		Value:           []byte(value),
+		IsCritical:      false,
+		IsHumanReadable: true,
+	}
+}
+
+func parseFileAttributes(md *openpgp.MessageDetails) (*FileAttributes, error) {
+	if md.Signature == nil {
+		return nil, nil
+	}
+
+	var attributes *FileAttributes
+	for _, notation := range md.Signature.Notations {
+		value := string(notation.Value)
+		var err error
+		switch notation.Name {
+		case constants.FileModeNotationName:
+			attributes = ensureFileAttributes(attributes)
+			attributes.Mode, err = strconv.ParseInt(value, 8, 64)
+		case constants.FileUIDNotationName:
+			attributes = ensureFileAttributes(attributes)
+			attributes.UID, err = strconv.ParseInt(value, 10, 64)
+		case constants.FileGIDNotationName:
+			attributes = ensureFileAttributes(attributes)
+			attributes.GID, err = strconv.ParseInt(value, 10, 64)
+		case constants.FileModTimeNotationName:
+			attributes = ensureFileAttributes(attributes)
+			var modTime time.Time
+			modTime, err = time.Parse(time.RFC3339Nano, value)
+			attributes.ModTime = modTime.Unix()
+			attributes.ModTimeNanoseconds = int64(modTime.Nanosecond())
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: invalid file attribute notation "+notation.Name)
+		}
+	}
+	return attributes, nil
+}
+
+func ensureFileAttributes(attributes *FileAttributes) *FileAttributes {
+	if attributes == nil {
+		return &FileAttributes{}
+	}
+	return attributes
+}
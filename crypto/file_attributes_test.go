@@ -0,0 +1,100 @@
+package crypto
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encryptWithFileAttributes(t *testing.T, attributes *FileAttributes, signKeyRing *KeyRing) ([]byte, error) {
+	var ciphertextBuf bytes.Buffer
+	metadata := NewPlainMessageMetadata(true, "backup.tar", attributes.ModTime)
+	metadata.Attributes = attributes
+	messageWriter, err := keyRingTestPublic.EncryptStream(&ciphertextBuf, metadata, signKeyRing)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := messageWriter.Write([]byte("Hello World!")); err != nil {
+		t.Fatal("Expected no error while writing data, got:", err)
+	}
+	if err := messageWriter.Close(); err != nil {
+		t.Fatal("Expected no error while closing plaintext writer, got:", err)
+	}
+	return ciphertextBuf.Bytes(), nil
+}
+
+func TestFileAttributesRoundTrip(t *testing.T) {
+	attributes := NewFileAttributes(0100640, 1000, 100, 1600000000, 123456789)
+	ciphertext, err := encryptWithFileAttributes(t, attributes, keyRingTestPrivate)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting with file attributes, got:", err)
+	}
+
+	reader, err := keyRingTestPrivate.DecryptStream(bytes.NewReader(ciphertext), keyRingTestPublic, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting stream, got:", err)
+	}
+	_, err = reader.GetFileAttributes()
+	assert.Error(t, err, "attributes must not be exposed before verification")
+
+	if _, err := ioutil.ReadAll(reader); err != nil {
+		t.Fatal("Expected no error while reading the decrypted data, got:", err)
+	}
+	decrypted, err := reader.GetFileAttributes()
+	if err != nil {
+		t.Fatal("Expected no error while getting the file attributes, got:", err)
+	}
+	assert.Exactly(t, attributes, decrypted)
+	assert.Equal(t, int64(1600000000), reader.GetMetadata().ModTime)
+	assert.Equal(t, 123456789, decrypted.GetModTime().Nanosecond())
+}
+
+func TestFileAttributesUnverified(t *testing.T) {
+	attributes := NewFileAttributes(0100600, 0, 0, 1600000000, 0)
+	ciphertext, err := encryptWithFileAttributes(t, attributes, keyRingTestPrivate)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting with file attributes, got:", err)
+	}
+
+	reader, err := keyRingTestPrivate.DecryptStream(bytes.NewReader(ciphertext), nil, 0)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting stream, got:", err)
+	}
+	if _, err := ioutil.ReadAll(reader); err != nil {
+		t.Fatal("Expected no error while reading the decrypted data, got:", err)
+	}
+	_, err = reader.GetFileAttributes()
+	assert.Error(t, err)
+}
+
+func TestFileAttributesRequireSignature(t *testing.T) {
+	_, err := encryptWithFileAttributes(t, NewFileAttributes(0100644, 0, 0, 1600000000, 0), nil)
+	assert.Error(t, err)
+}
+
+func TestFileAttributesAbsent(t *testing.T) {
+	var ciphertextBuf bytes.Buffer
+	messageWriter, err := keyRingTestPublic.EncryptStream(&ciphertextBuf, testMeta, keyRingTestPrivate)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting stream, got:", err)
+	}
+	_, _ = messageWriter.Write([]byte("Hello World!"))
+	if err := messageWriter.Close(); err != nil {
+		t.Fatal("Expected no error while closing plaintext writer, got:", err)
+	}
+
+	reader, err := keyRingTestPrivate.DecryptStream(&ciphertextBuf, keyRingTestPublic, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting stream, got:", err)
+	}
+	if _, err := ioutil.ReadAll(reader); err != nil {
+		t.Fatal("Expected no error while reading the decrypted data, got:", err)
+	}
+	attributes, err := reader.GetFileAttributes()
+	if err != nil {
+		t.Fatal("Expected no error while getting the file attributes, got:", err)
+	}
+	assert.Nil(t, attributes)
+}
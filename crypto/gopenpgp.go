@@ -1,14 +1,26 @@
 // Package crypto provides a high-level API for common OpenPGP functionality.
 package crypto
 
-import "sync"
+import (
+	"io"
+	"sync"
+)
 
 // GopenPGP is used as a "namespace" for many of the functions in this package.
-// It is a struct that keeps track of time skew between server and client.
+// It is a struct that keeps track of time skew between server and client,
+// of the source of randomness, of the memory budget, of the metrics collector, and of the vectorized GCM switch.
 type GopenPGP struct {
-	latestServerTime int64
-	generationOffset int64
-	lock             *sync.RWMutex
+	latestServerTime      int64
+	generationOffset      int64
+	randomSource          io.Reader
+	savedRandomSource     io.Reader
+	reproducible          bool
+	fixedTime             int64
+	maxPlaintextSize      int64
+	maxReusedBufferSize   int64
+	metricsCollector      MetricsCollector
+	vectorizedGCMDisabled bool
+	lock                  *sync.RWMutex
 }
 
 var pgp = GopenPGP{
@@ -10,6 +10,7 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ProtonMail/gopenpgp/v2/armor"
 	"github.com/ProtonMail/gopenpgp/v2/constants"
@@ -17,12 +18,15 @@ import (
 
 	openpgp "github.com/ProtonMail/go-crypto/openpgp"
 	packet "github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/go-crypto/openpgp/s2k"
 )
 
 // Key contains a single private or public key.
 type Key struct {
 	// PGP entities in this keyring.
 	entity *openpgp.Entity
+	// Raw packets preserved by NewKeyWithUnknownPacketPolicy.
+	unknownPackets [][]byte
 }
 
 // --- Create Key object
@@ -91,7 +95,12 @@ func (key *Key) Copy() (*Key, error) {
 		return nil, err
 	}
 
-	return NewKey(serialized)
+	copied, err := NewKey(serialized)
+	if err != nil {
+		return nil, err
+	}
+	copied.unknownPackets = key.unknownPackets
+	return copied, nil
 }
 
 // Lock locks a copy of the key.
@@ -115,7 +124,7 @@ func (key *Key) Lock(passphrase []byte) (*Key, error) {
 	}
 
 	if lockedKey.entity.PrivateKey != nil && !lockedKey.entity.PrivateKey.Dummy() {
-		err = lockedKey.entity.PrivateKey.Encrypt(passphrase)
+		err = lockedKey.entity.PrivateKey.EncryptWithConfig(passphrase, lockConfig())
 		if err != nil {
 			return nil, errors.Wrap(err, "gopenpgp: error in locking key")
 		}
@@ -123,7 +132,7 @@ func (key *Key) Lock(passphrase []byte) (*Key, error) {
 
 	for _, sub := range lockedKey.entity.Subkeys {
 		if sub.PrivateKey != nil && !sub.PrivateKey.Dummy() {
-			if err := sub.PrivateKey.Encrypt(passphrase); err != nil {
+			if err := sub.PrivateKey.EncryptWithConfig(passphrase, lockConfig()); err != nil {
 				return nil, errors.Wrap(err, "gopenpgp: error in locking sub key")
 			}
 		}
@@ -200,6 +209,9 @@ func (key *Key) Serialize() ([]byte, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "gopenpgp: error in serializing key")
 	}
+	for _, unknownPacket := range key.unknownPackets {
+		buffer.Write(unknownPacket)
+	}
 
 	return buffer.Bytes(), nil
 }
@@ -251,11 +263,15 @@ func (key *Key) GetArmoredPublicKeyWithCustomHeaders(comment, version string) (s
 }
 
 // GetPublicKey returns the unarmored public keys from this keyring.
+// Local certifications are not exported.
 func (key *Key) GetPublicKey() (b []byte, err error) {
 	var outBuf bytes.Buffer
-	if err = key.entity.Serialize(&outBuf); err != nil {
+	if err = serializeForExport(key.entity, &outBuf); err != nil {
 		return nil, errors.Wrap(err, "gopenpgp: error in serializing public key")
 	}
+	for _, unknownPacket := range key.getPublicUnknownPackets() {
+		outBuf.Write(unknownPacket)
+	}
 
 	return outBuf.Bytes(), nil
 }
@@ -274,6 +290,63 @@ func (key *Key) CanEncrypt() bool {
 	return canEncrypt
 }
 
+// CanEncryptAt returns true if any of the subkeys can be used for encryption
+// at the given unix time, considering key flags, expiration and revocation.
+func (key *Key) CanEncryptAt(unixTime int64) bool {
+	_, canEncrypt := key.entity.EncryptionKey(time.Unix(unixTime, 0))
+	return canEncrypt
+}
+
+// CanSignAt returns true if any of the subkeys can be used for signing
+// or verification at the given unix time, considering key flags, expiration and revocation.
+// It does not check whether the private key material is available.
+func (key *Key) CanSignAt(unixTime int64) bool {
+	_, canSign := key.entity.SigningKey(time.Unix(unixTime, 0))
+	return canSign
+}
+
+// CanAuthenticateAt returns true if any of the subkeys can be used for authentication
+// at the given unix time, considering key flags, expiration and revocation.
+func (key *Key) CanAuthenticateAt(unixTime int64) bool {
+	_, canAuthenticate := key.authenticationKey(time.Unix(unixTime, 0))
+	return canAuthenticate
+}
+
+// authenticationKey returns the newest subkey that can be used for authentication at the given time,
+// or the primary key if none of the subkeys can but the primary key can.
+func (key *Key) authenticationKey(now time.Time) (*packet.PublicKey, bool) {
+	entity := key.entity
+	i := entity.PrimaryIdentity()
+	if i == nil || i.SelfSignature == nil ||
+		entity.PrimaryKey.KeyExpired(i.SelfSignature, now) || // primary key has expired
+		i.SelfSignature.SigExpired(now) || // user ID self-signature has expired
+		entity.Revoked(now) || // primary key has been revoked
+		i.Revoked(now) { // user ID has been revoked
+		return nil, false
+	}
+
+	var authenticationKey *packet.PublicKey
+	for _, subkey := range entity.Subkeys {
+		if subkey.Sig.FlagsValid && subkey.Sig.FlagAuthenticate &&
+			subkey.PublicKey.PubKeyAlgo.CanSign() &&
+			!subkey.PublicKey.KeyExpired(subkey.Sig, now) &&
+			!subkey.Sig.SigExpired(now) &&
+			!subkey.Revoked(now) &&
+			(authenticationKey == nil || subkey.PublicKey.CreationTime.After(authenticationKey.CreationTime)) {
+			authenticationKey = subkey.PublicKey
+		}
+	}
+	if authenticationKey != nil {
+		return authenticationKey, true
+	}
+
+	if i.SelfSignature.FlagsValid && i.SelfSignature.FlagAuthenticate &&
+		entity.PrimaryKey.PubKeyAlgo.CanSign() {
+		return entity.PrimaryKey, true
+	}
+	return nil, false
+}
+
 // IsExpired checks whether the key is expired.
 func (key *Key) IsExpired() bool {
 	i := key.entity.PrimaryIdentity()
@@ -365,6 +438,18 @@ func (key *Key) GetFingerprint() string {
 	return hex.EncodeToString(key.entity.PrimaryKey.Fingerprint)
 }
 
+// SubkeyFingerprints returns the hex encoded fingerprints of the primary key and of all the subkeys,
+// indexed by their hex encoded key IDs, e.g. to find the key of a key packet from its key ID.
+func (key *Key) SubkeyFingerprints() map[string]string {
+	fingerprints := map[string]string{
+		keyIDToHex(key.entity.PrimaryKey.KeyId): hex.EncodeToString(key.entity.PrimaryKey.Fingerprint),
+	}
+	for _, subkey := range key.entity.Subkeys {
+		fingerprints[keyIDToHex(subkey.PublicKey.KeyId)] = hex.EncodeToString(subkey.PublicKey.Fingerprint)
+	}
+	return fingerprints
+}
+
 // GetSHA256Fingerprints computes the SHA256 fingerprints of the key and subkeys.
 func (key *Key) GetSHA256Fingerprints() (fingerprints []string) {
 	fingerprints = append(fingerprints, hex.EncodeToString(getSHA256FingerprintBytes(key.entity.PrimaryKey)))
@@ -407,6 +492,20 @@ func getSHA256FingerprintBytes(pk *packet.PublicKey) []byte {
 	return fingerPrint.Sum(nil)
 }
 
+// lockConfig returns the configuration used to encrypt private keys,
+// which matches the default one of go-crypto, with the configured source of randomness.
+func lockConfig() *packet.Config {
+	return &packet.Config{
+		S2KConfig: &s2k.Config{
+			S2KMode:  s2k.IteratedSaltedS2K,
+			S2KCount: 65536,
+			Hash:     crypto.SHA256,
+		},
+		DefaultCipher: packet.CipherAES256,
+		Rand:          getRandomSource(),
+	}
+}
+
 // readFrom reads unarmored and armored keys from r and adds them to the keyring.
 func (key *Key) readFrom(r io.Reader, armored bool) error {
 	var err error
@@ -444,18 +543,7 @@ func generateKey(
 
 	comments := ""
 
-	cfg := &packet.Config{
-		Algorithm:              packet.PubKeyAlgoRSA,
-		RSABits:                bits,
-		Time:                   getKeyGenerationTimeGenerator(),
-		DefaultHash:            crypto.SHA256,
-		DefaultCipher:          packet.CipherAES256,
-		DefaultCompressionAlgo: packet.CompressionZLIB,
-	}
-
-	if keyType == "x25519" {
-		cfg.Algorithm = packet.PubKeyAlgoEdDSA
-	}
+	cfg := keyGenerationConfig(keyType, bits)
 
 	if prime1 != nil && prime2 != nil && prime3 != nil && prime4 != nil {
 		var bigPrimes [4]*big.Int
@@ -483,6 +571,24 @@ func generateKey(
 	return NewKeyFromEntity(newEntity)
 }
 
+// keyGenerationConfig returns the configuration to generate a key of the given keyType ("rsa" or "x25519").
+func keyGenerationConfig(keyType string, bits int) *packet.Config {
+	cfg := &packet.Config{
+		Algorithm:              packet.PubKeyAlgoRSA,
+		RSABits:                bits,
+		Time:                   getKeyGenerationTimeGenerator(),
+		DefaultHash:            crypto.SHA256,
+		DefaultCipher:          packet.CipherAES256,
+		DefaultCompressionAlgo: packet.CompressionZLIB,
+		Rand:                   getRandomSource(),
+	}
+
+	if keyType == "x25519" {
+		cfg.Algorithm = packet.PubKeyAlgoEdDSA
+	}
+	return cfg
+}
+
 // keyIDToHex casts a keyID to hex with the correct padding.
 func keyIDToHex(keyID uint64) string {
 	return fmt.Sprintf("%016v", strconv.FormatUint(keyID, 16))
@@ -0,0 +1,156 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"hash"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+const (
+	// sigTypeAttestation is the type of the attestation key signatures, see draft-dkg-openpgp-1pa3pc.
+	sigTypeAttestation packet.SignatureType = 0x16
+
+	attestedCertificationSubpacketType = 37
+)
+
+// AttestCertifications creates an attestation key signature (1PA3PC) over a user ID of the key,
+// listing the third-party certifications of this user ID which the key holder allows keyservers
+// to distribute. The certifications are selected by the hex key IDs of their issuers;
+// an attestation without certifications withdraws all the previous attestations.
+// The key must be unlocked. The attestation is returned as a detached signature, to be
+// published along with the key, since keys containing attestations can't be parsed by this library.
+func (key *Key) AttestCertifications(userID string, issuerKeyIDs ...string) (*PGPSignature, error) {
+	primaryKey := key.entity.PrivateKey
+	if primaryKey == nil || primaryKey.Encrypted || primaryKey.Dummy() {
+		return nil, errors.New("gopenpgp: the key must be an unlocked private key to attest certifications")
+	}
+	if primaryKey.Version != 4 {
+		return nil, errors.New("gopenpgp: attestations are only supported for version 4 keys")
+	}
+	identity, ok := key.entity.Identities[userID]
+	if !ok {
+		return nil, errors.New("gopenpgp: user ID not found: " + userID)
+	}
+
+	var digests [][]byte
+	for _, issuerKeyID := range issuerKeyIDs {
+		found := false
+		for _, sig := range identity.Signatures {
+			if !isThirdPartyCertification(key.entity, sig) || !strings.EqualFold(keyIDToHex(*sig.IssuerKeyId), issuerKeyID) {
+				continue
+			}
+			digest, err := attestationDigest(sig)
+			if err != nil {
+				return nil, err
+			}
+			digests = append(digests, digest)
+			found = true
+		}
+		if !found {
+			return nil, errors.New("gopenpgp: no certification found from issuer " + issuerKeyID)
+		}
+	}
+	// The attested digests are sorted in binary order
+	sort.Slice(digests, func(i, j int) bool { return bytes.Compare(digests[i], digests[j]) < 0 })
+
+	config := &packet.Config{DefaultHash: crypto.SHA512, Time: getTimeGenerator(), Rand: getRandomSource()}
+	sig := &packet.Signature{
+		SigType:           sigTypeAttestation,
+		PubKeyAlgo:        primaryKey.PubKeyAlgo,
+		Hash:              config.Hash(),
+		CreationTime:      config.Now(),
+		IssuerKeyId:       &primaryKey.KeyId,
+		IssuerFingerprint: primaryKey.Fingerprint,
+	}
+	newHash := func() hash.Hash {
+		h := sig.Hash.New()
+		writeUserIDSignaturePrefix(h, &primaryKey.PublicKey, userID)
+		return h
+	}
+	attested := append([]byte{attestedCertificationSubpacketType}, bytes.Join(digests, nil)...)
+	sig, err := signWithSubpackets(sig, newHash, primaryKey, config, attested)
+	if err != nil {
+		return nil, err
+	}
+
+	var serialized bytes.Buffer
+	if err := sig.Serialize(&serialized); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in serializing attestation")
+	}
+	return NewPGPSignature(serialized.Bytes()), nil
+}
+
+// GetAttestedCertifications verifies an attestation created with AttestCertifications
+// over a user ID of the key, and returns the third-party certifications of the key
+// which it attests.
+func (key *Key) GetAttestedCertifications(userID string, attestation *PGPSignature) ([]*Certification, error) {
+	identity, ok := key.entity.Identities[userID]
+	if !ok {
+		return nil, errors.New("gopenpgp: user ID not found: " + userID)
+	}
+	p, err := packet.Read(bytes.NewReader(attestation.Data))
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to parse attestation")
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok || sig.SigType != sigTypeAttestation || !sig.Hash.Available() {
+		return nil, errors.New("gopenpgp: not an attestation key signature")
+	}
+	if !sig.CheckKeyIdOrFingerprint(key.entity.PrimaryKey) {
+		return nil, errors.New("gopenpgp: the attestation was not issued by the key")
+	}
+	h := sig.Hash.New()
+	writeUserIDSignaturePrefix(h, key.entity.PrimaryKey, userID)
+	if err := key.entity.PrimaryKey.VerifySignature(h, sig); err != nil {
+		return nil, newSignatureFailed(err)
+	}
+
+	attested, err := getHashedSubpacket(sig, attestedCertificationSubpacketType)
+	if err != nil {
+		return nil, err
+	}
+
+	certifications := []*Certification{}
+	for _, certification := range identity.Signatures {
+		if !isThirdPartyCertification(key.entity, certification) {
+			continue
+		}
+		digest, err := attestationDigest(certification)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+len(digest) <= len(attested); i += len(digest) {
+			if bytes.Equal(attested[i:i+len(digest)], digest) {
+				certifications = append(certifications, newCertification(userID, certification))
+				break
+			}
+		}
+	}
+	return certifications, nil
+}
+
+// attestationDigest returns the digest of a certification listed by an attestation,
+// which is computed as the hash of a third-party confirmation signature.
+func attestationDigest(certification *packet.Signature) ([]byte, error) {
+	var serialized bytes.Buffer
+	if err := certification.Serialize(&serialized); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in serializing certification")
+	}
+	raw, err := parseRawSignature(serialized.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return raw.confirmationHash(crypto.SHA512).Sum(nil), nil
+}
+
+// writeUserIDSignaturePrefix writes the data hashed by the signatures over a user ID, see RFC 4880, section 5.2.4.
+func writeUserIDSignaturePrefix(h hash.Hash, primaryKey *packet.PublicKey, userID string) {
+	_ = primaryKey.SerializeForHash(h)
+	_, _ = h.Write([]byte{0xb4, byte(len(userID) >> 24), byte(len(userID) >> 16), byte(len(userID) >> 8), byte(len(userID))})
+	_, _ = h.Write([]byte(userID))
+}
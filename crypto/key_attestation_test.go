@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttestCertifications(t *testing.T) {
+	for _, keyType := range []string{"x25519", "rsa"} {
+		key, err := GenerateKey(keyTestName, keyTestDomain, keyType, 1024)
+		if err != nil {
+			t.Fatal("Expected no error while generating key, got:", err)
+		}
+		firstSigner, err := GenerateKey("first", "first@protonmail.ch", "x25519", 0)
+		if err != nil {
+			t.Fatal("Expected no error while generating key, got:", err)
+		}
+		secondSigner, err := GenerateKey("second", "second@protonmail.ch", "x25519", 0)
+		if err != nil {
+			t.Fatal("Expected no error while generating key, got:", err)
+		}
+		certifyKey(t, key, firstSigner, GetTime())
+		certifyKey(t, key, secondSigner, GetTime())
+		userID := key.entity.PrimaryIdentity().Name
+
+		attestation, err := key.AttestCertifications(userID, firstSigner.GetHexKeyID())
+		if err != nil {
+			t.Fatal("Expected no error while attesting certifications, got:", err)
+		}
+		publicKey, err := key.ToPublic()
+		if err != nil {
+			t.Fatal("Expected no error while getting the public key, got:", err)
+		}
+		certifications, err := publicKey.GetAttestedCertifications(userID, attestation)
+		if err != nil {
+			t.Fatal("Expected no error while verifying the attestation, got:", err)
+		}
+		assert.Len(t, certifications, 1)
+		assert.Exactly(t, firstSigner.GetHexKeyID(), certifications[0].IssuerKeyID)
+		assert.Exactly(t, userID, certifications[0].UserID)
+
+		empty, err := key.AttestCertifications(userID)
+		if err != nil {
+			t.Fatal("Expected no error while attesting no certifications, got:", err)
+		}
+		certifications, err = publicKey.GetAttestedCertifications(userID, empty)
+		if err != nil {
+			t.Fatal("Expected no error while verifying the attestation, got:", err)
+		}
+		assert.Empty(t, certifications)
+
+		_, err = key.AttestCertifications(userID, "0123456789abcdef")
+		assert.Error(t, err)
+		_, err = key.AttestCertifications("unknown <unknown@example.com>")
+		assert.Error(t, err)
+		_, err = publicKey.AttestCertifications(userID)
+		assert.Error(t, err)
+
+		// The attestation is bound to the user ID and to the key
+		otherUserID := firstSigner.entity.PrimaryIdentity().Name
+		_, err = firstSigner.GetAttestedCertifications(otherUserID, attestation)
+		assert.Error(t, err)
+
+		tampered := NewPGPSignature(clone(attestation.GetBinary()))
+		tampered.Data[len(tampered.Data)-1] ^= 1
+		_, err = publicKey.GetAttestedCertifications(userID, tampered)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,99 @@
+package crypto
+
+import (
+	"crypto"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+// BindSubkey returns a copy of the key with the subkey of source with the given hex key ID
+// bound under its primary key, e.g. to consolidate identities or to import a subkey generated elsewhere.
+// The new binding signature keeps the key flags and the expiration of the original binding,
+// and signing subkeys are cross-certified again.
+// Both the key and the subkey of source must be unlocked.
+func (key *Key) BindSubkey(source *Key, subkeyID string) (*Key, error) {
+	primaryKey := key.entity.PrivateKey
+	if primaryKey == nil || primaryKey.Encrypted || primaryKey.Dummy() {
+		return nil, errors.New("gopenpgp: the key must be an unlocked private key to bind subkeys")
+	}
+	for _, subkey := range key.entity.Subkeys {
+		if strings.EqualFold(keyIDToHex(subkey.PublicKey.KeyId), subkeyID) {
+			return nil, errors.New("gopenpgp: subkey already bound to the key: " + subkeyID)
+		}
+	}
+
+	sourceCopy, err := source.Copy()
+	if err != nil {
+		return nil, err
+	}
+	var subkey *openpgp.Subkey
+	for i := range sourceCopy.entity.Subkeys {
+		if strings.EqualFold(keyIDToHex(sourceCopy.entity.Subkeys[i].PublicKey.KeyId), subkeyID) {
+			subkey = &sourceCopy.entity.Subkeys[i]
+			break
+		}
+	}
+	if subkey == nil {
+		return nil, errors.New("gopenpgp: subkey not found: " + subkeyID)
+	}
+	if subkey.PrivateKey == nil || subkey.PrivateKey.Encrypted || subkey.PrivateKey.Dummy() {
+		return nil, errors.New("gopenpgp: the subkey must be unlocked to be bound")
+	}
+
+	bound, err := key.Copy()
+	if err != nil {
+		return nil, err
+	}
+	binding, err := newSubkeyBinding(bound.entity.PrivateKey, subkey)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in binding subkey")
+	}
+	bound.entity.Subkeys = append(bound.entity.Subkeys, openpgp.Subkey{
+		PublicKey:  subkey.PublicKey,
+		PrivateKey: subkey.PrivateKey,
+		Sig:        binding,
+	})
+	return bound, nil
+}
+
+// newSubkeyBinding creates a binding signature of the subkey by primaryKey,
+// with the key flags and the expiration of the current binding of the subkey.
+func newSubkeyBinding(primaryKey *packet.PrivateKey, subkey *openpgp.Subkey) (*packet.Signature, error) {
+	config := &packet.Config{DefaultHash: crypto.SHA256, Time: getTimeGenerator(), Rand: getRandomSource()}
+	binding := &packet.Signature{
+		Version:                   primaryKey.Version,
+		SigType:                   packet.SigTypeSubkeyBinding,
+		PubKeyAlgo:                primaryKey.PubKeyAlgo,
+		Hash:                      config.Hash(),
+		CreationTime:              config.Now(),
+		IssuerKeyId:               &primaryKey.KeyId,
+		IssuerFingerprint:         primaryKey.Fingerprint,
+		KeyLifetimeSecs:           subkey.Sig.KeyLifetimeSecs,
+		FlagsValid:                subkey.Sig.FlagsValid,
+		FlagCertify:               subkey.Sig.FlagCertify,
+		FlagSign:                  subkey.Sig.FlagSign,
+		FlagEncryptCommunications: subkey.Sig.FlagEncryptCommunications,
+		FlagEncryptStorage:        subkey.Sig.FlagEncryptStorage,
+		FlagAuthenticate:          subkey.Sig.FlagAuthenticate,
+	}
+	if binding.FlagSign || binding.FlagCertify {
+		binding.EmbeddedSignature = &packet.Signature{
+			Version:      subkey.PrivateKey.Version,
+			SigType:      packet.SigTypePrimaryKeyBinding,
+			PubKeyAlgo:   subkey.PrivateKey.PubKeyAlgo,
+			Hash:         config.Hash(),
+			CreationTime: binding.CreationTime,
+			IssuerKeyId:  &subkey.PrivateKey.KeyId,
+		}
+		if err := binding.EmbeddedSignature.CrossSignKey(subkey.PublicKey, &primaryKey.PublicKey, subkey.PrivateKey, config); err != nil {
+			return nil, err
+		}
+	}
+	if err := binding.SignKey(subkey.PublicKey, primaryKey, config); err != nil {
+		return nil, err
+	}
+	return binding, nil
+}
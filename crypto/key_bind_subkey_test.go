@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindSubkey(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	source, err := GenerateKeyWithSubkeys(
+		"source", "source@protonmail.ch", "x25519", 0,
+		NewSubkeySpec("x25519", 0, true, false, false),
+		NewSubkeySpec("rsa", 1024, false, true, false),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	signingSubkeyID := keyIDToHex(source.entity.Subkeys[0].PublicKey.KeyId)
+	encryptionSubkeyID := keyIDToHex(source.entity.Subkeys[1].PublicKey.KeyId)
+
+	bound, err := key.BindSubkey(source, signingSubkeyID)
+	if err != nil {
+		t.Fatal("Expected no error while binding subkey, got:", err)
+	}
+	bound, err = bound.BindSubkey(source, encryptionSubkeyID)
+	if err != nil {
+		t.Fatal("Expected no error while binding subkey, got:", err)
+	}
+	assert.Len(t, key.entity.Subkeys, 1, "the original key is not modified")
+
+	// Parse the serialized key to check the binding signatures
+	serialized, err := bound.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+	parsed, err := NewKey(serialized)
+	if err != nil {
+		t.Fatal("Expected no error while parsing key, got:", err)
+	}
+	subkeys := parsed.entity.Subkeys
+	assert.Len(t, subkeys, 3)
+	assert.True(t, subkeys[1].Sig.FlagSign)
+	assert.NotNil(t, subkeys[1].Sig.EmbeddedSignature)
+	assert.True(t, subkeys[2].Sig.FlagEncryptCommunications)
+
+	keyRing, err := NewKeyRing(parsed)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	signature, err := keyRing.SignDetached(NewPlainMessageFromString("bound"))
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	signatureKeyIDs, _ := signature.GetSignatureKeyIDs()
+	assert.Exactly(t, []uint64{subkeys[1].PublicKey.KeyId}, signatureKeyIDs)
+	assert.NoError(t, keyRing.VerifyDetached(NewPlainMessageFromString("bound"), signature, GetUnixTime()))
+
+	_, err = bound.BindSubkey(source, signingSubkeyID)
+	assert.Error(t, err, "the subkey is already bound")
+	_, err = key.BindSubkey(source, "0000000000000000")
+	assert.Error(t, err)
+	locked, err := source.Lock(testMailboxPassword)
+	if err != nil {
+		t.Fatal("Expected no error while locking key, got:", err)
+	}
+	_, err = key.BindSubkey(locked, signingSubkeyID)
+	assert.Error(t, err)
+}
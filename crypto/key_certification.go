@@ -0,0 +1,212 @@
+package crypto
+
+import (
+	"crypto"
+	"hash"
+	"io"
+	"math"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+// exportableCertificationSubpacketType is the type of the exportable certification subpacket.
+const exportableCertificationSubpacketType = 4
+
+// CertificationOptions are the optional properties of a third-party certification.
+type CertificationOptions struct {
+	// Lifetime is the number of seconds after its creation at which the certification expires,
+	// 0 if it does not expire.
+	Lifetime int64
+	// Local marks the certification as non-exportable: it is only meant for the issuer,
+	// and it is stripped when the key is exported.
+	Local bool
+	// TrustDepth makes the certification a trust signature if not 0: the certified key is trusted
+	// to introduce other keys, through at most TrustDepth levels of trust signatures.
+	TrustDepth int
+	// TrustAmount is the amount of trust placed in the introducer, from 1 to 255,
+	// 120 (full trust) if it is 0. It is ignored if TrustDepth is 0.
+	TrustAmount int
+	// TrustRegularExpression restricts the user IDs that the introducer can certify, if not empty.
+	// It requires TrustDepth to be set.
+	TrustRegularExpression string
+}
+
+// CertifyKey certifies a user ID of the key with the signing key of the keyring,
+// and returns a copy of the key with the new third-party certification.
+// If lifetime is not 0, the certification expires lifetime seconds after its creation.
+func (keyRing *KeyRing) CertifyKey(key *Key, userID string, lifetime int64) (*Key, error) {
+	return keyRing.CertifyKeyWithOptions(key, userID, &CertificationOptions{Lifetime: lifetime})
+}
+
+// CertifyKeyWithOptions certifies a user ID of the key as CertifyKey, with the given options.
+func (keyRing *KeyRing) CertifyKeyWithOptions(key *Key, userID string, options *CertificationOptions) (*Key, error) {
+	signEntity, err := keyRing.getSigningEntity()
+	if err != nil {
+		return nil, err
+	}
+	certified, err := key.Copy()
+	if err != nil {
+		return nil, err
+	}
+	if err := certifyIdentity(certified.entity, userID, signEntity, options); err != nil {
+		return nil, err
+	}
+	return certified, nil
+}
+
+// RenewCertifications re-issues the certifications made by the signing key of the keyring
+// over the keys of certifiedKeyRing, which are still valid at unixTime but expire
+// within window seconds. Only the newest certification of each user ID is considered,
+// and revoked certifications are not renewed.
+// The renewed certifications are added to the keys of certifiedKeyRing, and expire
+// lifetime seconds after their creation, or never if lifetime is 0.
+// Local certifications are renewed as local certifications.
+// It returns the number of renewed certifications.
+func (keyRing *KeyRing) RenewCertifications(certifiedKeyRing *KeyRing, unixTime, window, lifetime int64) (int, error) {
+	signEntity, err := keyRing.getSigningEntity()
+	if err != nil {
+		return 0, err
+	}
+
+	renewed := 0
+	for _, entity := range certifiedKeyRing.entities {
+		for userID, identity := range entity.Identities {
+			certification := getNewestCertification(identity.Signatures, signEntity)
+			if certification == nil || certification.SigType == packet.SigTypeCertificationRevocation {
+				continue
+			}
+			expirationTime := getCertificationExpirationTime(certification)
+			if expirationTime == 0 || expirationTime <= unixTime || expirationTime > unixTime+window {
+				continue
+			}
+			options := getCertificationOptions(certification)
+			options.Lifetime = lifetime
+			if err := certifyIdentity(entity, userID, signEntity, options); err != nil {
+				return renewed, err
+			}
+			renewed++
+		}
+	}
+	return renewed, nil
+}
+
+func certifyIdentity(entity *openpgp.Entity, userID string, signEntity *openpgp.Entity, options *CertificationOptions) error {
+	if options.Lifetime < 0 || options.Lifetime > math.MaxUint32 {
+		return errors.New("gopenpgp: invalid certification lifetime")
+	}
+	identity, ok := entity.Identities[userID]
+	if !ok {
+		return errors.New("gopenpgp: user ID not found: " + userID)
+	}
+	trustLevel, trustAmount, trustRegularExpression, err := getTrustParameters(options)
+	if err != nil {
+		return err
+	}
+	config := &packet.Config{DefaultHash: crypto.SHA512, Time: getTimeGenerator(), Rand: getRandomSource()}
+	certificationKey, ok := signEntity.CertificationKey(config.Now())
+	if !ok || certificationKey.PrivateKey == nil || certificationKey.PrivateKey.Encrypted || certificationKey.PrivateKey.Dummy() {
+		return errors.New("gopenpgp: no valid certification key found")
+	}
+
+	lifetime := uint32(options.Lifetime)
+	sig := &packet.Signature{
+		SigType:                packet.SigTypeGenericCert,
+		PubKeyAlgo:             certificationKey.PrivateKey.PubKeyAlgo,
+		Hash:                   config.Hash(),
+		CreationTime:           config.Now(),
+		IssuerKeyId:            &certificationKey.PrivateKey.KeyId,
+		IssuerFingerprint:      certificationKey.PrivateKey.Fingerprint,
+		SigLifetimeSecs:        &lifetime,
+		TrustLevel:             trustLevel,
+		TrustAmount:            trustAmount,
+		TrustRegularExpression: trustRegularExpression,
+	}
+	var subpackets [][]byte
+	if options.Local {
+		subpackets = append(subpackets, []byte{exportableCertificationSubpacketType, 0})
+	}
+	newHash := func() hash.Hash {
+		h := sig.Hash.New()
+		writeUserIDSignaturePrefix(h, entity.PrimaryKey, userID)
+		return h
+	}
+	sig, err = signWithSubpackets(sig, newHash, certificationKey.PrivateKey, config, subpackets...)
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: error in certifying user ID")
+	}
+	identity.Signatures = append(identity.Signatures, sig)
+	return nil
+}
+
+// getCertificationOptions returns the options with which the certification was created.
+func getCertificationOptions(sig *packet.Signature) *CertificationOptions {
+	options := &CertificationOptions{
+		Local:       isLocalCertification(sig),
+		TrustDepth:  int(sig.TrustLevel),
+		TrustAmount: int(sig.TrustAmount),
+	}
+	if sig.TrustRegularExpression != nil {
+		options.TrustRegularExpression = *sig.TrustRegularExpression
+	}
+	return options
+}
+
+// isLocalCertification returns true if the certification is marked as non-exportable.
+func isLocalCertification(sig *packet.Signature) bool {
+	exportable, err := getHashedSubpacket(sig, exportableCertificationSubpacketType)
+	return err == nil && len(exportable) == 1 && exportable[0] == 0
+}
+
+// serializeForExport serializes the public part of the entity without its local certifications.
+func serializeForExport(entity *openpgp.Entity, w io.Writer) error {
+	exported := *entity
+	exported.Identities = make(map[string]*openpgp.Identity, len(entity.Identities))
+	for name, identity := range entity.Identities {
+		exportedIdentity := *identity
+		exportedIdentity.Signatures = nil
+		for _, sig := range identity.Signatures {
+			if !isLocalCertification(sig) {
+				exportedIdentity.Signatures = append(exportedIdentity.Signatures, sig)
+			}
+		}
+		exported.Identities[name] = &exportedIdentity
+	}
+	return exported.Serialize(w)
+}
+
+// getNewestCertification returns the newest certification, or certification revocation,
+// issued by signEntity among the signatures, nil if there is none.
+// The last one wins between signatures created at the same time, as new signatures are appended.
+func getNewestCertification(signatures []*packet.Signature, signEntity *openpgp.Entity) *packet.Signature {
+	var newest *packet.Signature
+	for _, sig := range signatures {
+		if !sig.CheckKeyIdOrFingerprint(signEntity.PrimaryKey) {
+			continue
+		}
+		if newest == nil || !sig.CreationTime.Before(newest.CreationTime) {
+			newest = sig
+		}
+	}
+	return newest
+}
+
+// getCertificationExpirationTime returns the expiration time of the certification as unix timestamp,
+// or 0 if it does not expire.
+func getCertificationExpirationTime(sig *packet.Signature) int64 {
+	if sig.SigLifetimeSecs == nil || *sig.SigLifetimeSecs == 0 {
+		return 0
+	}
+	return sig.CreationTime.Add(time.Duration(*sig.SigLifetimeSecs) * time.Second).Unix()
+}
+
+func newCertification(userID string, sig *packet.Signature) *Certification {
+	return &Certification{
+		UserID:         userID,
+		IssuerKeyID:    keyIDToHex(*sig.IssuerKeyId),
+		CreationTime:   sig.CreationTime.Unix(),
+		ExpirationTime: getCertificationExpirationTime(sig),
+	}
+}
@@ -0,0 +1,159 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertifyKey(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	signer, err := GenerateKey("signer", "signer@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	signerKeyRing, err := NewKeyRing(signer)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	userID := key.entity.PrimaryIdentity().Name
+	publicKey, err := key.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while getting the public key, got:", err)
+	}
+
+	certified, err := signerKeyRing.CertifyKey(publicKey, userID, 24*3600)
+	if err != nil {
+		t.Fatal("Expected no error while certifying key, got:", err)
+	}
+	publicKeyRing, _ := NewKeyRing(publicKey)
+	certifiedKeyRing, _ := NewKeyRing(certified)
+	diff := publicKeyRing.Diff(certifiedKeyRing)
+	assert.Len(t, diff.ChangedKeys, 1)
+	assert.Len(t, diff.ChangedKeys[0].AddedCertifications, 1)
+	certification := diff.ChangedKeys[0].AddedCertifications[0]
+	assert.Exactly(t, signer.GetHexKeyID(), certification.IssuerKeyID)
+	assert.Exactly(t, certification.CreationTime+24*3600, certification.ExpirationTime)
+
+	_, err = signerKeyRing.CertifyKey(publicKey, "unknown <unknown@example.com>", 0)
+	assert.Error(t, err)
+	_, err = signerKeyRing.CertifyKey(publicKey, userID, -1)
+	assert.Error(t, err)
+}
+
+func TestRenewCertifications(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	signer, err := GenerateKey("signer", "signer@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	signerKeyRing, _ := NewKeyRing(signer)
+	userID := key.entity.PrimaryIdentity().Name
+
+	certified, err := signerKeyRing.CertifyKey(key, userID, 24*3600)
+	if err != nil {
+		t.Fatal("Expected no error while certifying key, got:", err)
+	}
+	certifiedKeyRing, _ := NewKeyRing(certified)
+	now := GetUnixTime()
+
+	renewed, err := signerKeyRing.RenewCertifications(certifiedKeyRing, now, 3600, 365*24*3600)
+	if err != nil {
+		t.Fatal("Expected no error while renewing certifications, got:", err)
+	}
+	assert.Exactly(t, 0, renewed, "the certification doesn't expire within the window")
+
+	renewed, err = signerKeyRing.RenewCertifications(certifiedKeyRing, now, 7*24*3600, 365*24*3600)
+	if err != nil {
+		t.Fatal("Expected no error while renewing certifications, got:", err)
+	}
+	assert.Exactly(t, 1, renewed)
+	newest := getNewestCertification(certified.entity.Identities[userID].Signatures, signer.entity)
+	assert.Exactly(t, uint32(365*24*3600), *newest.SigLifetimeSecs)
+
+	renewed, err = signerKeyRing.RenewCertifications(certifiedKeyRing, now, 7*24*3600, 365*24*3600)
+	if err != nil {
+		t.Fatal("Expected no error while renewing certifications, got:", err)
+	}
+	assert.Exactly(t, 0, renewed, "the renewed certification doesn't expire within the window")
+
+	renewed, err = signerKeyRing.RenewCertifications(certifiedKeyRing, now+2*365*24*3600, 7*24*3600, 0)
+	if err != nil {
+		t.Fatal("Expected no error while renewing certifications, got:", err)
+	}
+	assert.Exactly(t, 0, renewed, "expired certifications are not renewed")
+}
+
+func TestCertifyKeyLocal(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	signer, err := GenerateKey("signer", "signer@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	signerKeyRing, _ := NewKeyRing(signer)
+	userID := key.entity.PrimaryIdentity().Name
+	publicKey, err := key.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while getting the public key, got:", err)
+	}
+
+	local, err := signerKeyRing.CertifyKeyWithOptions(publicKey, userID, &CertificationOptions{Local: true})
+	if err != nil {
+		t.Fatal("Expected no error while certifying key, got:", err)
+	}
+	certified, err := signerKeyRing.CertifyKeyWithOptions(local, userID, &CertificationOptions{})
+	if err != nil {
+		t.Fatal("Expected no error while certifying key, got:", err)
+	}
+
+	copied, err := certified.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	signatures := copied.entity.Identities[userID].Signatures
+	assert.Len(t, signatures, 3)
+	assert.Exactly(t, 1, countLocalCertifications(signatures), "local certifications are kept in copies")
+
+	armored, err := certified.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal("Expected no error while exporting key, got:", err)
+	}
+	exported, err := NewKeyFromArmored(armored)
+	if err != nil {
+		t.Fatal("Expected no error while reading exported key, got:", err)
+	}
+	signatures = exported.entity.Identities[userID].Signatures
+	assert.Len(t, signatures, 2)
+	assert.Exactly(t, 0, countLocalCertifications(signatures), "local certifications are not exported")
+
+	certifiedKeyRing, _ := NewKeyRing(certified)
+	serialized, err := certifiedKeyRing.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing keyring, got:", err)
+	}
+	exported, err = NewKey(serialized)
+	if err != nil {
+		t.Fatal("Expected no error while reading exported key, got:", err)
+	}
+	assert.Len(t, exported.entity.Identities[userID].Signatures, 2)
+}
+
+func countLocalCertifications(signatures []*packet.Signature) int {
+	count := 0
+	for _, sig := range signatures {
+		if isLocalCertification(sig) {
+			count++
+		}
+	}
+	return count
+}
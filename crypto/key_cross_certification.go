@@ -0,0 +1,163 @@
+package crypto
+
+import (
+	"bytes"
+	goerrors "errors"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+// ValidateCrossCertification checks that every signing subkey of the key
+// carries a valid primary key binding signature, proving that the owner
+// of the subkey agreed to bind it to the primary key.
+func (key *Key) ValidateCrossCertification() error {
+	for _, subkey := range key.entity.Subkeys {
+		if subkey.Sig == nil || !subkey.Sig.FlagSign {
+			continue
+		}
+		if err := key.entity.PrimaryKey.VerifyKeySignature(subkey.PublicKey, subkey.Sig); err != nil {
+			return errors.Wrap(err, "gopenpgp: invalid cross-certification for subkey "+keyIDToHex(subkey.PublicKey.KeyId))
+		}
+	}
+	return nil
+}
+
+// RepairCrossCertification parses the unarmored private key binKeys, and adds
+// the missing or invalid primary key binding signatures of its signing subkeys,
+// that some buggy generators omit and without which the key can't be parsed.
+// The primary key and the signing subkeys to repair are unlocked with the passphrase,
+// and the binding signatures of the repaired subkeys are signed again by the primary key.
+// The returned key is locked with the same passphrase as binKeys.
+func RepairCrossCertification(binKeys []byte, passphrase []byte) (*Key, error) {
+	// The packets are read twice: the first copy is serialized as is,
+	// while the second one is unlocked to compute the signatures.
+	packets, err := readKeyPackets(binKeys)
+	if err != nil {
+		return nil, err
+	}
+	signingPackets, err := readKeyPackets(binKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	var primaryKey, subkey *packet.PrivateKey
+	var subkeyPublicKey *packet.PublicKey
+	defer func() {
+		for _, p := range signingPackets {
+			if privateKey, ok := p.(*packet.PrivateKey); ok && !privateKey.Encrypted {
+				_ = clearPrivateKey(privateKey.PrivateKey)
+			}
+		}
+	}()
+
+	for i, p := range packets {
+		switch p := p.(type) {
+		case *packet.PrivateKey:
+			if p.IsSubkey {
+				subkey, subkeyPublicKey = signingPackets[i].(*packet.PrivateKey), &p.PublicKey
+			} else {
+				primaryKey = signingPackets[i].(*packet.PrivateKey)
+			}
+		case *packet.PublicKey:
+			if !p.IsSubkey {
+				return nil, errors.New("gopenpgp: a public key cannot be repaired")
+			}
+			subkey, subkeyPublicKey = nil, p
+		case *packet.Signature:
+			if subkeyPublicKey == nil || p.SigType != packet.SigTypeSubkeyBinding || !p.FlagSign {
+				continue
+			}
+			if err := repairSubkeyBinding(p, primaryKey, subkey, subkeyPublicKey, passphrase); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var serialized bytes.Buffer
+	for _, p := range packets {
+		serializable, ok := p.(interface{ Serialize(io.Writer) error })
+		if !ok {
+			return nil, errors.New("gopenpgp: unable to serialize key packet")
+		}
+		if err := serializable.Serialize(&serialized); err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: unable to serialize key packet")
+		}
+	}
+
+	return NewKey(serialized.Bytes())
+}
+
+// repairSubkeyBinding adds a primary key binding signature to the binding signature
+// of a signing subkey, if it is missing or invalid.
+func repairSubkeyBinding(
+	binding *packet.Signature,
+	primaryKey, subkey *packet.PrivateKey,
+	subkeyPublicKey *packet.PublicKey,
+	passphrase []byte,
+) error {
+	if primaryKey == nil {
+		return errors.New("gopenpgp: subkey found before the primary key")
+	}
+	if primaryKey.PublicKey.VerifyKeySignature(subkeyPublicKey, binding) == nil {
+		return nil
+	}
+
+	// Only repair subkeys legitimately bound by the primary key:
+	// the key flags don't change the hash, but disable the cross-certification check.
+	unflagged := *binding
+	unflagged.FlagSign = false
+	if err := primaryKey.PublicKey.VerifyKeySignature(subkeyPublicKey, &unflagged); err != nil {
+		return errors.Wrap(err, "gopenpgp: invalid binding signature for subkey "+keyIDToHex(subkeyPublicKey.KeyId))
+	}
+
+	if subkey == nil {
+		return errors.New("gopenpgp: missing private subkey " + keyIDToHex(subkeyPublicKey.KeyId))
+	}
+	for _, privateKey := range []*packet.PrivateKey{primaryKey, subkey} {
+		if !privateKey.Encrypted {
+			continue
+		}
+		if err := privateKey.Decrypt(passphrase); err != nil {
+			return errors.Wrap(err, "gopenpgp: error in unlocking key")
+		}
+	}
+
+	config := &packet.Config{Rand: getRandomSource()}
+	binding.EmbeddedSignature = &packet.Signature{
+		Version:      subkey.Version,
+		SigType:      packet.SigTypePrimaryKeyBinding,
+		PubKeyAlgo:   subkey.PubKeyAlgo,
+		Hash:         binding.Hash,
+		CreationTime: binding.CreationTime,
+		IssuerKeyId:  &subkey.KeyId,
+	}
+	if err := binding.EmbeddedSignature.CrossSignKey(subkeyPublicKey, &primaryKey.PublicKey, subkey, config); err != nil {
+		return errors.Wrap(err, "gopenpgp: error in cross-certifying subkey")
+	}
+	// The embedded signature is a hashed subpacket, so the binding must be signed again
+	if err := binding.SignKey(subkeyPublicKey, primaryKey, config); err != nil {
+		return errors.Wrap(err, "gopenpgp: error in signing subkey binding")
+	}
+	return nil
+}
+
+func readKeyPackets(binKeys []byte) ([]packet.Packet, error) {
+	var packets []packet.Packet
+	reader := packet.NewReader(bytes.NewReader(binKeys))
+	for {
+		p, err := reader.Next()
+		if goerrors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: unable to read key packets")
+		}
+		packets = append(packets, p)
+	}
+	if len(packets) == 0 {
+		return nil, errors.New("gopenpgp: no key found")
+	}
+	return packets, nil
+}
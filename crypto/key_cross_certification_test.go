@@ -0,0 +1,99 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+// generateKeyWithoutCrossCertification generates a key with a signing subkey
+// missing its primary key binding signature, as produced by buggy generators.
+func generateKeyWithoutCrossCertification(t *testing.T) *Key {
+	key, err := GenerateKeyWithSubkeys(
+		keyTestName, keyTestDomain, "x25519", 0,
+		NewSubkeySpec("x25519", 0, true, false, false),
+		NewSubkeySpec("x25519", 0, false, true, false),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+
+	subkey := &key.entity.Subkeys[0]
+	subkey.Sig.EmbeddedSignature = nil
+	if err = subkey.Sig.SignKey(subkey.PublicKey, key.entity.PrivateKey, nil); err != nil {
+		t.Fatal("Expected no error while signing subkey binding, got:", err)
+	}
+	return key
+}
+
+func TestValidateCrossCertification(t *testing.T) {
+	assert.NoError(t, keyTestEC.ValidateCrossCertification())
+
+	key := generateKeyWithoutCrossCertification(t)
+	defer key.ClearPrivateParams()
+	assert.Error(t, key.ValidateCrossCertification())
+}
+
+func TestRepairCrossCertification(t *testing.T) {
+	key := generateKeyWithoutCrossCertification(t)
+
+	// Key.Lock can't be used, as it parses a copy of the key
+	fingerprint := key.GetFingerprint()
+	for _, privateKey := range []*packet.PrivateKey{key.entity.PrivateKey, key.entity.Subkeys[0].PrivateKey, key.entity.Subkeys[1].PrivateKey} {
+		if err := privateKey.EncryptWithConfig(keyTestPassphrase, lockConfig()); err != nil {
+			t.Fatal("Expected no error while locking key, got:", err)
+		}
+	}
+	serialized, err := key.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+
+	_, err = NewKey(serialized)
+	assert.Error(t, err)
+
+	_, err = RepairCrossCertification(serialized, []byte("wrong passphrase"))
+	assert.Error(t, err)
+
+	repairedKey, err := RepairCrossCertification(serialized, keyTestPassphrase)
+	if err != nil {
+		t.Fatal("Expected no error while repairing key, got:", err)
+	}
+	assert.NoError(t, repairedKey.ValidateCrossCertification())
+	assert.Equal(t, fingerprint, repairedKey.GetFingerprint())
+
+	isLocked, err := repairedKey.IsLocked()
+	if err != nil {
+		t.Fatal("Expected no error while checking lock, got:", err)
+	}
+	assert.True(t, isLocked)
+
+	unlockedKey, err := repairedKey.Unlock(keyTestPassphrase)
+	if err != nil {
+		t.Fatal("Expected no error while unlocking key, got:", err)
+	}
+	defer unlockedKey.ClearPrivateParams()
+	keyRing, err := NewKeyRing(unlockedKey)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+
+	message := NewPlainMessageFromString("repaired")
+	signature, err := keyRing.SignDetached(message)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	signatureKeyIDs, _ := signature.GetSignatureKeyIDs()
+	assert.Equal(t, []uint64{unlockedKey.entity.Subkeys[0].PublicKey.KeyId}, signatureKeyIDs)
+	assert.NoError(t, keyRing.VerifyDetached(message, signature, GetUnixTime()))
+}
+
+func TestRepairCrossCertificationPublicKey(t *testing.T) {
+	publicKey, err := keyTestEC.GetPublicKey()
+	if err != nil {
+		t.Fatal("Expected no error while getting public key, got:", err)
+	}
+	_, err = RepairCrossCertification(publicKey, nil)
+	assert.Error(t, err)
+}
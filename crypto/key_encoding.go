@@ -0,0 +1,197 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MarshalJSON implements json.Marshaler, encoding the armored public key as a JSON string.
+// The private key material is never encoded, use UnsafeEncoder to encode it.
+func (key *Key) MarshalJSON() ([]byte, error) {
+	armored, err := key.GetArmoredPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(armored)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading an armored public or private key.
+func (key *Key) UnmarshalJSON(data []byte) error {
+	var armored string
+	if err := json.Unmarshal(data, &armored); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to decode the armored key")
+	}
+	return key.readFrom(strings.NewReader(armored), true)
+}
+
+// GobEncode implements gob.GobEncoder, encoding the binary public key.
+// The private key material is never encoded, use UnsafeEncoder to encode it.
+func (key *Key) GobEncode() ([]byte, error) {
+	return key.GetPublicKey()
+}
+
+// GobDecode implements gob.GobDecoder, reading a binary public or private key.
+func (key *Key) GobDecode(data []byte) error {
+	return key.readFrom(bytes.NewReader(clone(data)), false)
+}
+
+// UnsafeKeyEncoder encodes a key with its private key material, if any,
+// unencrypted if the key is unlocked.
+type UnsafeKeyEncoder struct {
+	key *Key
+}
+
+// UnsafeEncoder returns an encoder of the key that includes the private key material.
+// It should only be used to transfer secrets through trusted channels,
+// as the Key encoding only includes public material.
+func (key *Key) UnsafeEncoder() *UnsafeKeyEncoder {
+	return &UnsafeKeyEncoder{key: key}
+}
+
+// MarshalJSON implements json.Marshaler, encoding the armored key as a JSON string.
+func (encoder *UnsafeKeyEncoder) MarshalJSON() ([]byte, error) {
+	armored, err := encoder.key.Armor()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(armored)
+}
+
+// GobEncode implements gob.GobEncoder, encoding the binary key.
+func (encoder *UnsafeKeyEncoder) GobEncode() ([]byte, error) {
+	return encoder.key.Serialize()
+}
+
+// keyRingEncoding is the encoded form of a keyring, with encoded keys.
+type keyRingEncoding struct {
+	Keys       []json.Marshaler `json:"keys"`
+	FirstKeyID string           `json:"firstKeyID,omitempty"`
+}
+
+// keyRingGobEncoding is the gob form of a keyring, with binary keys,
+// as gob can't encode the interfaces of keyRingEncoding.
+type keyRingGobEncoding struct {
+	Keys       [][]byte
+	FirstKeyID string
+}
+
+// keyRingDecoding is the decoded form of a keyring.
+type keyRingDecoding struct {
+	Keys       []*Key `json:"keys"`
+	FirstKeyID string `json:"firstKeyID,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding the armored public keys and the FirstKeyID.
+// The private key material is never encoded, use UnsafeEncoder to encode it.
+func (keyRing *KeyRing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(keyRing.newEncoding(false))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading the keys, which must be public or unlocked,
+// and the FirstKeyID.
+func (keyRing *KeyRing) UnmarshalJSON(data []byte) error {
+	var decoding keyRingDecoding
+	if err := json.Unmarshal(data, &decoding); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to decode the keyring")
+	}
+	return keyRing.setDecoding(&decoding)
+}
+
+// GobEncode implements gob.GobEncoder, encoding the binary public keys and the FirstKeyID.
+// The private key material is never encoded, use UnsafeEncoder to encode it.
+func (keyRing *KeyRing) GobEncode() ([]byte, error) {
+	return keyRing.gobEncode(false)
+}
+
+// GobDecode implements gob.GobDecoder, reading the keys, which must be public or unlocked,
+// and the FirstKeyID.
+func (keyRing *KeyRing) GobDecode(data []byte) error {
+	var encoding keyRingGobEncoding
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&encoding); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to decode the keyring")
+	}
+	decoding := keyRingDecoding{FirstKeyID: encoding.FirstKeyID}
+	for _, serialized := range encoding.Keys {
+		key, err := NewKey(serialized)
+		if err != nil {
+			return err
+		}
+		decoding.Keys = append(decoding.Keys, key)
+	}
+	return keyRing.setDecoding(&decoding)
+}
+
+// UnsafeKeyRingEncoder encodes a keyring with the private key material of its keys.
+type UnsafeKeyRingEncoder struct {
+	keyRing *KeyRing
+}
+
+// UnsafeEncoder returns an encoder of the keyring that includes the private key material
+// of its keys, unencrypted. It should only be used to transfer secrets through trusted channels,
+// as the KeyRing encoding only includes public material.
+func (keyRing *KeyRing) UnsafeEncoder() *UnsafeKeyRingEncoder {
+	return &UnsafeKeyRingEncoder{keyRing: keyRing}
+}
+
+// MarshalJSON implements json.Marshaler, encoding the armored keys and the FirstKeyID.
+func (encoder *UnsafeKeyRingEncoder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encoder.keyRing.newEncoding(true))
+}
+
+// GobEncode implements gob.GobEncoder, encoding the binary keys and the FirstKeyID.
+func (encoder *UnsafeKeyRingEncoder) GobEncode() ([]byte, error) {
+	return encoder.keyRing.gobEncode(true)
+}
+
+func (keyRing *KeyRing) newEncoding(withSecrets bool) *keyRingEncoding {
+	encoding := &keyRingEncoding{FirstKeyID: keyRing.FirstKeyID}
+	for _, key := range keyRing.GetKeys() {
+		if withSecrets {
+			encoding.Keys = append(encoding.Keys, key.UnsafeEncoder())
+		} else {
+			encoding.Keys = append(encoding.Keys, key)
+		}
+	}
+	return encoding
+}
+
+func (keyRing *KeyRing) gobEncode(withSecrets bool) ([]byte, error) {
+	encoding := keyRingGobEncoding{FirstKeyID: keyRing.FirstKeyID}
+	for _, key := range keyRing.GetKeys() {
+		var serialized []byte
+		var err error
+		if withSecrets {
+			serialized, err = key.Serialize()
+		} else {
+			serialized, err = key.GetPublicKey()
+		}
+		if err != nil {
+			return nil, err
+		}
+		encoding.Keys = append(encoding.Keys, serialized)
+	}
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(&encoding); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to encode the keyring")
+	}
+	return buffer.Bytes(), nil
+}
+
+func (keyRing *KeyRing) setDecoding(decoding *keyRingDecoding) error {
+	keyRing.entities = nil
+	keyRing.unknownPackets = nil
+	for _, key := range decoding.Keys {
+		if key == nil {
+			return errors.New("gopenpgp: missing key in the keyring")
+		}
+		if err := keyRing.AddKey(key); err != nil {
+			return err
+		}
+	}
+	keyRing.FirstKeyID = decoding.FirstKeyID
+	return nil
+}
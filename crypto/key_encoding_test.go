@@ -0,0 +1,103 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyJSONEncoding(t *testing.T) {
+	privateKey := keyRingTestPrivate.GetKeys()[0]
+	assert.True(t, privateKey.IsPrivate())
+
+	encoded, err := json.Marshal(privateKey)
+	if err != nil {
+		t.Fatal("Expected no error while encoding the key, got:", err)
+	}
+	assert.NotContains(t, string(encoded), "PRIVATE")
+	var decoded Key
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal("Expected no error while decoding the key, got:", err)
+	}
+	assert.False(t, decoded.IsPrivate())
+	assert.Exactly(t, privateKey.GetFingerprint(), decoded.GetFingerprint())
+
+	encoded, err = json.Marshal(privateKey.UnsafeEncoder())
+	if err != nil {
+		t.Fatal("Expected no error while encoding the key, got:", err)
+	}
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal("Expected no error while decoding the key, got:", err)
+	}
+	assert.True(t, decoded.IsPrivate())
+	unlocked, err := decoded.IsUnlocked()
+	assert.NoError(t, err)
+	assert.True(t, unlocked)
+
+	assert.Error(t, json.Unmarshal([]byte(`"not a key"`), &decoded))
+}
+
+func TestKeyRingJSONEncoding(t *testing.T) {
+	keyRing, err := keyRingTestPrivate.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying the keyring, got:", err)
+	}
+	keyRing.FirstKeyID = "first"
+
+	encoded, err := json.Marshal(keyRing)
+	if err != nil {
+		t.Fatal("Expected no error while encoding the keyring, got:", err)
+	}
+	assert.NotContains(t, string(encoded), "PRIVATE")
+	var decoded KeyRing
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal("Expected no error while decoding the keyring, got:", err)
+	}
+	assert.Exactly(t, keyRing.CountEntities(), decoded.CountEntities())
+	assert.Exactly(t, "first", decoded.FirstKeyID)
+	assert.False(t, decoded.GetKeys()[0].IsPrivate())
+
+	encoded, err = json.Marshal(keyRing.UnsafeEncoder())
+	if err != nil {
+		t.Fatal("Expected no error while encoding the keyring, got:", err)
+	}
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal("Expected no error while decoding the keyring, got:", err)
+	}
+	assert.True(t, decoded.GetKeys()[0].IsPrivate())
+}
+
+func TestKeyRingGobEncoding(t *testing.T) {
+	type container struct {
+		Key     *Key
+		KeyRing *KeyRing
+	}
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(&container{
+		Key:     keyRingTestPrivate.GetKeys()[0],
+		KeyRing: keyRingTestPrivate,
+	})
+	if err != nil {
+		t.Fatal("Expected no error while encoding, got:", err)
+	}
+	var decoded container
+	if err = gob.NewDecoder(&buffer).Decode(&decoded); err != nil {
+		t.Fatal("Expected no error while decoding, got:", err)
+	}
+	assert.False(t, decoded.Key.IsPrivate())
+	assert.Exactly(t, keyRingTestPrivate.CountEntities(), decoded.KeyRing.CountEntities())
+	assert.False(t, decoded.KeyRing.GetKeys()[0].IsPrivate())
+
+	encoded, err := keyRingTestPrivate.UnsafeEncoder().GobEncode()
+	if err != nil {
+		t.Fatal("Expected no error while encoding, got:", err)
+	}
+	var decodedKeyRing KeyRing
+	if err = decodedKeyRing.GobDecode(encoded); err != nil {
+		t.Fatal("Expected no error while decoding, got:", err)
+	}
+	assert.True(t, decodedKeyRing.GetKeys()[0].IsPrivate())
+}
@@ -0,0 +1,206 @@
+package crypto
+
+import (
+	"math"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+const (
+	// keyFlagsSigning are the key flags requiring a signing algorithm.
+	keyFlagsSigning = constants.KEY_FLAG_CERTIFY | constants.KEY_FLAG_SIGN | constants.KEY_FLAG_AUTHENTICATE
+	// keyFlagsEncryption are the key flags requiring an encryption algorithm.
+	keyFlagsEncryption = constants.KEY_FLAG_ENCRYPT_COMMUNICATIONS | constants.KEY_FLAG_ENCRYPT_STORAGE
+)
+
+// SubkeySpec describes a subkey to create with GenerateKeyWithSubkeys.
+type SubkeySpec struct {
+	// KeyType is the algorithm of the subkey, "rsa" or "x25519".
+	KeyType string
+	// Bits is the RSA bitsize of the subkey, unused for "x25519".
+	Bits int
+	// Lifetime is the validity period of the subkey in seconds,
+	// 0 if the subkey does not expire.
+	Lifetime int64
+	// Sign, Encrypt and Authenticate are the capabilities of the subkey,
+	// at least one of them must be set.
+	// A "x25519" subkey can't both encrypt and sign or authenticate.
+	Sign, Encrypt, Authenticate bool
+	// Flags, if not 0, are the key flags of the subkey as a combination of constants.KEY_FLAG_*,
+	// and replace Sign, Encrypt and Authenticate.
+	Flags int
+}
+
+// NewSubkeySpec creates a SubkeySpec of the given keyType ("rsa" or "x25519")
+// and capabilities, that does not expire.
+func NewSubkeySpec(keyType string, bits int, sign, encrypt, authenticate bool) *SubkeySpec {
+	return &SubkeySpec{
+		KeyType:      keyType,
+		Bits:         bits,
+		Sign:         sign,
+		Encrypt:      encrypt,
+		Authenticate: authenticate,
+	}
+}
+
+// NewSubkeySpecWithFlags creates a SubkeySpec of the given keyType ("rsa" or "x25519")
+// with the given key flags, as a combination of constants.KEY_FLAG_*, that does not expire.
+func NewSubkeySpecWithFlags(keyType string, bits int, flags int) *SubkeySpec {
+	return &SubkeySpec{
+		KeyType: keyType,
+		Bits:    bits,
+		Flags:   flags,
+	}
+}
+
+// GenerateKeyWithSubkeys generates a key of the given keyType ("rsa" or "x25519")
+// with exactly the given subkeys, instead of the single encryption subkey created by GenerateKey.
+// The primary key can certify and sign, and does not expire.
+func GenerateKeyWithSubkeys(name, email string, keyType string, bits int, subkeys ...*SubkeySpec) (*Key, error) {
+	return GenerateKeyWithFlags(
+		name, email, keyType, bits,
+		constants.KEY_FLAG_CERTIFY|constants.KEY_FLAG_SIGN,
+		subkeys...,
+	)
+}
+
+// GenerateKeyWithFlags generates a key as GenerateKeyWithSubkeys, with the given
+// key flags for the primary key, as a combination of constants.KEY_FLAG_*.
+// The primary key always issues the bindings of its subkeys and user IDs,
+// its flags only restrict the other uses of the key, e.g. a certification-only primary key.
+func GenerateKeyWithFlags(name, email string, keyType string, bits int, flags int, subkeys ...*SubkeySpec) (*Key, error) {
+	if err := checkKeyFlags(keyType, flags); err != nil {
+		return nil, err
+	}
+	if keyType == "x25519" && flags&keyFlagsEncryption != 0 {
+		return nil, errors.New("gopenpgp: x25519 primary key can't encrypt")
+	}
+	for _, spec := range subkeys {
+		if err := spec.check(); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := generateKey(name, email, keyType, bits, nil, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	entity := key.entity
+
+	if flags != constants.KEY_FLAG_CERTIFY|constants.KEY_FLAG_SIGN {
+		if err := setPrimaryKeyFlags(entity, keyType, bits, flags); err != nil {
+			key.ClearPrivateParams()
+			return nil, errors.Wrap(err, "gopenpgp: error in setting primary key flags")
+		}
+	}
+
+	// openpgp.NewEntity always adds an encryption subkey, which is replaced by the requested ones
+	for _, subkey := range entity.Subkeys {
+		_ = clearPrivateKey(subkey.PrivateKey.PrivateKey)
+	}
+	entity.Subkeys = nil
+
+	for _, spec := range subkeys {
+		if err := addSubkey(entity, spec); err != nil {
+			key.ClearPrivateParams()
+			return nil, errors.Wrap(err, "gopenpgp: error in generating subkey")
+		}
+	}
+	return key, nil
+}
+
+func (spec *SubkeySpec) check() error {
+	if spec == nil {
+		return errors.New("gopenpgp: nil subkey spec provided")
+	}
+	if err := checkKeyFlags(spec.KeyType, spec.keyFlags()); err != nil {
+		return err
+	}
+	if spec.Lifetime < 0 || spec.Lifetime > math.MaxUint32 {
+		return errors.New("gopenpgp: invalid subkey lifetime")
+	}
+	return nil
+}
+
+// keyFlags returns the key flags of the subkey.
+func (spec *SubkeySpec) keyFlags() int {
+	if spec.Flags != 0 {
+		return spec.Flags
+	}
+	flags := 0
+	if spec.Sign {
+		flags |= constants.KEY_FLAG_SIGN
+	}
+	if spec.Encrypt {
+		flags |= constants.KEY_FLAG_ENCRYPT_COMMUNICATIONS | constants.KEY_FLAG_ENCRYPT_STORAGE
+	}
+	if spec.Authenticate {
+		flags |= constants.KEY_FLAG_AUTHENTICATE
+	}
+	return flags
+}
+
+// checkKeyFlags checks that the key flags are supported, and compatible with the keyType.
+func checkKeyFlags(keyType string, flags int) error {
+	if flags == 0 {
+		return errors.New("gopenpgp: key must be able to certify, sign, encrypt or authenticate")
+	}
+	if flags&^(keyFlagsSigning|keyFlagsEncryption) != 0 {
+		return errors.New("gopenpgp: unsupported key flags")
+	}
+	if keyType == "x25519" && flags&keyFlagsEncryption != 0 && flags&keyFlagsSigning != 0 {
+		return errors.New("gopenpgp: x25519 key can't both encrypt and certify, sign or authenticate")
+	}
+	return nil
+}
+
+// setPrimaryKeyFlags sets the key flags of the primary key, and signs its user ID again.
+func setPrimaryKeyFlags(entity *openpgp.Entity, keyType string, bits int, flags int) error {
+	cfg := keyGenerationConfig(keyType, bits)
+	for _, identity := range entity.Identities {
+		setSignatureKeyFlags(identity.SelfSignature, flags)
+		if err := identity.SelfSignature.SignUserId(identity.UserId.Id, entity.PrimaryKey, entity.PrivateKey, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setSignatureKeyFlags sets the key flags of a self-signature or binding signature.
+func setSignatureKeyFlags(sig *packet.Signature, flags int) {
+	sig.FlagsValid = true
+	sig.FlagCertify = flags&constants.KEY_FLAG_CERTIFY != 0
+	sig.FlagSign = flags&constants.KEY_FLAG_SIGN != 0
+	sig.FlagEncryptCommunications = flags&constants.KEY_FLAG_ENCRYPT_COMMUNICATIONS != 0
+	sig.FlagEncryptStorage = flags&constants.KEY_FLAG_ENCRYPT_STORAGE != 0
+	sig.FlagAuthenticate = flags&constants.KEY_FLAG_AUTHENTICATE != 0
+}
+
+// addSubkey generates the subkey described by spec, and binds it to the entity.
+func addSubkey(entity *openpgp.Entity, spec *SubkeySpec) (err error) {
+	cfg := keyGenerationConfig(spec.KeyType, spec.Bits)
+	cfg.KeyLifetimeSecs = uint32(spec.Lifetime)
+	flags := spec.keyFlags()
+
+	if flags&keyFlagsSigning != 0 {
+		err = entity.AddSigningSubkey(cfg)
+	} else {
+		err = entity.AddEncryptionSubkey(cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	subkey := &entity.Subkeys[len(entity.Subkeys)-1]
+	setSignatureKeyFlags(subkey.Sig, flags)
+	if flags&(constants.KEY_FLAG_CERTIFY|constants.KEY_FLAG_SIGN) == 0 {
+		// The primary key binding signature is only required for signing subkeys
+		subkey.Sig.EmbeddedSignature = nil
+	}
+
+	// Sign the binding again, as the key flags changed
+	return subkey.Sig.SignKey(subkey.PublicKey, entity.PrivateKey, cfg)
+}
@@ -0,0 +1,195 @@
+package crypto
+
+import (
+	"math"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+// preferredHashIDs are the OpenPGP identifiers of the hashes, see RFC 4880, section 9.4.
+var preferredHashIDs = map[string]uint8{
+	constants.SHA256: 8,
+	constants.SHA384: 9,
+	constants.SHA512: 10,
+	constants.SHA224: 11,
+}
+
+// KeyGenerationBuilder configures the generation of a key step by step:
+// user IDs, primary key algorithm, flags and expiration, subkeys, and algorithm preferences.
+// The configuration errors are returned by Generate.
+type KeyGenerationBuilder struct {
+	identities       []*Identity
+	keyType          string
+	bits             int
+	flags            int
+	lifetime         int64
+	subkeys          []*SubkeySpec
+	preferredCiphers []string
+	preferredHashes  []string
+}
+
+// NewKeyGenerationBuilder creates a builder for a "x25519" key with the given primary user ID,
+// that can certify and sign, does not expire, and has a single encryption subkey,
+// as GenerateKey generates.
+func NewKeyGenerationBuilder(name, email string) *KeyGenerationBuilder {
+	return &KeyGenerationBuilder{
+		identities: []*Identity{{Name: name, Email: email}},
+		keyType:    "x25519",
+		flags:      constants.KEY_FLAG_CERTIFY | constants.KEY_FLAG_SIGN,
+	}
+}
+
+// AddUserID adds a secondary user ID to the key.
+func (builder *KeyGenerationBuilder) AddUserID(name, email string) *KeyGenerationBuilder {
+	builder.identities = append(builder.identities, &Identity{Name: name, Email: email})
+	return builder
+}
+
+// KeyType sets the algorithm of the primary key, "rsa" or "x25519", with the RSA bitsize,
+// unused for "x25519". The default encryption subkey has the same algorithm.
+func (builder *KeyGenerationBuilder) KeyType(keyType string, bits int) *KeyGenerationBuilder {
+	builder.keyType = keyType
+	builder.bits = bits
+	return builder
+}
+
+// PrimaryKeyFlags sets the key flags of the primary key, as a combination of constants.KEY_FLAG_*,
+// e.g. constants.KEY_FLAG_CERTIFY for a certification-only primary key.
+func (builder *KeyGenerationBuilder) PrimaryKeyFlags(flags int) *KeyGenerationBuilder {
+	builder.flags = flags
+	return builder
+}
+
+// Lifetime sets the validity period of the key in seconds, 0 if the key does not expire.
+func (builder *KeyGenerationBuilder) Lifetime(lifetime int64) *KeyGenerationBuilder {
+	builder.lifetime = lifetime
+	return builder
+}
+
+// AddSubkey adds a subkey to the key. If subkeys are added, they replace the default encryption subkey.
+func (builder *KeyGenerationBuilder) AddSubkey(spec *SubkeySpec) *KeyGenerationBuilder {
+	builder.subkeys = append(builder.subkeys, spec)
+	return builder
+}
+
+// PreferredCiphers sets the preferred symmetric algorithms of the key, by decreasing preference,
+// e.g. constants.AES256.
+func (builder *KeyGenerationBuilder) PreferredCiphers(algos ...string) *KeyGenerationBuilder {
+	builder.preferredCiphers = algos
+	return builder
+}
+
+// PreferredHashes sets the preferred hash algorithms of the key, by decreasing preference,
+// e.g. constants.SHA512.
+func (builder *KeyGenerationBuilder) PreferredHashes(algos ...string) *KeyGenerationBuilder {
+	builder.preferredHashes = algos
+	return builder
+}
+
+// Generate generates the configured key.
+func (builder *KeyGenerationBuilder) Generate() (*Key, error) {
+	if builder.lifetime < 0 || builder.lifetime > math.MaxUint32 {
+		return nil, errors.New("gopenpgp: invalid key lifetime")
+	}
+	preferredSymmetric, err := getPreferredCipherIDs(builder.preferredCiphers)
+	if err != nil {
+		return nil, err
+	}
+	preferredHash, err := getPreferredHashIDs(builder.preferredHashes)
+	if err != nil {
+		return nil, err
+	}
+	subkeys := builder.subkeys
+	if len(subkeys) == 0 {
+		subkeys = []*SubkeySpec{NewSubkeySpec(builder.keyType, builder.bits, false, true, false)}
+	}
+
+	primary := builder.identities[0]
+	key, err := GenerateKeyWithFlags(primary.Name, primary.Email, builder.keyType, builder.bits, builder.flags, subkeys...)
+	if err != nil {
+		return nil, err
+	}
+	entity := key.entity
+
+	cfg := keyGenerationConfig(builder.keyType, builder.bits)
+	cfg.KeyLifetimeSecs = uint32(builder.lifetime)
+	for _, identity := range builder.identities[1:] {
+		if err := entity.AddUserId(identity.Name, "", identity.Email, cfg); err != nil {
+			key.ClearPrivateParams()
+			return nil, errors.Wrap(err, "gopenpgp: error in adding user ID")
+		}
+	}
+
+	for _, identity := range entity.Identities {
+		sig := identity.SelfSignature
+		setSignatureKeyFlags(sig, builder.flags)
+		lifetime := uint32(builder.lifetime)
+		sig.KeyLifetimeSecs = &lifetime
+		if preferredSymmetric != nil {
+			setPreferredSymmetric(sig, preferredSymmetric)
+		}
+		if preferredHash != nil {
+			sig.PreferredHash = preferredHash
+		}
+		if err := sig.SignUserId(identity.UserId.Id, entity.PrimaryKey, entity.PrivateKey, cfg); err != nil {
+			key.ClearPrivateParams()
+			return nil, errors.Wrap(err, "gopenpgp: error in signing user ID")
+		}
+	}
+	return key, nil
+}
+
+// getPreferredCipherIDs returns the OpenPGP identifiers of the symmetric algorithms, nil if there are none.
+func getPreferredCipherIDs(algos []string) ([]uint8, error) {
+	var ids []uint8
+	for _, algo := range algos {
+		cf, ok := symKeyAlgos[algo]
+		if !ok {
+			return nil, errors.New("gopenpgp: unsupported cipher function: " + algo)
+		}
+		ids = append(ids, uint8(cf))
+	}
+	return ids, nil
+}
+
+// getPreferredHashIDs returns the OpenPGP identifiers of the hash algorithms, nil if there are none.
+func getPreferredHashIDs(algos []string) ([]uint8, error) {
+	var ids []uint8
+	for _, algo := range algos {
+		id, ok := preferredHashIDs[algo]
+		if !ok {
+			return nil, errors.New("gopenpgp: unsupported hash algorithm: " + algo)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// setPreferredSymmetric sets the preferred symmetric algorithms of the self-signature,
+// and the matching AEAD cipher suites, with the AEAD modes already preferred.
+func setPreferredSymmetric(sig *packet.Signature, ciphers []uint8) {
+	var modes []uint8
+	for _, suite := range sig.PreferredCipherSuites {
+		if !containsUint8(modes, suite[1]) {
+			modes = append(modes, suite[1])
+		}
+	}
+	sig.PreferredSymmetric = ciphers
+	sig.PreferredCipherSuites = nil
+	for _, cipher := range ciphers {
+		for _, mode := range modes {
+			sig.PreferredCipherSuites = append(sig.PreferredCipherSuites, [2]uint8{cipher, mode})
+		}
+	}
+}
+
+func containsUint8(list []uint8, value uint8) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
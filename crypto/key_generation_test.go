@@ -0,0 +1,194 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKeyWithSubkeys(t *testing.T) {
+	signSpec := NewSubkeySpec("x25519", 0, true, false, false)
+	signSpec.Lifetime = 3600
+	encryptSpec := NewSubkeySpec("rsa", 1024, false, true, false)
+	authSpec := NewSubkeySpec("x25519", 0, false, false, true)
+
+	generated, err := GenerateKeyWithSubkeys(keyTestName, keyTestDomain, "x25519", 0, signSpec, encryptSpec, authSpec)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	defer generated.ClearPrivateParams()
+
+	// Parse the serialized key to check the binding signatures
+	serialized, err := generated.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+	key, err := NewKey(serialized)
+	if err != nil {
+		t.Fatal("Expected no error while parsing key, got:", err)
+	}
+
+	subkeys := key.entity.Subkeys
+	assert.Len(t, subkeys, 3)
+
+	assert.True(t, subkeys[0].Sig.FlagSign)
+	assert.False(t, subkeys[0].Sig.FlagEncryptCommunications)
+	assert.False(t, subkeys[0].Sig.FlagAuthenticate)
+	assert.NotNil(t, subkeys[0].Sig.EmbeddedSignature)
+	assert.Equal(t, uint32(3600), *subkeys[0].Sig.KeyLifetimeSecs)
+
+	assert.False(t, subkeys[1].Sig.FlagSign)
+	assert.True(t, subkeys[1].Sig.FlagEncryptCommunications)
+	assert.True(t, subkeys[1].Sig.FlagEncryptStorage)
+	assert.Equal(t, packet.PubKeyAlgoRSA, subkeys[1].PublicKey.PubKeyAlgo)
+
+	assert.False(t, subkeys[2].Sig.FlagSign)
+	assert.False(t, subkeys[2].Sig.FlagEncryptCommunications)
+	assert.True(t, subkeys[2].Sig.FlagAuthenticate)
+
+	keyRing, err := NewKeyRing(key)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+
+	message := NewPlainMessageFromString("layout")
+	ciphertext, err := keyRing.Encrypt(message, keyRing)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	encryptionKeyIDs, ok := ciphertext.GetEncryptionKeyIDs()
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{subkeys[1].PublicKey.KeyId}, encryptionKeyIDs)
+
+	decrypted, err := keyRing.Decrypt(ciphertext, keyRing, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Equal(t, "layout", decrypted.GetString())
+
+	signature, err := keyRing.SignDetached(message)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	signatureKeyIDs, ok := signature.GetSignatureKeyIDs()
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{subkeys[0].PublicKey.KeyId}, signatureKeyIDs)
+}
+
+func TestGenerateKeyWithoutSubkeys(t *testing.T) {
+	key, err := GenerateKeyWithSubkeys(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	defer key.ClearPrivateParams()
+
+	assert.Empty(t, key.entity.Subkeys)
+	assert.True(t, key.CanVerify())
+	assert.False(t, key.CanEncrypt())
+}
+
+func TestGenerateKeyWithInvalidSubkeys(t *testing.T) {
+	invalidSpecs := []*SubkeySpec{
+		nil,
+		NewSubkeySpec("x25519", 0, false, false, false),
+		NewSubkeySpec("x25519", 0, true, true, false),
+		{KeyType: "x25519", Encrypt: true, Lifetime: -1},
+		NewSubkeySpecWithFlags("x25519", 0, 0x40),
+		NewSubkeySpecWithFlags("x25519", 0, constants.KEY_FLAG_CERTIFY|constants.KEY_FLAG_ENCRYPT_STORAGE),
+	}
+	for _, spec := range invalidSpecs {
+		_, err := GenerateKeyWithSubkeys(keyTestName, keyTestDomain, "x25519", 0, spec)
+		assert.Error(t, err)
+	}
+}
+
+func TestGenerateKeyWithFlags(t *testing.T) {
+	signSpec := NewSubkeySpecWithFlags("x25519", 0, constants.KEY_FLAG_SIGN)
+	storageSpec := NewSubkeySpecWithFlags("x25519", 0, constants.KEY_FLAG_ENCRYPT_STORAGE)
+
+	generated, err := GenerateKeyWithFlags(keyTestName, keyTestDomain, "x25519", 0, constants.KEY_FLAG_CERTIFY, signSpec, storageSpec)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	defer generated.ClearPrivateParams()
+
+	serialized, err := generated.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+	key, err := NewKey(serialized)
+	if err != nil {
+		t.Fatal("Expected no error while parsing key, got:", err)
+	}
+
+	selfSignature := key.entity.PrimaryIdentity().SelfSignature
+	assert.True(t, selfSignature.FlagCertify)
+	assert.False(t, selfSignature.FlagSign)
+
+	subkeys := key.entity.Subkeys
+	assert.Len(t, subkeys, 2)
+	assert.True(t, subkeys[0].Sig.FlagSign)
+	assert.False(t, subkeys[0].Sig.FlagCertify)
+	assert.NotNil(t, subkeys[0].Sig.EmbeddedSignature)
+	assert.False(t, subkeys[1].Sig.FlagEncryptCommunications)
+	assert.True(t, subkeys[1].Sig.FlagEncryptStorage)
+
+	_, err = GenerateKeyWithFlags(keyTestName, keyTestDomain, "x25519", 0, constants.KEY_FLAG_ENCRYPT_STORAGE)
+	assert.Error(t, err)
+	_, err = GenerateKeyWithFlags(keyTestName, keyTestDomain, "x25519", 0, 0)
+	assert.Error(t, err)
+}
+
+func TestKeyGenerationBuilder(t *testing.T) {
+	generated, err := NewKeyGenerationBuilder(keyTestName, keyTestDomain).
+		AddUserID("Second", "second@example.com").
+		PrimaryKeyFlags(constants.KEY_FLAG_CERTIFY).
+		Lifetime(86400).
+		AddSubkey(NewSubkeySpec("x25519", 0, true, false, false)).
+		AddSubkey(NewSubkeySpec("x25519", 0, false, true, false)).
+		PreferredCiphers(constants.AES128, constants.AES256).
+		PreferredHashes(constants.SHA512).
+		Generate()
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	defer generated.ClearPrivateParams()
+
+	serialized, err := generated.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+	key, err := NewKey(serialized)
+	if err != nil {
+		t.Fatal("Expected no error while parsing key, got:", err)
+	}
+	assert.Len(t, key.entity.Identities, 2)
+	for _, identity := range key.entity.Identities {
+		sig := identity.SelfSignature
+		assert.True(t, sig.FlagCertify)
+		assert.False(t, sig.FlagSign)
+		assert.Equal(t, uint32(86400), *sig.KeyLifetimeSecs)
+		assert.Equal(t, []uint8{uint8(packet.CipherAES128), uint8(packet.CipherAES256)}, sig.PreferredSymmetric)
+		assert.Equal(t, []uint8{10}, sig.PreferredHash)
+	}
+	assert.Len(t, key.entity.Subkeys, 2)
+	assert.True(t, key.entity.Subkeys[0].Sig.FlagSign)
+	assert.True(t, key.entity.Subkeys[1].Sig.FlagEncryptCommunications)
+	assert.False(t, key.IsExpired())
+	assert.True(t, key.CanEncrypt())
+	assert.True(t, key.CanVerify())
+
+	defaultKey, err := NewKeyGenerationBuilder(keyTestName, keyTestDomain).Generate()
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	assert.Len(t, defaultKey.entity.Subkeys, 1)
+	assert.True(t, defaultKey.CanEncrypt())
+
+	_, err = NewKeyGenerationBuilder(keyTestName, keyTestDomain).PreferredCiphers("unknown").Generate()
+	assert.Error(t, err)
+	_, err = NewKeyGenerationBuilder(keyTestName, keyTestDomain).Lifetime(-1).Generate()
+	assert.Error(t, err)
+}
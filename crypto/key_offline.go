@@ -0,0 +1,76 @@
+package crypto
+
+import (
+	"bytes"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+const (
+	// secretKeyPacketTag is the tag of secret key packets.
+	secretKeyPacketTag = 5
+	// s2kUsageSHA1 is the S2K usage octet of secret keys protected with a SHA-1 checksum.
+	s2kUsageSHA1 = 254
+	// gnuDummyS2K is the GNU extension S2K specifier of secret keys without secret material,
+	// with mode 101, no hash and protection mode 1.
+	gnuDummyS2K = "\x65\x00GNU\x01"
+)
+
+// IsPrimaryKeyOffline returns true if the key is a private key without the secret material
+// of its primary key, as exported by gpg --export-secret-subkeys.
+// Such keys can sign and decrypt with their subkeys, but can't certify or bind keys.
+func (key *Key) IsPrimaryKeyOffline() bool {
+	return key.entity.PrivateKey != nil && key.entity.PrivateKey.Dummy()
+}
+
+// ToSecretSubkeysOnly returns a copy of the private key without the secret material of its
+// primary key, which is replaced by a GNU dummy stub, as gpg --export-secret-subkeys does.
+// This is the usual setup to keep the primary key offline, while the subkeys are used day to day.
+func (key *Key) ToSecretSubkeysOnly() (*Key, error) {
+	if !key.IsPrivate() {
+		return nil, errors.New("gopenpgp: the key must be a private key")
+	}
+	stripped, err := key.Copy()
+	if err != nil {
+		return nil, err
+	}
+	if stripped.IsPrimaryKeyOffline() {
+		return stripped, nil
+	}
+
+	primaryKey := stripped.entity.PrivateKey
+	dummy, err := newDummyPrivateKey(&primaryKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !primaryKey.Encrypted {
+		_ = clearPrivateKey(primaryKey.PrivateKey)
+	}
+	stripped.entity.PrivateKey = dummy
+	return stripped, nil
+}
+
+// newDummyPrivateKey returns a private key without secret material for the public key.
+func newDummyPrivateKey(publicKey *packet.PublicKey) (*packet.PrivateKey, error) {
+	var serialized bytes.Buffer
+	if err := publicKey.Serialize(&serialized); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in serializing public key")
+	}
+	_, body, _, err := readRawPacket(serialized.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	body = append(append(body, s2kUsageSHA1, byte(packet.CipherAES256)), gnuDummyS2K...)
+	dummyPacket := appendRawLength([]byte{0xc0 | secretKeyPacketTag}, len(body))
+	p, err := packet.Read(bytes.NewReader(append(dummyPacket, body...)))
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in reading dummy private key")
+	}
+	dummy, ok := p.(*packet.PrivateKey)
+	if !ok || !dummy.Dummy() {
+		return nil, errors.New("gopenpgp: error in creating dummy private key")
+	}
+	return dummy, nil
+}
@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToSecretSubkeysOnly(t *testing.T) {
+	key, err := GenerateKeyWithSubkeys(
+		keyTestName, keyTestDomain, "x25519", 0,
+		NewSubkeySpec("x25519", 0, true, false, false),
+		NewSubkeySpec("x25519", 0, false, true, false),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	assert.False(t, key.IsPrimaryKeyOffline())
+
+	stripped, err := key.ToSecretSubkeysOnly()
+	if err != nil {
+		t.Fatal("Expected no error while stripping primary key, got:", err)
+	}
+	assert.False(t, key.IsPrimaryKeyOffline(), "the original key is not modified")
+	locked, err := stripped.Lock(testMailboxPassword)
+	if err != nil {
+		t.Fatal("Expected no error while locking key, got:", err)
+	}
+	armored, err := locked.Armor()
+	if err != nil {
+		t.Fatal("Expected no error while armoring key, got:", err)
+	}
+
+	imported, err := NewKeyFromArmored(armored)
+	if err != nil {
+		t.Fatal("Expected no error while importing key, got:", err)
+	}
+	assert.True(t, imported.IsPrimaryKeyOffline())
+	assert.Exactly(t, key.GetFingerprint(), imported.GetFingerprint())
+	unlocked, err := imported.Unlock(testMailboxPassword)
+	if err != nil {
+		t.Fatal("Expected no error while unlocking key, got:", err)
+	}
+	keyRing, err := NewKeyRing(unlocked)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+
+	message := NewPlainMessageFromString("offline")
+	ciphertext, err := keyRing.Encrypt(message, keyRing)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	decrypted, err := keyRing.Decrypt(ciphertext, keyRing, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, "offline", decrypted.GetString())
+
+	signature, err := keyRing.SignDetached(message)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	signatureKeyIDs, _ := signature.GetSignatureKeyIDs()
+	assert.Exactly(t, []uint64{imported.entity.Subkeys[0].PublicKey.KeyId}, signatureKeyIDs)
+	assert.NoError(t, keyRing.VerifyDetached(message, signature, GetUnixTime()))
+
+	other, err := GenerateKey("other", "other@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	_, err = keyRing.CertifyKey(other, other.entity.PrimaryIdentity().Name, 0)
+	assert.Error(t, err, "the primary key can't certify")
+
+	publicKey, err := other.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while getting the public key, got:", err)
+	}
+	_, err = publicKey.ToSecretSubkeysOnly()
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"encoding/hex"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+)
+
+// RevocationStatus describes the revocations of a key and its subkeys, as returned by Key.RevocationStatus.
+type RevocationStatus struct {
+	// Revoked is true if the primary key is revoked.
+	Revoked bool
+	// Revocations are the revocation signatures of the primary key.
+	Revocations []*Revocation
+	// Subkeys are the revocation statuses of the subkeys, in the key order.
+	Subkeys []*SubkeyRevocationStatus
+}
+
+// SubkeyRevocationStatus describes the revocations of a subkey.
+type SubkeyRevocationStatus struct {
+	// Fingerprint is the hex fingerprint of the subkey.
+	Fingerprint string
+	// Revoked is true if the subkey is revoked.
+	Revoked bool
+	// Revocations are the revocation signatures of the subkey.
+	Revocations []*Revocation
+}
+
+// Revocation describes a revocation signature.
+type Revocation struct {
+	// Reason is the reason for revocation, one of the constants.REVOCATION_REASON_* values.
+	Reason int
+	// ReasonText is the explanation given along with the reason, possibly empty.
+	ReasonText string
+	// Time is the creation time of the revocation signature, as unix timestamp.
+	Time int64
+	// IssuerKeyID is the hex key ID of the revocation signature issuer.
+	IssuerKeyID string
+	// IsDesignatedRevoker is true if the revocation was issued by a designated revoker,
+	// rather than by the key owner.
+	IsDesignatedRevoker bool
+}
+
+// IsCompromised returns true if the key material was reported as compromised,
+// in which case even the signatures made before the revocation can't be trusted.
+func (revocation *Revocation) IsCompromised() bool {
+	return revocation.Reason == constants.REVOCATION_REASON_COMPROMISED
+}
+
+// RevocationStatus returns the revocation status of the primary key and of each subkey,
+// evaluated at the current time, with the reasons and times of the revocations.
+// Revocations made in the future are listed, but don't revoke the key yet,
+// unless the key was compromised.
+func (key *Key) RevocationStatus() *RevocationStatus {
+	now := getNow()
+	entity := key.entity
+	status := &RevocationStatus{
+		Revoked:     entity.Revoked(now),
+		Revocations: getRevocations(entity.PrimaryKey, entity.Revocations),
+	}
+
+	for _, subkey := range entity.Subkeys {
+		status.Subkeys = append(status.Subkeys, &SubkeyRevocationStatus{
+			Fingerprint: hex.EncodeToString(subkey.PublicKey.Fingerprint),
+			Revoked:     subkey.Revoked(now),
+			Revocations: getRevocations(entity.PrimaryKey, subkey.Revocations),
+		})
+	}
+	return status
+}
+
+func getRevocations(primaryKey *packet.PublicKey, signatures []*packet.Signature) []*Revocation {
+	revocations := make([]*Revocation, 0, len(signatures))
+	for _, sig := range signatures {
+		revocation := &Revocation{
+			Reason:     constants.REVOCATION_REASON_NONE,
+			ReasonText: sig.RevocationReasonText,
+			Time:       sig.CreationTime.Unix(),
+		}
+		if sig.RevocationReason != nil {
+			revocation.Reason = int(*sig.RevocationReason)
+		}
+		if sig.IssuerKeyId != nil {
+			revocation.IssuerKeyID = keyIDToHex(*sig.IssuerKeyId)
+			revocation.IsDesignatedRevoker = *sig.IssuerKeyId != primaryKey.KeyId
+		}
+		revocations = append(revocations, revocation)
+	}
+	return revocations
+}
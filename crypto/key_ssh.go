@@ -0,0 +1,59 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp/eddsa"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// NewAuthenticationSubkeySpec creates a SubkeySpec for an authentication subkey of the given
+// keyType ("rsa" or "x25519"), that does not expire, e.g. to be used as SSH key.
+func NewAuthenticationSubkeySpec(keyType string, bits int) *SubkeySpec {
+	return NewSubkeySpecWithFlags(keyType, bits, constants.KEY_FLAG_AUTHENTICATE)
+}
+
+// GenerateKeyWithAuthentication generates a key as GenerateKey, with an additional
+// authentication subkey of the same type, e.g. to be used as SSH key.
+func GenerateKeyWithAuthentication(name, email string, keyType string, bits int) (*Key, error) {
+	return GenerateKeyWithSubkeys(
+		name, email, keyType, bits,
+		NewSubkeySpec(keyType, bits, false, true, false),
+		NewAuthenticationSubkeySpec(keyType, bits),
+	)
+}
+
+// GetAuthorizedSSHKey returns the OpenSSH public key line of the authentication key,
+// as used in authorized_keys files, commented with the key ID as gpg --export-ssh-key does.
+// The newest valid authentication subkey is used, or the primary key if none of the subkeys
+// can authenticate but the primary key can.
+func (key *Key) GetAuthorizedSSHKey() (string, error) {
+	authenticationKey, ok := key.authenticationKey(getNow())
+	if !ok {
+		return "", errors.New("gopenpgp: no valid authentication key found")
+	}
+
+	var publicKey interface{}
+	switch pub := authenticationKey.PublicKey.(type) {
+	case *rsa.PublicKey:
+		publicKey = pub
+	case *eddsa.PublicKey:
+		if len(pub.X) != ed25519.PublicKeySize {
+			return "", errors.New("gopenpgp: unsupported authentication key curve")
+		}
+		publicKey = ed25519.PublicKey(pub.X)
+	default:
+		return "", errors.New("gopenpgp: unsupported authentication key algorithm")
+	}
+
+	sshKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", errors.Wrap(err, "gopenpgp: error in encoding SSH public key")
+	}
+	authorizedKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(sshKey)), "\n")
+	return authorizedKey + " openpgp:0x" + authenticationKey.KeyIdShortString(), nil
+}
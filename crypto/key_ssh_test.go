@@ -0,0 +1,47 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/eddsa"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGetAuthorizedSSHKey(t *testing.T) {
+	for _, keyType := range []struct {
+		name    string
+		bits    int
+		sshType string
+	}{{"x25519", 0, ssh.KeyAlgoED25519}, {"rsa", 1024, ssh.KeyAlgoRSA}} {
+		key, err := GenerateKeyWithAuthentication(keyTestName, keyTestDomain, keyType.name, keyType.bits)
+		if err != nil {
+			t.Fatal("Expected no error while generating key, got:", err)
+		}
+		assert.True(t, key.CanEncrypt())
+		assert.True(t, key.CanAuthenticateAt(GetUnixTime()))
+
+		authorizedKey, err := key.GetAuthorizedSSHKey()
+		if err != nil {
+			t.Fatal("Expected no error while exporting SSH key, got:", err)
+		}
+		sshKey, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+		if err != nil {
+			t.Fatal("Expected no error while parsing SSH key, got:", err)
+		}
+		authenticationKey := key.entity.Subkeys[1].PublicKey
+		assert.Exactly(t, keyType.sshType, sshKey.Type())
+		assert.Exactly(t, "openpgp:0x"+authenticationKey.KeyIdShortString(), comment)
+		if pub, ok := authenticationKey.PublicKey.(*eddsa.PublicKey); ok {
+			assert.Exactly(t, ed25519.PublicKey(pub.X), sshKey.(ssh.CryptoPublicKey).CryptoPublicKey())
+		}
+	}
+
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	_, err = key.GetAuthorizedSSHKey()
+	assert.Error(t, err)
+}
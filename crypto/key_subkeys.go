@@ -0,0 +1,129 @@
+package crypto
+
+import (
+	"crypto"
+	"encoding/hex"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+// AddSubkey returns a copy of the unlocked private key with a new subkey described by spec,
+// bound with the primary key, e.g. NewSubkeySpec("x25519", 0, false, true, false)
+// for an encryption subkey, with spec.Lifetime as expiration.
+func (key *Key) AddSubkey(spec *SubkeySpec) (*Key, error) {
+	if err := spec.check(); err != nil {
+		return nil, err
+	}
+	updated, err := key.copyUnlocked()
+	if err != nil {
+		return nil, err
+	}
+	if err := addSubkey(updated.entity, spec); err != nil {
+		updated.ClearPrivateParams()
+		return nil, errors.Wrap(err, "gopenpgp: error in generating subkey")
+	}
+	return updated, nil
+}
+
+// RevokeSubkey returns a copy of the unlocked private key where the subkey with the given hex fingerprint
+// is revoked, with the reason, one of the constants.REVOCATION_REASON_* values, and its explanation.
+func (key *Key) RevokeSubkey(fingerprint string, reason int, reasonText string) (*Key, error) {
+	updated, err := key.copyUnlocked()
+	if err != nil {
+		return nil, err
+	}
+	subkey := updated.findSubkey(fingerprint)
+	if subkey == nil {
+		updated.ClearPrivateParams()
+		return nil, errors.New("gopenpgp: no subkey with fingerprint " + fingerprint)
+	}
+	if err := revokeSubkey(updated.entity, subkey, reason, reasonText); err != nil {
+		updated.ClearPrivateParams()
+		return nil, err
+	}
+	return updated, nil
+}
+
+// RotateEncryptionSubkey returns a copy of the unlocked private key with a new encryption subkey,
+// of the algorithm of the current encryption subkey, and where the previous encryption subkeys
+// are revoked as superseded.
+func (key *Key) RotateEncryptionSubkey() (*Key, error) {
+	updated, err := key.copyUnlocked()
+	if err != nil {
+		return nil, err
+	}
+	entity := updated.entity
+	current, ok := entity.EncryptionKey(getNow())
+	if !ok || current.PublicKey == entity.PrimaryKey {
+		updated.ClearPrivateParams()
+		return nil, errors.New("gopenpgp: the key has no encryption subkey to rotate")
+	}
+	spec := NewSubkeySpec("x25519", 0, false, true, false)
+	if current.PublicKey.PubKeyAlgo == packet.PubKeyAlgoRSA {
+		bits, err := current.PublicKey.BitLength()
+		if err != nil {
+			updated.ClearPrivateParams()
+			return nil, errors.Wrap(err, "gopenpgp: unable to read the encryption subkey size")
+		}
+		spec = NewSubkeySpec("rsa", int(bits), false, true, false)
+	}
+
+	previous := make([]*openpgp.Subkey, 0, len(entity.Subkeys))
+	for i := range entity.Subkeys {
+		subkey := &entity.Subkeys[i]
+		if subkey.Sig.FlagsValid && (subkey.Sig.FlagEncryptCommunications || subkey.Sig.FlagEncryptStorage) &&
+			!subkey.Revoked(getNow()) {
+			previous = append(previous, subkey)
+		}
+	}
+	for _, subkey := range previous {
+		if err := revokeSubkey(entity, subkey, constants.REVOCATION_REASON_SUPERSEDED, "rotated"); err != nil {
+			updated.ClearPrivateParams()
+			return nil, err
+		}
+	}
+	if err := addSubkey(entity, spec); err != nil {
+		updated.ClearPrivateParams()
+		return nil, errors.Wrap(err, "gopenpgp: error in generating subkey")
+	}
+	return updated, nil
+}
+
+// copyUnlocked returns a copy of the key, which must be an unlocked private key.
+func (key *Key) copyUnlocked() (*Key, error) {
+	unlocked, err := key.IsUnlocked()
+	if err != nil {
+		return nil, err
+	}
+	if !unlocked {
+		return nil, errors.New("gopenpgp: the key must be unlocked to update its subkeys")
+	}
+	return key.Copy()
+}
+
+// findSubkey returns the subkey with the given hex fingerprint, or nil.
+func (key *Key) findSubkey(fingerprint string) *openpgp.Subkey {
+	for i := range key.entity.Subkeys {
+		subkey := &key.entity.Subkeys[i]
+		if hex.EncodeToString(subkey.PublicKey.Fingerprint) == strings.ToLower(fingerprint) {
+			return subkey
+		}
+	}
+	return nil
+}
+
+func revokeSubkey(entity *openpgp.Entity, subkey *openpgp.Subkey, reason int, reasonText string) error {
+	config := &packet.Config{
+		DefaultHash: crypto.SHA256,
+		Time:        getTimeGenerator(),
+		Rand:        getRandomSource(),
+	}
+	if err := entity.RevokeSubkey(subkey, packet.ReasonForRevocation(reason), reasonText, config); err != nil {
+		return errors.Wrap(err, "gopenpgp: error in revoking subkey")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySubkeyManagement(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	defer key.ClearPrivateParams()
+
+	signSpec := NewSubkeySpec("x25519", 0, true, false, false)
+	signSpec.Lifetime = 3600
+	withSigningSubkey, err := key.AddSubkey(signSpec)
+	if err != nil {
+		t.Fatal("Expected no error while adding subkey, got:", err)
+	}
+	assert.Len(t, key.entity.Subkeys, 1)
+	assert.Len(t, withSigningSubkey.entity.Subkeys, 2)
+
+	rotated, err := withSigningSubkey.RotateEncryptionSubkey()
+	if err != nil {
+		t.Fatal("Expected no error while rotating subkey, got:", err)
+	}
+	// Parse the serialized key to check the signatures
+	serialized, err := rotated.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+	rotated, err = NewKey(serialized)
+	if err != nil {
+		t.Fatal("Expected no error while parsing key, got:", err)
+	}
+	status := rotated.RevocationStatus()
+	assert.Len(t, status.Subkeys, 3)
+	assert.True(t, status.Subkeys[0].Revoked)
+	assert.Exactly(t, constants.REVOCATION_REASON_SUPERSEDED, status.Subkeys[0].Revocations[0].Reason)
+	assert.False(t, status.Subkeys[1].Revoked)
+	assert.False(t, status.Subkeys[2].Revoked)
+
+	keyRing, err := NewKeyRing(rotated)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	ciphertext, err := keyRing.Encrypt(NewPlainMessageFromString(testMessage), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	encryptionKeyIDs, ok := ciphertext.GetEncryptionKeyIDs()
+	assert.True(t, ok)
+	assert.Equal(t, []uint64{rotated.entity.Subkeys[2].PublicKey.KeyId}, encryptionKeyIDs)
+
+	revoked, err := rotated.RevokeSubkey(status.Subkeys[1].Fingerprint, constants.REVOCATION_REASON_COMPROMISED, "leaked")
+	if err != nil {
+		t.Fatal("Expected no error while revoking subkey, got:", err)
+	}
+	status = revoked.RevocationStatus()
+	assert.True(t, status.Subkeys[1].Revoked)
+	assert.True(t, status.Subkeys[1].Revocations[0].IsCompromised())
+	assert.Exactly(t, "leaked", status.Subkeys[1].Revocations[0].ReasonText)
+
+	_, err = rotated.RevokeSubkey("0000", constants.REVOCATION_REASON_NONE, "")
+	assert.Error(t, err)
+	publicKey, err := rotated.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while getting public key, got:", err)
+	}
+	_, err = publicKey.RotateEncryptionSubkey()
+	assert.Error(t, err)
+}
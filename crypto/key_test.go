@@ -11,6 +11,7 @@ import (
 	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/ProtonMail/go-crypto/openpgp/armor"
 	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -417,6 +418,31 @@ func TestKeyCapabilities(t *testing.T) {
 	assert.True(t, publicKey.CanEncrypt())
 }
 
+func TestKeyCapabilitiesAt(t *testing.T) {
+	encryptSpec := NewSubkeySpec("x25519", 0, false, true, false)
+	encryptSpec.Lifetime = 3600
+	authSpec := NewSubkeySpec("x25519", 0, false, false, true)
+	authSpec.Lifetime = 3600
+
+	key, err := GenerateKeyWithSubkeys(keyTestName, keyTestDomain, "x25519", 0, encryptSpec, authSpec)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	defer key.ClearPrivateParams()
+
+	now := GetUnixTime()
+	assert.True(t, key.CanEncryptAt(now))
+	assert.True(t, key.CanSignAt(now))
+	assert.True(t, key.CanAuthenticateAt(now))
+
+	// The subkeys expire, while the primary key can still sign
+	assert.False(t, key.CanEncryptAt(now+7200))
+	assert.True(t, key.CanSignAt(now+7200))
+	assert.False(t, key.CanAuthenticateAt(now+7200))
+
+	assert.False(t, keyTestEC.CanAuthenticateAt(now))
+}
+
 func TestRevokedKeyCapabilities(t *testing.T) {
 	pgp.latestServerTime = 1632219895
 	defer func() {
@@ -430,6 +456,8 @@ func TestRevokedKeyCapabilities(t *testing.T) {
 
 	assert.False(t, revokedKey.CanVerify())
 	assert.False(t, revokedKey.CanEncrypt())
+	assert.False(t, revokedKey.CanSignAt(GetUnixTime()))
+	assert.False(t, revokedKey.CanEncryptAt(GetUnixTime()))
 	assert.False(t, revokedKey.IsExpired())
 	assert.True(t, revokedKey.IsRevoked())
 }
@@ -452,3 +480,36 @@ func TestKeyCompression(t *testing.T) {
 		keyTestEC.entity.PrimaryIdentity().SelfSignature.PreferredCompression,
 	)
 }
+
+func TestRevocationStatus(t *testing.T) {
+	status := keyTestEC.RevocationStatus()
+	assert.False(t, status.Revoked)
+	assert.Empty(t, status.Revocations)
+	assert.Len(t, status.Subkeys, 1)
+	assert.False(t, status.Subkeys[0].Revoked)
+
+	key, err := keyTestEC.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	config := &packet.Config{Time: getTimeGenerator(), Rand: getRandomSource()}
+	if err = key.entity.RevokeSubkey(&key.entity.Subkeys[0], packet.KeyRetired, "not used anymore", config); err != nil {
+		t.Fatal("Expected no error while revoking subkey, got:", err)
+	}
+	if err = key.entity.RevokeKey(packet.KeyCompromised, "", config); err != nil {
+		t.Fatal("Expected no error while revoking key, got:", err)
+	}
+
+	status = key.RevocationStatus()
+	assert.True(t, status.Revoked)
+	assert.Len(t, status.Revocations, 1)
+	assert.Equal(t, constants.REVOCATION_REASON_COMPROMISED, status.Revocations[0].Reason)
+	assert.True(t, status.Revocations[0].IsCompromised())
+	assert.Equal(t, key.GetHexKeyID(), status.Revocations[0].IssuerKeyID)
+	assert.False(t, status.Revocations[0].IsDesignatedRevoker)
+	assert.Equal(t, GetUnixTime(), status.Revocations[0].Time)
+
+	assert.True(t, status.Subkeys[0].Revoked)
+	assert.Equal(t, constants.REVOCATION_REASON_RETIRED, status.Subkeys[0].Revocations[0].Reason)
+	assert.Equal(t, "not used anymore", status.Subkeys[0].Revocations[0].ReasonText)
+}
@@ -0,0 +1,169 @@
+package crypto
+
+import (
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+// fullTrustAmount is the trust amount of fully trusted introducers.
+const fullTrustAmount = 120
+
+// TrustDomainRegularExpression returns the trust signature regular expression
+// matching the user IDs with an email address in the given domain or its subdomains,
+// as used to scope organizational introducers.
+func TrustDomainRegularExpression(domain string) string {
+	return "<[^>]+[@.]" + regexp.QuoteMeta(domain) + ">$"
+}
+
+// IsUserIDTrusted returns true if the user ID of the key is valid at unixTime in the
+// web of trust rooted at the keys of the keyring, which are trusted ultimately.
+// The user ID is valid if it is certified by one of the trusted keys, or by a key of
+// introducers reached from them through a chain of fully trusted trust signatures.
+// Each trust signature limits the depth of the rest of the chain, and the user IDs
+// it applies to, if it has a regular expression.
+// The key, the trusted keys and the introducers must not be revoked or expired at unixTime.
+func (keyRing *KeyRing) IsUserIDTrusted(key *Key, userID string, introducers *KeyRing, unixTime int64) bool {
+	type trustedKey struct {
+		entity  *openpgp.Entity
+		depth   int
+		regexps []*regexp.Regexp
+	}
+
+	if !isEntityValidAt(key.entity, unixTime) {
+		return false
+	}
+	var queue []trustedKey
+	// bestDepth is the largest depth each key was reached with, a key is only
+	// queued again if it is reached with a larger depth.
+	bestDepth := make(map[string]int)
+	for _, entity := range keyRing.entities {
+		if !isEntityValidAt(entity, unixTime) {
+			continue
+		}
+		queue = append(queue, trustedKey{entity: entity, depth: math.MaxInt32})
+		bestDepth[string(entity.PrimaryKey.Fingerprint)] = math.MaxInt32
+	}
+
+	for len(queue) > 0 {
+		trusted := queue[0]
+		queue = queue[1:]
+
+		if matchesAll(trusted.regexps, userID) &&
+			getValidCertification(key.entity, userID, trusted.entity, unixTime) != nil {
+			return true
+		}
+		if trusted.depth < 2 || introducers == nil {
+			continue
+		}
+		for _, entity := range introducers.entities {
+			if depth, ok := bestDepth[string(entity.PrimaryKey.Fingerprint)]; ok && depth >= trusted.depth-1 ||
+				!isEntityValidAt(entity, unixTime) {
+				continue
+			}
+			for introducerUserID := range entity.Identities {
+				if !matchesAll(trusted.regexps, introducerUserID) {
+					continue
+				}
+				sig := getValidCertification(entity, introducerUserID, trusted.entity, unixTime)
+				if sig == nil || sig.TrustLevel == 0 || sig.TrustAmount < fullTrustAmount {
+					continue
+				}
+				regexps := trusted.regexps
+				if sig.TrustRegularExpression != nil {
+					re, err := regexp.Compile(*sig.TrustRegularExpression)
+					if err != nil {
+						continue
+					}
+					regexps = append(append([]*regexp.Regexp{}, regexps...), re)
+				}
+				depth := int(sig.TrustLevel)
+				if trusted.depth-1 < depth {
+					depth = trusted.depth - 1
+				}
+				if best, ok := bestDepth[string(entity.PrimaryKey.Fingerprint)]; ok && best >= depth {
+					continue
+				}
+				queue = append(queue, trustedKey{entity: entity, depth: depth, regexps: regexps})
+				bestDepth[string(entity.PrimaryKey.Fingerprint)] = depth
+			}
+		}
+	}
+	return false
+}
+
+// isEntityValidAt returns true if the key is neither revoked nor expired at unixTime.
+func isEntityValidAt(entity *openpgp.Entity, unixTime int64) bool {
+	if entity.Revoked(time.Unix(unixTime, 0)) {
+		return false
+	}
+	expirationTime := getEntityExpirationTime(entity)
+	return expirationTime == 0 || unixTime < expirationTime
+}
+
+// getValidCertification returns the newest certification of the user ID of the entity
+// by signEntity, if it is valid at unixTime, nil otherwise.
+func getValidCertification(entity *openpgp.Entity, userID string, signEntity *openpgp.Entity, unixTime int64) *packet.Signature {
+	identity, ok := entity.Identities[userID]
+	if !ok {
+		return nil
+	}
+	var signatures []*packet.Signature
+	for _, sig := range identity.Signatures {
+		if sig.CreationTime.Unix() <= unixTime {
+			signatures = append(signatures, sig)
+		}
+	}
+	sig := getNewestCertification(signatures, signEntity)
+	if sig == nil || sig.SigType == packet.SigTypeCertificationRevocation {
+		return nil
+	}
+	if expirationTime := getCertificationExpirationTime(sig); expirationTime != 0 && expirationTime <= unixTime {
+		return nil
+	}
+	if signEntity.PrimaryKey.VerifyUserIdSignature(userID, entity.PrimaryKey, sig) != nil {
+		return nil
+	}
+	return sig
+}
+
+// getTrustParameters validates the trust signature options, and returns the corresponding subpacket values.
+func getTrustParameters(options *CertificationOptions) (packet.TrustLevel, packet.TrustAmount, *string, error) {
+	if options.TrustDepth == 0 {
+		if options.TrustRegularExpression != "" {
+			return 0, 0, nil, errors.New("gopenpgp: trust regular expression without trust depth")
+		}
+		return 0, 0, nil, nil
+	}
+	if options.TrustDepth < 0 || options.TrustDepth > math.MaxUint8 {
+		return 0, 0, nil, errors.New("gopenpgp: invalid trust depth")
+	}
+	amount := options.TrustAmount
+	if amount == 0 {
+		amount = fullTrustAmount
+	}
+	if amount < 0 || amount > math.MaxUint8 {
+		return 0, 0, nil, errors.New("gopenpgp: invalid trust amount")
+	}
+	if options.TrustRegularExpression == "" {
+		return packet.TrustLevel(options.TrustDepth), packet.TrustAmount(amount), nil, nil
+	}
+	if _, err := regexp.Compile(options.TrustRegularExpression); err != nil {
+		return 0, 0, nil, errors.Wrap(err, "gopenpgp: invalid trust regular expression")
+	}
+	regularExpression := options.TrustRegularExpression
+	return packet.TrustLevel(options.TrustDepth), packet.TrustAmount(amount), &regularExpression, nil
+}
+
+func matchesAll(regexps []*regexp.Regexp, userID string) bool {
+	for _, re := range regexps {
+		if !re.MatchString(userID) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUserIDTrusted(t *testing.T) {
+	root, err := GenerateKey("root", "root@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	ca, err := GenerateKey("ca", "ca@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	alice, err := GenerateKey("alice", "alice@sub.example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	bob, err := GenerateKey("bob", "bob@example.org", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	rootKeyRing, _ := NewKeyRing(root)
+	caKeyRing, _ := NewKeyRing(ca)
+	caUserID := ca.entity.PrimaryIdentity().Name
+	aliceUserID := alice.entity.PrimaryIdentity().Name
+	bobUserID := bob.entity.PrimaryIdentity().Name
+
+	alice, err = caKeyRing.CertifyKey(alice, aliceUserID, 0)
+	if err != nil {
+		t.Fatal("Expected no error while certifying key, got:", err)
+	}
+	bob, err = caKeyRing.CertifyKey(bob, bobUserID, 0)
+	if err != nil {
+		t.Fatal("Expected no error while certifying key, got:", err)
+	}
+	now := GetUnixTime()
+
+	introducers, _ := NewKeyRing(ca)
+	assert.False(t, rootKeyRing.IsUserIDTrusted(alice, aliceUserID, introducers, now), "the CA is not an introducer")
+
+	introducer, err := rootKeyRing.CertifyKeyWithOptions(ca, caUserID, &CertificationOptions{
+		TrustDepth:             1,
+		TrustRegularExpression: TrustDomainRegularExpression("example.com"),
+	})
+	if err != nil {
+		t.Fatal("Expected no error while certifying key, got:", err)
+	}
+	introducers, _ = NewKeyRing(introducer)
+	assert.True(t, rootKeyRing.IsUserIDTrusted(introducer, caUserID, nil, now))
+	assert.True(t, rootKeyRing.IsUserIDTrusted(alice, aliceUserID, introducers, now))
+	assert.False(t, rootKeyRing.IsUserIDTrusted(bob, bobUserID, introducers, now), "the CA is scoped to example.com")
+	assert.False(t, rootKeyRing.IsUserIDTrusted(alice, aliceUserID, nil, now))
+
+	// A revoked introducer or target key is not trusted
+	revokedIntroducer, err := introducer.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	if err = revokedIntroducer.entity.RevokeKey(packet.KeyRetired, "", &packet.Config{Time: getTimeGenerator()}); err != nil {
+		t.Fatal("Expected no error while revoking key, got:", err)
+	}
+	revokedIntroducers, _ := NewKeyRing(revokedIntroducer)
+	assert.False(t, rootKeyRing.IsUserIDTrusted(alice, aliceUserID, revokedIntroducers, now+1))
+	revokedAlice, err := alice.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	if err = revokedAlice.entity.RevokeKey(packet.KeyRetired, "", &packet.Config{Time: getTimeGenerator()}); err != nil {
+		t.Fatal("Expected no error while revoking key, got:", err)
+	}
+	assert.False(t, rootKeyRing.IsUserIDTrusted(revokedAlice, aliceUserID, introducers, now+1))
+
+	_, err = rootKeyRing.CertifyKeyWithOptions(ca, caUserID, &CertificationOptions{TrustRegularExpression: "example"})
+	assert.Error(t, err)
+	_, err = rootKeyRing.CertifyKeyWithOptions(ca, caUserID, &CertificationOptions{TrustDepth: 1, TrustRegularExpression: "("})
+	assert.Error(t, err)
+}
+
+func TestIsUserIDTrustedLongerChain(t *testing.T) {
+	var keys [5]*Key
+	for i, name := range []string{"root", "a", "b", "c", "target"} {
+		key, err := GenerateKey(name, name+"@protonmail.ch", "x25519", 0)
+		if err != nil {
+			t.Fatal("Expected no error while generating key, got:", err)
+		}
+		keys[i] = key
+	}
+	root, a, b, c, target := keys[0], keys[1], keys[2], keys[3], keys[4]
+	certify := func(signer, key *Key, depth int) *Key {
+		signerKeyRing, _ := NewKeyRing(signer)
+		certified, err := signerKeyRing.CertifyKeyWithOptions(
+			key, key.entity.PrimaryIdentity().Name, &CertificationOptions{TrustDepth: depth},
+		)
+		if err != nil {
+			t.Fatal("Expected no error while certifying key, got:", err)
+		}
+		return certified
+	}
+
+	// The root reaches a directly with depth 1, and through b with depth 2,
+	// which is needed to reach the target through c
+	a = certify(root, a, 1)
+	b = certify(root, b, 3)
+	a = certify(b, a, 2)
+	c = certify(a, c, 1)
+	target = certify(c, target, 0)
+
+	rootKeyRing, _ := NewKeyRing(root)
+	introducers, _ := NewKeyRing(a)
+	for _, key := range []*Key{b, c} {
+		if err := introducers.AddKey(key); err != nil {
+			t.Fatal("Expected no error while adding key, got:", err)
+		}
+	}
+	assert.True(t, rootKeyRing.IsUserIDTrusted(target, target.entity.PrimaryIdentity().Name, introducers, GetUnixTime()))
+}
@@ -0,0 +1,103 @@
+package crypto
+
+import (
+	"bytes"
+	goerrors "errors"
+	"strconv"
+
+	pgpErrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+const (
+	packetTagSignature    = 2
+	packetTagSecretKey    = 5
+	packetTagPublicKey    = 6
+	packetTagSecretSubkey = 7
+)
+
+// NewKeyWithUnknownPacketPolicy creates a new key from the first key in the unarmored binary data,
+// handling the packets with unknown tags or unsupported algorithms, along with their signatures,
+// according to unknownPacketPolicy, one of constants.UNKNOWN_PACKET_FAIL, UNKNOWN_PACKET_WARN
+// or UNKNOWN_PACKET_PRESERVE. NewKey silently skips such packets.
+// Returns the key and a warning for each skipped packet.
+// The preserved packets are written after the known packets by Key.Serialize,
+// and the public ones by Key.GetPublicKey, but are not kept in keyrings.
+// The primary key must be supported.
+func NewKeyWithUnknownPacketPolicy(binKeys []byte, unknownPacketPolicy int) (key *Key, warnings []string, err error) {
+	var knownPackets bytes.Buffer
+	var unknownPackets [][]byte
+	skipSignatures := false
+	for data := binKeys; len(data) > 0; {
+		tag, body, err := parsePacket(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		rawPacket := data[:packetLength(data, body)]
+		data = data[len(rawPacket):]
+
+		if knownPackets.Len() > 0 && (tag == packetTagPublicKey || tag == packetTagSecretKey) {
+			// Start of the next key
+			break
+		}
+		if skipSignatures && tag == packetTagSignature {
+			last := len(unknownPackets) - 1
+			unknownPackets[last] = append(unknownPackets[last], rawPacket...)
+			continue
+		}
+		skipSignatures = false
+
+		warning := getUnknownPacketWarning(tag, rawPacket)
+		if warning == "" {
+			knownPackets.Write(rawPacket)
+			continue
+		}
+		if knownPackets.Len() == 0 {
+			return nil, nil, errors.New("gopenpgp: unsupported primary key: " + warning)
+		}
+		if unknownPacketPolicy == constants.UNKNOWN_PACKET_FAIL {
+			return nil, nil, errors.New("gopenpgp: key contains an " + warning)
+		}
+		warnings = append(warnings, warning)
+		unknownPackets = append(unknownPackets, clone(rawPacket))
+		skipSignatures = tag != packetTagSignature
+	}
+
+	key, err = NewKey(knownPackets.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	if unknownPacketPolicy == constants.UNKNOWN_PACKET_PRESERVE {
+		key.unknownPackets = unknownPackets
+	}
+	return key, warnings, nil
+}
+
+// getUnknownPacketWarning returns a description of the packet
+// if it has an unknown tag or an unsupported algorithm, and an empty string otherwise.
+func getUnknownPacketWarning(tag byte, rawPacket []byte) string {
+	_, err := packet.Read(bytes.NewReader(rawPacket))
+	var unknownPacketTypeError pgpErrors.UnknownPacketTypeError
+	if goerrors.As(err, &unknownPacketTypeError) {
+		return "unknown packet with tag " + strconv.Itoa(int(tag))
+	}
+	var unsupportedError pgpErrors.UnsupportedError
+	if goerrors.As(err, &unsupportedError) {
+		return "unsupported packet with tag " + strconv.Itoa(int(tag)) + ": " + unsupportedError.Error()
+	}
+	return ""
+}
+
+// getPublicUnknownPackets returns the preserved unknown packets of the key, except the secret key packets.
+func (key *Key) getPublicUnknownPackets() [][]byte {
+	var publicPackets [][]byte
+	for _, rawPacket := range key.unknownPackets {
+		tag, _, err := parsePacket(rawPacket)
+		if err == nil && tag != packetTagSecretKey && tag != packetTagSecretSubkey {
+			publicPackets = append(publicPackets, rawPacket)
+		}
+	}
+	return publicPackets
+}
@@ -0,0 +1,128 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKeyWithUnknownPacketPolicy(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	publicKey, err := key.GetPublicKey()
+	if err != nil {
+		t.Fatal("Expected no error while serializing public key, got:", err)
+	}
+	// Private use packet tag 60, and public subkey with the experimental algorithm 100
+	unknownPacket := []byte{0xc0 | 60, 3, 1, 2, 3}
+	experimentalSubkey := []byte{0xc0 | 14, 8, 4, 0x60, 0, 0, 0, 100, 0xaa, 0xbb}
+	binKey := append(append(clone(publicKey), unknownPacket...), experimentalSubkey...)
+
+	_, _, err = NewKeyWithUnknownPacketPolicy(binKey, constants.UNKNOWN_PACKET_FAIL)
+	assert.Error(t, err)
+
+	warnedKey, warnings, err := NewKeyWithUnknownPacketPolicy(binKey, constants.UNKNOWN_PACKET_WARN)
+	if err != nil {
+		t.Fatal("Expected no error while reading key, got:", err)
+	}
+	assert.Len(t, warnings, 2)
+	assert.Exactly(t, key.GetFingerprint(), warnedKey.GetFingerprint())
+	serialized, err := warnedKey.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+	assert.Exactly(t, publicKey, serialized)
+
+	preservedKey, warnings, err := NewKeyWithUnknownPacketPolicy(binKey, constants.UNKNOWN_PACKET_PRESERVE)
+	if err != nil {
+		t.Fatal("Expected no error while reading key, got:", err)
+	}
+	assert.Len(t, warnings, 2)
+	copiedKey, err := preservedKey.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	for _, k := range []*Key{preservedKey, copiedKey} {
+		serialized, err = k.Serialize()
+		if err != nil {
+			t.Fatal("Expected no error while serializing key, got:", err)
+		}
+		assert.Exactly(t, binKey, serialized)
+	}
+
+	privateKey, err := key.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+	unknownSecretSubkey := []byte{0xc0 | 7, 8, 4, 0x60, 0, 0, 0, 100, 0xaa, 0xbb}
+	preservedKey, _, err = NewKeyWithUnknownPacketPolicy(
+		append(append(privateKey, unknownPacket...), unknownSecretSubkey...),
+		constants.UNKNOWN_PACKET_PRESERVE,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while reading key, got:", err)
+	}
+	exported, err := preservedKey.GetPublicKey()
+	if err != nil {
+		t.Fatal("Expected no error while serializing public key, got:", err)
+	}
+	assert.True(t, bytes.HasSuffix(exported, unknownPacket))
+}
+
+func TestKeyRingSerializeUnknownPackets(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	publicKey, err := key.GetPublicKey()
+	if err != nil {
+		t.Fatal("Expected no error while serializing public key, got:", err)
+	}
+	unknownPacket := []byte{0xc0 | 60, 3, 1, 2, 3}
+	binKey := append(clone(publicKey), unknownPacket...)
+	preservedKey, _, err := NewKeyWithUnknownPacketPolicy(binKey, constants.UNKNOWN_PACKET_PRESERVE)
+	if err != nil {
+		t.Fatal("Expected no error while reading key, got:", err)
+	}
+	keyRing, err := NewKeyRing(preservedKey)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	copiedKeyRing, err := keyRing.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying keyring, got:", err)
+	}
+	for _, kr := range []*KeyRing{keyRing, copiedKeyRing} {
+		serialized, err := kr.Serialize()
+		if err != nil {
+			t.Fatal("Expected no error while serializing keyring, got:", err)
+		}
+		assert.Exactly(t, binKey, serialized)
+	}
+
+	privateKey, err := key.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+	unknownSecretSubkey := []byte{0xc0 | 7, 8, 4, 0x60, 0, 0, 0, 100, 0xaa, 0xbb}
+	preservedKey, _, err = NewKeyWithUnknownPacketPolicy(
+		append(append(privateKey, unknownPacket...), unknownSecretSubkey...),
+		constants.UNKNOWN_PACKET_PRESERVE,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while reading key, got:", err)
+	}
+	keyRing, err = NewKeyRing(preservedKey)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	serialized, err := keyRing.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing keyring, got:", err)
+	}
+	assert.Exactly(t, binKey, serialized)
+}
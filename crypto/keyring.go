@@ -6,14 +6,22 @@ import (
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
 	"github.com/pkg/errors"
 )
 
 // KeyRing contains multiple private and public keys.
+// A keyring can be reused across messages, and shared by concurrent encryptions,
+// decryptions, signatures and verifications, as long as it is not modified meanwhile,
+// e.g. with AddKey or ClearPrivateParams. Use Copy to get an independent keyring.
 type KeyRing struct {
 	// PGP entities in this keyring.
 	entities openpgp.EntityList
 
+	// unknownPackets preserved by NewKeyWithUnknownPacketPolicy, by entity.
+	unknownPackets map[*openpgp.Entity][][]byte
+
 	// FirstKeyID as obtained from API to match salt
 	FirstKeyID string
 }
@@ -55,7 +63,7 @@ func (keyRing *KeyRing) AddKey(key *Key) error {
 func (keyRing *KeyRing) GetKeys() []*Key {
 	keys := make([]*Key, keyRing.CountEntities())
 	for i, entity := range keyRing.entities {
-		keys[i] = &Key{entity}
+		keys[i] = &Key{entity: entity, unknownPackets: keyRing.unknownPackets[entity]}
 	}
 	return keys
 }
@@ -65,7 +73,8 @@ func (keyRing *KeyRing) GetKey(n int) (*Key, error) {
 	if n >= keyRing.CountEntities() {
 		return nil, errors.New("gopenpgp: out of bound when fetching key")
 	}
-	return &Key{keyRing.entities[n]}, nil
+	entity := keyRing.entities[n]
+	return &Key{entity: entity, unknownPackets: keyRing.unknownPackets[entity]}, nil
 }
 
 // getSigningEntity returns first private unlocked signing entity from keyring.
@@ -145,6 +154,17 @@ func (keyRing *KeyRing) GetKeyIDs() []uint64 {
 	return res
 }
 
+// FingerprintForKeyID returns the hex encoded fingerprint of the primary key or subkey
+// of the keyring with the given key ID, e.g. the key ID of a key packet.
+func (keyRing *KeyRing) FingerprintForKeyID(keyID uint64) (string, error) {
+	for _, key := range keyRing.GetKeys() {
+		if fingerprint, ok := key.SubkeyFingerprints()[keyIDToHex(keyID)]; ok {
+			return fingerprint, nil
+		}
+	}
+	return "", errors.New("gopenpgp: no key found with the key ID " + keyIDToHex(keyID))
+}
+
 // --- Filter keyrings
 
 // FilterExpiredKeys takes a given KeyRing list and it returns only those
@@ -193,6 +213,31 @@ func FilterExpiredKeys(contactKeys []*KeyRing) (filteredKeys []*KeyRing, err err
 	return filteredKeys, nil
 }
 
+// FilterEncryptionKeys returns a copy of the keyring with only the keys
+// that can be used for encryption at the given unix time, as Key.CanEncryptAt.
+// The returned keyring is empty if none of the keys can be used.
+func (keyRing *KeyRing) FilterEncryptionKeys(unixTime int64) (*KeyRing, error) {
+	return keyRing.filterKeys(func(key *Key) bool { return key.CanEncryptAt(unixTime) })
+}
+
+// FilterSigningKeys returns a copy of the keyring with only the keys
+// that can be used for signing or verification at the given unix time, as Key.CanSignAt.
+// The returned keyring is empty if none of the keys can be used.
+func (keyRing *KeyRing) FilterSigningKeys(unixTime int64) (*KeyRing, error) {
+	return keyRing.filterKeys(func(key *Key) bool { return key.CanSignAt(unixTime) })
+}
+
+func (keyRing *KeyRing) filterKeys(keep func(key *Key) bool) (*KeyRing, error) {
+	newKeyRing := &KeyRing{FirstKeyID: keyRing.FirstKeyID}
+	for _, key := range keyRing.GetKeys() {
+		if keep(key) {
+			newKeyRing.appendKey(key)
+		}
+	}
+
+	return newKeyRing.Copy()
+}
+
 // FirstKey returns a KeyRing with only the first key of the original one.
 func (keyRing *KeyRing) FirstKey() (*KeyRing, error) {
 	if len(keyRing.entities) == 0 {
@@ -229,6 +274,12 @@ func (keyRing *KeyRing) Copy() (*KeyRing, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "gopenpgp: unable to copy key: error in reading entity")
 		}
+		if unknownPackets, ok := keyRing.unknownPackets[entity]; ok {
+			if newKeyRing.unknownPackets == nil {
+				newKeyRing.unknownPackets = make(map[*openpgp.Entity][][]byte)
+			}
+			newKeyRing.unknownPackets[entities[id]] = unknownPackets
+		}
 	}
 	newKeyRing.entities = entities
 	newKeyRing.FirstKeyID = keyRing.FirstKeyID
@@ -236,6 +287,43 @@ func (keyRing *KeyRing) Copy() (*KeyRing, error) {
 	return newKeyRing, nil
 }
 
+// Serialize returns the unarmored public keys of the keyring, concatenated
+// as gpg --export does for multiple keys. Private key material and local certifications are never exported.
+// The public unknown packets preserved by NewKeyWithUnknownPacketPolicy follow their key, as in Key.GetPublicKey.
+func (keyRing *KeyRing) Serialize() ([]byte, error) {
+	var buffer bytes.Buffer
+	for _, key := range keyRing.GetKeys() {
+		if err := serializeForExport(key.entity, &buffer); err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: error in serializing public key")
+		}
+		for _, unknownPacket := range key.getPublicUnknownPackets() {
+			buffer.Write(unknownPacket)
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// Armor returns the public keys of the keyring in a single armored block.
+func (keyRing *KeyRing) Armor() (string, error) {
+	serialized, err := keyRing.Serialize()
+	if err != nil {
+		return "", err
+	}
+
+	return armor.ArmorWithType(serialized, constants.PublicKeyHeader)
+}
+
+// ArmorWithCustomHeaders returns the public keys of the keyring in a single armored block,
+// with the given headers. Empty parameters are omitted from the headers.
+func (keyRing *KeyRing) ArmorWithCustomHeaders(comment, version string) (string, error) {
+	serialized, err := keyRing.Serialize()
+	if err != nil {
+		return "", err
+	}
+
+	return armor.ArmorWithTypeAndCustomHeaders(serialized, constants.PublicKeyHeader, version, comment)
+}
+
 func (keyRing *KeyRing) ClearPrivateParams() {
 	for _, key := range keyRing.GetKeys() {
 		key.ClearPrivateParams()
@@ -247,4 +335,10 @@ func (keyRing *KeyRing) ClearPrivateParams() {
 // appendKey appends a key to the keyring.
 func (keyRing *KeyRing) appendKey(key *Key) {
 	keyRing.entities = append(keyRing.entities, key.entity)
+	if len(key.unknownPackets) > 0 {
+		if keyRing.unknownPackets == nil {
+			keyRing.unknownPackets = make(map[*openpgp.Entity][][]byte)
+		}
+		keyRing.unknownPackets[key.entity] = key.unknownPackets
+	}
 }
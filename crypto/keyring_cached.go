@@ -0,0 +1,44 @@
+package crypto
+
+import (
+	goerrors "errors"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// DecryptWithCachedSessionKey decrypts encrypted string using a cached session key,
+// and falls back to decrypting the session key from the key packets of the message
+// with the private keys of the keyring if the cached session key is missing or stale.
+// The path used is reported in PlainMessage.GetDecryptionDetails().IsCachedSessionKey().
+// The session key is only known to be stale once the whole message has been decrypted
+// and its integrity checked, hence the fallback is not available for streams.
+// The decryption is restricted by the given policy, as in KeyRing.DecryptWithPolicy.
+func (keyRing *KeyRing) DecryptWithCachedSessionKey(
+	message *PGPMessage, sessionKey *SessionKey, verifyKey *KeyRing, verifyTime int64, policy *DecryptionPolicy,
+) (*PlainMessage, error) {
+	if policy == nil {
+		policy = NewDecryptionPolicy()
+	}
+	if sessionKey != nil {
+		plainMessage, err := decryptWithPolicy(message, sessionKeyDecrypter(sessionKey), verifyKey, verifyTime, policy)
+		if err == nil || goerrors.As(err, &SignatureVerificationError{}) {
+			plainMessage.decryptionDetails.CachedSessionKey = true
+			return plainMessage, err
+		}
+	}
+	return decryptWithPolicy(message, policy.keyRingDecrypter(keyRing), verifyKey, verifyTime, policy)
+}
+
+func sessionKeyDecrypter(sessionKey *SessionKey) keyPacketDecrypter {
+	return func(_ []*packet.EncryptedKey, _ *DecryptionDetails) (*packet.EncryptedKey, *openpgp.Key, error) {
+		if err := sessionKey.checkSize(); err != nil {
+			return nil, nil, err
+		}
+		cipherFunc, err := sessionKey.GetCipherFunc()
+		if err != nil {
+			return nil, nil, err
+		}
+		return &packet.EncryptedKey{CipherFunc: cipherFunc, Key: sessionKey.Key}, nil, nil
+	}
+}
@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyRingDecryptWithCachedSessionKey(t *testing.T) {
+	message, err := keyRingTestPublic.Encrypt(NewPlainMessageFromString(testMessage), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	split, err := message.SplitMessage()
+	if err != nil {
+		t.Fatal("Expected no error while splitting the message, got:", err)
+	}
+	sessionKey, err := keyRingTestPrivate.DecryptSessionKey(split.GetBinaryKeyPacket())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the session key, got:", err)
+	}
+	staleSessionKey, err := GenerateSessionKeyAlgo(sessionKey.Algo)
+	if err != nil {
+		t.Fatal("Expected no error while generating the session key, got:", err)
+	}
+
+	for _, testCase := range []struct {
+		sessionKey *SessionKey
+		cached     bool
+	}{
+		{sessionKey, true},
+		{staleSessionKey, false},
+		{nil, false},
+	} {
+		decrypted, err := keyRingTestPrivate.DecryptWithCachedSessionKey(message, testCase.sessionKey, nil, 0, nil)
+		if err != nil {
+			t.Fatal("Expected no error while decrypting, got:", err)
+		}
+		assert.Exactly(t, testMessage, decrypted.GetString())
+		assert.Exactly(t, testCase.cached, decrypted.GetDecryptionDetails().IsCachedSessionKey())
+	}
+
+	otherKeyRing, err := NewKeyRing(keyTestRSA)
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	if _, err = otherKeyRing.DecryptWithCachedSessionKey(message, staleSessionKey, nil, 0, nil); err == nil {
+		t.Fatal("Expected an error while decrypting with a stale session key and the wrong keyring, got nil")
+	}
+}
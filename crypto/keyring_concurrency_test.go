@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyRingConcurrentEncryptDecrypt(t *testing.T) {
+	options := NewEncryptionOptions().WithCompression()
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			message := NewPlainMessageFromString("concurrent")
+			ciphertext, err := keyRingTestPublic.EncryptWithOptions(message, keyRingTestPrivate, options.Copy().WithHash(constants.SHA512))
+			if err != nil {
+				errs <- err
+				return
+			}
+			decrypted, err := keyRingTestPrivate.Decrypt(ciphertext, keyRingTestPublic, GetUnixTime())
+			if err != nil {
+				errs <- err
+				return
+			}
+			if decrypted.GetString() != "concurrent" {
+				errs <- assert.AnError
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal("Expected no error while encrypting and decrypting concurrently, got:", err)
+	}
+}
+
+func TestKeyRingConcurrentDecryptWithPolicy(t *testing.T) {
+	ciphertext, err := keyRingTestPublic.Encrypt(NewPlainMessageFromString("shared"), keyRingTestPrivate)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	policy := NewDecryptionPolicy().AllowLegacyCiphers()
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			decrypted, err := keyRingTestPrivate.DecryptWithPolicy(ciphertext, keyRingTestPublic, GetUnixTime(), policy)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if decrypted.GetString() != "shared" {
+				errs <- assert.AnError
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal("Expected no error while decrypting concurrently, got:", err)
+	}
+}
+
+func TestEncryptionOptionsCopy(t *testing.T) {
+	options := NewEncryptionOptions()
+	derived := options.Copy().WithCipher(constants.AES128).WithCompression()
+	assert.Exactly(t, NewEncryptionOptions(), options)
+	assert.Exactly(t, constants.AES128, derived.cipher)
+	assert.True(t, derived.compress)
+}
@@ -0,0 +1,181 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// KeyRingDiff describes the changes between two keyrings, as returned by KeyRing.Diff.
+type KeyRingDiff struct {
+	// AddedKeys are the keys only present in the other keyring.
+	AddedKeys []*Key
+	// RemovedKeys are the keys only present in the original keyring.
+	RemovedKeys []*Key
+	// ChangedKeys describe the changes of the keys present in both keyrings.
+	ChangedKeys []*KeyDiff
+}
+
+// KeyDiff describes the changes of a key present in both keyrings.
+type KeyDiff struct {
+	// Key is the updated key, from the other keyring.
+	Key *Key
+	// AddedSubkeys are the hex fingerprints of the new subkeys.
+	AddedSubkeys []string
+	// AddedUserIDs are the new user IDs.
+	AddedUserIDs []string
+	// AddedCertifications are the new third-party certifications of the user IDs.
+	AddedCertifications []*Certification
+	// Revoked is true if the key was revoked.
+	Revoked bool
+	// RevokedSubkeys are the hex fingerprints of the revoked subkeys.
+	RevokedSubkeys []string
+	// RevokedUserIDs are the revoked user IDs.
+	RevokedUserIDs []string
+	// OldExpirationTime and NewExpirationTime are the expiration times of the key
+	// as unix timestamps, 0 if the key does not expire.
+	OldExpirationTime, NewExpirationTime int64
+}
+
+// Certification is a third-party certification of a user ID.
+type Certification struct {
+	UserID       string
+	IssuerKeyID  string
+	CreationTime int64
+	// ExpirationTime is the unix time at which the certification expires, 0 if it does not expire.
+	ExpirationTime int64
+}
+
+// IsEmpty returns true if the keyrings contain the same keys, without any change.
+func (diff *KeyRingDiff) IsEmpty() bool {
+	return len(diff.AddedKeys) == 0 && len(diff.RemovedKeys) == 0 && len(diff.ChangedKeys) == 0
+}
+
+// ExpirationChanged returns true if the expiration time of the key changed.
+func (diff *KeyDiff) ExpirationChanged() bool {
+	return diff.OldExpirationTime != diff.NewExpirationTime
+}
+
+// Diff compares the keyring with other, usually a refreshed version of the same keys,
+// and reports the added and removed keys, and the changes of the keys present in both keyrings.
+// Keys are matched by fingerprint, and revocations are evaluated at the current time.
+func (keyRing *KeyRing) Diff(other *KeyRing) *KeyRingDiff {
+	diff := &KeyRingDiff{}
+	now := getNow()
+
+	for _, newEntity := range other.entities {
+		oldEntity := findEntity(keyRing.entities, newEntity)
+		if oldEntity == nil {
+			diff.AddedKeys = append(diff.AddedKeys, &Key{entity: newEntity})
+			continue
+		}
+		if keyDiff := diffEntity(oldEntity, newEntity, now); keyDiff != nil {
+			diff.ChangedKeys = append(diff.ChangedKeys, keyDiff)
+		}
+	}
+
+	for _, oldEntity := range keyRing.entities {
+		if findEntity(other.entities, oldEntity) == nil {
+			diff.RemovedKeys = append(diff.RemovedKeys, &Key{entity: oldEntity})
+		}
+	}
+
+	return diff
+}
+
+// diffEntity returns the changes between the two versions of the same key, or nil if there is none.
+func diffEntity(oldEntity, newEntity *openpgp.Entity, now time.Time) *KeyDiff {
+	keyDiff := &KeyDiff{
+		Key:               &Key{entity: newEntity},
+		Revoked:           newEntity.Revoked(now) && !oldEntity.Revoked(now),
+		OldExpirationTime: getEntityExpirationTime(oldEntity),
+		NewExpirationTime: getEntityExpirationTime(newEntity),
+	}
+
+	for _, newSubkey := range newEntity.Subkeys {
+		fingerprint := hex.EncodeToString(newSubkey.PublicKey.Fingerprint)
+		oldSubkey := findSubkey(oldEntity, newSubkey.PublicKey)
+		if oldSubkey == nil {
+			keyDiff.AddedSubkeys = append(keyDiff.AddedSubkeys, fingerprint)
+		} else if newSubkey.Revoked(now) && !oldSubkey.Revoked(now) {
+			keyDiff.RevokedSubkeys = append(keyDiff.RevokedSubkeys, fingerprint)
+		}
+	}
+
+	for name, newIdentity := range newEntity.Identities {
+		oldIdentity, ok := oldEntity.Identities[name]
+		if !ok {
+			keyDiff.AddedUserIDs = append(keyDiff.AddedUserIDs, name)
+			oldIdentity = &openpgp.Identity{}
+		} else if newIdentity.Revoked(now) && !oldIdentity.Revoked(now) {
+			keyDiff.RevokedUserIDs = append(keyDiff.RevokedUserIDs, name)
+		}
+
+		for _, sig := range newIdentity.Signatures {
+			if !isThirdPartyCertification(newEntity, sig) || hasSignature(oldIdentity.Signatures, sig) {
+				continue
+			}
+			keyDiff.AddedCertifications = append(keyDiff.AddedCertifications, newCertification(name, sig))
+		}
+	}
+
+	if len(keyDiff.AddedSubkeys) == 0 && len(keyDiff.AddedUserIDs) == 0 &&
+		len(keyDiff.AddedCertifications) == 0 && !keyDiff.Revoked &&
+		len(keyDiff.RevokedSubkeys) == 0 && len(keyDiff.RevokedUserIDs) == 0 &&
+		!keyDiff.ExpirationChanged() {
+		return nil
+	}
+	return keyDiff
+}
+
+func findEntity(entities openpgp.EntityList, entity *openpgp.Entity) *openpgp.Entity {
+	for _, candidate := range entities {
+		if candidate.PrimaryKey.KeyId == entity.PrimaryKey.KeyId &&
+			bytes.Equal(candidate.PrimaryKey.Fingerprint, entity.PrimaryKey.Fingerprint) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func findSubkey(entity *openpgp.Entity, publicKey *packet.PublicKey) *openpgp.Subkey {
+	for i := range entity.Subkeys {
+		if entity.Subkeys[i].PublicKey.KeyId == publicKey.KeyId &&
+			bytes.Equal(entity.Subkeys[i].PublicKey.Fingerprint, publicKey.Fingerprint) {
+			return &entity.Subkeys[i]
+		}
+	}
+	return nil
+}
+
+// isThirdPartyCertification returns true if sig certifies a user ID of the entity,
+// and was issued by another key.
+func isThirdPartyCertification(entity *openpgp.Entity, sig *packet.Signature) bool {
+	return sig.SigType != packet.SigTypeCertificationRevocation &&
+		sig.IssuerKeyId != nil && !sig.CheckKeyIdOrFingerprint(entity.PrimaryKey)
+}
+
+func hasSignature(signatures []*packet.Signature, sig *packet.Signature) bool {
+	for _, candidate := range signatures {
+		if candidate.SigType == sig.SigType && candidate.CreationTime.Equal(sig.CreationTime) &&
+			candidate.IssuerKeyId != nil && *candidate.IssuerKeyId == *sig.IssuerKeyId {
+			return true
+		}
+	}
+	return false
+}
+
+// getEntityExpirationTime returns the expiration time of the entity as unix timestamp,
+// or 0 if it does not expire.
+func getEntityExpirationTime(entity *openpgp.Entity) int64 {
+	identity := entity.PrimaryIdentity()
+	if identity == nil || identity.SelfSignature == nil ||
+		identity.SelfSignature.KeyLifetimeSecs == nil || *identity.SelfSignature.KeyLifetimeSecs == 0 {
+		return 0
+	}
+	lifetime := time.Duration(*identity.SelfSignature.KeyLifetimeSecs) * time.Second
+	return entity.PrimaryKey.CreationTime.Add(lifetime).Unix()
+}
@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyRingDiff(t *testing.T) {
+	changedKey, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	removedKey, err := GenerateKey("removed", "removed@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	addedKey, err := GenerateKey("added", "added@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+
+	oldKeyRing, err := NewKeyRing(changedKey)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	if err = oldKeyRing.AddKey(removedKey); err != nil {
+		t.Fatal("Expected no error while adding key, got:", err)
+	}
+
+	assert.True(t, oldKeyRing.Diff(oldKeyRing).IsEmpty())
+
+	updatedKey, err := changedKey.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	config := &packet.Config{Time: getTimeGenerator(), Rand: getRandomSource()}
+	entity := updatedKey.entity
+	revokedSubkey := entity.Subkeys[0]
+	if err = entity.RevokeSubkey(&entity.Subkeys[0], packet.KeySuperseded, "", config); err != nil {
+		t.Fatal("Expected no error while revoking subkey, got:", err)
+	}
+	if err = entity.AddEncryptionSubkey(config); err != nil {
+		t.Fatal("Expected no error while adding subkey, got:", err)
+	}
+	identity := entity.PrimaryIdentity()
+	if err = entity.SignIdentity(identity.Name, removedKey.entity, config); err != nil {
+		t.Fatal("Expected no error while certifying identity, got:", err)
+	}
+	lifetime := uint32(86400)
+	identity.SelfSignature.KeyLifetimeSecs = &lifetime
+	if err = identity.SelfSignature.SignUserId(identity.Name, entity.PrimaryKey, entity.PrivateKey, config); err != nil {
+		t.Fatal("Expected no error while signing identity, got:", err)
+	}
+
+	// Parse the updated key again, as after a refresh
+	updatedKey, err = updatedKey.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	newKeyRing, err := NewKeyRing(updatedKey)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	if err = newKeyRing.AddKey(addedKey); err != nil {
+		t.Fatal("Expected no error while adding key, got:", err)
+	}
+
+	diff := oldKeyRing.Diff(newKeyRing)
+	assert.False(t, diff.IsEmpty())
+
+	assert.Len(t, diff.AddedKeys, 1)
+	assert.Equal(t, addedKey.GetFingerprint(), diff.AddedKeys[0].GetFingerprint())
+	assert.Len(t, diff.RemovedKeys, 1)
+	assert.Equal(t, removedKey.GetFingerprint(), diff.RemovedKeys[0].GetFingerprint())
+
+	assert.Len(t, diff.ChangedKeys, 1)
+	keyDiff := diff.ChangedKeys[0]
+	assert.Equal(t, changedKey.GetFingerprint(), keyDiff.Key.GetFingerprint())
+	assert.Equal(t, []string{hex.EncodeToString(updatedKey.entity.Subkeys[1].PublicKey.Fingerprint)}, keyDiff.AddedSubkeys)
+	assert.Equal(t, []string{hex.EncodeToString(revokedSubkey.PublicKey.Fingerprint)}, keyDiff.RevokedSubkeys)
+	assert.Empty(t, keyDiff.AddedUserIDs)
+	assert.Empty(t, keyDiff.RevokedUserIDs)
+	assert.False(t, keyDiff.Revoked)
+
+	assert.Len(t, keyDiff.AddedCertifications, 1)
+	assert.Equal(t, identity.Name, keyDiff.AddedCertifications[0].UserID)
+	assert.Equal(t, removedKey.GetHexKeyID(), keyDiff.AddedCertifications[0].IssuerKeyID)
+
+	assert.True(t, keyDiff.ExpirationChanged())
+	assert.Equal(t, int64(0), keyDiff.OldExpirationTime)
+	assert.Equal(t, entity.PrimaryKey.CreationTime.Unix()+86400, keyDiff.NewExpirationTime)
+}
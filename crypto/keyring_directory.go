@@ -0,0 +1,99 @@
+package crypto
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/pkg/errors"
+)
+
+// keyFileExtensions are the extensions of the files imported by NewKeyRingFromDirectory.
+var keyFileExtensions = []string{".asc", ".pgp", ".gpg"}
+
+// KeyFileError reports a key file that couldn't be imported by NewKeyRingFromDirectory.
+type KeyFileError struct {
+	Path string
+	Err  error
+}
+
+func (e *KeyFileError) Error() string {
+	return "gopenpgp: unable to import keys from " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *KeyFileError) Unwrap() error {
+	return e.Err
+}
+
+// NewKeyRingFromDirectory walks the directory at dirPath and its subdirectories,
+// and imports the keys of every .asc, .pgp and .gpg file, armored or not, in a single KeyRing.
+// A file can contain multiple keys. Files that can't be imported are skipped, and reported
+// in fileErrors, while err is only set if the directory itself can't be walked.
+func NewKeyRingFromDirectory(dirPath string) (keyRing *KeyRing, fileErrors []*KeyFileError, err error) {
+	keyRing = &KeyRing{}
+	err = filepath.Walk(dirPath, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			if path == dirPath {
+				return walkErr
+			}
+			fileErrors = append(fileErrors, &KeyFileError{Path: path, Err: walkErr})
+			return nil
+		}
+		if info.IsDir() || !isKeyFile(path) {
+			return nil
+		}
+		if fileErr := keyRing.importKeyFile(path); fileErr != nil {
+			fileErrors = append(fileErrors, &KeyFileError{Path: path, Err: fileErr})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "gopenpgp: unable to read key directory")
+	}
+	return keyRing, fileErrors, nil
+}
+
+// importKeyFile adds all the keys of the file to the keyring, or none of them if any is invalid.
+func (keyRing *KeyRing) importKeyFile(path string) error {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	var entities openpgp.EntityList
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+		entities, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	} else {
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return err
+	}
+
+	keys := make([]*Key, len(entities))
+	for i, entity := range entities {
+		keys[i] = &Key{entity: entity}
+		if keys[i].IsPrivate() {
+			if unlocked, err := keys[i].IsUnlocked(); err != nil || !unlocked {
+				return errors.New("gopenpgp: unable to add locked key to a keyring")
+			}
+		}
+	}
+	for _, key := range keys {
+		keyRing.appendKey(key)
+	}
+	return nil
+}
+
+func isKeyFile(path string) bool {
+	extension := strings.ToLower(filepath.Ext(path))
+	for _, keyFileExtension := range keyFileExtensions {
+		if extension == keyFileExtension {
+			return true
+		}
+	}
+	return false
+}
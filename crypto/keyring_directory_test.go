@@ -0,0 +1,64 @@
+package crypto
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKeyRingFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	armoredKeyRing, err := keyRingTestMultiple.Armor()
+	if err != nil {
+		t.Fatal("Expected no error while armoring keyring, got:", err)
+	}
+	binaryKey, err := keyTestRSA.GetPublicKey()
+	if err != nil {
+		t.Fatal("Expected no error while serializing key, got:", err)
+	}
+	lockedKey, err := keyTestEC.Lock(keyTestPassphrase)
+	if err != nil {
+		t.Fatal("Expected no error while locking key, got:", err)
+	}
+	armoredLockedKey, err := lockedKey.Armor()
+	if err != nil {
+		t.Fatal("Expected no error while armoring key, got:", err)
+	}
+
+	if err = os.Mkdir(filepath.Join(dir, "nested"), 0700); err != nil {
+		t.Fatal("Expected no error while creating directory, got:", err)
+	}
+	files := map[string][]byte{
+		"multiple.asc":        []byte(armoredKeyRing),
+		"nested/binary.PGP":   binaryKey,
+		"broken.asc":          []byte("not a key"),
+		"locked.asc":          []byte(armoredLockedKey),
+		"ignored.txt":         []byte("not a key"),
+		"nested/ignored.json": []byte("{}"),
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			t.Fatal("Expected no error while writing file, got:", err)
+		}
+	}
+
+	keyRing, fileErrors, err := NewKeyRingFromDirectory(dir)
+	if err != nil {
+		t.Fatal("Expected no error while loading directory, got:", err)
+	}
+	assert.Equal(t, 4, keyRing.CountEntities())
+	assert.Equal(t, keyRingTestMultiple.entities[0].PrimaryKey.Fingerprint, keyRing.entities[0].PrimaryKey.Fingerprint)
+	assert.Equal(t, keyTestRSA.GetFingerprint(), keyRing.GetKeys()[3].GetFingerprint())
+
+	assert.Len(t, fileErrors, 2)
+	assert.Equal(t, filepath.Join(dir, "broken.asc"), fileErrors[0].Path)
+	assert.Equal(t, filepath.Join(dir, "locked.asc"), fileErrors[1].Path)
+	assert.Error(t, fileErrors[0])
+
+	_, _, err = NewKeyRingFromDirectory(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// KeyExpiration describes a primary key or a subkey expiring soon, as returned by KeyRing.GetExpiringKeys.
+type KeyExpiration struct {
+	// Key is the key the expiring component belongs to.
+	Key *Key
+	// Fingerprint is the hex fingerprint of the expiring primary key or subkey.
+	Fingerprint string
+	// IsSubkey is true if the expiring component is a subkey.
+	IsSubkey bool
+	// ExpirationTime is the expiration time of the component as unix timestamp.
+	ExpirationTime int64
+}
+
+// GetExpiringKeys returns the primary keys and subkeys of the keyring expiring
+// between unixTime and unixTime + window seconds, sorted by expiration time,
+// to remind users to extend or rotate them. Revoked keys and subkeys are ignored,
+// as well as the subkeys of expiring primary keys.
+func (keyRing *KeyRing) GetExpiringKeys(unixTime, window int64) []*KeyExpiration {
+	now := time.Unix(unixTime, 0)
+	var expirations []*KeyExpiration
+
+	for _, entity := range keyRing.entities {
+		if entity.Revoked(now) {
+			continue
+		}
+		key := &Key{entity: entity}
+
+		if expirationTime := getEntityExpirationTime(entity); isExpiringIn(expirationTime, unixTime, window) {
+			expirations = append(expirations, &KeyExpiration{
+				Key:            key,
+				Fingerprint:    key.GetFingerprint(),
+				ExpirationTime: expirationTime,
+			})
+			continue
+		}
+
+		for _, subkey := range entity.Subkeys {
+			if subkey.Revoked(now) {
+				continue
+			}
+			if expirationTime := getSubkeyExpirationTime(subkey); isExpiringIn(expirationTime, unixTime, window) {
+				expirations = append(expirations, &KeyExpiration{
+					Key:            key,
+					Fingerprint:    hex.EncodeToString(subkey.PublicKey.Fingerprint),
+					IsSubkey:       true,
+					ExpirationTime: expirationTime,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(expirations, func(i, j int) bool {
+		return expirations[i].ExpirationTime < expirations[j].ExpirationTime
+	})
+	return expirations
+}
+
+func isExpiringIn(expirationTime, unixTime, window int64) bool {
+	return expirationTime != 0 && expirationTime >= unixTime && expirationTime <= unixTime+window
+}
+
+// getSubkeyExpirationTime returns the expiration time of the subkey as unix timestamp,
+// or 0 if it does not expire.
+func getSubkeyExpirationTime(subkey openpgp.Subkey) int64 {
+	if subkey.Sig == nil || subkey.Sig.KeyLifetimeSecs == nil || *subkey.Sig.KeyLifetimeSecs == 0 {
+		return 0
+	}
+	lifetime := time.Duration(*subkey.Sig.KeyLifetimeSecs) * time.Second
+	return subkey.PublicKey.CreationTime.Add(lifetime).Unix()
+}
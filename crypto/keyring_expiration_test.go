@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExpiringKeys(t *testing.T) {
+	soonSpec := NewSubkeySpec("x25519", 0, false, true, false)
+	soonSpec.Lifetime = 3600
+	laterSpec := NewSubkeySpec("x25519", 0, true, false, false)
+	laterSpec.Lifetime = 86400
+	key, err := GenerateKeyWithSubkeys(keyTestName, keyTestDomain, "x25519", 0, laterSpec, soonSpec)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	keyRing, err := NewKeyRing(keyTestEC)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	if err = keyRing.AddKey(key); err != nil {
+		t.Fatal("Expected no error while adding key, got:", err)
+	}
+
+	creationTime := key.entity.PrimaryKey.CreationTime.Unix()
+	now := GetUnixTime()
+
+	assert.Empty(t, keyRing.GetExpiringKeys(now, 60))
+
+	expirations := keyRing.GetExpiringKeys(now, 2*86400)
+	assert.Len(t, expirations, 2)
+	assert.Equal(t, key.GetFingerprint(), expirations[0].Key.GetFingerprint())
+	assert.True(t, expirations[0].IsSubkey)
+	assert.Equal(t, hex.EncodeToString(key.entity.Subkeys[1].PublicKey.Fingerprint), expirations[0].Fingerprint)
+	assert.Equal(t, creationTime+3600, expirations[0].ExpirationTime)
+	assert.Equal(t, hex.EncodeToString(key.entity.Subkeys[0].PublicKey.Fingerprint), expirations[1].Fingerprint)
+	assert.Equal(t, creationTime+86400, expirations[1].ExpirationTime)
+
+	// Already expired subkeys are not reported
+	expirations = keyRing.GetExpiringKeys(now+7200, 86400)
+	assert.Len(t, expirations, 1)
+	assert.Equal(t, creationTime+86400, expirations[0].ExpirationTime)
+
+	// The subkeys of an expiring primary key are not reported
+	lifetime := uint32(600)
+	key.entity.PrimaryIdentity().SelfSignature.KeyLifetimeSecs = &lifetime
+	expirations = keyRing.GetExpiringKeys(now, 2*86400)
+	assert.Len(t, expirations, 1)
+	assert.False(t, expirations[0].IsSubkey)
+	assert.Equal(t, key.GetFingerprint(), expirations[0].Fingerprint)
+	assert.Equal(t, creationTime+600, expirations[0].ExpirationTime)
+}
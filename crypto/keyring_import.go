@@ -0,0 +1,122 @@
+package crypto
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/pkg/errors"
+)
+
+// KeyImportError reports a key that couldn't be imported by NewKeyRingFromArmoredWithErrors
+// or NewKeyRingFromBinaryWithErrors.
+type KeyImportError struct {
+	// Index of the key in the imported data.
+	Index int
+	// Offset of the key in the binary data.
+	Offset int
+	// KeyID of the key, in hexadecimal, empty if the primary key packet can't be parsed.
+	KeyID string
+	Err   error
+}
+
+func (e *KeyImportError) Error() string {
+	description := "gopenpgp: unable to import key " + strconv.Itoa(e.Index)
+	if e.KeyID != "" {
+		description += " (" + e.KeyID + ")"
+	}
+	return description + ": " + e.Err.Error()
+}
+
+func (e *KeyImportError) Unwrap() error {
+	return e.Err
+}
+
+// NewKeyRingFromArmoredWithErrors imports the keys of an armored keyring, e.g. an exported
+// keyring with many keys, as NewKeyRingFromBinaryWithErrors.
+func NewKeyRingFromArmoredWithErrors(armored string) (keyRing *KeyRing, keyErrors []*KeyImportError, err error) {
+	data, err := armor.Unarmor(armored)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "gopenpgp: unable to unarmor the keyring")
+	}
+	return NewKeyRingFromBinaryWithErrors(data)
+}
+
+// NewKeyRingFromBinaryWithErrors imports the keys of a binary keyring one by one:
+// the keys that can't be imported, e.g. with an unsupported algorithm, corrupted packets,
+// or locked private keys, are skipped and reported in keyErrors, and the others are imported.
+// err is only set if no key could be imported.
+func NewKeyRingFromBinaryWithErrors(data []byte) (keyRing *KeyRing, keyErrors []*KeyImportError, err error) {
+	keyRing = &KeyRing{}
+	for index, offset := 0, 0; offset < len(data); index++ {
+		length, keyErr := nextKeyLength(data[offset:])
+		if keyErr == nil {
+			keyErr = keyRing.importKey(data[offset : offset+length])
+		}
+		if keyErr != nil {
+			keyErrors = append(keyErrors, &KeyImportError{
+				Index:  index,
+				Offset: offset,
+				KeyID:  readKeyID(data[offset:]),
+				Err:    keyErr,
+			})
+		}
+		if length == 0 {
+			// The packets can't be delimited further
+			break
+		}
+		offset += length
+	}
+	if keyRing.CountEntities() == 0 {
+		return nil, keyErrors, errors.New("gopenpgp: no key could be imported")
+	}
+	return keyRing, keyErrors, nil
+}
+
+// nextKeyLength returns the length of the packets of the first key of the data,
+// up to the next primary key packet, or 0 and an error if the packets are malformed.
+func nextKeyLength(data []byte) (int, error) {
+	offset := 0
+	for offset < len(data) {
+		tag, length, expected, found := readPacketLayout(data[offset:])
+		if expected != "" {
+			return 0, errors.New("gopenpgp: malformed packet at offset " + strconv.Itoa(offset) +
+				": expected " + expected + ", found " + found)
+		}
+		if offset == 0 && tag != packetTagPublicKey && tag != packetTagSecretKey {
+			return 0, errors.New("gopenpgp: the key does not start with a primary key packet")
+		}
+		if offset > 0 && (tag == packetTagPublicKey || tag == packetTagSecretKey) {
+			break
+		}
+		offset += length
+	}
+	return offset, nil
+}
+
+// importKey adds the key serialized in data to the keyring.
+func (keyRing *KeyRing) importKey(data []byte) error {
+	entity, err := openpgp.ReadEntity(packet.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to read the key")
+	}
+	return keyRing.AddKey(&Key{entity: entity})
+}
+
+// readKeyID returns the hexadecimal key ID of the primary key packet at the start of the data,
+// or an empty string if it can't be parsed.
+func readKeyID(data []byte) string {
+	p, err := packet.Read(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	switch key := p.(type) {
+	case *packet.PublicKey:
+		return keyIDToHex(key.KeyId)
+	case *packet.PrivateKey:
+		return keyIDToHex(key.KeyId)
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKeyRingWithErrors(t *testing.T) {
+	var keys []*Key
+	for i := 0; i < 4; i++ {
+		key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+		if err != nil {
+			t.Fatal("Expected no error while generating key, got:", err)
+		}
+		keys = append(keys, key)
+	}
+
+	var data []byte
+	publicKey, err := keys[0].GetPublicKey()
+	if err != nil {
+		t.Fatal("Expected no error while serializing the public key, got:", err)
+	}
+	data = append(data, publicKey...)
+
+	unsupportedKey, err := keys[1].GetPublicKey()
+	if err != nil {
+		t.Fatal("Expected no error while serializing the public key, got:", err)
+	}
+	// New format public key packet with a one byte length: version, creation time and algorithm
+	assert.Exactly(t, byte(0xc6), unsupportedKey[0])
+	assert.Less(t, unsupportedKey[1], byte(192))
+	unsupportedKey[2+1+4] = 99
+	data = append(data, unsupportedKey...)
+
+	privateKey, err := keys[2].Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing the private key, got:", err)
+	}
+	data = append(data, privateKey...)
+
+	lockedKey, err := keys[3].Lock([]byte("passphrase"))
+	if err != nil {
+		t.Fatal("Expected no error while locking the key, got:", err)
+	}
+	serializedLockedKey, err := lockedKey.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing the locked key, got:", err)
+	}
+	data = append(data, serializedLockedKey...)
+
+	armored, err := armor.ArmorWithType(data, constants.PublicKeyHeader)
+	if err != nil {
+		t.Fatal("Expected no error while armoring the keyring, got:", err)
+	}
+	keyRing, keyErrors, err := NewKeyRingFromArmoredWithErrors(armored)
+	if err != nil {
+		t.Fatal("Expected no error while importing the keyring, got:", err)
+	}
+	assert.Exactly(t, 2, keyRing.CountEntities())
+	assert.Exactly(t, keys[0].GetFingerprint(), keyRing.GetKeys()[0].GetFingerprint())
+	assert.Exactly(t, keys[2].GetFingerprint(), keyRing.GetKeys()[1].GetFingerprint())
+
+	assert.Len(t, keyErrors, 2)
+	assert.Exactly(t, 1, keyErrors[0].Index)
+	assert.Exactly(t, len(publicKey), keyErrors[0].Offset)
+	assert.Exactly(t, 3, keyErrors[1].Index)
+	assert.Exactly(t, keys[3].GetHexKeyID(), keyErrors[1].KeyID)
+
+	// Truncated data
+	keyRing, keyErrors, err = NewKeyRingFromBinaryWithErrors(data[:len(data)-10])
+	if err != nil {
+		t.Fatal("Expected no error while importing the keyring, got:", err)
+	}
+	assert.Exactly(t, 2, keyRing.CountEntities())
+	assert.Len(t, keyErrors, 2)
+
+	_, keyErrors, err = NewKeyRingFromBinaryWithErrors(unsupportedKey)
+	assert.Error(t, err)
+	assert.Len(t, keyErrors, 1)
+}
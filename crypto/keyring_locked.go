@@ -0,0 +1,153 @@
+package crypto
+
+import (
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+// PassphraseProvider provides the passphrases of locked keys on demand.
+type PassphraseProvider interface {
+	// GetPassphrase returns the passphrase to unlock the given private key.
+	GetPassphrase(key *Key) ([]byte, error)
+}
+
+// LockedKeyRing contains locked private keys, which are only unlocked
+// when needed to decrypt a message.
+type LockedKeyRing struct {
+	keys []*Key
+}
+
+// NewLockedKeyRing creates a new LockedKeyRing, empty if key is nil.
+func NewLockedKeyRing(key *Key) (*LockedKeyRing, error) {
+	keyRing := &LockedKeyRing{}
+	var err error
+	if key != nil {
+		err = keyRing.AddKey(key)
+	}
+	return keyRing, err
+}
+
+// AddKey adds the given private key to the LockedKeyRing.
+func (keyRing *LockedKeyRing) AddKey(key *Key) error {
+	if !key.IsPrivate() {
+		return errors.New("gopenpgp: unable to add a public key to a locked keyring")
+	}
+	keyRing.keys = append(keyRing.keys, key)
+	return nil
+}
+
+// CountEntities returns the number of keys in the LockedKeyRing.
+func (keyRing *LockedKeyRing) CountEntities() int {
+	return len(keyRing.keys)
+}
+
+// DecryptWithPolicy decrypts encrypted string using the locked keys, returning a PlainMessage.
+// Only the key matching a key packet of the message is unlocked, with the passphrase
+// returned by the provider, and the unlocked copy is cleared once the session key is decrypted.
+// The decryption is restricted by the given policy, as in KeyRing.DecryptWithPolicy.
+func (keyRing *LockedKeyRing) DecryptWithPolicy(
+	message *PGPMessage,
+	passphrases PassphraseProvider,
+	verifyKey *KeyRing,
+	verifyTime int64,
+	policy *DecryptionPolicy,
+) (*PlainMessage, error) {
+	if policy == nil {
+		policy = NewDecryptionPolicy()
+	}
+	return decryptWithPolicy(message, keyRing.decrypter(passphrases, policy), verifyKey, verifyTime, policy)
+}
+
+// DecryptStreamWithPolicy is used to decrypt a pgp message as a Reader, using the locked keys.
+// Only the key matching a key packet of the message is unlocked, with the passphrase
+// returned by the provider, and the unlocked copy is cleared once the session key is decrypted.
+// The decryption is restricted by the given policy, as in KeyRing.DecryptStreamWithPolicy.
+func (keyRing *LockedKeyRing) DecryptStreamWithPolicy(
+	message Reader,
+	passphrases PassphraseProvider,
+	verifyKeyRing *KeyRing,
+	verifyTime int64,
+	policy *DecryptionPolicy,
+) (*PlainMessageReader, error) {
+	if policy == nil {
+		policy = NewDecryptionPolicy()
+	}
+	return decryptStreamReaderWithPolicy(message, keyRing.decrypter(passphrases, policy), verifyKeyRing, verifyTime, policy)
+}
+
+func (keyRing *LockedKeyRing) decrypter(passphrases PassphraseProvider, policy *DecryptionPolicy) keyPacketDecrypter {
+	return func(keyPackets []*packet.EncryptedKey, _ *DecryptionDetails) (*packet.EncryptedKey, *openpgp.Key, error) {
+		var lastErr error
+		for _, ek := range keyPackets {
+			for _, key := range keyRing.keys {
+				if !key.hasDecryptionKeyID(ek.KeyId) {
+					continue
+				}
+				decryptedEK, decryptionKey, err := unlockAndDecryptKeyPacket(key, ek, passphrases, policy)
+				if err == nil {
+					return decryptedEK, decryptionKey, nil
+				}
+				lastErr = err
+			}
+		}
+		if lastErr != nil {
+			return nil, nil, lastErr
+		}
+		return nil, nil, ErrNoDecryptionKey
+	}
+}
+
+// unlockAndDecryptKeyPacket unlocks a copy of the key, decrypts the key packet with it,
+// and clears the unlocked copy.
+func unlockAndDecryptKeyPacket(
+	key *Key, ek *packet.EncryptedKey, passphrases PassphraseProvider, policy *DecryptionPolicy,
+) (*packet.EncryptedKey, *openpgp.Key, error) {
+	locked, err := key.IsLocked()
+	if err != nil {
+		return nil, nil, err
+	}
+	var passphrase []byte
+	if locked {
+		if passphrase, err = passphrases.GetPassphrase(key); err != nil {
+			return nil, nil, errors.Wrap(err, "gopenpgp: unable to get the key passphrase")
+		}
+	}
+	unlockedKey, err := key.Unlock(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlockedKey.ClearPrivateParams()
+
+	unlockedKeyRing, err := NewKeyRing(unlockedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	decryptedEK, unlockedDecryptionKey, err := policy.decryptKeyPackets([]*packet.EncryptedKey{ek}, unlockedKeyRing)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Report the locked key, as the unlocked copy is cleared
+	for _, decryptionKey := range key.decryptionKeys() {
+		if decryptionKey.PublicKey.KeyId == unlockedDecryptionKey.PublicKey.KeyId {
+			decryptionKey := decryptionKey
+			return decryptedEK, &decryptionKey, nil
+		}
+	}
+	return nil, nil, errors.New("gopenpgp: unable to find the decryption key")
+}
+
+func (key *Key) decryptionKeys() []openpgp.Key {
+	return openpgp.EntityList{key.entity}.DecryptionKeys()
+}
+
+// hasDecryptionKeyID returns true if the key has a decryption key with the given ID,
+// or any decryption key if the ID is 0.
+func (key *Key) hasDecryptionKeyID(id uint64) bool {
+	for _, decryptionKey := range key.decryptionKeys() {
+		if id == 0 || decryptionKey.PublicKey.KeyId == id {
+			return true
+		}
+	}
+	return false
+}
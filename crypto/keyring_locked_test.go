@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPassphraseProvider struct {
+	passphrases map[string][]byte
+	requested   []string
+}
+
+func (provider *testPassphraseProvider) GetPassphrase(key *Key) ([]byte, error) {
+	fingerprint := key.GetFingerprint()
+	provider.requested = append(provider.requested, fingerprint)
+	passphrase, ok := provider.passphrases[fingerprint]
+	if !ok {
+		return nil, errors.New("no passphrase")
+	}
+	return passphrase, nil
+}
+
+func TestLockedKeyRingDecryptWithPolicy(t *testing.T) {
+	provider := &testPassphraseProvider{passphrases: map[string][]byte{}}
+	lockedKeyRing, err := NewLockedKeyRing(nil)
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	var lockedKeys []*Key
+	for _, passphrase := range []string{"first", "second"} {
+		key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+		if err != nil {
+			t.Fatal("Expected no error while generating the key, got:", err)
+		}
+		lockedKey, err := key.Lock([]byte(passphrase))
+		if err != nil {
+			t.Fatal("Expected no error while locking the key, got:", err)
+		}
+		provider.passphrases[key.GetFingerprint()] = []byte(passphrase)
+		if err = lockedKeyRing.AddKey(lockedKey); err != nil {
+			t.Fatal("Expected no error while adding the key, got:", err)
+		}
+		lockedKeys = append(lockedKeys, lockedKey)
+	}
+	assert.Exactly(t, 2, lockedKeyRing.CountEntities())
+
+	publicKey, err := lockedKeys[1].ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while getting the public key, got:", err)
+	}
+	publicKeyRing, err := NewKeyRing(publicKey)
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	message, err := publicKeyRing.Encrypt(NewPlainMessageFromString(testMessage), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	decrypted, err := lockedKeyRing.DecryptWithPolicy(message, provider, nil, 0, nil)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.Exactly(t, []string{lockedKeys[1].GetFingerprint()}, provider.requested)
+
+	for _, key := range lockedKeys {
+		locked, err := key.IsLocked()
+		if err != nil {
+			t.Fatal("Expected no error while checking the key, got:", err)
+		}
+		assert.True(t, locked)
+	}
+
+	delete(provider.passphrases, lockedKeys[1].GetFingerprint())
+	_, err = lockedKeyRing.DecryptWithPolicy(message, provider, nil, 0, nil)
+	assert.Error(t, err)
+
+	if err = lockedKeyRing.AddKey(publicKey); err == nil {
+		t.Fatal("Expected an error while adding a public key, got nil")
+	}
+}
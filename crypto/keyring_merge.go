@@ -0,0 +1,180 @@
+package crypto
+
+import (
+	"bytes"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+// Merge imports all the keys of other in the keyring, as MergeKey.
+func (keyRing *KeyRing) Merge(other *KeyRing) error {
+	for _, key := range other.GetKeys() {
+		if err := keyRing.MergeKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MergeKey imports the key in the keyring. If the keyring already contains a key
+// with the same fingerprint, the new user IDs, subkeys, revocations and certifications
+// are added to it, keeping its key material. New subkeys can only be merged into a private key
+// with their private key material. Byte-identical signatures are deduplicated,
+// and only the newest certification of each third-party issuer of the keyring that verifies
+// against the issuer key is kept for a user ID, so that keyrings synchronized from multiple sources
+// don't grow without bound. The certifications of issuers that aren't in the keyring are kept,
+// up to a limit.
+func (keyRing *KeyRing) MergeKey(key *Key) error {
+	existing := findEntity(keyRing.entities, key.entity)
+	if existing == nil {
+		return keyRing.AddKey(key)
+	}
+	if err := mergeEntity(keyRing.entities, existing, key.entity); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to merge key "+key.GetFingerprint())
+	}
+	return nil
+}
+
+func mergeEntity(entities openpgp.EntityList, dst, src *openpgp.Entity) (err error) {
+	if dst.PrivateKey != nil {
+		// A private key can't hold public subkeys, they couldn't be serialized
+		for _, srcSubkey := range src.Subkeys {
+			if srcSubkey.PrivateKey == nil && findSubkey(dst, srcSubkey.PublicKey) == nil {
+				return errors.New("gopenpgp: cannot merge a public subkey into a private key")
+			}
+		}
+	}
+
+	if dst.Revocations, err = deduplicateSignatures(append(dst.Revocations, src.Revocations...)); err != nil {
+		return err
+	}
+
+	for name, srcIdentity := range src.Identities {
+		dstIdentity, ok := dst.Identities[name]
+		if !ok {
+			dstIdentity = &openpgp.Identity{Name: srcIdentity.Name, UserId: srcIdentity.UserId}
+			dst.Identities[name] = dstIdentity
+		}
+		if dstIdentity.SelfSignature == nil || (srcIdentity.SelfSignature != nil &&
+			srcIdentity.SelfSignature.CreationTime.After(dstIdentity.SelfSignature.CreationTime)) {
+			dstIdentity.SelfSignature = srcIdentity.SelfSignature
+		}
+		if dstIdentity.Revocations, err = deduplicateSignatures(
+			append(dstIdentity.Revocations, srcIdentity.Revocations...),
+		); err != nil {
+			return err
+		}
+		if dstIdentity.Signatures, err = deduplicateSignatures(
+			append(dstIdentity.Signatures, srcIdentity.Signatures...),
+		); err != nil {
+			return err
+		}
+		dstIdentity.Signatures = keepNewestCertifications(entities, dst, name, dstIdentity.Signatures)
+	}
+
+	for _, srcSubkey := range src.Subkeys {
+		dstSubkey := findSubkey(dst, srcSubkey.PublicKey)
+		if dstSubkey == nil {
+			subkey := openpgp.Subkey{
+				PublicKey:   srcSubkey.PublicKey,
+				Sig:         srcSubkey.Sig,
+				Revocations: srcSubkey.Revocations,
+			}
+			if dst.PrivateKey != nil {
+				subkey.PrivateKey = srcSubkey.PrivateKey
+			}
+			dst.Subkeys = append(dst.Subkeys, subkey)
+			continue
+		}
+		if srcSubkey.Sig.CreationTime.After(dstSubkey.Sig.CreationTime) {
+			dstSubkey.Sig = srcSubkey.Sig
+		}
+		if dstSubkey.Revocations, err = deduplicateSignatures(
+			append(dstSubkey.Revocations, srcSubkey.Revocations...),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deduplicateSignatures removes the byte-identical signatures, keeping the order of the first occurrences.
+func deduplicateSignatures(signatures []*packet.Signature) ([]*packet.Signature, error) {
+	deduplicated := make([]*packet.Signature, 0, len(signatures))
+	seen := make(map[string]bool, len(signatures))
+	for _, sig := range signatures {
+		var buffer bytes.Buffer
+		if err := sig.Serialize(&buffer); err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: unable to serialize signature")
+		}
+		if seen[buffer.String()] {
+			continue
+		}
+		seen[buffer.String()] = true
+		deduplicated = append(deduplicated, sig)
+	}
+	return deduplicated, nil
+}
+
+// maxUnverifiedCertifications is the maximum number of certifications of a user ID
+// kept from issuers that are not in the keyring, as they can't be verified.
+const maxUnverifiedCertifications = 32
+
+// keepNewestCertifications only keeps the newest third-party certification of each issuer
+// of the keyring that verifies against the issuer key, and drops the ones that don't verify.
+// The certifications of issuers that aren't in the keyring are all kept,
+// up to maxUnverifiedCertifications. The self-signatures and the revocations are all kept.
+func keepNewestCertifications(
+	entities openpgp.EntityList, entity *openpgp.Entity, userID string, signatures []*packet.Signature,
+) []*packet.Signature {
+	newest := make(map[uint64]*packet.Signature)
+	forged := make(map[*packet.Signature]bool)
+	for _, sig := range signatures {
+		if !isThirdPartyCertification(entity, sig) {
+			continue
+		}
+		issuers := entities.KeysById(*sig.IssuerKeyId)
+		if len(issuers) == 0 {
+			continue
+		}
+		if !verifiesCertification(issuers, entity, userID, sig) {
+			forged[sig] = true
+			continue
+		}
+		if current, ok := newest[*sig.IssuerKeyId]; !ok || sig.CreationTime.After(current.CreationTime) {
+			newest[*sig.IssuerKeyId] = sig
+		}
+	}
+
+	kept := make([]*packet.Signature, 0, len(signatures))
+	unverified := 0
+	for _, sig := range signatures {
+		switch {
+		case !isThirdPartyCertification(entity, sig):
+			kept = append(kept, sig)
+		case forged[sig]:
+		case newest[*sig.IssuerKeyId] != nil:
+			if newest[*sig.IssuerKeyId] == sig {
+				kept = append(kept, sig)
+			}
+		case unverified < maxUnverifiedCertifications:
+			unverified++
+			kept = append(kept, sig)
+		}
+	}
+	return kept
+}
+
+// verifiesCertification checks whether one of the issuer keys made the certification of the user ID.
+func verifiesCertification(
+	issuers []openpgp.Key, entity *openpgp.Entity, userID string, sig *packet.Signature,
+) bool {
+	for _, issuer := range issuers {
+		if issuer.PublicKey.VerifyUserIdSignature(userID, entity.PrimaryKey, sig) == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func certifyKey(t *testing.T, key, signer *Key, signatureTime time.Time) {
+	config := &packet.Config{Time: func() time.Time { return signatureTime }, Rand: getRandomSource()}
+	if err := key.entity.SignIdentity(key.entity.PrimaryIdentity().Name, signer.entity, config); err != nil {
+		t.Fatal("Expected no error while certifying identity, got:", err)
+	}
+}
+
+func TestKeyRingMerge(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	firstSigner, err := GenerateKey("first", "first@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	secondSigner, err := GenerateKey("second", "second@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+
+	now := GetTime()
+	later := now.Add(time.Hour)
+	certifyKey(t, key, firstSigner, now)
+	certifyKey(t, key, firstSigner, later)
+
+	firstSource, err := key.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	secondSource, err := key.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	certifyKey(t, secondSource, secondSigner, later)
+	if err = secondSource.entity.AddEncryptionSubkey(&packet.Config{Time: getTimeGenerator(), Rand: getRandomSource()}); err != nil {
+		t.Fatal("Expected no error while adding subkey, got:", err)
+	}
+	secondSource, err = secondSource.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+
+	keyRing, err := NewKeyRing(firstSource)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	if err = keyRing.MergeKey(firstSigner); err != nil {
+		t.Fatal("Expected no error while merging key, got:", err)
+	}
+	if err = keyRing.MergeKey(secondSource); err != nil {
+		t.Fatal("Expected no error while merging key, got:", err)
+	}
+	assert.Equal(t, 2, keyRing.CountEntities())
+
+	merged := keyRing.entities[0]
+	assert.Len(t, merged.Subkeys, 2)
+	signatures := merged.PrimaryIdentity().Signatures
+	// The self-signature, and the newest certification of each signer
+	assert.Len(t, signatures, 3)
+	for _, sig := range signatures[1:] {
+		assert.Equal(t, later.Unix(), sig.CreationTime.Unix())
+	}
+
+	// Merging the same keys again doesn't change the keyring
+	serialized, err := keyRing.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing keyring, got:", err)
+	}
+	otherKeyRing, err := keyRing.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying keyring, got:", err)
+	}
+	if err = keyRing.Merge(otherKeyRing); err != nil {
+		t.Fatal("Expected no error while merging keyring, got:", err)
+	}
+	reserialized, err := keyRing.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing keyring, got:", err)
+	}
+	assert.Equal(t, serialized, reserialized)
+}
+
+func TestKeyRingMergeForgedCertification(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	signer, err := GenerateKey("signer", "signer@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	forger, err := GenerateKey("forger", "forger@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+
+	now := GetTime()
+	genuine, err := key.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	certifyKey(t, genuine, signer, now)
+	forged, err := key.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	certifyKey(t, forged, forger, now.Add(time.Hour))
+	forgedSignatures := forged.entity.PrimaryIdentity().Signatures
+	forgedSignatures[len(forgedSignatures)-1].IssuerKeyId = &signer.entity.PrimaryKey.KeyId
+
+	keyRing, err := NewKeyRing(signer)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	if err = keyRing.MergeKey(genuine); err != nil {
+		t.Fatal("Expected no error while merging key, got:", err)
+	}
+	if err = keyRing.MergeKey(forged); err != nil {
+		t.Fatal("Expected no error while merging key, got:", err)
+	}
+
+	signatures := keyRing.entities[1].PrimaryIdentity().Signatures
+	// The self-signature, and the genuine certification
+	assert.Len(t, signatures, 2)
+	assert.Equal(t, now.Unix(), signatures[1].CreationTime.Unix())
+}
+
+func TestKeyRingMergeUnverifiedCertifications(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	signer, err := GenerateKey("signer", "signer@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	now := GetTime()
+	for i := 0; i < maxUnverifiedCertifications+1; i++ {
+		certifyKey(t, key, signer, now.Add(time.Duration(i)*time.Second))
+	}
+	source, err := key.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+	keyRing, err := NewKeyRing(source)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	if err = keyRing.MergeKey(source); err != nil {
+		t.Fatal("Expected no error while merging key, got:", err)
+	}
+
+	// The self-signature, and the certifications up to the limit, as the signer isn't in the keyring
+	assert.Len(t, keyRing.entities[0].PrimaryIdentity().Signatures, maxUnverifiedCertifications+1)
+}
+
+func TestKeyRingMergePublicSubkeyIntoPrivateKey(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	updated, err := key.AddSubkey(NewSubkeySpec("x25519", 0, false, true, false))
+	if err != nil {
+		t.Fatal("Expected no error while adding subkey, got:", err)
+	}
+	updatedPublic, err := updated.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while copying key, got:", err)
+	}
+
+	keyRing, err := NewKeyRing(key)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	assert.Error(t, keyRing.MergeKey(updatedPublic))
+	assert.Len(t, keyRing.GetKeys()[0].entity.Subkeys, 1)
+
+	if err = keyRing.MergeKey(updated); err != nil {
+		t.Fatal("Expected no error while merging key, got:", err)
+	}
+	merged := keyRing.GetKeys()[0]
+	assert.Len(t, merged.entity.Subkeys, 2)
+	if _, err = merged.Armor(); err != nil {
+		t.Fatal("Expected no error while armoring merged key, got:", err)
+	}
+
+	publicKeyRing, err := NewKeyRing(updatedPublic)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	if err = publicKeyRing.MergeKey(updated); err != nil {
+		t.Fatal("Expected no error while merging key, got:", err)
+	}
+	assert.False(t, publicKeyRing.GetKeys()[0].IsPrivate())
+	if _, err = publicKeyRing.GetKeys()[0].Armor(); err != nil {
+		t.Fatal("Expected no error while armoring merged key, got:", err)
+	}
+}
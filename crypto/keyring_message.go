@@ -3,7 +3,6 @@ package crypto
 import (
 	"bytes"
 	"io"
-	"io/ioutil"
 	"time"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
@@ -17,7 +16,7 @@ import (
 // * message    : The plaintext input as a PlainMessage.
 // * privateKey : (optional) an unlocked private keyring to include signature in the message.
 func (keyRing *KeyRing) Encrypt(message *PlainMessage, privateKey *KeyRing) (*PGPMessage, error) {
-	return asymmetricEncrypt(message, keyRing, privateKey, false, nil)
+	return asymmetricEncrypt(message, keyRing, privateKey, nil, nil)
 }
 
 // EncryptWithContext encrypts a PlainMessage, outputs a PGPMessage.
@@ -26,7 +25,7 @@ func (keyRing *KeyRing) Encrypt(message *PlainMessage, privateKey *KeyRing) (*PG
 // * privateKey : (optional) an unlocked private keyring to include signature in the message.
 // * signingContext : (optional) the context for the signature.
 func (keyRing *KeyRing) EncryptWithContext(message *PlainMessage, privateKey *KeyRing, signingContext *SigningContext) (*PGPMessage, error) {
-	return asymmetricEncrypt(message, keyRing, privateKey, false, signingContext)
+	return asymmetricEncrypt(message, keyRing, privateKey, nil, signingContext)
 }
 
 // EncryptWithCompression encrypts with compression support a PlainMessage to PGPMessage using public/private keys.
@@ -34,7 +33,7 @@ func (keyRing *KeyRing) EncryptWithContext(message *PlainMessage, privateKey *Ke
 // * privateKey : (optional) an unlocked private keyring to include signature in the message.
 // * output  : The encrypted data as PGPMessage.
 func (keyRing *KeyRing) EncryptWithCompression(message *PlainMessage, privateKey *KeyRing) (*PGPMessage, error) {
-	return asymmetricEncrypt(message, keyRing, privateKey, true, nil)
+	return asymmetricEncrypt(message, keyRing, privateKey, NewEncryptionOptions().WithCompression(), nil)
 }
 
 // EncryptWithContextAndCompression encrypts with compression support a PlainMessage to PGPMessage using public/private keys.
@@ -43,7 +42,7 @@ func (keyRing *KeyRing) EncryptWithCompression(message *PlainMessage, privateKey
 // * signingContext : (optional) the context for the signature.
 // * output  : The encrypted data as PGPMessage.
 func (keyRing *KeyRing) EncryptWithContextAndCompression(message *PlainMessage, privateKey *KeyRing, signingContext *SigningContext) (*PGPMessage, error) {
-	return asymmetricEncrypt(message, keyRing, privateKey, true, signingContext)
+	return asymmetricEncrypt(message, keyRing, privateKey, NewEncryptionOptions().WithCompression(), signingContext)
 }
 
 // Decrypt decrypts encrypted string using pgp keys, returning a PlainMessage
@@ -199,7 +198,7 @@ func (keyRing *KeyRing) GetVerifiedSignatureTimestampWithContext(
 func asymmetricEncrypt(
 	plainMessage *PlainMessage,
 	publicKey, privateKey *KeyRing,
-	compress bool,
+	options *EncryptionOptions,
 	signingContext *SigningContext,
 ) (*PGPMessage, error) {
 	var outBuf bytes.Buffer
@@ -212,7 +211,7 @@ func asymmetricEncrypt(
 		ModTime:  plainMessage.getFormattedTime(),
 	}
 
-	encryptWriter, err = asymmetricEncryptStream(hints, &outBuf, &outBuf, publicKey, privateKey, compress, signingContext)
+	encryptWriter, err = asymmetricEncryptStream(hints, &outBuf, &outBuf, publicKey, privateKey, options, signingContext, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -236,17 +235,22 @@ func asymmetricEncryptStream(
 	keyPacketWriter io.Writer,
 	dataPacketWriter io.Writer,
 	publicKey, privateKey *KeyRing,
-	compress bool,
+	options *EncryptionOptions,
 	signingContext *SigningContext,
+	fileAttributes *FileAttributes,
 ) (encryptWriter io.WriteCloser, err error) {
-	config := &packet.Config{
-		DefaultCipher: packet.CipherAES256,
-		Time:          getTimeGenerator(),
-	}
+	defer func() {
+		if err != nil {
+			reportOperation(constants.OperationEncrypt, 0, err)
+		}
+	}()
 
-	if compress {
-		config.DefaultCompressionAlgo = constants.DefaultCompression
-		config.CompressionConfig = &packet.CompressionConfig{Level: constants.DefaultCompressionLevel}
+	if options == nil {
+		options = NewEncryptionOptions()
+	}
+	config, err := options.getConfig()
+	if err != nil {
+		return nil, err
 	}
 
 	if signingContext != nil {
@@ -262,6 +266,13 @@ func asymmetricEncryptStream(
 		}
 	}
 
+	if fileAttributes != nil {
+		if signEntity == nil {
+			return nil, errors.New("gopenpgp: file attributes can only be recorded in a signed message")
+		}
+		config.SignatureNotations = append(config.SignatureNotations, fileAttributes.getNotations()...)
+	}
+
 	if hints.IsBinary {
 		encryptWriter, err = openpgp.EncryptSplit(keyPacketWriter, dataPacketWriter, publicKey.entities, signEntity, hints, config)
 	} else {
@@ -270,7 +281,7 @@ func asymmetricEncryptStream(
 	if err != nil {
 		return nil, errors.Wrap(err, "gopenpgp: error in encrypting asymmetrically")
 	}
-	return encryptWriter, nil
+	return withWriteMetrics(encryptWriter, constants.OperationEncrypt), nil
 }
 
 // Core for decryption+verification (non streaming) functions.
@@ -292,7 +303,7 @@ func asymmetricDecrypt(
 		return nil, err
 	}
 
-	body, err := ioutil.ReadAll(messageDetails.UnverifiedBody)
+	body, err := readAllPlaintext(messageDetails.UnverifiedBody)
 	if err != nil {
 		return nil, errors.Wrap(err, "gopenpgp: error in reading message body")
 	}
@@ -349,7 +360,9 @@ func asymmetricDecryptStream(
 
 	messageDetails, err = openpgp.ReadMessage(encryptedIO, privKeyEntries, nil, config)
 	if err != nil {
+		reportOperation(constants.OperationDecrypt, 0, err)
 		return nil, errors.Wrap(err, "gopenpgp: error in reading message")
 	}
+	messageDetails.UnverifiedBody = withReadMetrics(messageDetails.UnverifiedBody, constants.OperationDecrypt)
 	return messageDetails, err
 }
@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+// OrderedKeyRings is a list of keyrings which are tried in order to decrypt messages,
+// e.g. the active keys first, then the archived keys.
+type OrderedKeyRings struct {
+	keyRings []*KeyRing
+}
+
+// NewOrderedKeyRings creates an empty list of keyrings.
+func NewOrderedKeyRings() *OrderedKeyRings {
+	return &OrderedKeyRings{}
+}
+
+// Add appends a keyring to the list, to be tried after the previously added ones,
+// and returns the list.
+func (keyRings *OrderedKeyRings) Add(keyRing *KeyRing) *OrderedKeyRings {
+	keyRings.keyRings = append(keyRings.keyRings, keyRing)
+	return keyRings
+}
+
+// Count returns the number of keyrings in the list.
+func (keyRings *OrderedKeyRings) Count() int {
+	return len(keyRings.keyRings)
+}
+
+// DecryptWithPolicy decrypts encrypted string using the first keyring which can decrypt the session key,
+// returning a PlainMessage. The index of the keyring and the decryption key are reported in
+// PlainMessage.GetDecryptionDetails().
+// The decryption is restricted by the given policy, as in KeyRing.DecryptWithPolicy.
+func (keyRings *OrderedKeyRings) DecryptWithPolicy(
+	message *PGPMessage, verifyKey *KeyRing, verifyTime int64, policy *DecryptionPolicy,
+) (*PlainMessage, error) {
+	if policy == nil {
+		policy = NewDecryptionPolicy()
+	}
+	return decryptWithPolicy(message, keyRings.decrypter(policy), verifyKey, verifyTime, policy)
+}
+
+// DecryptStreamWithPolicy is used to decrypt a pgp message as a Reader, using the first keyring
+// which can decrypt the session key. The index of the keyring and the decryption key are reported in
+// PlainMessageReader.GetDecryptionDetails().
+// The decryption is restricted by the given policy, as in KeyRing.DecryptStreamWithPolicy.
+func (keyRings *OrderedKeyRings) DecryptStreamWithPolicy(
+	message Reader, verifyKeyRing *KeyRing, verifyTime int64, policy *DecryptionPolicy,
+) (*PlainMessageReader, error) {
+	if policy == nil {
+		policy = NewDecryptionPolicy()
+	}
+	return decryptStreamReaderWithPolicy(message, keyRings.decrypter(policy), verifyKeyRing, verifyTime, policy)
+}
+
+func (keyRings *OrderedKeyRings) decrypter(policy *DecryptionPolicy) keyPacketDecrypter {
+	return func(keyPackets []*packet.EncryptedKey, details *DecryptionDetails) (*packet.EncryptedKey, *openpgp.Key, error) {
+		err := errors.New("gopenpgp: no keyring to decrypt the session key")
+		for index, keyRing := range keyRings.keyRings {
+			var ek *packet.EncryptedKey
+			var key *openpgp.Key
+			if ek, key, err = policy.decryptKeyPackets(keyPackets, keyRing); err == nil {
+				details.KeyRingIndex = index
+				return ek, key, nil
+			}
+		}
+		return nil, nil, err
+	}
+}
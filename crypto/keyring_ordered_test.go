@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedKeyRingsDecryptWithPolicy(t *testing.T) {
+	keyRings := NewOrderedKeyRings()
+	var keys []*Key
+	for i := 0; i < 2; i++ {
+		key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+		if err != nil {
+			t.Fatal("Expected no error while generating the key, got:", err)
+		}
+		keyRing, err := NewKeyRing(key)
+		if err != nil {
+			t.Fatal("Expected no error while creating the keyring, got:", err)
+		}
+		keyRings.Add(keyRing)
+		keys = append(keys, key)
+	}
+	assert.Exactly(t, 2, keyRings.Count())
+
+	publicKeyRing, err := NewKeyRing(keys[1])
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	message, err := publicKeyRing.Encrypt(NewPlainMessageFromString(testMessage), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	decrypted, err := keyRings.DecryptWithPolicy(message, nil, 0, nil)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	details := decrypted.GetDecryptionDetails()
+	assert.Exactly(t, 1, details.GetKeyRingIndex())
+	assert.Exactly(t,
+		hex.EncodeToString(keys[1].entity.Subkeys[0].PublicKey.Fingerprint),
+		details.GetDecryptionKeyFingerprint(),
+	)
+
+	otherKeyRings := NewOrderedKeyRings().Add(keyRings.keyRings[0])
+	if _, err = otherKeyRings.DecryptWithPolicy(message, nil, 0, nil); err == nil {
+		t.Fatal("Expected an error while decrypting with the wrong keyrings, got nil")
+	}
+	if _, err = NewOrderedKeyRings().DecryptWithPolicy(message, nil, 0, nil); err == nil {
+		t.Fatal("Expected an error while decrypting without keyrings, got nil")
+	}
+}
@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// EncryptStreamReader is used to encrypt data as a Reader, the counterpart of EncryptStream.
+// It takes a reader for the plaintext data and returns a reader for the encrypted data:
+// the plaintext is encrypted in a goroutine, through an io.Pipe, as the returned reader is read.
+// If signKeyRing is not nil, it is used to do an embedded signature.
+// Encryption errors, and errors reading the plaintext, are returned by the Read of the returned reader.
+// The returned reader should be closed if it is not read entirely, to stop the goroutine.
+func (keyRing *KeyRing) EncryptStreamReader(
+	plainMessage Reader,
+	plainMessageMetadata *PlainMessageMetadata,
+	signKeyRing *KeyRing,
+) io.ReadCloser {
+	pgpMessageReader, pgpMessageWriter := io.Pipe()
+	go func() {
+		plainMessageWriter, err := keyRing.EncryptStream(pgpMessageWriter, plainMessageMetadata, signKeyRing)
+		if err == nil {
+			_, err = io.Copy(plainMessageWriter, plainMessage)
+			if err != nil {
+				err = errors.Wrap(err, "gopenpgp: error in encrypting the plaintext stream")
+			}
+		}
+		if err == nil {
+			err = plainMessageWriter.Close()
+		}
+		_ = pgpMessageWriter.CloseWithError(err)
+	}()
+	return pgpMessageReader
+}
+
+// PipedPlainMessageReader is the PlainMessageReader returned by EncryptDecryptPipe.
+// It must be closed, even after it has been read entirely, to stop the encryption goroutine.
+type PipedPlainMessageReader struct {
+	*PlainMessageReader
+	pgpMessage io.Closer
+}
+
+// Close stops the encryption goroutine, and releases the reader as PlainMessageReader.Close.
+func (msg *PipedPlainMessageReader) Close() error {
+	_ = msg.PlainMessageReader.Close()
+	return msg.pgpMessage.Close()
+}
+
+// EncryptDecryptPipe connects the encryption of the plaintext read from plainMessage
+// to its decryption, without buffering the encrypted message, e.g. for tests and relay services.
+// The plaintext is encrypted to encryptKeyRing, and signed with signKeyRing if not nil.
+// The encrypted message is decrypted with decryptKeyRing, and if verifyKeyRing is not nil,
+// PlainMessageReader.VerifySignature() verifies the embedded signature with the given key ring and verification time.
+// Encryption errors are returned by the Read of the returned reader.
+// The returned reader must be closed, otherwise the encryption goroutine leaks if it is not read entirely.
+func EncryptDecryptPipe(
+	plainMessage Reader,
+	plainMessageMetadata *PlainMessageMetadata,
+	encryptKeyRing, signKeyRing *KeyRing,
+	decryptKeyRing, verifyKeyRing *KeyRing,
+	verifyTime int64,
+) (*PipedPlainMessageReader, error) {
+	pgpMessage := encryptKeyRing.EncryptStreamReader(plainMessage, plainMessageMetadata, signKeyRing)
+	plainMessageReader, err := decryptKeyRing.DecryptStream(pgpMessage, verifyKeyRing, verifyTime)
+	if err != nil {
+		_ = pgpMessage.Close()
+		return nil, err
+	}
+	return &PipedPlainMessageReader{PlainMessageReader: plainMessageReader, pgpMessage: pgpMessage}, nil
+}
@@ -0,0 +1,93 @@
+package crypto
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptStreamReader(t *testing.T) {
+	plainData := bytes.Repeat([]byte("piped data "), 10000)
+	pgpMessageReader := keyRingTestPublic.EncryptStreamReader(bytes.NewReader(plainData), nil, keyRingTestPrivate)
+	pgpMessage, err := ioutil.ReadAll(pgpMessageReader)
+	if err != nil {
+		t.Fatal("Expected no error while reading the encrypted stream, got:", err)
+	}
+
+	decrypted, err := keyRingTestPrivate.Decrypt(NewPGPMessage(pgpMessage), keyRingTestPublic, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, plainData, decrypted.GetBinary())
+}
+
+func TestEncryptStreamReaderError(t *testing.T) {
+	pgpMessageReader := keyRingTestPublic.EncryptStreamReader(failingReader{}, nil, nil)
+	_, err := ioutil.ReadAll(pgpMessageReader)
+	if err == nil {
+		t.Fatal("Expected the plaintext error to be propagated, got nil")
+	}
+	assert.Contains(t, err.Error(), "no randomness")
+}
+
+func TestEncryptDecryptPipe(t *testing.T) {
+	plainData := bytes.Repeat([]byte("piped data "), 10000)
+	metadata := NewPlainMessageMetadata(true, "piped.txt", testTime)
+	plainMessageReader, err := EncryptDecryptPipe(
+		bytes.NewReader(plainData),
+		metadata,
+		keyRingTestPublic, keyRingTestPrivate,
+		keyRingTestPrivate, keyRingTestPublic,
+		GetUnixTime(),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the pipe, got:", err)
+	}
+	defer func() { _ = plainMessageReader.Close() }()
+	decrypted, err := ioutil.ReadAll(plainMessageReader)
+	if err != nil {
+		t.Fatal("Expected no error while reading the pipe, got:", err)
+	}
+	assert.Exactly(t, plainData, decrypted)
+	assert.Exactly(t, "piped.txt", plainMessageReader.GetMetadata().Filename)
+	if err = plainMessageReader.VerifySignature(); err != nil {
+		t.Fatal("Expected no error while verifying the signature, got:", err)
+	}
+}
+
+// endlessReader returns zeros forever.
+type endlessReader struct{}
+
+func (endlessReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = 0
+	}
+	return len(b), nil
+}
+
+func TestEncryptDecryptPipeClose(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+	plainMessageReader, err := EncryptDecryptPipe(
+		endlessReader{}, nil, keyRingTestPublic, nil, keyRingTestPrivate, nil, 0,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the pipe, got:", err)
+	}
+	if _, err = io.ReadFull(plainMessageReader, make([]byte, 1024)); err != nil {
+		t.Fatal("Expected no error while reading the pipe, got:", err)
+	}
+	if err = plainMessageReader.Close(); err != nil {
+		t.Fatal("Expected no error while closing the pipe, got:", err)
+	}
+
+	// The encryption goroutine stops
+	for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+}
@@ -9,6 +9,10 @@ import (
 	"github.com/ProtonMail/go-crypto/openpgp/packet"
 )
 
+// ErrNoDecryptionKey is returned when the session key of a message can't be decrypted,
+// as none of the private keys matches its key packets.
+var ErrNoDecryptionKey = errors.New("gopenpgp: unable to decrypt session key: no valid decryption key")
+
 // DecryptSessionKey returns the decrypted session key from one or multiple binary encrypted session key packets.
 func (keyRing *KeyRing) DecryptSessionKey(keyPacket []byte) (*SessionKey, error) {
 	var p packet.Packet
@@ -67,7 +71,7 @@ Loop:
 	}
 
 	if ek == nil || ek.Key == nil {
-		return nil, errors.New("gopenpgp: unable to decrypt session key: no valid decryption key")
+		return nil, ErrNoDecryptionKey
 	}
 
 	return newSessionKeyFromEncrypted(ek)
@@ -95,7 +99,7 @@ func (keyRing *KeyRing) EncryptSessionKey(sk *SessionKey) ([]byte, error) {
 	}
 
 	for _, pub := range pubKeys {
-		if err := packet.SerializeEncryptedKey(outbuf, pub, cf, sk.Key, nil); err != nil {
+		if err := packet.SerializeEncryptedKey(outbuf, pub, cf, sk.Key, &packet.Config{Rand: getRandomSource()}); err != nil {
 			return nil, errors.Wrap(err, "gopenpgp: cannot set key")
 		}
 	}
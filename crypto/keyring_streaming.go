@@ -2,13 +2,24 @@ package crypto
 
 import (
 	"bytes"
+	"context"
+	"crypto"
+	"hash"
 	"io"
 	"time"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
 	"github.com/pkg/errors"
 )
 
+var plaintextHashAlgos = map[string]crypto.Hash{
+	constants.SHA224: crypto.SHA224,
+	constants.SHA256: crypto.SHA256,
+	constants.SHA384: crypto.SHA384,
+	constants.SHA512: crypto.SHA512,
+}
+
 type Reader interface {
 	Read(b []byte) (n int, err error)
 }
@@ -26,12 +37,21 @@ type PlainMessageMetadata struct {
 	IsBinary bool
 	Filename string
 	ModTime  int64
+	// Attributes are optionally recorded as notations of the embedded signature,
+	// hence they require a signing keyring.
+	Attributes *FileAttributes
 }
 
 func NewPlainMessageMetadata(isBinary bool, filename string, modTime int64) *PlainMessageMetadata {
 	return &PlainMessageMetadata{IsBinary: isBinary, Filename: filename, ModTime: modTime}
 }
 
+// IsForYourEyesOnly returns whether the message is marked as for your eyes only,
+// and should be displayed but not saved to disk.
+func (metadata *PlainMessageMetadata) IsForYourEyesOnly() bool {
+	return metadata.Filename == constants.ForYourEyesOnlyFilename
+}
+
 // EncryptStream is used to encrypt data as a Writer.
 // It takes a writer for the encrypted data and returns a WriteCloser for the plaintext data
 // If signKeyRing is not nil, it is used to do an embedded signature.
@@ -46,7 +66,7 @@ func (keyRing *KeyRing) EncryptStream(
 		pgpMessageWriter,
 		plainMessageMetadata,
 		signKeyRing,
-		false,
+		nil,
 		nil,
 	)
 }
@@ -67,7 +87,7 @@ func (keyRing *KeyRing) EncryptStreamWithContext(
 		pgpMessageWriter,
 		plainMessageMetadata,
 		signKeyRing,
-		false,
+		nil,
 		signingContext,
 	)
 }
@@ -87,7 +107,7 @@ func (keyRing *KeyRing) EncryptStreamWithCompression(
 		pgpMessageWriter,
 		plainMessageMetadata,
 		signKeyRing,
-		true,
+		NewEncryptionOptions().WithCompression(),
 		nil,
 	)
 }
@@ -109,7 +129,7 @@ func (keyRing *KeyRing) EncryptStreamWithContextAndCompression(
 		pgpMessageWriter,
 		plainMessageMetadata,
 		signKeyRing,
-		true,
+		NewEncryptionOptions().WithCompression(),
 		signingContext,
 	)
 }
@@ -120,7 +140,7 @@ func encryptStream(
 	dataPacketWriter Writer,
 	plainMessageMetadata *PlainMessageMetadata,
 	signKeyRing *KeyRing,
-	compress bool,
+	options *EncryptionOptions,
 	signingContext *SigningContext,
 ) (plainMessageWriter WriteCloser, err error) {
 	if plainMessageMetadata == nil {
@@ -138,11 +158,20 @@ func encryptStream(
 		ModTime:  time.Unix(plainMessageMetadata.ModTime, 0),
 	}
 
-	plainMessageWriter, err = asymmetricEncryptStream(hints, keyPacketWriter, dataPacketWriter, encryptionKeyRing, signKeyRing, compress, signingContext)
+	encryptWriter, err := asymmetricEncryptStream(
+		hints,
+		keyPacketWriter,
+		dataPacketWriter,
+		encryptionKeyRing,
+		signKeyRing,
+		options,
+		signingContext,
+		plainMessageMetadata.Attributes,
+	)
 	if err != nil {
 		return nil, err
 	}
-	return plainMessageWriter, nil
+	return &plainMessageWriteCloser{encryptWriter}, nil
 }
 
 // EncryptSplitResult is used to wrap the encryption writecloser while storing the key packet.
@@ -191,7 +220,7 @@ func (keyRing *KeyRing) EncryptSplitStream(
 		dataPacketWriter,
 		plainMessageMetadata,
 		signKeyRing,
-		false,
+		nil,
 		nil,
 	)
 }
@@ -213,7 +242,7 @@ func (keyRing *KeyRing) EncryptSplitStreamWithContext(
 		dataPacketWriter,
 		plainMessageMetadata,
 		signKeyRing,
-		false,
+		nil,
 		signingContext,
 	)
 }
@@ -233,7 +262,7 @@ func (keyRing *KeyRing) EncryptSplitStreamWithCompression(
 		dataPacketWriter,
 		plainMessageMetadata,
 		signKeyRing,
-		true,
+		NewEncryptionOptions().WithCompression(),
 		nil,
 	)
 }
@@ -255,7 +284,7 @@ func (keyRing *KeyRing) EncryptSplitStreamWithContextAndCompression(
 		dataPacketWriter,
 		plainMessageMetadata,
 		signKeyRing,
-		true,
+		NewEncryptionOptions().WithCompression(),
 		signingContext,
 	)
 }
@@ -265,7 +294,7 @@ func encryptSplitStream(
 	dataPacketWriter Writer,
 	plainMessageMetadata *PlainMessageMetadata,
 	signKeyRing *KeyRing,
-	compress bool,
+	options *EncryptionOptions,
 	signingContext *SigningContext,
 ) (*EncryptSplitResult, error) {
 	var keyPacketBuf bytes.Buffer
@@ -275,7 +304,7 @@ func encryptSplitStream(
 		dataPacketWriter,
 		plainMessageMetadata,
 		signKeyRing,
-		compress,
+		options,
 		signingContext,
 	)
 	if err != nil {
@@ -295,7 +324,16 @@ type PlainMessageReader struct {
 	verifyKeyRing       *KeyRing
 	verifyTime          int64
 	readAll             bool
+	readStarted         bool
 	verificationContext *VerificationContext
+	plaintextHash       hash.Hash
+	decryptionDetails   *DecryptionDetails
+	decryptionPolicy    *DecryptionPolicy
+	bufferedPlaintext   io.Reader
+	verified            bool
+	verificationErr     error
+	detachedVerifier    *detachedSignatureVerifier
+	ctx                 context.Context
 }
 
 // GetMetadata returns the metadata of the decrypted message.
@@ -310,24 +348,125 @@ func (msg *PlainMessageReader) GetMetadata() *PlainMessageMetadata {
 // Read is used to access the message decrypted data.
 // Makes PlainMessageReader implement the Reader interface.
 func (msg *PlainMessageReader) Read(b []byte) (n int, err error) {
-	n, err = msg.details.UnverifiedBody.Read(b)
+	if msg.ctx != nil {
+		if err = msg.ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+	if !msg.readStarted {
+		msg.readStarted = true
+		if err = msg.releasePlaintext(); err != nil {
+			return 0, err
+		}
+	}
+	if msg.bufferedPlaintext != nil {
+		n, err = msg.bufferedPlaintext.Read(b)
+	} else {
+		n, err = msg.details.UnverifiedBody.Read(b)
+	}
+	if msg.plaintextHash != nil && n > 0 {
+		// Hashing can't return an error
+		_, _ = msg.plaintextHash.Write(b[:n])
+	}
+	if msg.detachedVerifier != nil && n > 0 {
+		msg.detachedVerifier.write(b[:n])
+	}
 	if errors.Is(err, io.EOF) {
 		msg.readAll = true
 	}
 	return
 }
 
+// EnablePlaintextHash makes the reader hash the decrypted data while it is being read,
+// so that the digest is available without a second pass over the plaintext.
+// hashAlgo is one of constants.SHA224, SHA256, SHA384 or SHA512; if empty, SHA256 is used.
+// This method must be called before the first call to Read.
+func (msg *PlainMessageReader) EnablePlaintextHash(hashAlgo string) error {
+	if msg.readStarted {
+		return errors.New("gopenpgp: can't enable plaintext hashing once the message reader has been read")
+	}
+	if hashAlgo == "" {
+		hashAlgo = constants.SHA256
+	}
+	h, ok := plaintextHashAlgos[hashAlgo]
+	if !ok {
+		return errors.New("gopenpgp: unsupported plaintext hash algorithm: " + hashAlgo)
+	}
+	msg.plaintextHash = h.New()
+	return nil
+}
+
+// GetPlaintextHash returns the digest of the decrypted data, as enabled with EnablePlaintextHash.
+// This method needs to be called once all the data has been read.
+func (msg *PlainMessageReader) GetPlaintextHash() ([]byte, error) {
+	if msg.plaintextHash == nil {
+		return nil, errors.New("gopenpgp: plaintext hashing was not enabled on the message reader")
+	}
+	if !msg.readAll {
+		return nil, errors.New("gopenpgp: can't access the plaintext hash until the message reader has been read entirely")
+	}
+	return msg.plaintextHash.Sum(nil), nil
+}
+
 // VerifySignature is used to verify that the signature is valid.
 // This method needs to be called once all the data has been read.
 // It will return an error if the signature is invalid
 // or if the message hasn't been read entirely.
 func (msg *PlainMessageReader) VerifySignature() (err error) {
+	if msg.verified {
+		return msg.verificationErr
+	}
 	if !msg.readAll {
 		return errors.New("gopenpgp: can't verify the signature until the message reader has been read entirely")
 	}
+	return msg.verifySignature()
+}
+
+// Close releases the verification of the detached signature of a reader returned by
+// DecryptStreamWithEncryptedSignature, if the reader is dropped before it has been read entirely.
+// It doesn't close the underlying message reader.
+func (msg *PlainMessageReader) Close() error {
+	if msg.detachedVerifier != nil && !msg.readAll {
+		msg.detachedVerifier.abort()
+	}
+	return nil
+}
+
+// releasePlaintext applies the plaintext release policy of the decryption policy
+// before the first read of the plaintext.
+func (msg *PlainMessageReader) releasePlaintext() error {
+	if msg.decryptionPolicy == nil || msg.verifyKeyRing == nil {
+		return nil
+	}
+	switch msg.decryptionPolicy.plaintextReleasePolicy {
+	case constants.PLAINTEXT_RELEASE_WARN:
+		msg.decryptionDetails.addWarning("plaintext released before signature verification")
+	case constants.PLAINTEXT_RELEASE_BUFFER:
+		plaintext, err := readAllPlaintext(msg.details.UnverifiedBody)
+		if err != nil {
+			return errors.Wrap(err, "gopenpgp: error in reading message body")
+		}
+		msg.readAll = true
+		msg.verified = true
+		msg.verificationErr = msg.verifySignature()
+		if msg.verificationErr != nil {
+			return msg.verificationErr
+		}
+		msg.bufferedPlaintext = bytes.NewReader(plaintext)
+	}
+	return nil
+}
+
+func (msg *PlainMessageReader) verifySignature() (err error) {
+	if msg.detachedVerifier != nil {
+		return msg.detachedVerifier.finish()
+	}
 	if msg.verifyKeyRing != nil {
 		processSignatureExpiration(msg.details, msg.verifyTime)
 		err = verifyDetailsSignature(msg.details, msg.verifyKeyRing, msg.verificationContext)
+		if err == nil && msg.decryptionPolicy != nil {
+			err = msg.decryptionPolicy.checkSignature(msg.details, msg.decryptionDetails)
+		}
 	} else {
 		err = errors.New("gopenpgp: no verify keyring was provided before decryption")
 	}
@@ -393,11 +532,10 @@ func decryptStream(
 	}
 
 	return &PlainMessageReader{
-		messageDetails,
-		verifyKeyRing,
-		verifyTime,
-		false,
-		verificationContext,
+		details:             messageDetails,
+		verifyKeyRing:       verifyKeyRing,
+		verifyTime:          verifyTime,
+		verificationContext: verificationContext,
 	}, err
 }
 
@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"context"
+)
+
+// EncryptStreamWithCancellation is used to encrypt data as a Writer, as EncryptStream,
+// and aborts the encryption once ctx is cancelled: the writes and the close then return ctx.Err(),
+// and the message is left unterminated, without its final integrity protection.
+func (keyRing *KeyRing) EncryptStreamWithCancellation(
+	ctx context.Context,
+	pgpMessageWriter Writer,
+	plainMessageMetadata *PlainMessageMetadata,
+	signKeyRing *KeyRing,
+) (plainMessageWriter WriteCloser, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	plainMessageWriter, err = keyRing.EncryptStream(pgpMessageWriter, plainMessageMetadata, signKeyRing)
+	if err != nil {
+		return nil, err
+	}
+	return &cancellableWriteCloser{ctx: ctx, writer: plainMessageWriter}, nil
+}
+
+// DecryptStreamWithCancellation is used to decrypt a pgp message as a Reader, as DecryptStream,
+// and aborts the decryption once ctx is cancelled: the reads then return ctx.Err().
+func (keyRing *KeyRing) DecryptStreamWithCancellation(
+	ctx context.Context,
+	message Reader,
+	verifyKeyRing *KeyRing,
+	verifyTime int64,
+) (plainMessage *PlainMessageReader, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	plainMessage, err = keyRing.DecryptStream(&cancellableReader{ctx: ctx, reader: message}, verifyKeyRing, verifyTime)
+	if err != nil {
+		return nil, err
+	}
+	plainMessage.ctx = ctx
+	return plainMessage, nil
+}
+
+// cancellableWriteCloser fails the writes and the close once the context is cancelled.
+type cancellableWriteCloser struct {
+	ctx    context.Context
+	writer WriteCloser
+}
+
+func (w *cancellableWriteCloser) Write(b []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.writer.Write(b)
+}
+
+func (w *cancellableWriteCloser) Close() error {
+	if err := w.ctx.Err(); err != nil {
+		// Don't terminate the message
+		return err
+	}
+	return w.writer.Close()
+}
+
+// cancellableReader fails the reads once the context is cancelled.
+type cancellableReader struct {
+	ctx    context.Context
+	reader Reader
+}
+
+func (r *cancellableReader) Read(b []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(b)
+}
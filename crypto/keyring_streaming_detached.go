@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// DecryptStreamWithEncryptedSignature is used to decrypt a pgp message as a Reader,
+// and to verify it with a detached signature encrypted to the same keyring,
+// as produced by SignDetachedEncryptedStream.
+// The encrypted signature is read and decrypted first, then the plaintext is verified while it is read:
+// PlainMessageReader.VerifySignature() returns the result of the verification of the detached signature
+// with verifyKeyRing and verifyTime, once the message reader has been read entirely.
+// Embedded signatures of the message, if any, are not verified.
+// The verification runs in a goroutine until the message reader has been read entirely:
+// if the reader is dropped before, PlainMessageReader.Close needs to be called to release it.
+func (keyRing *KeyRing) DecryptStreamWithEncryptedSignature(
+	message Reader,
+	encryptedSignature Reader,
+	verifyKeyRing *KeyRing,
+	verifyTime int64,
+) (plainMessage *PlainMessageReader, err error) {
+	if verifyKeyRing == nil {
+		return nil, errors.New("gopenpgp: no verify keyring provided")
+	}
+	signatureReader, err := keyRing.DecryptStream(encryptedSignature, nil, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt the signature")
+	}
+	signature, err := ioutil.ReadAll(signatureReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt the signature")
+	}
+	plainMessage, err = keyRing.DecryptStream(message, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	plainMessage.detachedVerifier = newDetachedSignatureVerifier(verifyKeyRing, signature, verifyTime)
+	return plainMessage, nil
+}
+
+// detachedSignatureVerifier verifies a detached signature of the data written to it,
+// in a separate goroutine fed through a pipe.
+type detachedSignatureVerifier struct {
+	pipeWriter *io.PipeWriter
+	result     chan error
+	finished   bool
+	err        error
+}
+
+func newDetachedSignatureVerifier(verifyKeyRing *KeyRing, signature []byte, verifyTime int64) *detachedSignatureVerifier {
+	pipeReader, pipeWriter := io.Pipe()
+	verifier := &detachedSignatureVerifier{
+		pipeWriter: pipeWriter,
+		result:     make(chan error, 1),
+	}
+	go func() {
+		_, err := verifySignature(verifyKeyRing.entities, pipeReader, signature, verifyTime, nil)
+		// Unblock the writes if the verification ended before the end of the data
+		_ = pipeReader.CloseWithError(errors.New("gopenpgp: signature verification ended"))
+		verifier.result <- err
+	}()
+	return verifier
+}
+
+// write feeds the data to the verification.
+func (verifier *detachedSignatureVerifier) write(data []byte) {
+	// The write only fails once the verification has ended, its result holds the error
+	_, _ = verifier.pipeWriter.Write(data)
+}
+
+// abort stops the verification before the end of the data.
+func (verifier *detachedSignatureVerifier) abort() {
+	if !verifier.finished {
+		verifier.finished = true
+		_ = verifier.pipeWriter.CloseWithError(errors.New("gopenpgp: the message reader was closed before the end of the data"))
+		verifier.err = <-verifier.result
+	}
+}
+
+// finish signals the end of the data and waits for the result of the verification.
+func (verifier *detachedSignatureVerifier) finish() error {
+	if !verifier.finished {
+		verifier.finished = true
+		_ = verifier.pipeWriter.Close()
+		verifier.err = <-verifier.result
+	}
+	return verifier.err
+}
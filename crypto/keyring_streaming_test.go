@@ -2,11 +2,14 @@ package crypto
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"io"
 	"io/ioutil"
 	"reflect"
 	"testing"
 
+	"github.com/ProtonMail/gopenpgp/v2/constants"
 	"github.com/pkg/errors"
 )
 
@@ -792,3 +795,164 @@ func TestKeyRing_VerifyDetachedEncryptedStreamCompatible(t *testing.T) {
 		t.Fatal("Expected no error while verifying the detached signature, got:", err)
 	}
 }
+
+func TestKeyRing_DecryptStreamPlaintextHash(t *testing.T) {
+	messageBytes := []byte("Hello World!")
+	expectedHash := sha256.Sum256(messageBytes)
+	ciphertext, err := keyRingTestPublic.Encrypt(NewPlainMessage(messageBytes), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	decryptedReader, err := keyRingTestPrivate.DecryptStream(
+		bytes.NewReader(ciphertext.GetBinary()),
+		nil,
+		0,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while calling decrypting stream, got:", err)
+	}
+	if err = decryptedReader.EnablePlaintextHash("md5"); err == nil {
+		t.Fatal("Expected an error while enabling an unsupported hash, got nil")
+	}
+	if err = decryptedReader.EnablePlaintextHash(""); err != nil {
+		t.Fatal("Expected no error while enabling the plaintext hash, got:", err)
+	}
+	if _, err = decryptedReader.GetPlaintextHash(); err == nil {
+		t.Fatal("Expected an error while getting the hash before reading, got nil")
+	}
+	decryptedBytes, err := ioutil.ReadAll(decryptedReader)
+	if err != nil {
+		t.Fatal("Expected no error while reading the decrypted data, got:", err)
+	}
+	if !bytes.Equal(decryptedBytes, messageBytes) {
+		t.Fatalf("Expected the decrypted data to be %s got %s", string(messageBytes), string(decryptedBytes))
+	}
+	if err = decryptedReader.EnablePlaintextHash(constants.SHA512); err == nil {
+		t.Fatal("Expected an error while enabling the hash after reading, got nil")
+	}
+	plaintextHash, err := decryptedReader.GetPlaintextHash()
+	if err != nil {
+		t.Fatal("Expected no error while getting the plaintext hash, got:", err)
+	}
+	if !bytes.Equal(plaintextHash, expectedHash[:]) {
+		t.Fatalf("Expected the plaintext hash to be %x got %x", expectedHash, plaintextHash)
+	}
+}
+
+func TestKeyRing_DecryptStreamWithEncryptedSignature(t *testing.T) {
+	messageBytes := bytes.Repeat([]byte("Hello World!"), 10000)
+	ciphertext, err := keyRingTestPublic.Encrypt(NewPlainMessage(messageBytes), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	encSignature, err := keyRingTestPrivate.SignDetachedEncryptedStream(bytes.NewReader(messageBytes), keyRingTestPublic)
+	if err != nil {
+		t.Fatal("Expected no error while signing the message, got:", err)
+	}
+
+	decryptedReader, err := keyRingTestPrivate.DecryptStreamWithEncryptedSignature(
+		bytes.NewReader(ciphertext.GetBinary()),
+		bytes.NewReader(encSignature.GetBinary()),
+		keyRingTestPublic,
+		GetUnixTime(),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while calling decrypting stream, got:", err)
+	}
+	if err = decryptedReader.VerifySignature(); err == nil {
+		t.Fatal("Expected an error while verifying before reading, got nil")
+	}
+	decryptedBytes, err := ioutil.ReadAll(decryptedReader)
+	if err != nil {
+		t.Fatal("Expected no error while reading the decrypted data, got:", err)
+	}
+	if !bytes.Equal(decryptedBytes, messageBytes) {
+		t.Fatal("Expected the decrypted data to match the message")
+	}
+	if err = decryptedReader.VerifySignature(); err != nil {
+		t.Fatal("Expected no error while verifying the detached signature, got:", err)
+	}
+
+	otherSignature, err := keyRingTestPrivate.SignDetachedEncryptedStream(bytes.NewReader([]byte("other")), keyRingTestPublic)
+	if err != nil {
+		t.Fatal("Expected no error while signing the message, got:", err)
+	}
+	decryptedReader, err = keyRingTestPrivate.DecryptStreamWithEncryptedSignature(
+		bytes.NewReader(ciphertext.GetBinary()),
+		bytes.NewReader(otherSignature.GetBinary()),
+		keyRingTestPublic,
+		GetUnixTime(),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while calling decrypting stream, got:", err)
+	}
+	if _, err = ioutil.ReadAll(decryptedReader); err != nil {
+		t.Fatal("Expected no error while reading the decrypted data, got:", err)
+	}
+	err = decryptedReader.VerifySignature()
+	var sigErr SignatureVerificationError
+	if !errors.As(err, &sigErr) || sigErr.Status != constants.SIGNATURE_FAILED {
+		t.Fatal("Expected a failed signature verification, got:", err)
+	}
+
+	// Dropping the reader before the end of the data releases the verification
+	decryptedReader, err = keyRingTestPrivate.DecryptStreamWithEncryptedSignature(
+		bytes.NewReader(ciphertext.GetBinary()),
+		bytes.NewReader(encSignature.GetBinary()),
+		keyRingTestPublic,
+		GetUnixTime(),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while calling decrypting stream, got:", err)
+	}
+	if _, err = decryptedReader.Read(make([]byte, 100)); err != nil {
+		t.Fatal("Expected no error while reading the decrypted data, got:", err)
+	}
+	if err = decryptedReader.Close(); err != nil {
+		t.Fatal("Expected no error while closing the reader, got:", err)
+	}
+	if err = decryptedReader.VerifySignature(); err == nil {
+		t.Fatal("Expected an error while verifying a partially read message, got nil")
+	}
+}
+
+func TestKeyRing_EncryptDecryptStreamWithCancellation(t *testing.T) {
+	messageBytes := bytes.Repeat([]byte("Hello World!"), 10000)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ciphertextBuf bytes.Buffer
+	messageWriter, err := keyRingTestPublic.EncryptStreamWithCancellation(ctx, &ciphertextBuf, nil, nil)
+	if err != nil {
+		t.Fatal("Expected no error while calling encrypt stream, got:", err)
+	}
+	if _, err = messageWriter.Write(messageBytes); err != nil {
+		t.Fatal("Expected no error while writing the message, got:", err)
+	}
+	if err = messageWriter.Close(); err != nil {
+		t.Fatal("Expected no error while closing the writer, got:", err)
+	}
+
+	decryptedReader, err := keyRingTestPrivate.DecryptStreamWithCancellation(ctx, bytes.NewReader(ciphertextBuf.Bytes()), nil, 0)
+	if err != nil {
+		t.Fatal("Expected no error while calling decrypt stream, got:", err)
+	}
+	buffer := make([]byte, 100)
+	if _, err = decryptedReader.Read(buffer); err != nil {
+		t.Fatal("Expected no error while reading the message, got:", err)
+	}
+
+	cancel()
+	if _, err = decryptedReader.Read(buffer); !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected a cancellation error while reading, got:", err)
+	}
+	if _, err = messageWriter.Write(messageBytes); !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected a cancellation error while writing, got:", err)
+	}
+
+	var abortedBuf bytes.Buffer
+	_, err = keyRingTestPublic.EncryptStreamWithCancellation(ctx, &abortedBuf, nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected a cancellation error while calling encrypt stream, got:", err)
+	}
+}
@@ -1,15 +1,21 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/rsa"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/ProtonMail/go-crypto/openpgp/ecdh"
 	"github.com/ProtonMail/go-crypto/openpgp/eddsa"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
 
+	"github.com/ProtonMail/gopenpgp/v2/armor"
 	"github.com/ProtonMail/gopenpgp/v2/constants"
 )
 
@@ -148,6 +154,34 @@ func TestMultipleKeyRing(t *testing.T) {
 	assert.Exactly(t, 1, singleKeyRing.CountDecryptionEntities())
 }
 
+func TestKeyRingArmor(t *testing.T) {
+	armored, err := keyRingTestMultiple.Armor()
+	if err != nil {
+		t.Fatal("Expected no error while armoring keyring, got:", err)
+	}
+	assert.Equal(t, 1, strings.Count(armored, "-----BEGIN PGP PUBLIC KEY BLOCK-----"))
+
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		t.Fatal("Expected no error while reading armored keyring, got:", err)
+	}
+	assert.Len(t, entities, 3)
+	for i, entity := range entities {
+		assert.Nil(t, entity.PrivateKey)
+		assert.Equal(t, keyRingTestMultiple.entities[i].PrimaryKey.Fingerprint, entity.PrimaryKey.Fingerprint)
+	}
+
+	serialized, err := keyRingTestMultiple.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing keyring, got:", err)
+	}
+	unarmored, err := armor.Unarmor(armored)
+	if err != nil {
+		t.Fatal("Expected no error while unarmoring keyring, got:", err)
+	}
+	assert.Equal(t, serialized, unarmored)
+}
+
 func TestClearPrivateKey(t *testing.T) {
 	keyRingCopy, err := keyRingTestMultiple.Copy()
 	if err != nil {
@@ -235,6 +269,60 @@ func TestKeyringCapabilities(t *testing.T) {
 	assert.True(t, keyRingTestMultiple.CanEncrypt())
 }
 
+func TestFilterKeys(t *testing.T) {
+	expiringSpec := NewSubkeySpec("x25519", 0, false, true, false)
+	expiringSpec.Lifetime = 3600
+	expiringKey, err := GenerateKeyWithSubkeys(keyTestName, keyTestDomain, "x25519", 0, expiringSpec)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	keyRing, err := NewKeyRing(keyTestEC)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	if err = keyRing.AddKey(expiringKey); err != nil {
+		t.Fatal("Expected no error while adding key, got:", err)
+	}
+
+	now := GetUnixTime()
+	encryptionKeyRing, err := keyRing.FilterEncryptionKeys(now)
+	if err != nil {
+		t.Fatal("Expected no error while filtering keys, got:", err)
+	}
+	assert.Equal(t, 2, encryptionKeyRing.CountEntities())
+
+	encryptionKeyRing, err = keyRing.FilterEncryptionKeys(now + 7200)
+	if err != nil {
+		t.Fatal("Expected no error while filtering keys, got:", err)
+	}
+	assert.Equal(t, 1, encryptionKeyRing.CountEntities())
+	assert.Equal(t, keyTestEC.GetFingerprint(), encryptionKeyRing.GetKeys()[0].GetFingerprint())
+
+	signingKeyRing, err := keyRing.FilterSigningKeys(now + 7200)
+	if err != nil {
+		t.Fatal("Expected no error while filtering keys, got:", err)
+	}
+	assert.Equal(t, 2, signingKeyRing.CountEntities())
+
+	pgp.latestServerTime = 1632219895
+	defer func() {
+		pgp.latestServerTime = testTime
+	}()
+	revokedKey, err := NewKeyFromArmored(readTestFile("key_revoked", false))
+	if err != nil {
+		t.Fatal("Cannot unarmor key:", err)
+	}
+	revokedKeyRing, err := NewKeyRing(revokedKey)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	signingKeyRing, err = revokedKeyRing.FilterSigningKeys(GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while filtering keys, got:", err)
+	}
+	assert.Equal(t, 0, signingKeyRing.CountEntities())
+}
+
 func TestVerificationTime(t *testing.T) {
 	message := NewPlainMessageFromString("Hello")
 	pgp.latestServerTime = 1632312383
@@ -274,3 +362,38 @@ func TestVerificationTime(t *testing.T) {
 		t.Fatalf("Got an error while decrypting %v", err)
 	}
 }
+
+func TestFingerprintForKeyID(t *testing.T) {
+	key := keyRingTestPublic.GetKeys()[0]
+	fingerprints := key.SubkeyFingerprints()
+	assert.Len(t, fingerprints, 1+len(key.entity.Subkeys))
+	assert.Exactly(t, key.GetFingerprint(), fingerprints[key.GetHexKeyID()])
+
+	fingerprint, err := keyRingTestPublic.FingerprintForKeyID(key.GetKeyID())
+	if err != nil {
+		t.Fatal("Expected no error while getting the fingerprint, got:", err)
+	}
+	assert.Exactly(t, key.GetFingerprint(), fingerprint)
+
+	keyPacket, err := keyRingTestPublic.EncryptSessionKey(testSessionKey)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting the session key, got:", err)
+	}
+	p, err := packet.Read(bytes.NewReader(keyPacket))
+	if err != nil {
+		t.Fatal("Expected no error while reading the key packet, got:", err)
+	}
+	encryptedKey, ok := p.(*packet.EncryptedKey)
+	if !ok {
+		t.Fatal("Expected an encrypted key packet")
+	}
+	fingerprint, err = keyRingTestPublic.FingerprintForKeyID(encryptedKey.KeyId)
+	if err != nil {
+		t.Fatal("Expected no error while getting the fingerprint, got:", err)
+	}
+	assert.Exactly(t, hex.EncodeToString(key.entity.Subkeys[0].PublicKey.Fingerprint), fingerprint)
+	assert.NotEqual(t, key.GetFingerprint(), fingerprint)
+
+	_, err = keyRingTestPublic.FingerprintForKeyID(0x1234)
+	assert.Error(t, err)
+}
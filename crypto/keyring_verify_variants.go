@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	"bytes"
+	goerrors "errors"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+)
+
+// Line ending variants of a text message, as reported by VerifyDetachedWithLineEndingRetry.
+const (
+	// LineEndingsOriginal is the message as given.
+	LineEndingsOriginal = "original"
+	// LineEndingsLF is the message with CRLF line endings converted to LF.
+	LineEndingsLF = "lf"
+	// LineEndingsCRLF is the message with LF line endings converted to CRLF.
+	LineEndingsCRLF = "crlf"
+	// LineEndingsTrimmed is the message without its trailing line endings.
+	LineEndingsTrimmed = "trimmed"
+	// LineEndingsLFTrimmed is the message with LF line endings, without its trailing line endings.
+	LineEndingsLFTrimmed = "lf-trimmed"
+	// LineEndingsCRLFTrimmed is the message with CRLF line endings, without its trailing line endings.
+	LineEndingsCRLFTrimmed = "crlf-trimmed"
+)
+
+// VerifyDetachedWithLineEndingRetry verifies a PlainMessage with a detached PGPSignature as VerifyDetached,
+// and, if a text signature or the signature of a text message is invalid, retries the verification
+// with the line ending variants of the message, since mail transports can alter line endings.
+// It returns the variant that matched, one of the LineEndings* constants,
+// or the error of the verification of the original message if none matched.
+func (keyRing *KeyRing) VerifyDetachedWithLineEndingRetry(
+	message *PlainMessage, signature *PGPSignature, verifyTime int64,
+) (variant string, err error) {
+	err = keyRing.VerifyDetached(message, signature, verifyTime)
+	if err == nil {
+		return LineEndingsOriginal, nil
+	}
+	var sigErr SignatureVerificationError
+	if !goerrors.As(err, &sigErr) || sigErr.Status != constants.SIGNATURE_FAILED {
+		return "", err
+	}
+	if !message.IsText() && !isTextSignature(signature.GetBinary()) {
+		return "", err
+	}
+
+	tried := [][]byte{message.Data}
+	for _, lineEndingVariant := range getLineEndingVariants(message.Data) {
+		if containsBytes(tried, lineEndingVariant.data) {
+			continue
+		}
+		tried = append(tried, lineEndingVariant.data)
+		variantMessage := &PlainMessage{
+			Data:     lineEndingVariant.data,
+			TextType: message.TextType,
+			Time:     message.Time,
+			Filename: message.Filename,
+		}
+		if keyRing.VerifyDetached(variantMessage, signature, verifyTime) == nil {
+			return lineEndingVariant.name, nil
+		}
+	}
+	return "", err
+}
+
+type lineEndingVariant struct {
+	name string
+	data []byte
+}
+
+// getLineEndingVariants returns the line ending variants of the data to retry, in order.
+func getLineEndingVariants(data []byte) []lineEndingVariant {
+	lf := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	crlf := bytes.ReplaceAll(lf, []byte("\n"), []byte("\r\n"))
+	return []lineEndingVariant{
+		{LineEndingsLF, lf},
+		{LineEndingsCRLF, crlf},
+		{LineEndingsTrimmed, bytes.TrimRight(data, "\r\n")},
+		{LineEndingsLFTrimmed, bytes.TrimRight(lf, "\r\n")},
+		{LineEndingsCRLFTrimmed, bytes.TrimRight(crlf, "\r\n")},
+	}
+}
+
+func containsBytes(list [][]byte, data []byte) bool {
+	for _, item := range list {
+		if bytes.Equal(item, data) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTextSignature returns true if any of the signature packets is a text signature.
+func isTextSignature(signature []byte) bool {
+	packets := packet.NewReader(bytes.NewReader(signature))
+	for {
+		p, err := packets.Next()
+		if err != nil {
+			return false
+		}
+		if sig, ok := p.(*packet.Signature); ok && sig.SigType == packet.SigTypeText {
+			return true
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyDetachedWithLineEndingRetry(t *testing.T) {
+	signature, err := keyRingTestPrivate.SignDetached(NewPlainMessageFromString("hello\nworld"))
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	variant, err := keyRingTestPublic.VerifyDetachedWithLineEndingRetry(
+		NewPlainMessageFromString("hello\nworld"), signature, GetUnixTime(),
+	)
+	assert.NoError(t, err)
+	assert.Exactly(t, LineEndingsOriginal, variant)
+
+	variant, err = keyRingTestPublic.VerifyDetachedWithLineEndingRetry(
+		NewPlainMessageFromString("hello\nworld\n\n"), signature, GetUnixTime(),
+	)
+	assert.NoError(t, err)
+	assert.Exactly(t, LineEndingsTrimmed, variant)
+
+	// Binary signature of a text message received with CRLF line endings
+	binarySignature, err := keyRingTestPrivate.SignDetached(NewPlainMessage([]byte("hello\nworld\n")))
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	received := &PlainMessage{Data: []byte("hello\r\nworld\r\n"), TextType: true}
+	variant, err = keyRingTestPublic.VerifyDetachedWithLineEndingRetry(received, binarySignature, GetUnixTime())
+	assert.NoError(t, err)
+	assert.Exactly(t, LineEndingsLF, variant)
+
+	// No retry for binary data and binary signatures
+	_, err = keyRingTestPublic.VerifyDetachedWithLineEndingRetry(
+		NewPlainMessage([]byte("hello\r\nworld\r\n")), binarySignature, GetUnixTime(),
+	)
+	assert.Error(t, err)
+
+	_, err = keyRingTestPublic.VerifyDetachedWithLineEndingRetry(
+		NewPlainMessageFromString("goodbye\nworld"), signature, GetUnixTime(),
+	)
+	var sigErr SignatureVerificationError
+	assert.ErrorAs(t, err, &sigErr)
+	assert.Exactly(t, constants.SIGNATURE_FAILED, sigErr.Status)
+}
@@ -0,0 +1,82 @@
+package crypto
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrMemoryBudgetExceeded is returned by the in-memory decryption functions
+// when the plaintext is larger than the memory budget set with SetMemoryBudget.
+// Large messages should be decrypted with the streaming functions instead.
+var ErrMemoryBudgetExceeded = errors.New("gopenpgp: the plaintext exceeds the memory budget, use the streaming functions")
+
+// plaintextBuffers holds the buffers reused to read the plaintext of in-memory decryptions.
+var plaintextBuffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// SetMemoryBudget bounds the memory used by the in-memory decryption functions
+// (e.g. KeyRing.Decrypt, KeyRing.DecryptAttachment and SessionKey.Decrypt), as an alternative
+// to calling FreeOSMemory after every operation on memory constrained devices.
+// * maxPlaintextSize: the largest plaintext held in memory, larger messages fail
+// with ErrMemoryBudgetExceeded before being read entirely. If 0, the size is not limited, which is the default.
+// * maxReusedBufferSize: the read buffers up to this capacity are reused by the next decryptions,
+// instead of being reallocated. If 0, the buffers are not reused, which is the default.
+// The plaintext is copied out of a reused buffer, which is wiped before being reused:
+// the peak memory of a decryption is then twice the plaintext size, in exchange for fewer allocations.
+func SetMemoryBudget(maxPlaintextSize, maxReusedBufferSize int64) {
+	pgp.lock.Lock()
+	defer pgp.lock.Unlock()
+
+	pgp.maxPlaintextSize = maxPlaintextSize
+	pgp.maxReusedBufferSize = maxReusedBufferSize
+}
+
+// ----- INTERNAL FUNCTIONS -----
+
+func getMemoryBudget() (maxPlaintextSize, maxReusedBufferSize int64) {
+	pgp.lock.RLock()
+	defer pgp.lock.RUnlock()
+
+	return pgp.maxPlaintextSize, pgp.maxReusedBufferSize
+}
+
+// readAllPlaintext works like ioutil.ReadAll, but enforces the memory budget
+// and reads through a reused buffer when enabled.
+func readAllPlaintext(reader io.Reader) ([]byte, error) {
+	maxPlaintextSize, maxReusedBufferSize := getMemoryBudget()
+	if maxPlaintextSize > 0 {
+		// Read one more byte to detect the plaintexts exceeding the budget
+		reader = io.LimitReader(reader, maxPlaintextSize+1)
+	}
+
+	var buffer *bytes.Buffer
+	if maxReusedBufferSize > 0 {
+		buffer = plaintextBuffers.Get().(*bytes.Buffer)
+		defer func() {
+			// Wipe the plaintext, including the bytes beyond the length left by the reads
+			clearMem(buffer.Bytes()[:buffer.Cap()])
+			if int64(buffer.Cap()) <= maxReusedBufferSize {
+				buffer.Reset()
+				plaintextBuffers.Put(buffer)
+			}
+		}()
+	} else {
+		buffer = new(bytes.Buffer)
+	}
+
+	if _, err := buffer.ReadFrom(reader); err != nil {
+		return nil, err
+	}
+	if maxPlaintextSize > 0 && int64(buffer.Len()) > maxPlaintextSize {
+		return nil, ErrMemoryBudgetExceeded
+	}
+	if maxReusedBufferSize > 0 {
+		// The reused buffer is wiped, the caller gets a copy: the peak memory is twice the plaintext size
+		return clone(buffer.Bytes()), nil
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	defer SetMemoryBudget(0, 0)
+
+	plainData := bytes.Repeat([]byte{0x42}, 1<<16)
+	encrypted, err := keyRingTestPublic.Encrypt(NewPlainMessage(plainData), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	SetMemoryBudget(1<<16, 1<<20)
+	for i := 0; i < 3; i++ {
+		decrypted, err := keyRingTestPrivate.Decrypt(encrypted, nil, 0)
+		if err != nil {
+			t.Fatal("Expected no error while decrypting within the budget, got:", err)
+		}
+		assert.Exactly(t, plainData, decrypted.GetBinary())
+	}
+
+	SetMemoryBudget(1<<16-1, 0)
+	_, err = keyRingTestPrivate.Decrypt(encrypted, nil, 0)
+	if !errors.Is(err, ErrMemoryBudgetExceeded) {
+		t.Fatal("Expected the memory budget to be exceeded, got:", err)
+	}
+
+	split, err := encrypted.SplitMessage()
+	if err != nil {
+		t.Fatal("Expected no error while splitting the message, got:", err)
+	}
+	_, err = keyRingTestPrivate.DecryptAttachment(split)
+	if !errors.Is(err, ErrMemoryBudgetExceeded) {
+		t.Fatal("Expected the memory budget to be exceeded, got:", err)
+	}
+
+	// The streaming functions are not bounded
+	reader, err := keyRingTestPrivate.DecryptStream(bytes.NewReader(encrypted.GetBinary()), nil, 0)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the stream, got:", err)
+	}
+	var decrypted bytes.Buffer
+	if _, err = decrypted.ReadFrom(reader); err != nil {
+		t.Fatal("Expected no error while reading the stream, got:", err)
+	}
+	assert.Exactly(t, plainData, decrypted.Bytes())
+}
+
+func TestMemoryBudgetWipesReusedBuffers(t *testing.T) {
+	defer SetMemoryBudget(0, 0)
+	SetMemoryBudget(0, 1<<20)
+
+	plainData := bytes.Repeat([]byte{0x42}, 1<<16)
+	read, err := readAllPlaintext(bytes.NewReader(plainData))
+	if err != nil {
+		t.Fatal("Expected no error while reading, got:", err)
+	}
+	assert.Exactly(t, plainData, read)
+
+	buffer := plaintextBuffers.Get().(*bytes.Buffer)
+	defer plaintextBuffers.Put(buffer)
+	assert.Zero(t, buffer.Len())
+	assert.False(t, bytes.Contains(buffer.Bytes()[:buffer.Cap()], []byte{0x42}))
+}
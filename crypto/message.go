@@ -30,6 +30,8 @@ type PlainMessage struct {
 	Time uint32
 	// The encrypted message's filename
 	Filename string
+
+	decryptionDetails *DecryptionDetails
 }
 
 // PGPMessage stores a PGP-encrypted message.
@@ -123,6 +125,19 @@ func NewPGPMessageFromArmored(armored string) (*PGPMessage, error) {
 	}, nil
 }
 
+// NewPGPMessageFromBase64 generates a new PGPMessage from the base64 encoding
+// of the unarmored binary data, without armor headers nor checksum.
+func NewPGPMessageFromBase64(encoded string) (*PGPMessage, error) {
+	message, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in decoding base64 message")
+	}
+
+	return &PGPMessage{
+		Data: message,
+	}, nil
+}
+
 // NewPGPSplitMessage generates a new PGPSplitMessage from the binary unarmored keypacket,
 // datapacket, and encryption algorithm.
 func NewPGPSplitMessage(keyPacket []byte, dataPacket []byte) *PGPSplitMessage {
@@ -143,6 +158,24 @@ func NewPGPSplitMessageFromArmored(encrypted string) (*PGPSplitMessage, error) {
 	return message.SplitMessage()
 }
 
+// NewPGPSplitMessageFromBase64 generates a new PGPSplitMessage from the base64 encodings
+// of the binary keypacket and datapacket, as exchanged e.g. for attachments.
+func NewPGPSplitMessageFromBase64(keyPacket, dataPacket string) (*PGPSplitMessage, error) {
+	keyPacketData, err := base64.StdEncoding.DecodeString(keyPacket)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in decoding base64 key packet")
+	}
+	dataPacketData, err := base64.StdEncoding.DecodeString(dataPacket)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in decoding base64 data packet")
+	}
+
+	return &PGPSplitMessage{
+		KeyPacket:  keyPacketData,
+		DataPacket: dataPacketData,
+	}, nil
+}
+
 // NewPGPSignature generates a new PGPSignature from the unarmored binary data.
 func NewPGPSignature(data []byte) *PGPSignature {
 	return &PGPSignature{
@@ -168,6 +201,19 @@ func NewPGPSignatureFromArmored(armored string) (*PGPSignature, error) {
 	}, nil
 }
 
+// NewPGPSignatureFromBase64 generates a new PGPSignature from the base64 encoding
+// of the unarmored binary data, without armor headers nor checksum.
+func NewPGPSignatureFromBase64(encoded string) (*PGPSignature, error) {
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in decoding base64 signature")
+	}
+
+	return &PGPSignature{
+		Data: signature,
+	}, nil
+}
+
 // NewClearTextMessage generates a new ClearTextMessage from data and
 // signature.
 func NewClearTextMessage(data []byte, signature []byte) *ClearTextMessage {
@@ -226,6 +272,17 @@ func (msg *PlainMessage) IsBinary() bool {
 	return !msg.TextType
 }
 
+// IsForYourEyesOnly returns whether the message is marked as for your eyes only,
+// and should be displayed but not saved to disk.
+func (msg *PlainMessage) IsForYourEyesOnly() bool {
+	return msg.Filename == constants.ForYourEyesOnlyFilename
+}
+
+// SetForYourEyesOnly marks the message as for your eyes only, replacing its filename.
+func (msg *PlainMessage) SetForYourEyesOnly() {
+	msg.Filename = constants.ForYourEyesOnlyFilename
+}
+
 // getFormattedTime returns the message (latest modification) Time as time.Time.
 func (msg *PlainMessage) getFormattedTime() time.Time {
 	return time.Unix(int64(msg.Time), 0)
@@ -253,6 +310,12 @@ func (msg *PGPMessage) GetArmoredWithCustomHeaders(comment, version string) (str
 	return armor.ArmorWithTypeAndCustomHeaders(msg.Data, constants.PGPMessageHeader, version, comment)
 }
 
+// GetBase64 returns the base64 encoding of the unarmored binary message,
+// without armor headers nor checksum.
+func (msg *PGPMessage) GetBase64() string {
+	return base64.StdEncoding.EncodeToString(msg.Data)
+}
+
 // GetEncryptionKeyIDs Returns the key IDs of the keys to which the session key is encrypted.
 func (msg *PGPMessage) GetEncryptionKeyIDs() ([]uint64, bool) {
 	packets := packet.NewReader(bytes.NewReader(msg.Data))
@@ -307,6 +370,16 @@ func (msg *PGPSplitMessage) GetBinaryKeyPacket() []byte {
 	return msg.KeyPacket
 }
 
+// GetBase64DataPacket returns the base64 encoding of the binary datapacket.
+func (msg *PGPSplitMessage) GetBase64DataPacket() string {
+	return base64.StdEncoding.EncodeToString(msg.DataPacket)
+}
+
+// GetBase64KeyPacket returns the base64 encoding of the binary keypacket.
+func (msg *PGPSplitMessage) GetBase64KeyPacket() string {
+	return base64.StdEncoding.EncodeToString(msg.KeyPacket)
+}
+
 // GetBinary returns the unarmored binary joined packets as a []byte.
 func (msg *PGPSplitMessage) GetBinary() []byte {
 	return append(msg.KeyPacket, msg.DataPacket...)
@@ -369,6 +442,12 @@ func (sig *PGPSignature) GetArmored() (string, error) {
 	return armor.ArmorWithType(sig.Data, constants.PGPSignatureHeader)
 }
 
+// GetBase64 returns the base64 encoding of the unarmored binary signature,
+// without armor headers nor checksum.
+func (sig *PGPSignature) GetBase64() string {
+	return base64.StdEncoding.EncodeToString(sig.Data)
+}
+
 // GetSignatureKeyIDs Returns the key IDs of the keys to which the (readable) signature packets are encrypted to.
 func (sig *PGPSignature) GetSignatureKeyIDs() ([]uint64, bool) {
 	return getSignatureKeyIDs(sig.Data)
@@ -395,15 +474,15 @@ func (msg *ClearTextMessage) GetBinarySignature() []byte {
 }
 
 // GetArmored armors plaintext and signature with the PGP SIGNED MESSAGE
-// armoring.
+// armoring, with the Hash header of the signature and the dash-escaped plaintext.
 func (msg *ClearTextMessage) GetArmored() (string, error) {
 	armSignature, err := armor.ArmorWithType(msg.GetBinarySignature(), constants.PGPSignatureHeader)
 	if err != nil {
 		return "", errors.Wrap(err, "gopenpgp: error in armoring cleartext message")
 	}
 
-	str := "-----BEGIN PGP SIGNED MESSAGE-----\r\nHash: SHA512\r\n\r\n"
-	str += msg.GetString()
+	str := "-----BEGIN PGP SIGNED MESSAGE-----\r\nHash: " + getClearTextHashHeader(msg.GetBinarySignature()) + "\r\n\r\n"
+	str += dashEscape(msg.GetString())
 	str += "\r\n"
 	str += armSignature
 
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -500,3 +501,77 @@ vA==
 		t.Error("Data packet was nil")
 	}
 }
+
+func TestMessageBase64(t *testing.T) {
+	message := NewPlainMessageFromString("plain text")
+	ciphertext, err := keyRingTestPublic.Encrypt(message, keyRingTestPrivate)
+	if err != nil {
+		t.Fatal("Expected no error when encrypting, got:", err)
+	}
+
+	decoded, err := NewPGPMessageFromBase64(ciphertext.GetBase64())
+	if err != nil {
+		t.Fatal("Expected no error when decoding the message, got:", err)
+	}
+	assert.Exactly(t, ciphertext.GetBinary(), decoded.GetBinary())
+
+	split, err := ciphertext.SplitMessage()
+	if err != nil {
+		t.Fatal("Expected no error when splitting, got:", err)
+	}
+	decodedSplit, err := NewPGPSplitMessageFromBase64(split.GetBase64KeyPacket(), split.GetBase64DataPacket())
+	if err != nil {
+		t.Fatal("Expected no error when decoding the split message, got:", err)
+	}
+	assert.Exactly(t, split, decodedSplit)
+	assert.Exactly(t, base64.StdEncoding.EncodeToString(split.GetBinaryKeyPacket()), split.GetBase64KeyPacket())
+
+	signature, err := keyRingTestPrivate.SignDetached(message)
+	if err != nil {
+		t.Fatal("Expected no error when signing, got:", err)
+	}
+	decodedSignature, err := NewPGPSignatureFromBase64(signature.GetBase64())
+	if err != nil {
+		t.Fatal("Expected no error when decoding the signature, got:", err)
+	}
+	assert.Exactly(t, signature, decodedSignature)
+
+	_, err = NewPGPMessageFromBase64("-----BEGIN PGP MESSAGE-----")
+	assert.Error(t, err)
+}
+
+func TestForYourEyesOnlyMessage(t *testing.T) {
+	message := NewPlainMessageFromString("do not save")
+	assert.False(t, message.IsForYourEyesOnly())
+	message.SetForYourEyesOnly()
+	assert.True(t, message.IsForYourEyesOnly())
+
+	ciphertext, err := keyRingTestPublic.Encrypt(message, nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	decrypted, err := keyRingTestPrivate.Decrypt(ciphertext, nil, 0)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.True(t, decrypted.IsForYourEyesOnly())
+	assert.Equal(t, "do not save", decrypted.GetString())
+
+	var buffer bytes.Buffer
+	metadata := NewPlainMessageMetadata(false, constants.ForYourEyesOnlyFilename, GetUnixTime())
+	plaintextWriter, err := keyRingTestPublic.EncryptStream(&buffer, metadata, nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if _, err = plaintextWriter.Write([]byte("do not save")); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if err = plaintextWriter.Close(); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	reader, err := keyRingTestPrivate.DecryptStream(&buffer, nil, 0)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.True(t, reader.GetMetadata().IsForYourEyesOnly())
+}
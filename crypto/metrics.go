@@ -0,0 +1,127 @@
+package crypto
+
+import (
+	goerrors "errors"
+	"io"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+)
+
+// MetricsCollector is notified of the operations of the library, e.g. to export them
+// as expvar or Prometheus metrics, and detect anomalies such as spikes of bad signatures.
+// Its methods are called synchronously, from any goroutine: they must be fast and safe for concurrent use.
+type MetricsCollector interface {
+	// OnOperation is called when an operation completes, with its name (one of constants.Operation*),
+	// the number of plaintext bytes processed (0 for verifications), and whether it succeeded.
+	// Streaming encryptions complete when the plaintext writer is closed,
+	// and streaming decryptions when the plaintext is read until EOF.
+	OnOperation(operation string, bytes int64, success bool)
+	// OnVerificationFailure is called when a signature verification fails, with the reason,
+	// one of constants.SIGNATURE_* other than SIGNATURE_OK.
+	OnVerificationFailure(status int)
+}
+
+// SetMetricsCollector sets the collector notified of the encryptions, decryptions,
+// signatures and verifications. If collector is nil, which is the default, no metrics are collected.
+func SetMetricsCollector(collector MetricsCollector) {
+	pgp.lock.Lock()
+	defer pgp.lock.Unlock()
+
+	pgp.metricsCollector = collector
+}
+
+// ----- INTERNAL FUNCTIONS -----
+
+func getMetricsCollector() MetricsCollector {
+	pgp.lock.RLock()
+	defer pgp.lock.RUnlock()
+
+	return pgp.metricsCollector
+}
+
+// reportOperation notifies the metrics collector, if any, of a completed operation.
+func reportOperation(operation string, bytes int64, err error) {
+	if collector := getMetricsCollector(); collector != nil {
+		collector.OnOperation(operation, bytes, err == nil)
+	}
+}
+
+// reportVerification notifies the metrics collector, if any, of a signature verification.
+func reportVerification(err error) {
+	collector := getMetricsCollector()
+	if collector == nil {
+		return
+	}
+	collector.OnOperation(constants.OperationVerify, 0, err == nil)
+	var sigErr SignatureVerificationError
+	if goerrors.As(err, &sigErr) {
+		collector.OnVerificationFailure(sigErr.Status)
+	}
+}
+
+// metricsWriteCloser reports the operation with the number of bytes written when it is closed.
+type metricsWriteCloser struct {
+	io.WriteCloser
+	operation string
+	bytes     int64
+}
+
+func (w *metricsWriteCloser) Write(b []byte) (n int, err error) {
+	n, err = w.WriteCloser.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *metricsWriteCloser) Close() error {
+	err := w.WriteCloser.Close()
+	reportOperation(w.operation, w.bytes, err)
+	return err
+}
+
+// countedReader counts the bytes read from the wrapped reader.
+type countedReader struct {
+	io.Reader
+	bytes int64
+}
+
+func (r *countedReader) Read(b []byte) (n int, err error) {
+	n, err = r.Reader.Read(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// metricsReader reports the operation with the number of bytes read when it is read until EOF, or fails.
+type metricsReader struct {
+	countedReader
+	operation string
+	reported  bool
+}
+
+func (r *metricsReader) Read(b []byte) (n int, err error) {
+	n, err = r.countedReader.Read(b)
+	if err != nil && !r.reported {
+		r.reported = true
+		if goerrors.Is(err, io.EOF) {
+			reportOperation(r.operation, r.bytes, nil)
+		} else {
+			reportOperation(r.operation, r.bytes, err)
+		}
+	}
+	return n, err
+}
+
+// withWriteMetrics wraps the writer to report the operation, if a metrics collector is set.
+func withWriteMetrics(w io.WriteCloser, operation string) io.WriteCloser {
+	if getMetricsCollector() == nil {
+		return w
+	}
+	return &metricsWriteCloser{WriteCloser: w, operation: operation}
+}
+
+// withReadMetrics wraps the reader to report the operation, if a metrics collector is set.
+func withReadMetrics(r io.Reader, operation string) io.Reader {
+	if getMetricsCollector() == nil {
+		return r
+	}
+	return &metricsReader{countedReader: countedReader{Reader: r}, operation: operation}
+}
@@ -82,22 +82,34 @@ func separateSigError(err error) (*SignatureVerificationError, error) {
 func parseMIME(
 	mimeBody string, verifierKey *KeyRing,
 ) (*gomime.BodyCollector, []string, []string, error) {
+	printAccepter := gomime.NewMIMEPrinter()
+	bodyCollector := gomime.NewBodyCollector(printAccepter)
+	attachmentsCollector := gomime.NewAttachmentsCollector(bodyCollector)
+
+	err := visitMIME(mimeBody, verifierKey, attachmentsCollector)
+
+	return bodyCollector,
+		attachmentsCollector.GetAttachments(),
+		attachmentsCollector.GetAttHeaders(),
+		err
+}
+
+// visitMIME visits the parts of the MIME message with the acceptor,
+// and verifies the MIME signature of the message, if any, with verifierKey.
+func visitMIME(mimeBody string, verifierKey *KeyRing, acceptor gomime.VisitAcceptor) error {
 	mm, err := mail.ReadMessage(strings.NewReader(mimeBody))
 	if err != nil {
-		return nil, nil, nil, errors.Wrap(err, "gopenpgp: error in reading message")
+		return errors.Wrap(err, "gopenpgp: error in reading message")
 	}
 	config := &packet.Config{DefaultCipher: packet.CipherAES256, Time: getTimeGenerator()}
 
 	h := textproto.MIMEHeader(mm.Header)
 	mmBodyData, err := ioutil.ReadAll(mm.Body)
 	if err != nil {
-		return nil, nil, nil, errors.Wrap(err, "gopenpgp: error in reading message body data")
+		return errors.Wrap(err, "gopenpgp: error in reading message body data")
 	}
 
-	printAccepter := gomime.NewMIMEPrinter()
-	bodyCollector := gomime.NewBodyCollector(printAccepter)
-	attachmentsCollector := gomime.NewAttachmentsCollector(bodyCollector)
-	mimeVisitor := gomime.NewMimeVisitor(attachmentsCollector)
+	mimeVisitor := gomime.NewMimeVisitor(acceptor)
 
 	var verifierEntities openpgp.KeyRing
 	if verifierKey != nil {
@@ -110,9 +122,5 @@ func parseMIME(
 	if err == nil && verifierKey != nil {
 		err = signatureCollector.verified
 	}
-
-	return bodyCollector,
-		attachmentsCollector.GetAttachments(),
-		attachmentsCollector.GetAttHeaders(),
-		err
+	return err
 }
@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	gomime "github.com/ProtonMail/go-mime"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+// MIMEAttachmentSinks provides the writers to which the attachments of a MIME message
+// are streamed by DecryptMIMEMessageToSinks.
+type MIMEAttachmentSinks interface {
+	// GetAttachmentWriter returns the writer for the attachment with the given headers,
+	// content ID (without angle brackets) and filename, any of which can be empty,
+	// or nil to skip the attachment. The writer is closed once the attachment is written.
+	GetAttachmentWriter(headers string, contentID string, filename string) (WriteCloser, error)
+}
+
+// DecryptMIMEMessageToSinks decrypts a MIME message as DecryptMIMEMessage,
+// but streams the attachments, decoded from their transfer encoding,
+// into the writers provided by sinks instead of passing them to callbacks.OnAttachment,
+// which is not called. The attachments are not converted to UTF-8.
+// The attachments are written before callbacks.OnVerified is called.
+func (keyRing *KeyRing) DecryptMIMEMessageToSinks(
+	message *PGPMessage, verifyKey *KeyRing, callbacks MIMECallbacks, sinks MIMEAttachmentSinks, verifyTime int64,
+) {
+	decryptedMessage, err := keyRing.Decrypt(message, verifyKey, verifyTime)
+	embeddedSigError, err := separateSigError(err)
+	if err != nil {
+		callbacks.OnError(err)
+		return
+	}
+	bodyCollector := gomime.NewBodyCollector(gomime.NewMIMEPrinter())
+	sinkCollector := &attachmentSinkCollector{target: bodyCollector, sinks: sinks}
+	err = visitMIME(string(decryptedMessage.GetBinary()), verifyKey, sinkCollector)
+	mimeSigError, err := separateSigError(err)
+	if err != nil {
+		callbacks.OnError(err)
+		return
+	}
+	// We only consider the signature to be failed if both embedded and mime verification failed
+	if embeddedSigError != nil && mimeSigError != nil {
+		callbacks.OnError(embeddedSigError)
+		callbacks.OnError(mimeSigError)
+		callbacks.OnVerified(prioritizeSignatureErrors(embeddedSigError, mimeSigError))
+	} else if verifyKey != nil {
+		callbacks.OnVerified(constants.SIGNATURE_OK)
+	}
+	bodyContent, bodyMimeType := bodyCollector.GetBody()
+	callbacks.OnBody(sanitizeString(bodyContent), bodyMimeType)
+	callbacks.OnEncryptedHeaders("")
+}
+
+// attachmentSinkCollector streams the attachments to the writers of the sinks,
+// and forwards the other parts to the target.
+type attachmentSinkCollector struct {
+	target gomime.VisitAcceptor
+	sinks  MIMEAttachmentSinks
+}
+
+func (ac *attachmentSinkCollector) Accept(
+	partReader io.Reader, header textproto.MIMEHeader, hasPlainSibling bool, isFirst, isLast bool,
+) error {
+	if isFirst && gomime.IsLeaf(header) && isAttachment(header) {
+		if err := ac.writeAttachment(partReader, header); err != nil {
+			return err
+		}
+		// The content was consumed, the target only sees the headers
+		return ac.target.Accept(bytes.NewReader(nil), header, hasPlainSibling, isFirst, isLast)
+	}
+	return ac.target.Accept(partReader, header, hasPlainSibling, isFirst, isLast)
+}
+
+func (ac *attachmentSinkCollector) writeAttachment(partReader io.Reader, header textproto.MIMEHeader) error {
+	headerBuf := new(bytes.Buffer)
+	if err := http.Header(header).Write(headerBuf); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to write the attachment headers")
+	}
+	contentID := strings.Trim(header.Get("Content-Id"), "<>")
+	writer, err := ac.sinks.GetAttachmentWriter(headerBuf.String(), contentID, getAttachmentFilename(header))
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to get the attachment writer")
+	}
+	if writer == nil {
+		return nil
+	}
+	decodedPart := gomime.DecodeContentEncoding(partReader, header.Get("Content-Transfer-Encoding"))
+	if decodedPart == nil {
+		// Unsupported transfer encoding, write the part as is
+		decodedPart = partReader
+	}
+	if _, err := io.Copy(writer, decodedPart); err != nil {
+		_ = writer.Close()
+		return errors.Wrap(err, "gopenpgp: unable to write the attachment")
+	}
+	if err := writer.Close(); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to close the attachment writer")
+	}
+	return nil
+}
+
+// isAttachment returns true if the leaf part is handled as an attachment,
+// as gomime.AttachmentsCollector does.
+func isAttachment(header textproto.MIMEHeader) bool {
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	disposition, _, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	return (mediaType != "text/html" && mediaType != "text/plain") || disposition == "attachment"
+}
+
+// getAttachmentFilename returns the filename of the Content-Disposition header,
+// or the name of the Content-Type header.
+func getAttachmentFilename(header textproto.MIMEHeader) string {
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		return params["filename"]
+	}
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil {
+		return params["name"]
+	}
+	return ""
+}
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"errors"
 	"io/ioutil"
 	"path/filepath"
@@ -340,3 +341,58 @@ func TestMessageVerificationNoVerifierFailed(t *testing.T) {
 	expectedStatus := []int{3}
 	compareStatus(expectedStatus, callbackResults.onVerified, t)
 }
+
+type testAttachmentSink struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (sink *testAttachmentSink) Close() error {
+	sink.closed = true
+	return nil
+}
+
+type testMIMEAttachmentSinks struct {
+	headers []string
+	sinks   map[string]*testAttachmentSink
+}
+
+func (ts *testMIMEAttachmentSinks) GetAttachmentWriter(headers, contentID, filename string) (WriteCloser, error) {
+	ts.headers = append(ts.headers, headers)
+	if filename == "signature.asc" {
+		return nil, nil
+	}
+	sink := &testAttachmentSink{}
+	ts.sinks[filename] = sink
+	return sink, nil
+}
+
+func TestDecryptMIMEMessageToSinks(t *testing.T) {
+	mimeMessage := readTestFile("mime_testMessage", false)
+	_, attachments, attachmentHeaders, err := parseMIME(mimeMessage, nil)
+	if err != nil {
+		t.Fatal("Expected no error while parsing message, got:", err)
+	}
+
+	message, err := keyRingTestPublic.Encrypt(NewPlainMessage([]byte(mimeMessage)), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	callbacks := &testMIMECallbacks{}
+	sinks := &testMIMEAttachmentSinks{sinks: make(map[string]*testAttachmentSink)}
+	keyRingTestPrivate.DecryptMIMEMessageToSinks(message, nil, callbacks, sinks, GetUnixTime())
+
+	assert.Empty(t, callbacks.onError)
+	assert.Empty(t, callbacks.onAttachment)
+	assert.Len(t, callbacks.onBody, 1)
+	assert.Exactly(t, readTestFile("mime_decodedBody", true), callbacks.onBody[0].body)
+	assert.Exactly(t, attachmentHeaders, sinks.headers)
+
+	assert.Len(t, sinks.sinks, 2)
+	screenshot := sinks.sinks["Screenshot from 2018-02-06 17-13-21.png"]
+	assert.True(t, screenshot.closed)
+	assert.Exactly(t, []byte(attachments[0]), screenshot.Bytes())
+	publicKey := sinks.sinks["publickey - kaykeytest3@protonmail.com - 0xE1DADAE3.asc"]
+	assert.True(t, publicKey.closed)
+	assert.Exactly(t, []byte(attachments[1]), publicKey.Bytes())
+}
@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	"fmt"
+	"math"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PassphrasePolicy sets the minimum quality of the passphrases used to lock keys
+// with Key.LockWithPolicy. The default policy doesn't restrict passphrases.
+type PassphrasePolicy struct {
+	minLength      int
+	minEntropyBits float64
+}
+
+// WeakPassphraseError is returned when a passphrase doesn't satisfy a PassphrasePolicy.
+type WeakPassphraseError struct {
+	Message string
+}
+
+// Error is the base method for all errors.
+func (e WeakPassphraseError) Error() string {
+	return fmt.Sprintf("Weak Passphrase Error: %v", e.Message)
+}
+
+// NewPassphrasePolicy creates a passphrase policy without restrictions.
+func NewPassphrasePolicy() *PassphrasePolicy {
+	return &PassphrasePolicy{}
+}
+
+// WithMinLength sets the minimum length of the passphrases, in characters, and returns the policy.
+func (policy *PassphrasePolicy) WithMinLength(minLength int) *PassphrasePolicy {
+	policy.minLength = minLength
+	return policy
+}
+
+// WithMinEntropy sets the minimum estimated entropy of the passphrases, in bits, and returns the policy.
+// The entropy is estimated from the length of the passphrase and the classes of characters it contains
+// (lowercase and uppercase letters, digits, symbols and other characters), assuming they are random.
+// It is an upper bound of the actual entropy, e.g. for dictionary words.
+func (policy *PassphrasePolicy) WithMinEntropy(minEntropyBits float64) *PassphrasePolicy {
+	policy.minEntropyBits = minEntropyBits
+	return policy
+}
+
+// Check returns a WeakPassphraseError if the passphrase doesn't satisfy the policy.
+func (policy *PassphrasePolicy) Check(passphrase []byte) error {
+	if policy == nil {
+		return nil
+	}
+	if length := utf8.RuneCount(passphrase); length < policy.minLength {
+		return WeakPassphraseError{
+			Message: fmt.Sprintf("passphrase has %d characters, at least %d are required", length, policy.minLength),
+		}
+	}
+	if entropy := EstimatePassphraseEntropy(passphrase); entropy < policy.minEntropyBits {
+		return WeakPassphraseError{
+			Message: fmt.Sprintf(
+				"passphrase has an estimated entropy of %.0f bits, at least %.0f are required",
+				entropy, policy.minEntropyBits,
+			),
+		}
+	}
+	return nil
+}
+
+// EstimatePassphraseEntropy estimates the entropy of a passphrase in bits,
+// as used by PassphrasePolicy.WithMinEntropy.
+func EstimatePassphraseEntropy(passphrase []byte) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range string(passphrase) {
+		switch {
+		case r > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	poolSize := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{
+		{lower, 26},
+		{upper, 26},
+		{digit, 10},
+		{symbol, 33},
+		{other, 100},
+	} {
+		if class.present {
+			poolSize += class.size
+		}
+	}
+	if poolSize == 0 {
+		return 0
+	}
+	return float64(utf8.RuneCount(passphrase)) * math.Log2(float64(poolSize))
+}
+
+// LockWithPolicy locks a copy of the key, as Key.Lock,
+// after checking the passphrase against the policy.
+// It returns a WeakPassphraseError if the passphrase doesn't satisfy the policy.
+func (key *Key) LockWithPolicy(passphrase []byte, policy *PassphrasePolicy) (*Key, error) {
+	if err := policy.Check(passphrase); err != nil {
+		return nil, err
+	}
+	return key.Lock(passphrase)
+}
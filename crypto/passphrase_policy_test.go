@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimatePassphraseEntropy(t *testing.T) {
+	assert.Exactly(t, float64(0), EstimatePassphraseEntropy(nil))
+	assert.InDelta(t, 8*4.70, EstimatePassphraseEntropy([]byte("password")), 0.1)
+	assert.InDelta(t, 12*5.95, EstimatePassphraseEntropy([]byte("Password1234")), 0.1)
+}
+
+func TestKeyLockWithPolicy(t *testing.T) {
+	policy := NewPassphrasePolicy().WithMinLength(10).WithMinEntropy(60)
+
+	for _, passphrase := range []string{"Pa55!", "passwordpass"} {
+		_, err := keyTestRSA.LockWithPolicy([]byte(passphrase), policy)
+		var weakErr WeakPassphraseError
+		if !errors.As(err, &weakErr) {
+			t.Fatal("Expected a weak passphrase error while locking the key, got:", err)
+		}
+	}
+
+	locked, err := keyTestRSA.LockWithPolicy([]byte("correct horse battery staple"), policy)
+	if err != nil {
+		t.Fatal("Expected no error while locking the key, got:", err)
+	}
+	isLocked, err := locked.IsLocked()
+	if err != nil {
+		t.Fatal("Expected no error while checking the key, got:", err)
+	}
+	assert.True(t, isLocked)
+
+	if _, err = keyTestRSA.LockWithPolicy([]byte("short"), nil); err != nil {
+		t.Fatal("Expected no error while locking the key without policy, got:", err)
+	}
+}
@@ -93,6 +93,7 @@ func EncryptSessionKeyWithPassword(sk *SessionKey, password []byte) ([]byte, err
 
 	config := &packet.Config{
 		DefaultCipher: cf,
+		Rand:          getRandomSource(),
 	}
 
 	err = packet.SerializeSymmetricKeyEncryptedReuseKey(outbuf, sk.Key, password, config)
@@ -110,6 +111,7 @@ func passwordEncrypt(message *PlainMessage, password []byte) ([]byte, error) {
 	config := &packet.Config{
 		DefaultCipher: packet.CipherAES256,
 		Time:          getTimeGenerator(),
+		Rand:          getRandomSource(),
 	}
 
 	hints := &openpgp.FileHints{
@@ -0,0 +1,38 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"io"
+)
+
+// SetRandomSource sets the source of randomness used to generate keys, session keys,
+// salts and the randomness of the encryption and signature algorithms,
+// e.g. to use a DRBG provided by a hardware security module.
+// If randomSource is nil, crypto/rand.Reader is used, which is the default.
+// NOTE: the source of randomness needs to be cryptographically secure,
+// and safe for concurrent use if the library is used concurrently.
+// While EnableReproducibleEncryption is in effect, the source of randomness
+// is only used once DisableReproducibleEncryption is called.
+func SetRandomSource(randomSource io.Reader) {
+	pgp.lock.Lock()
+	defer pgp.lock.Unlock()
+
+	if pgp.reproducible {
+		pgp.savedRandomSource = randomSource
+		return
+	}
+	pgp.randomSource = randomSource
+}
+
+// ----- INTERNAL FUNCTIONS -----
+
+// getRandomSource returns the configured source of randomness.
+func getRandomSource() io.Reader {
+	pgp.lock.RLock()
+	defer pgp.lock.RUnlock()
+
+	if pgp.randomSource == nil {
+		return rand.Reader
+	}
+	return pgp.randomSource
+}
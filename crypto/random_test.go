@@ -0,0 +1,57 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingReader struct {
+	reader io.Reader
+	count  int
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.reader.Read(b)
+	r.count += n
+	return n, err
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("no randomness")
+}
+
+func TestSetRandomSource(t *testing.T) {
+	defer SetRandomSource(nil)
+
+	SetRandomSource(bytes.NewReader(bytes.Repeat([]byte{0x42}, 32)))
+	sessionKey, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating the session key, got:", err)
+	}
+	assert.Exactly(t, bytes.Repeat([]byte{0x42}, 32), sessionKey.Key)
+
+	source := &countingReader{reader: rand.Reader}
+	SetRandomSource(source)
+	if _, err = keyRingTestPublic.Encrypt(NewPlainMessageFromString(testMessage), nil); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if _, err = keyTestRSA.Lock(testMailboxPassword); err != nil {
+		t.Fatal("Expected no error while locking the key, got:", err)
+	}
+	assert.NotZero(t, source.count)
+
+	SetRandomSource(failingReader{})
+	if _, err = GenerateSessionKey(); err == nil {
+		t.Fatal("Expected an error while generating a session key without randomness, got nil")
+	}
+	if _, err = keyRingTestPublic.Encrypt(NewPlainMessageFromString(testMessage), nil); err == nil {
+		t.Fatal("Expected an error while encrypting without randomness, got nil")
+	}
+}
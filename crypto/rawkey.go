@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"strconv"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+// NewSessionKeyFromRawKey creates a session key from a raw symmetric key
+// already derived by the application, e.g. obtained from a KMS or an SRP exchange.
+// The AES variant is selected by the length of the key, of 16, 24 or 32 bytes.
+func NewSessionKeyFromRawKey(key []byte) (*SessionKey, error) {
+	var algo string
+	switch len(key) {
+	case 16:
+		algo = constants.AES128
+	case 24:
+		algo = constants.AES192
+	case 32:
+		algo = constants.AES256
+	default:
+		return nil, errors.New("gopenpgp: invalid raw key length " + strconv.Itoa(len(key)) + ", expected 16, 24 or 32 bytes")
+	}
+	return NewSessionKeyFromToken(clone(key), algo), nil
+}
+
+// EncryptMessageWithRawKey encrypts a PlainMessage to a PGPMessage with a raw symmetric key,
+// without S2K derivation: the message only contains a symmetrically encrypted data packet
+// keyed by the raw key, and no key packet.
+// * message : The plain data as a PlainMessage.
+// * key     : A 16, 24 or 32 bytes AES key.
+// * output  : The encrypted data as PGPMessage.
+func EncryptMessageWithRawKey(message *PlainMessage, key []byte) (*PGPMessage, error) {
+	sessionKey, err := NewSessionKeyFromRawKey(key)
+	if err != nil {
+		return nil, err
+	}
+	dataPacket, err := sessionKey.Encrypt(message)
+	if err != nil {
+		return nil, err
+	}
+	return NewPGPMessage(dataPacket), nil
+}
+
+// DecryptMessageWithRawKey decrypts a message encrypted by EncryptMessageWithRawKey.
+// * message: The encrypted data as PGPMessage.
+// * key    : The 16, 24 or 32 bytes AES key.
+// * output : The decrypted data as PlainMessage.
+func DecryptMessageWithRawKey(message *PGPMessage, key []byte) (*PlainMessage, error) {
+	sessionKey, err := NewSessionKeyFromRawKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return sessionKey.Decrypt(message.GetBinary())
+}
+
+// EncryptStreamWithRawKey is used to encrypt data as a Writer with a raw symmetric key,
+// as EncryptMessageWithRawKey.
+// It takes a writer for the encrypted message and returns a writer for the plaintext data.
+func EncryptStreamWithRawKey(
+	pgpMessageWriter Writer,
+	key []byte,
+	plainMessageMetadata *PlainMessageMetadata,
+) (plainMessageWriter WriteCloser, err error) {
+	sessionKey, err := NewSessionKeyFromRawKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return sessionKey.EncryptStream(pgpMessageWriter, plainMessageMetadata, nil)
+}
+
+// DecryptStreamWithRawKey is used to decrypt a message encrypted with a raw symmetric key as a Reader.
+// It takes a reader for the encrypted message and returns a PlainMessageReader for the plaintext data.
+func DecryptStreamWithRawKey(pgpMessageReader Reader, key []byte) (plainMessage *PlainMessageReader, err error) {
+	sessionKey, err := NewSessionKeyFromRawKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return sessionKey.DecryptStream(pgpMessageReader, nil, 0)
+}
@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRawKeyEncryption(t *testing.T) {
+	key, err := RandomToken(32)
+	if err != nil {
+		t.Fatal("Expected no error while generating the raw key, got:", err)
+	}
+	message := NewPlainMessageFromString(testMessage)
+
+	encrypted, err := EncryptMessageWithRawKey(message, key)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting with the raw key, got:", err)
+	}
+	// The message only contains the data packet
+	p, err := packet.Read(bytes.NewReader(encrypted.GetBinary()))
+	if err != nil {
+		t.Fatal("Expected no error while reading the packet, got:", err)
+	}
+	_, ok := p.(*packet.SymmetricallyEncrypted)
+	assert.True(t, ok)
+
+	decrypted, err := DecryptMessageWithRawKey(encrypted, key)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with the raw key, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+
+	wrongKey := clone(key)
+	wrongKey[0] ^= 1
+	_, err = DecryptMessageWithRawKey(encrypted, wrongKey)
+	assert.Error(t, err)
+
+	_, err = EncryptMessageWithRawKey(message, key[:20])
+	assert.Error(t, err)
+}
+
+func TestRawKeyEncryptionStream(t *testing.T) {
+	key, err := RandomToken(16)
+	if err != nil {
+		t.Fatal("Expected no error while generating the raw key, got:", err)
+	}
+	var ciphertext bytes.Buffer
+	plaintextWriter, err := EncryptStreamWithRawKey(&ciphertext, key, nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting with the raw key, got:", err)
+	}
+	if _, err = plaintextWriter.Write([]byte(testMessage)); err != nil {
+		t.Fatal("Expected no error while writing the plaintext, got:", err)
+	}
+	if err = plaintextWriter.Close(); err != nil {
+		t.Fatal("Expected no error while closing the plaintext writer, got:", err)
+	}
+
+	reader, err := DecryptStreamWithRawKey(&ciphertext, key)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting with the raw key, got:", err)
+	}
+	decrypted, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal("Expected no error while reading the plaintext, got:", err)
+	}
+	assert.Exactly(t, testMessage, string(decrypted))
+}
@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// EnableReproducibleEncryption makes the output of the library deterministic,
+// to generate test vectors and golden files: the source of randomness is replaced
+// with a deterministic stream derived from the seed, and the current time is fixed to unixTime.
+// The session keys, the randomness of the key packets and the timestamps of the messages,
+// signatures and keys are then reproducible, given the same sequence of calls.
+// WARNING: this is insecure, and must only be used in tests.
+// NOTE: RSA encryption is not reproducible, as the Go standard library ignores
+// custom sources of randomness for it.
+func EnableReproducibleEncryption(seed []byte, unixTime int64) error {
+	if len(seed) == 0 {
+		return errors.New("gopenpgp: empty seed for reproducible encryption")
+	}
+	if unixTime <= 0 {
+		return errors.New("gopenpgp: invalid time for reproducible encryption")
+	}
+	source, err := newDeterministicReader(seed)
+	if err != nil {
+		return err
+	}
+
+	pgp.lock.Lock()
+	defer pgp.lock.Unlock()
+
+	if !pgp.reproducible {
+		pgp.savedRandomSource = pgp.randomSource
+		pgp.reproducible = true
+	}
+	pgp.randomSource = source
+	pgp.fixedTime = unixTime
+	return nil
+}
+
+// DisableReproducibleEncryption restores the source of randomness in use before
+// EnableReproducibleEncryption, e.g. set with SetRandomSource, and the current time.
+func DisableReproducibleEncryption() {
+	pgp.lock.Lock()
+	defer pgp.lock.Unlock()
+
+	if pgp.reproducible {
+		pgp.randomSource = pgp.savedRandomSource
+		pgp.savedRandomSource = nil
+		pgp.reproducible = false
+	}
+	pgp.fixedTime = 0
+}
+
+// deterministicReader is the key stream of AES-256-CTR keyed with the SHA-256 hash of a seed.
+type deterministicReader struct {
+	stream cipher.Stream
+	lock   sync.Mutex
+}
+
+func newDeterministicReader(seed []byte) (*deterministicReader, error) {
+	key := sha256.Sum256(seed)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to initialize the deterministic random source")
+	}
+	return &deterministicReader{
+		stream: cipher.NewCTR(block, make([]byte, aes.BlockSize)),
+	}, nil
+}
+
+func (r *deterministicReader) Read(b []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i := range b {
+		b[i] = 0
+	}
+	r.stream.XORKeyStream(b, b)
+	return len(b), nil
+}
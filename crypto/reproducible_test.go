@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableReproducibleEncryption(t *testing.T) {
+	defer DisableReproducibleEncryption()
+	const reproducibleTime = testTime - 3600
+
+	encrypt := func(seed string) (*KeyRing, []byte) {
+		if err := EnableReproducibleEncryption([]byte(seed), reproducibleTime); err != nil {
+			t.Fatal("Expected no error while enabling reproducible encryption, got:", err)
+		}
+		key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+		if err != nil {
+			t.Fatal("Expected no error while generating the key, got:", err)
+		}
+		keyRing, err := NewKeyRing(key)
+		if err != nil {
+			t.Fatal("Expected no error while creating the keyring, got:", err)
+		}
+		message, err := keyRing.Encrypt(NewPlainMessageFromString(testMessage), keyRing)
+		if err != nil {
+			t.Fatal("Expected no error while encrypting, got:", err)
+		}
+		return keyRing, message.GetBinary()
+	}
+
+	keyRing, ciphertext := encrypt("seed")
+	_, otherCiphertext := encrypt("seed")
+	assert.Exactly(t, ciphertext, otherCiphertext)
+	_, otherCiphertext = encrypt("other seed")
+	assert.NotEqual(t, ciphertext, otherCiphertext)
+
+	decrypted, err := keyRing.Decrypt(NewPGPMessage(ciphertext), keyRing, reproducibleTime)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	assert.Exactly(t, uint32(reproducibleTime), decrypted.Time)
+
+	DisableReproducibleEncryption()
+	assert.NotEqual(t, int64(reproducibleTime), GetUnixTime())
+	assert.Error(t, EnableReproducibleEncryption(nil, reproducibleTime))
+}
+
+func TestDisableReproducibleEncryptionRestoresRandomSource(t *testing.T) {
+	source := bytes.NewReader(make([]byte, 64))
+	SetRandomSource(source)
+	defer SetRandomSource(nil)
+
+	if err := EnableReproducibleEncryption([]byte("seed"), testTime); err != nil {
+		t.Fatal("Expected no error while enabling reproducible encryption, got:", err)
+	}
+	if err := EnableReproducibleEncryption([]byte("other seed"), testTime); err != nil {
+		t.Fatal("Expected no error while enabling reproducible encryption, got:", err)
+	}
+	assert.NotEqual(t, source, getRandomSource())
+	DisableReproducibleEncryption()
+	assert.Equal(t, source, getRandomSource())
+}
+
+func TestSetRandomSourceDuringReproducibleEncryption(t *testing.T) {
+	defer SetRandomSource(nil)
+
+	if err := EnableReproducibleEncryption([]byte("seed"), testTime); err != nil {
+		t.Fatal("Expected no error while enabling reproducible encryption, got:", err)
+	}
+	reproducibleSource := getRandomSource()
+
+	source := bytes.NewReader(make([]byte, 64))
+	SetRandomSource(source)
+	assert.Equal(t, reproducibleSource, getRandomSource())
+
+	DisableReproducibleEncryption()
+	assert.Equal(t, source, getRandomSource())
+}
@@ -2,6 +2,8 @@ package crypto
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/ProtonMail/gopenpgp/v2/constants"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 	pgpErrors "github.com/ProtonMail/go-crypto/openpgp/errors"
@@ -69,9 +72,15 @@ func (sk *SessionKey) GetBase64Key() string {
 	return base64.StdEncoding.EncodeToString(sk.Key)
 }
 
+// sessionKeyMixingInfo is the HKDF info used to mix additional entropy into session keys.
+const sessionKeyMixingInfo = "gopenpgp session key entropy mixing"
+
+// convergentSessionKeyInfo is the HKDF info used to derive convergent session keys.
+const convergentSessionKeyInfo = "gopenpgp convergent session key"
+
 // RandomToken generates a random token with the specified key size.
 func RandomToken(size int) ([]byte, error) {
-	config := &packet.Config{DefaultCipher: packet.CipherAES256}
+	config := &packet.Config{DefaultCipher: packet.CipherAES256, Rand: getRandomSource()}
 	symKey := make([]byte, size)
 	if _, err := io.ReadFull(config.Random(), symKey); err != nil {
 		return nil, errors.Wrap(err, "gopenpgp: error in generating random token")
@@ -103,6 +112,80 @@ func GenerateSessionKey() (*SessionKey, error) {
 	return GenerateSessionKeyAlgo(constants.AES256)
 }
 
+// GenerateSessionKeyAlgoWithEntropy generates a random key of the correct length for the
+// specified algorithm, mixed with additional entropy provided by the caller.
+// The random key and the additional entropy are combined with HKDF-SHA256,
+// hence the session key is unpredictable as long as one of the two sources is.
+func GenerateSessionKeyAlgoWithEntropy(algo string, entropy []byte) (*SessionKey, error) {
+	if len(entropy) == 0 {
+		return nil, errors.New("gopenpgp: no additional entropy provided")
+	}
+	sk, err := GenerateSessionKeyAlgo(algo)
+	if err != nil {
+		return nil, err
+	}
+	defer sk.Clear()
+
+	inputKey := make([]byte, 0, len(sk.Key)+len(entropy))
+	inputKey = append(append(inputKey, sk.Key...), entropy...)
+	mixedKey := make([]byte, len(sk.Key))
+	if _, err = io.ReadFull(hkdf.New(sha256.New, inputKey, nil, []byte(sessionKeyMixingInfo)), mixedKey); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in mixing entropy into the session key")
+	}
+	for i := range inputKey {
+		inputKey[i] = 0
+	}
+
+	return &SessionKey{
+		Key:  mixedKey,
+		Algo: algo,
+	}, nil
+}
+
+// GenerateSessionKeyWithEntropy generates a random key for the default cipher,
+// mixed with additional entropy provided by the caller, as GenerateSessionKeyAlgoWithEntropy.
+func GenerateSessionKeyWithEntropy(entropy []byte) (*SessionKey, error) {
+	return GenerateSessionKeyAlgoWithEntropy(constants.AES256, entropy)
+}
+
+// DeriveConvergentSessionKeyAlgo derives a session key for the specified algorithm
+// deterministically from the plaintext, keyed with the convergence key provided by the caller:
+// the same plaintext and convergence key always give the same session key, e.g. so that
+// deduplicating storage systems can detect identical files.
+// The session key is derived with HKDF-SHA256 from the HMAC-SHA256 of the plaintext.
+// WARNING: anyone knowing the convergence key can confirm whether a message contains a guessed
+// plaintext, so the convergence key needs to be kept secret, and shared only between the users
+// whose files are deduplicated. Only use this mode explicitly: the other functions of this
+// package always generate random session keys.
+func DeriveConvergentSessionKeyAlgo(algo string, convergenceKey []byte, plaintext Reader) (*SessionKey, error) {
+	cf, ok := symKeyAlgos[algo]
+	if !ok {
+		return nil, errors.New("gopenpgp: unknown symmetric key generation algorithm")
+	}
+	if len(convergenceKey) == 0 {
+		return nil, errors.New("gopenpgp: no convergence key provided")
+	}
+	mac := hmac.New(sha256.New, convergenceKey)
+	if _, err := io.Copy(mac, plaintext); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in reading the plaintext")
+	}
+	key := make([]byte, cf.KeySize())
+	info := []byte(convergentSessionKeyInfo + " " + algo)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, mac.Sum(nil), nil, info), key); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in deriving the convergent session key")
+	}
+	return &SessionKey{
+		Key:  key,
+		Algo: algo,
+	}, nil
+}
+
+// DeriveConvergentSessionKey derives a session key for the default cipher deterministically
+// from the plaintext and the convergence key, as DeriveConvergentSessionKeyAlgo.
+func DeriveConvergentSessionKey(convergenceKey, plaintext []byte) (*SessionKey, error) {
+	return DeriveConvergentSessionKeyAlgo(constants.AES256, convergenceKey, bytes.NewReader(plaintext))
+}
+
 func NewSessionKeyFromToken(token []byte, algo string) *SessionKey {
 	return &SessionKey{
 		Key:  clone(token),
@@ -227,6 +310,7 @@ func encryptStreamWithSessionKey(
 	config := &packet.Config{
 		Time:          getTimeGenerator(),
 		DefaultCipher: dc,
+		Rand:          getRandomSource(),
 	}
 
 	var signEntity *openpgp.Entity
@@ -255,6 +339,13 @@ func encryptStreamWithSessionKey(
 		}
 	}
 
+	if plainMessageMetadata.Attributes != nil {
+		if signEntity == nil {
+			return nil, nil, errors.New("gopenpgp: file attributes can only be recorded in a signed message")
+		}
+		config.SignatureNotations = append(config.SignatureNotations, plainMessageMetadata.Attributes.getNotations()...)
+	}
+
 	return encryptStreamWithSessionKeyAndConfig(
 		plainMessageMetadata.IsBinary,
 		plainMessageMetadata.Filename,
@@ -371,8 +462,7 @@ func decryptWithSessionKeyAndContext(
 	if err != nil {
 		return nil, err
 	}
-	messageBuf := new(bytes.Buffer)
-	_, err = messageBuf.ReadFrom(md.UnverifiedBody)
+	body, err := readAllPlaintext(md.UnverifiedBody)
 	if err != nil {
 		return nil, errors.Wrap(err, "gopenpgp: error in reading message body")
 	}
@@ -383,7 +473,7 @@ func decryptWithSessionKeyAndContext(
 	}
 
 	return &PlainMessage{
-		Data:     messageBuf.Bytes(),
+		Data:     body,
 		TextType: !md.LiteralData.IsBinary,
 		Filename: md.LiteralData.FileName,
 		Time:     md.LiteralData.Time,
@@ -0,0 +1,438 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/eax"
+	"github.com/ProtonMail/go-crypto/ocb"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// aeadVersion is the version of the SEIPDv2 packet.
+	aeadVersion = 2
+	// aeadTag is the tag of the SEIPD packet, in the new packet format, used in the associated data.
+	aeadTag = 0xD2
+	// aeadSaltLength is the length of the salt used to derive the message key.
+	aeadSaltLength = 32
+	// aeadChunkSizeByte sets the size of the chunks to 1 << (12 + 6) bytes, i.e. 256 KiB.
+	aeadChunkSizeByte = 12
+	// aeadMaxChunkSizeByte is the largest chunk size byte allowed by RFC 9580.
+	aeadMaxChunkSizeByte = 16
+	// aeadHeaderLength is the length of the version, cipher, mode, chunk size and salt.
+	aeadHeaderLength = 4 + aeadSaltLength
+)
+
+// EncryptAEAD encrypts the plaintext with the session key in AEAD chunks, as in a SEIPDv2 packet,
+// authenticating the given associated data, and returns the ciphertext.
+// The ciphertext is the body of a SEIPDv2 packet, without the packet header:
+// the version, cipher, AEAD mode (OCB) and chunk size bytes, the salt,
+// the encrypted chunks and the final authentication tag.
+// If associatedData is empty, the ciphertext can be decrypted as the body of a SEIPDv2 packet,
+// otherwise it is appended to the associated data of every chunk and of the final tag.
+// The session key needs to be an AES key, or to have no algorithm as a version 6 session key.
+func (sk *SessionKey) EncryptAEAD(plaintext, associatedData []byte) ([]byte, error) {
+	var ciphertext bytes.Buffer
+	encryptWriter, err := sk.EncryptAEADStream(&ciphertext, associatedData)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = encryptWriter.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err = encryptWriter.Close(); err != nil {
+		return nil, err
+	}
+	return ciphertext.Bytes(), nil
+}
+
+// DecryptAEAD decrypts a ciphertext produced by SessionKey.EncryptAEAD with the session key,
+// authenticating the given associated data, and returns the plaintext.
+func (sk *SessionKey) DecryptAEAD(ciphertext, associatedData []byte) ([]byte, error) {
+	decryptReader, err := sk.DecryptAEADStream(bytes.NewReader(ciphertext), associatedData)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := readAllPlaintext(decryptReader)
+	if err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+// EncryptAEADStream is used to encrypt data with the session key in AEAD chunks, as SessionKey.EncryptAEAD.
+// It takes a writer for the ciphertext and returns a WriteCloser for the plaintext.
+// The header is written to ciphertextWriter immediately, the chunks as they are filled,
+// and the last chunk with the final authentication tag when the WriteCloser is closed.
+// The ciphertextWriter is not closed.
+func (sk *SessionKey) EncryptAEADStream(ciphertextWriter Writer, associatedData []byte) (WriteCloser, error) {
+	cipherFunc, err := sk.aeadCipherFunc(0)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, aeadHeaderLength)
+	header[0] = aeadVersion
+	header[1] = byte(cipherFunc)
+	header[2] = byte(packet.AEADModeOCB)
+	header[3] = aeadChunkSizeByte
+	if _, err = io.ReadFull(getRandomSource(), header[4:]); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in generating the salt")
+	}
+	crypter, err := sk.newAEADChunkCrypter(header, associatedData)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = ciphertextWriter.Write(header); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in writing the header")
+	}
+	return &aeadChunkWriter{
+		aeadChunkCrypter: crypter,
+		writer:           ciphertextWriter,
+		header:           header,
+	}, nil
+}
+
+// DecryptAEADStream is used to decrypt data encrypted with SessionKey.EncryptAEADStream.
+// It takes a reader for the ciphertext and returns a Reader for the plaintext.
+// The chunks are authenticated as they are read, and the end of the data when
+// the final authentication tag is read: an error is returned if the ciphertext is truncated.
+func (sk *SessionKey) DecryptAEADStream(ciphertextReader Reader, associatedData []byte) (Reader, error) {
+	header := make([]byte, aeadHeaderLength)
+	if _, err := io.ReadFull(ciphertextReader, header); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in reading the header")
+	}
+	return sk.newAEADChunkReader(header, ciphertextReader, 0, associatedData)
+}
+
+// DecryptAEADStreamFromOffset is used to decrypt data encrypted with SessionKey.EncryptAEADStream
+// from a plaintext offset, e.g. to decrypt a ranged download of the ciphertext.
+// It takes the header of the ciphertext, i.e. its first GetAEADHeaderLength() bytes,
+// and a reader for the ciphertext from the offset returned by
+// GetAEADCiphertextOffset up to its end, and returns a Reader for the plaintext from the offset.
+// The offset needs to be a multiple of the chunk size. The final authentication tag is only
+// verified at the end of the ciphertext: the chunks before are authenticated individually.
+func (sk *SessionKey) DecryptAEADStreamFromOffset(
+	header []byte,
+	ciphertextReader Reader,
+	plaintextOffset int64,
+	associatedData []byte,
+) (Reader, error) {
+	index, err := getAEADChunkIndex(header, plaintextOffset)
+	if err != nil {
+		return nil, err
+	}
+	return sk.newAEADChunkReader(clone(header), ciphertextReader, index, associatedData)
+}
+
+// GetAEADHeaderLength returns the length of the header of the ciphertexts
+// produced by SessionKey.EncryptAEAD and SessionKey.EncryptAEADStream.
+func GetAEADHeaderLength() int {
+	return aeadHeaderLength
+}
+
+// GetAEADCiphertextOffset returns the offset in the ciphertext with the given header
+// of the chunk starting at the plaintext offset, which needs to be a multiple of the chunk size.
+func GetAEADCiphertextOffset(header []byte, plaintextOffset int64) (int64, error) {
+	index, err := getAEADChunkIndex(header, plaintextOffset)
+	if err != nil {
+		return 0, err
+	}
+	mode := packet.AEADMode(header[2])
+	if mode != packet.AEADModeEAX && mode != packet.AEADModeOCB && mode != packet.AEADModeGCM {
+		return 0, errors.New("gopenpgp: unsupported AEAD mode")
+	}
+	// The tags of all the supported modes are 16 bytes long
+	chunkLength := int64(1)<<(header[3]+6) + 16
+	return aeadHeaderLength + int64(index)*chunkLength, nil
+}
+
+// getAEADChunkIndex checks the header and returns the index of the chunk starting at the plaintext offset.
+func getAEADChunkIndex(header []byte, plaintextOffset int64) (uint64, error) {
+	if len(header) != aeadHeaderLength {
+		return 0, errors.New("gopenpgp: invalid AEAD header length")
+	}
+	if err := checkAEADHeader(header); err != nil {
+		return 0, err
+	}
+	chunkSize := int64(1) << (header[3] + 6)
+	if plaintextOffset < 0 || plaintextOffset%chunkSize != 0 {
+		return 0, errors.New("gopenpgp: the offset is not a multiple of the chunk size")
+	}
+	return uint64(plaintextOffset / chunkSize), nil
+}
+
+func checkAEADHeader(header []byte) error {
+	if header[0] != aeadVersion {
+		return errors.New("gopenpgp: unsupported AEAD version")
+	}
+	if header[3] > aeadMaxChunkSizeByte {
+		return errors.New("gopenpgp: invalid AEAD chunk size")
+	}
+	return nil
+}
+
+// newAEADChunkReader returns a reader decrypting the ciphertext from the chunk with the given index.
+func (sk *SessionKey) newAEADChunkReader(header []byte, ciphertextReader Reader, index uint64, associatedData []byte) (Reader, error) {
+	crypter, err := sk.newAEADChunkCrypter(header, associatedData)
+	if err != nil {
+		return nil, err
+	}
+	crypter.index = index
+	crypter.amount = index * uint64(crypter.chunkSize)
+	return &aeadChunkReader{
+		aeadChunkCrypter: crypter,
+		reader:           ciphertextReader,
+		buffer:           make([]byte, crypter.chunkSize+2*crypter.aead.Overhead()),
+	}, nil
+}
+
+// aeadCipherFunc returns the cipher of the session key, which needs to be AES.
+// If the session key has no algorithm, the cipher is given by headerCipher, if not 0,
+// or by the size of the key.
+func (sk *SessionKey) aeadCipherFunc(headerCipher packet.CipherFunction) (packet.CipherFunction, error) {
+	var cipherFunc packet.CipherFunction
+	if sk.Algo != "" {
+		var err error
+		if cipherFunc, err = sk.GetCipherFunc(); err != nil {
+			return 0, err
+		}
+	} else if headerCipher != 0 {
+		cipherFunc = headerCipher
+	} else {
+		switch len(sk.Key) {
+		case packet.CipherAES128.KeySize():
+			cipherFunc = packet.CipherAES128
+		case packet.CipherAES192.KeySize():
+			cipherFunc = packet.CipherAES192
+		default:
+			cipherFunc = packet.CipherAES256
+		}
+	}
+
+	switch cipherFunc {
+	case packet.CipherAES128, packet.CipherAES192, packet.CipherAES256:
+	default:
+		return 0, errors.New("gopenpgp: AEAD encryption requires an AES session key")
+	}
+	if headerCipher != 0 && headerCipher != cipherFunc {
+		return 0, errors.New("gopenpgp: the session key algorithm doesn't match the encrypted data")
+	}
+	if len(sk.Key) != cipherFunc.KeySize() {
+		return 0, errors.New("gopenpgp: wrong session key size")
+	}
+	return cipherFunc, nil
+}
+
+// newAEADChunkCrypter derives the message key and nonce from the session key and the header,
+// as for SEIPDv2 packets.
+func (sk *SessionKey) newAEADChunkCrypter(header, associatedData []byte) (*aeadChunkCrypter, error) {
+	if err := checkAEADHeader(header); err != nil {
+		return nil, err
+	}
+	cipherFunc, err := sk.aeadCipherFunc(packet.CipherFunction(header[1]))
+	if err != nil {
+		return nil, err
+	}
+	mode := packet.AEADMode(header[2])
+	chunkSizeByte := header[3]
+	salt := header[4:aeadHeaderLength]
+
+	prefix := []byte{aeadTag, aeadVersion, byte(cipherFunc), byte(mode), chunkSizeByte}
+	hkdfReader := hkdf.New(sha256.New, sk.Key, salt, prefix)
+	key := make([]byte, cipherFunc.KeySize())
+	if _, err = io.ReadFull(hkdfReader, key); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in deriving the message key")
+	}
+	aead, err := newAEAD(mode, key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, mode.IvLength()-8)
+	if _, err = io.ReadFull(hkdfReader, nonce); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in deriving the message key")
+	}
+
+	return &aeadChunkCrypter{
+		aead:           aead,
+		nonce:          nonce,
+		prefix:         prefix,
+		associatedData: clone(associatedData),
+		chunkSize:      1 << (chunkSizeByte + 6),
+	}, nil
+}
+
+func newAEAD(mode packet.AEADMode, key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in initializing the cipher")
+	}
+	switch mode {
+	case packet.AEADModeEAX:
+		return eax.NewEAX(block)
+	case packet.AEADModeOCB:
+		return ocb.NewOCB(block)
+	case packet.AEADModeGCM:
+		return newGCM(block)
+	}
+	return nil, errors.New("gopenpgp: unsupported AEAD mode")
+}
+
+// aeadChunkCrypter seals and opens the chunks of SEIPDv2 data.
+type aeadChunkCrypter struct {
+	aead           cipher.AEAD
+	nonce          []byte
+	prefix         []byte
+	associatedData []byte
+	chunkSize      int
+	index          uint64
+	amount         uint64
+}
+
+// nextNonce returns the nonce of the next chunk, i.e. the nonce followed by the chunk index.
+func (crypter *aeadChunkCrypter) nextNonce() []byte {
+	nonce := make([]byte, len(crypter.nonce)+8)
+	copy(nonce, crypter.nonce)
+	binary.BigEndian.PutUint64(nonce[len(crypter.nonce):], crypter.index)
+	return nonce
+}
+
+func (crypter *aeadChunkCrypter) chunkAssociatedData() []byte {
+	adata := make([]byte, 0, len(crypter.prefix)+len(crypter.associatedData))
+	adata = append(adata, crypter.prefix...)
+	return append(adata, crypter.associatedData...)
+}
+
+// finalAssociatedData returns the associated data of the final tag, which includes the amount of plaintext.
+func (crypter *aeadChunkCrypter) finalAssociatedData() []byte {
+	adata := make([]byte, len(crypter.prefix)+8, len(crypter.prefix)+8+len(crypter.associatedData))
+	copy(adata, crypter.prefix)
+	binary.BigEndian.PutUint64(adata[len(crypter.prefix):], crypter.amount)
+	return append(adata, crypter.associatedData...)
+}
+
+func (crypter *aeadChunkCrypter) seal(chunk []byte) []byte {
+	sealed := crypter.aead.Seal(nil, crypter.nextNonce(), chunk, crypter.chunkAssociatedData())
+	crypter.index++
+	crypter.amount += uint64(len(chunk))
+	return sealed
+}
+
+func (crypter *aeadChunkCrypter) open(chunk []byte) ([]byte, error) {
+	opened, err := crypter.aead.Open(nil, crypter.nextNonce(), chunk, crypter.chunkAssociatedData())
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in authenticating a chunk")
+	}
+	crypter.index++
+	crypter.amount += uint64(len(opened))
+	return opened, nil
+}
+
+type aeadChunkWriter struct {
+	*aeadChunkCrypter
+	writer Writer
+	header []byte
+	buffer []byte
+	// plaintextDigest, if not nil, hashes the plaintext of the complete chunks.
+	plaintextDigest hash.Hash
+}
+
+func (w *aeadChunkWriter) Write(b []byte) (int, error) {
+	w.buffer = append(w.buffer, b...)
+	for len(w.buffer) >= w.chunkSize {
+		if w.plaintextDigest != nil {
+			_, _ = w.plaintextDigest.Write(w.buffer[:w.chunkSize])
+		}
+		if _, err := w.writer.Write(w.seal(w.buffer[:w.chunkSize])); err != nil {
+			return 0, errors.Wrap(err, "gopenpgp: error in writing a chunk")
+		}
+		w.buffer = append(w.buffer[:0], w.buffer[w.chunkSize:]...)
+	}
+	return len(b), nil
+}
+
+// Close writes the last chunk, which is empty if there is no data, and the final tag.
+func (w *aeadChunkWriter) Close() error {
+	if len(w.buffer) > 0 || w.amount == 0 {
+		if _, err := w.writer.Write(w.seal(w.buffer)); err != nil {
+			return errors.Wrap(err, "gopenpgp: error in writing a chunk")
+		}
+		w.buffer = nil
+	}
+	finalTag := w.aead.Seal(nil, w.nextNonce(), nil, w.finalAssociatedData())
+	if _, err := w.writer.Write(finalTag); err != nil {
+		return errors.Wrap(err, "gopenpgp: error in writing the final tag")
+	}
+	return nil
+}
+
+type aeadChunkReader struct {
+	*aeadChunkCrypter
+	reader    Reader
+	buffer    []byte
+	buffered  int
+	plaintext []byte
+	eof       bool
+}
+
+func (r *aeadChunkReader) Read(b []byte) (int, error) {
+	for len(r.plaintext) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(b, r.plaintext)
+	r.plaintext = r.plaintext[n:]
+	return n, nil
+}
+
+// readChunk reads and opens the next chunk. The data is read ahead by one tag length,
+// to detect the last chunk, which is followed by the final tag.
+func (r *aeadChunkReader) readChunk() error {
+	tagLength := r.aead.Overhead()
+	n, err := io.ReadFull(r.reader, r.buffer[r.buffered:])
+	r.buffered += n
+	if err == nil {
+		chunkLength := r.chunkSize + tagLength
+		plaintext, err := r.open(r.buffer[:chunkLength])
+		if err != nil {
+			return err
+		}
+		r.buffered = copy(r.buffer, r.buffer[chunkLength:r.buffered])
+		r.plaintext = plaintext
+		return nil
+	}
+	if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return errors.Wrap(err, "gopenpgp: error in reading a chunk")
+	}
+
+	if r.buffered < tagLength {
+		return errors.New("gopenpgp: the encrypted data is truncated")
+	}
+	lastChunk := r.buffer[:r.buffered-tagLength]
+	finalTag := r.buffer[r.buffered-tagLength : r.buffered]
+	var plaintext []byte
+	if len(lastChunk) > 0 {
+		if plaintext, err = r.open(lastChunk); err != nil {
+			return err
+		}
+	} else if r.index == 0 {
+		return errors.New("gopenpgp: the encrypted data is truncated")
+	}
+	if _, err = r.aead.Open(nil, r.nextNonce(), finalTag, r.finalAssociatedData()); err != nil {
+		return errors.Wrap(err, "gopenpgp: error in authenticating the final tag")
+	}
+	r.plaintext = plaintext
+	r.eof = true
+	return nil
+}
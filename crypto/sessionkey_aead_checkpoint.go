@@ -0,0 +1,199 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// aeadCheckpointVersion is the version of the serialized AEADCheckpoint.
+const aeadCheckpointVersion = 2
+
+// AEADCheckpoint is the state of an AEAD stream encryption after its last complete chunk,
+// from which the encryption can be resumed, e.g. after a long upload is interrupted.
+// It contains the session key, and needs to be stored as securely as the session key,
+// and a digest of the plaintext encrypted before the checkpoint.
+type AEADCheckpoint struct {
+	// PlaintextOffset is the amount of plaintext encrypted before the checkpoint:
+	// the resumed encryption takes the plaintext from this offset.
+	PlaintextOffset int64
+	// CiphertextOffset is the amount of ciphertext written before the checkpoint:
+	// the resumed encryption writes the ciphertext from this offset.
+	CiphertextOffset int64
+	sessionKey       *SessionKey
+	header           []byte
+	associatedData   []byte
+	index            uint64
+	plaintextDigest  []byte
+}
+
+// NewAEADCheckpoint parses a checkpoint serialized by AEADCheckpoint.GetBinary.
+func NewAEADCheckpoint(data []byte) (*AEADCheckpoint, error) {
+	reader := bytes.NewReader(data)
+	version, err := reader.ReadByte()
+	if err != nil || version != aeadCheckpointVersion {
+		return nil, errors.New("gopenpgp: unsupported checkpoint version")
+	}
+	header := make([]byte, aeadHeaderLength)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in reading the checkpoint")
+	}
+	if err = checkAEADHeader(header); err != nil {
+		return nil, err
+	}
+	var index uint64
+	if err = binary.Read(reader, binary.BigEndian, &index); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in reading the checkpoint")
+	}
+	var fields [4][]byte
+	for i := range fields {
+		var length uint32
+		if err = binary.Read(reader, binary.BigEndian, &length); err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: error in reading the checkpoint")
+		}
+		if int64(length) > int64(reader.Len()) {
+			return nil, errors.New("gopenpgp: the checkpoint is truncated")
+		}
+		fields[i] = make([]byte, length)
+		if _, err = io.ReadFull(reader, fields[i]); err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: error in reading the checkpoint")
+		}
+	}
+	if reader.Len() != 0 {
+		return nil, errors.New("gopenpgp: unexpected data after the checkpoint")
+	}
+	if len(fields[3]) != sha256.Size {
+		return nil, errors.New("gopenpgp: invalid plaintext digest in the checkpoint")
+	}
+	return newAEADCheckpoint(
+		&SessionKey{Algo: string(fields[0]), Key: fields[1]},
+		header, fields[2], index, fields[3],
+	)
+}
+
+func newAEADCheckpoint(
+	sessionKey *SessionKey,
+	header, associatedData []byte,
+	index uint64,
+	plaintextDigest []byte,
+) (*AEADCheckpoint, error) {
+	crypter, err := sessionKey.newAEADChunkCrypter(header, associatedData)
+	if err != nil {
+		return nil, err
+	}
+	chunkLength := uint64(crypter.chunkSize + crypter.aead.Overhead())
+	return &AEADCheckpoint{
+		PlaintextOffset:  int64(index * uint64(crypter.chunkSize)),
+		CiphertextOffset: int64(aeadHeaderLength + index*chunkLength),
+		sessionKey:       sessionKey,
+		header:           header,
+		associatedData:   associatedData,
+		index:            index,
+		plaintextDigest:  plaintextDigest,
+	}, nil
+}
+
+// GetBinary serializes the checkpoint, including the session key.
+func (checkpoint *AEADCheckpoint) GetBinary() []byte {
+	data := []byte{aeadCheckpointVersion}
+	data = append(data, checkpoint.header...)
+	data = appendUint64(data, checkpoint.index)
+	for _, field := range [][]byte{
+		[]byte(checkpoint.sessionKey.Algo), checkpoint.sessionKey.Key, checkpoint.associatedData,
+		checkpoint.plaintextDigest,
+	} {
+		data = appendUint32(data, uint32(len(field)))
+		data = append(data, field...)
+	}
+	return data
+}
+
+// AEADCheckpointWriter encrypts data as the WriteCloser returned by SessionKey.EncryptAEADStream,
+// and exports checkpoints from which the encryption can be resumed.
+type AEADCheckpointWriter struct {
+	*aeadChunkWriter
+	sessionKey *SessionKey
+}
+
+// EncryptAEADStreamWithCheckpoints is used to encrypt data with the session key in AEAD chunks,
+// as SessionKey.EncryptAEADStream, with an encryption that can be checkpointed and resumed
+// with ResumeEncryptAEADStream.
+func (sk *SessionKey) EncryptAEADStreamWithCheckpoints(
+	ciphertextWriter Writer,
+	associatedData []byte,
+) (*AEADCheckpointWriter, error) {
+	writeCloser, err := sk.EncryptAEADStream(ciphertextWriter, associatedData)
+	if err != nil {
+		return nil, err
+	}
+	chunkWriter := writeCloser.(*aeadChunkWriter)
+	chunkWriter.plaintextDigest = sha256.New()
+	return &AEADCheckpointWriter{
+		aeadChunkWriter: chunkWriter,
+		sessionKey:      NewSessionKeyFromToken(sk.Key, sk.Algo),
+	}, nil
+}
+
+// ResumeEncryptAEADStream resumes the encryption from the checkpoint, writing the ciphertext
+// that follows the first checkpoint.CiphertextOffset bytes to ciphertextWriter.
+// The first checkpoint.PlaintextOffset bytes of the plaintext are read from plaintextReader,
+// and must match the plaintext encrypted before the checkpoint.
+// The rest of the plaintext, e.g. the rest of plaintextReader, then needs to be written.
+//
+// The resumed encryption uses the same nonces as the chunks the original encryption
+// may have written after the checkpoint: the plaintext written from checkpoint.PlaintextOffset
+// must be byte-identical to the original plaintext, otherwise the confidentiality
+// and the integrity of these chunks are lost. Only the plaintext before the checkpoint is checked.
+func ResumeEncryptAEADStream(
+	ciphertextWriter Writer,
+	checkpoint *AEADCheckpoint,
+	plaintextReader Reader,
+) (*AEADCheckpointWriter, error) {
+	crypter, err := checkpoint.sessionKey.newAEADChunkCrypter(checkpoint.header, checkpoint.associatedData)
+	if err != nil {
+		return nil, err
+	}
+	plaintextDigest := sha256.New()
+	if _, err = io.CopyN(plaintextDigest, plaintextReader, checkpoint.PlaintextOffset); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in reading the plaintext before the checkpoint")
+	}
+	if subtle.ConstantTimeCompare(plaintextDigest.Sum(nil), checkpoint.plaintextDigest) != 1 {
+		return nil, errors.New("gopenpgp: the plaintext doesn't match the checkpoint")
+	}
+	crypter.index = checkpoint.index
+	crypter.amount = checkpoint.index * uint64(crypter.chunkSize)
+	return &AEADCheckpointWriter{
+		aeadChunkWriter: &aeadChunkWriter{
+			aeadChunkCrypter: crypter,
+			writer:           ciphertextWriter,
+			header:           clone(checkpoint.header),
+			plaintextDigest:  plaintextDigest,
+		},
+		sessionKey: checkpoint.sessionKey,
+	}, nil
+}
+
+// Checkpoint returns the state of the encryption after the last complete chunk written
+// to the ciphertext writer. The plaintext buffered for the next chunk is not included,
+// and needs to be written again after resuming.
+func (w *AEADCheckpointWriter) Checkpoint() (*AEADCheckpoint, error) {
+	return newAEADCheckpoint(
+		w.sessionKey, clone(w.header), clone(w.associatedData), w.index, w.plaintextDigest.Sum(nil),
+	)
+}
+
+func appendUint32(data []byte, value uint32) []byte {
+	var buffer [4]byte
+	binary.BigEndian.PutUint32(buffer[:], value)
+	return append(data, buffer[:]...)
+}
+
+func appendUint64(data []byte, value uint64) []byte {
+	var buffer [8]byte
+	binary.BigEndian.PutUint64(buffer[:], value)
+	return append(data, buffer[:]...)
+}
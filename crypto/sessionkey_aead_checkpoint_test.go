@@ -0,0 +1,103 @@
+package crypto
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAEADStreamCheckpoint(t *testing.T) {
+	sessionKey, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating session key, got:", err)
+	}
+	plaintext := bytes.Repeat([]byte(testMessage), 600<<10/len(testMessage))
+	associatedData := []byte("context")
+
+	var ciphertext bytes.Buffer
+	encryptWriter, err := sessionKey.EncryptAEADStreamWithCheckpoints(&ciphertext, associatedData)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if _, err = encryptWriter.Write(plaintext[:300<<10]); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	checkpoint, err := encryptWriter.Checkpoint()
+	if err != nil {
+		t.Fatal("Expected no error while checkpointing, got:", err)
+	}
+	assert.Exactly(t, int64(256<<10), checkpoint.PlaintextOffset)
+	assert.Exactly(t, int64(aeadHeaderLength+256<<10+16), checkpoint.CiphertextOffset)
+	assert.Exactly(t, checkpoint.CiphertextOffset, int64(ciphertext.Len()))
+
+	if _, err = encryptWriter.Write(plaintext[300<<10:]); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if err = encryptWriter.Close(); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	// Resume from the serialized checkpoint, as after an interruption
+	checkpoint, err = NewAEADCheckpoint(checkpoint.GetBinary())
+	if err != nil {
+		t.Fatal("Expected no error while parsing the checkpoint, got:", err)
+	}
+	resumed := bytes.NewBuffer(clone(ciphertext.Bytes()[:checkpoint.CiphertextOffset]))
+	plaintextReader := bytes.NewReader(plaintext)
+	resumeWriter, err := ResumeEncryptAEADStream(resumed, checkpoint, plaintextReader)
+	if err != nil {
+		t.Fatal("Expected no error while resuming, got:", err)
+	}
+	if _, err = io.Copy(resumeWriter, plaintextReader); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if err = resumeWriter.Close(); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	assert.Exactly(t, ciphertext.Bytes(), resumed.Bytes())
+
+	decrypted, err := sessionKey.DecryptAEAD(resumed.Bytes(), associatedData)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, plaintext, decrypted)
+
+	_, err = NewAEADCheckpoint(checkpoint.GetBinary()[:40])
+	assert.Error(t, err)
+
+	// The plaintext before the checkpoint must be the original one
+	modified := clone(plaintext)
+	modified[0] ^= 1
+	_, err = ResumeEncryptAEADStream(&bytes.Buffer{}, checkpoint, bytes.NewReader(modified))
+	assert.Error(t, err)
+	_, err = ResumeEncryptAEADStream(&bytes.Buffer{}, checkpoint, bytes.NewReader(plaintext[:1000]))
+	assert.Error(t, err)
+}
+
+func TestAEADStreamCheckpointCorrupted(t *testing.T) {
+	sessionKey, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating session key, got:", err)
+	}
+	var ciphertext bytes.Buffer
+	encryptWriter, err := sessionKey.EncryptAEADStreamWithCheckpoints(&ciphertext, nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	checkpoint, err := encryptWriter.Checkpoint()
+	if err != nil {
+		t.Fatal("Expected no error while checkpointing, got:", err)
+	}
+
+	// Chunk size byte, after the checkpoint version and the AEAD version, cipher and mode
+	corrupted := checkpoint.GetBinary()
+	corrupted[4] = 100
+	_, err = NewAEADCheckpoint(corrupted)
+	assert.Error(t, err)
+
+	checkpoint.header[3] = 100
+	_, err = ResumeEncryptAEADStream(&ciphertext, checkpoint, bytes.NewReader(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,160 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionKeyEncryptDecryptAEAD(t *testing.T) {
+	associatedData := []byte("associated data")
+	chunkSize := 1 << (aeadChunkSizeByte + 6)
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 2 * chunkSize} {
+		plaintext, err := RandomToken(size)
+		if err != nil {
+			t.Fatal("Expected no error while generating the plaintext, got:", err)
+		}
+		ciphertext, err := testSessionKey.EncryptAEAD(plaintext, associatedData)
+		if err != nil {
+			t.Fatal("Expected no error while encrypting, got:", err)
+		}
+		decrypted, err := testSessionKey.DecryptAEAD(ciphertext, associatedData)
+		if err != nil {
+			t.Fatal("Expected no error while decrypting, got:", err)
+		}
+		assert.Exactly(t, plaintext, decrypted)
+
+		if _, err = testSessionKey.DecryptAEAD(ciphertext, []byte("other data")); err == nil {
+			t.Fatal("Expected an error while decrypting with the wrong associated data, got nil")
+		}
+		if _, err = testSessionKey.DecryptAEAD(ciphertext[:len(ciphertext)-1], associatedData); err == nil {
+			t.Fatal("Expected an error while decrypting truncated data, got nil")
+		}
+	}
+
+	v6SessionKey := NewSessionKeyFromToken(testSessionKey.Key, "")
+	ciphertext, err := v6SessionKey.EncryptAEAD([]byte(testMessage), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	decrypted, err := testSessionKey.DecryptAEAD(ciphertext, nil)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, testMessage, string(decrypted))
+
+	if _, err = NewSessionKeyFromToken(testSessionKey.Key[:16], constants.AES128).DecryptAEAD(ciphertext, nil); err == nil {
+		t.Fatal("Expected an error while decrypting with a session key of another algorithm, got nil")
+	}
+}
+
+func TestSessionKeyDecryptAEADStream(t *testing.T) {
+	plaintext, err := RandomToken(3 * (1 << (aeadChunkSizeByte + 6)) / 2)
+	if err != nil {
+		t.Fatal("Expected no error while generating the plaintext, got:", err)
+	}
+	var ciphertext bytes.Buffer
+	encryptWriter, err := testSessionKey.EncryptAEADStream(&ciphertext, nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	for i := 0; i < len(plaintext); i += 1000 {
+		end := i + 1000
+		if end > len(plaintext) {
+			end = len(plaintext)
+		}
+		if _, err = encryptWriter.Write(plaintext[i:end]); err != nil {
+			t.Fatal("Expected no error while writing, got:", err)
+		}
+	}
+	if err = encryptWriter.Close(); err != nil {
+		t.Fatal("Expected no error while closing, got:", err)
+	}
+
+	decryptReader, err := testSessionKey.DecryptAEADStream(bytes.NewReader(ciphertext.Bytes()), nil)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	decrypted, err := ioutil.ReadAll(decryptReader)
+	if err != nil {
+		t.Fatal("Expected no error while reading, got:", err)
+	}
+	assert.Exactly(t, plaintext, decrypted)
+}
+
+func TestSessionKeyEncryptAEADCompatible(t *testing.T) {
+	var literal bytes.Buffer
+	literalWriter, err := packet.SerializeLiteral(nopWriteCloser{&literal}, true, "", testTime)
+	if err != nil {
+		t.Fatal("Expected no error while serializing the literal data, got:", err)
+	}
+	if _, err = literalWriter.Write([]byte(testMessage)); err != nil {
+		t.Fatal("Expected no error while serializing the literal data, got:", err)
+	}
+	if err = literalWriter.Close(); err != nil {
+		t.Fatal("Expected no error while serializing the literal data, got:", err)
+	}
+
+	body, err := testSessionKey.EncryptAEAD(literal.Bytes(), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	dataPacket := []byte{0xC0 | 18, 0xFF, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(dataPacket[2:], uint32(len(body)))
+	dataPacket = append(dataPacket, body...)
+
+	decrypted, err := testSessionKey.Decrypt(dataPacket)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the SEIPDv2 packet, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+}
+
+func TestSessionKeyDecryptAEADStreamFromOffset(t *testing.T) {
+	sessionKey, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating session key, got:", err)
+	}
+	plaintext := bytes.Repeat([]byte(testMessage), 600<<10/len(testMessage))
+	ciphertext, err := sessionKey.EncryptAEAD(plaintext, nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	header := ciphertext[:GetAEADHeaderLength()]
+
+	for _, plaintextOffset := range []int64{0, 256 << 10, 512 << 10} {
+		ciphertextOffset, err := GetAEADCiphertextOffset(header, plaintextOffset)
+		if err != nil {
+			t.Fatal("Expected no error while computing the offset, got:", err)
+		}
+		decryptReader, err := sessionKey.DecryptAEADStreamFromOffset(
+			header, bytes.NewReader(ciphertext[ciphertextOffset:]), plaintextOffset, nil,
+		)
+		if err != nil {
+			t.Fatal("Expected no error while decrypting, got:", err)
+		}
+		decrypted, err := ioutil.ReadAll(decryptReader)
+		if err != nil {
+			t.Fatal("Expected no error while decrypting, got:", err)
+		}
+		assert.Exactly(t, plaintext[plaintextOffset:], decrypted)
+	}
+
+	_, err = GetAEADCiphertextOffset(header, 1000)
+	assert.Error(t, err)
+
+	// The chunk index is authenticated
+	decryptReader, err := sessionKey.DecryptAEADStreamFromOffset(
+		header, bytes.NewReader(ciphertext[GetAEADHeaderLength():]), 256<<10, nil,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	_, err = ioutil.ReadAll(decryptReader)
+	assert.Error(t, err)
+}
@@ -0,0 +1,238 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"encoding/binary"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/cast5"
+)
+
+// mdcLength is the length of the Modification Detection Code packet at the end of SEIPDv1 data.
+const mdcLength = 22
+
+// SalvageResult reports the data recovered from a truncated or corrupted message by SessionKey.SalvageDecrypt.
+type SalvageResult struct {
+	// Data is the recovered content of the literal data.
+	Data []byte
+	// DecryptedBytes is the number of bytes of the encrypted data packet that were decrypted,
+	// i.e. of the packets of the message inside the encrypted data packet.
+	DecryptedBytes int
+	// AuthenticatedChunks is the number of authenticated AEAD chunks, for SEIPDv2 packets.
+	AuthenticatedChunks int
+	// Authenticated is true if all the recovered data was authenticated: it is always true for
+	// SEIPDv2 packets, whose chunks are authenticated individually, and true for SEIPDv1 packets
+	// only if they are complete.
+	Authenticated bool
+	// Complete is true if the whole message was recovered.
+	Complete bool
+}
+
+// SalvageDecrypt decrypts as much as possible of a truncated or corrupted encrypted data packet,
+// SEIPDv1 or SEIPDv2, with the session key, e.g. to restore partially transferred backups.
+// For SEIPDv2 packets, the complete AEAD chunks are decrypted and authenticated up to the first
+// missing or corrupted one. For SEIPDv1 packets, all the available data is decrypted, but it can
+// only be authenticated if the packet is complete.
+// The signatures of the message are not verified.
+// An error is returned if no data can be decrypted, e.g. if the session key is wrong.
+func (sk *SessionKey) SalvageDecrypt(dataPacket []byte) (*SalvageResult, error) {
+	tag, body, complete, err := readSalvagedPacketBody(dataPacket)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, errors.New("gopenpgp: no encrypted data to salvage")
+	}
+
+	var result *SalvageResult
+	var decrypted []byte
+	switch {
+	case tag == 18 && body[0] == 1:
+		decrypted, result, err = sk.salvageCFB(body[1:], complete)
+	case tag == 18 && body[0] == aeadVersion:
+		decrypted, result, err = sk.salvageAEAD(body, complete)
+	default:
+		return nil, errors.New("gopenpgp: the data packet is not a SEIPD packet")
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.DecryptedBytes = len(decrypted)
+	result.Data = salvageLiteralData(decrypted)
+	return result, nil
+}
+
+// salvageAEAD decrypts the complete chunks of the body of a SEIPDv2 packet.
+func (sk *SessionKey) salvageAEAD(body []byte, complete bool) ([]byte, *SalvageResult, error) {
+	if len(body) < aeadHeaderLength {
+		return nil, nil, errors.New("gopenpgp: the encrypted data is truncated")
+	}
+	if body[3] > aeadMaxChunkSizeByte {
+		return nil, nil, errors.New("gopenpgp: invalid AEAD chunk size")
+	}
+	crypter, err := sk.newAEADChunkCrypter(body[:aeadHeaderLength], nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := &SalvageResult{Authenticated: true}
+	tagLength := crypter.aead.Overhead()
+	chunkLength := crypter.chunkSize + tagLength
+	ciphertext := body[aeadHeaderLength:]
+	var decrypted []byte
+	for {
+		// As aeadChunkReader.readChunk, the chunk is the last one if it can't be followed by the final tag
+		if complete && len(ciphertext) < chunkLength+tagLength {
+			if len(ciphertext) >= tagLength {
+				lastChunk := ciphertext[:len(ciphertext)-tagLength]
+				finalTag := ciphertext[len(ciphertext)-tagLength:]
+				var plaintext []byte
+				var err error
+				if len(lastChunk) > 0 {
+					plaintext, err = crypter.open(lastChunk)
+				}
+				if err == nil {
+					_, err = crypter.aead.Open(nil, crypter.nextNonce(), finalTag, crypter.finalAssociatedData())
+				}
+				if err == nil {
+					if len(lastChunk) > 0 {
+						result.AuthenticatedChunks++
+					}
+					result.Complete = true
+					decrypted = append(decrypted, plaintext...)
+				}
+			}
+			break
+		}
+		if len(ciphertext) < chunkLength {
+			// Truncated chunk
+			break
+		}
+		plaintext, err := crypter.open(ciphertext[:chunkLength])
+		if err != nil {
+			break
+		}
+		result.AuthenticatedChunks++
+		decrypted = append(decrypted, plaintext...)
+		ciphertext = ciphertext[chunkLength:]
+	}
+	if result.AuthenticatedChunks == 0 {
+		return nil, nil, errors.New("gopenpgp: no AEAD chunk could be authenticated")
+	}
+	return decrypted, result, nil
+}
+
+// salvageCFB decrypts the ciphertext of a SEIPDv1 packet, and checks the MDC if the packet is complete.
+func (sk *SessionKey) salvageCFB(ciphertext []byte, complete bool) ([]byte, *SalvageResult, error) {
+	block, err := sk.newBlockCipher()
+	if err != nil {
+		return nil, nil, err
+	}
+	prefixLength := block.BlockSize() + 2
+	if len(ciphertext) < prefixLength {
+		return nil, nil, errors.New("gopenpgp: the encrypted data is truncated")
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCFBDecrypter(block, make([]byte, block.BlockSize())).XORKeyStream(plaintext, ciphertext)
+	prefix := plaintext[:prefixLength]
+	if prefix[prefixLength-4] != prefix[prefixLength-2] || prefix[prefixLength-3] != prefix[prefixLength-1] {
+		return nil, nil, errors.New("gopenpgp: wrong session key")
+	}
+
+	result := &SalvageResult{}
+	decrypted := plaintext[prefixLength:]
+	if complete && len(decrypted) >= mdcLength {
+		mdc := decrypted[len(decrypted)-mdcLength:]
+		h := sha1.New() //nolint:gosec
+		_, _ = h.Write(plaintext[:len(plaintext)-mdcLength+2])
+		if mdc[0] == 0xd3 && mdc[1] == 0x14 && bytes.Equal(h.Sum(nil), mdc[2:]) {
+			result.Authenticated = true
+			result.Complete = true
+			decrypted = decrypted[:len(decrypted)-mdcLength]
+		}
+	}
+	return decrypted, result, nil
+}
+
+// newBlockCipher returns the block cipher of the session key.
+func (sk *SessionKey) newBlockCipher() (cipher.Block, error) {
+	cipherFunc, err := sk.GetCipherFunc()
+	if err != nil {
+		return nil, err
+	}
+	if len(sk.Key) != cipherFunc.KeySize() {
+		return nil, errors.New("gopenpgp: wrong session key size")
+	}
+	var block cipher.Block
+	switch cipherFunc {
+	case packet.CipherAES128, packet.CipherAES192, packet.CipherAES256:
+		block, err = aes.NewCipher(sk.Key)
+	case packet.Cipher3DES:
+		block, err = des.NewTripleDESCipher(sk.Key)
+	case packet.CipherCAST5:
+		block, err = cast5.NewCipher(sk.Key)
+	default:
+		return nil, errors.New("gopenpgp: unsupported cipher function: " + sk.Algo)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in initializing the cipher")
+	}
+	return block, nil
+}
+
+// readSalvagedPacketBody reads the tag and the available body of a possibly truncated packet,
+// joining partial bodies, and returns whether the packet is complete.
+func readSalvagedPacketBody(data []byte) (tag byte, body []byte, complete bool, err error) {
+	if len(data) < 2 || data[0]&0xc0 != 0xc0 {
+		return 0, nil, false, errors.New("gopenpgp: invalid encrypted data packet header")
+	}
+	tag = data[0] & 0x3f
+	data = data[1:]
+	for len(data) > 0 {
+		var length, offset int
+		partial := false
+		switch {
+		case data[0] < 192:
+			length, offset = int(data[0]), 1
+		case data[0] < 224:
+			if len(data) < 2 {
+				return tag, body, false, nil
+			}
+			length, offset = (int(data[0])-192)<<8+int(data[1])+192, 2
+		case data[0] == 255:
+			if len(data) < 5 {
+				return tag, body, false, nil
+			}
+			length, offset = int(binary.BigEndian.Uint32(data[1:5])), 5
+		default:
+			length, offset, partial = 1<<(data[0]&0x1f), 1, true
+		}
+		data = data[offset:]
+		if len(data) < length {
+			return tag, append(body, data...), false, nil
+		}
+		body = append(body, data[:length]...)
+		data = data[length:]
+		if !partial {
+			return tag, body, true, nil
+		}
+	}
+	return tag, body, false, nil
+}
+
+// salvageLiteralData reads as much as possible of the literal data of the decrypted packets.
+func salvageLiteralData(decrypted []byte) []byte {
+	md, err := openpgp.ReadMessage(bytes.NewReader(decrypted), nil, nil, &packet.Config{})
+	if err != nil {
+		return nil
+	}
+	var data bytes.Buffer
+	_, _ = io.Copy(&data, md.UnverifiedBody)
+	return data.Bytes()
+}
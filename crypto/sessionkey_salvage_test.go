@@ -0,0 +1,149 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSalvageDecryptAEAD(t *testing.T) {
+	sessionKey, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating session key, got:", err)
+	}
+	plaintext := bytes.Repeat([]byte(testMessage), 600<<10/len(testMessage))
+	dataPacket, err := newSEIPDv2Packet(sessionKey, NewPlainMessage(plaintext))
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	result, err := sessionKey.SalvageDecrypt(dataPacket)
+	if err != nil {
+		t.Fatal("Expected no error while salvaging complete packet, got:", err)
+	}
+	assert.True(t, result.Complete)
+	assert.True(t, result.Authenticated)
+	assert.Exactly(t, 3, result.AuthenticatedChunks)
+	assert.Exactly(t, plaintext, result.Data)
+
+	// Truncated in the third chunk
+	result, err = sessionKey.SalvageDecrypt(dataPacket[:len(dataPacket)-1000])
+	if err != nil {
+		t.Fatal("Expected no error while salvaging truncated packet, got:", err)
+	}
+	assert.False(t, result.Complete)
+	assert.True(t, result.Authenticated)
+	assert.Exactly(t, 2, result.AuthenticatedChunks)
+	assert.Exactly(t, 2*(256<<10), result.DecryptedBytes)
+	assert.NotEmpty(t, result.Data)
+	assert.True(t, bytes.HasPrefix(plaintext, result.Data))
+
+	// Corrupted in the second chunk
+	corrupted := clone(dataPacket)
+	corrupted[len(corrupted)-300<<10] ^= 1
+	result, err = sessionKey.SalvageDecrypt(corrupted)
+	if err != nil {
+		t.Fatal("Expected no error while salvaging corrupted packet, got:", err)
+	}
+	assert.False(t, result.Complete)
+	assert.Exactly(t, 1, result.AuthenticatedChunks)
+	assert.True(t, bytes.HasPrefix(plaintext, result.Data))
+
+	otherSessionKey, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating session key, got:", err)
+	}
+	_, err = otherSessionKey.SalvageDecrypt(dataPacket)
+	assert.Error(t, err)
+}
+
+func TestSalvageDecryptCFB(t *testing.T) {
+	sessionKey, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating session key, got:", err)
+	}
+	plaintext := bytes.Repeat([]byte(testMessage), 100)
+	dataPacket, err := sessionKey.Encrypt(NewPlainMessage(plaintext))
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	result, err := sessionKey.SalvageDecrypt(dataPacket)
+	if err != nil {
+		t.Fatal("Expected no error while salvaging complete packet, got:", err)
+	}
+	assert.True(t, result.Complete)
+	assert.True(t, result.Authenticated)
+	assert.Exactly(t, plaintext, result.Data)
+
+	result, err = sessionKey.SalvageDecrypt(dataPacket[:len(dataPacket)/2])
+	if err != nil {
+		t.Fatal("Expected no error while salvaging truncated packet, got:", err)
+	}
+	assert.False(t, result.Complete)
+	assert.False(t, result.Authenticated)
+	assert.NotZero(t, result.DecryptedBytes)
+	assert.True(t, bytes.HasPrefix(plaintext, result.Data))
+
+	otherSessionKey, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating session key, got:", err)
+	}
+	_, err = otherSessionKey.SalvageDecrypt(dataPacket)
+	assert.Error(t, err)
+}
+
+func TestSalvageDecryptAEADChunkBoundaries(t *testing.T) {
+	sessionKey, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating session key, got:", err)
+	}
+	chunkSize := 1 << (aeadChunkSizeByte + 6)
+	chunkLength := chunkSize + 16
+	for _, test := range []struct {
+		plaintextLength int
+		chunks          int
+	}{
+		{chunkSize - 15, 1},
+		{chunkSize - 1, 1},
+		{chunkSize, 1},
+		{2*chunkSize - 15, 2},
+		{2 * chunkSize, 2},
+		{2*chunkSize + 1, 3},
+	} {
+		plaintext := bytes.Repeat([]byte{'a'}, test.plaintextLength)
+		ciphertext, err := sessionKey.EncryptAEAD(plaintext, nil)
+		if err != nil {
+			t.Fatal("Expected no error while encrypting, got:", err)
+		}
+		result, err := sessionKey.SalvageDecrypt(newSalvageTestPacket(ciphertext))
+		if err != nil {
+			t.Fatal("Expected no error while salvaging complete packet, got:", err)
+		}
+		assert.True(t, result.Complete, "plaintext length %d", test.plaintextLength)
+		assert.Exactly(t, test.chunks, result.AuthenticatedChunks, "plaintext length %d", test.plaintextLength)
+		assert.Exactly(t, test.plaintextLength, result.DecryptedBytes, "plaintext length %d", test.plaintextLength)
+	}
+
+	// Truncated on a chunk boundary
+	ciphertext, err := sessionKey.EncryptAEAD(bytes.Repeat([]byte{'a'}, 3*chunkSize), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	truncated := newSalvageTestPacket(ciphertext)[:6+aeadHeaderLength+2*chunkLength]
+	result, err := sessionKey.SalvageDecrypt(truncated)
+	if err != nil {
+		t.Fatal("Expected no error while salvaging truncated packet, got:", err)
+	}
+	assert.False(t, result.Complete)
+	assert.Exactly(t, 2, result.AuthenticatedChunks)
+	assert.Exactly(t, 2*chunkSize, result.DecryptedBytes)
+}
+
+// newSalvageTestPacket wraps the body in a SEIPD packet, with a five-octet length.
+func newSalvageTestPacket(body []byte) []byte {
+	length := len(body)
+	header := []byte{0xc0 | 18, 255, byte(length >> 24), byte(length >> 16), byte(length >> 8), byte(length)}
+	return append(header, body...)
+}
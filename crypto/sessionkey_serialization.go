@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+// Version of the session key serialization format.
+const sessionKeySerializationVersion = 1
+
+// Versions of the serialized session keys, matching the versions of the key packets:
+// the algorithm is only encoded in version 3.
+const (
+	sessionKeyVersion3 = 3
+	sessionKeyVersion6 = 6
+)
+
+// Serialize encodes the session key in a stable binary format, to store it or transfer it between services.
+// The format is a format version byte (1), the session key version byte,
+// the algorithm byte for version 3 only, and the key bytes.
+// A session key without algorithm (empty Algo), as in version 6 key packets, is serialized as version 6,
+// and a session key with an algorithm as version 3.
+// The session key is validated before it is serialized.
+func (sk *SessionKey) Serialize() ([]byte, error) {
+	if err := sk.Validate(); err != nil {
+		return nil, err
+	}
+	if sk.Algo == "" {
+		serialized := make([]byte, 0, 2+len(sk.Key))
+		serialized = append(serialized, sessionKeySerializationVersion, sessionKeyVersion6)
+		return append(serialized, sk.Key...), nil
+	}
+
+	cf, err := sk.GetCipherFunc()
+	if err != nil {
+		return nil, err
+	}
+	serialized := make([]byte, 0, 3+len(sk.Key))
+	serialized = append(serialized, sessionKeySerializationVersion, sessionKeyVersion3, byte(cf))
+	return append(serialized, sk.Key...), nil
+}
+
+// NewSessionKeyFromSerialized parses and validates a session key serialized with SessionKey.Serialize.
+func NewSessionKeyFromSerialized(serialized []byte) (*SessionKey, error) {
+	if len(serialized) < 2 {
+		return nil, errors.New("gopenpgp: serialized session key is too short")
+	}
+	if serialized[0] != sessionKeySerializationVersion {
+		return nil, errors.Errorf("gopenpgp: unsupported session key serialization version %d", serialized[0])
+	}
+
+	var sk *SessionKey
+	switch serialized[1] {
+	case sessionKeyVersion3:
+		if len(serialized) < 3 {
+			return nil, errors.New("gopenpgp: serialized session key is too short")
+		}
+		algo := cipherName(packet.CipherFunction(serialized[2]))
+		if _, ok := symKeyAlgos[algo]; !ok {
+			return nil, errors.New("gopenpgp: unsupported cipher function: " + algo)
+		}
+		sk = NewSessionKeyFromToken(serialized[3:], algo)
+	case sessionKeyVersion6:
+		sk = NewSessionKeyFromToken(serialized[2:], "")
+	default:
+		return nil, errors.Errorf("gopenpgp: unsupported session key version %d", serialized[1])
+	}
+
+	if err := sk.Validate(); err != nil {
+		return nil, err
+	}
+	return sk, nil
+}
+
+// Validate checks that the size of the session key matches its algorithm,
+// or is the size of an AES key if the session key has no algorithm.
+func (sk *SessionKey) Validate() error {
+	if sk.Algo == "" {
+		switch len(sk.Key) {
+		case packet.CipherAES128.KeySize(), packet.CipherAES192.KeySize(), packet.CipherAES256.KeySize():
+			return nil
+		}
+		return errors.New("gopenpgp: wrong session key size")
+	}
+	if err := sk.checkSize(); err != nil {
+		return errors.Wrap(err, "gopenpgp: invalid session key")
+	}
+	return nil
+}
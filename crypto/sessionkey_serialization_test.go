@@ -0,0 +1,51 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionKeySerialization(t *testing.T) {
+	serialized, err := testSessionKey.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing the session key, got:", err)
+	}
+	assert.Exactly(t, []byte{1, 3, 9}, serialized[:3])
+	parsed, err := NewSessionKeyFromSerialized(serialized)
+	if err != nil {
+		t.Fatal("Expected no error while parsing the session key, got:", err)
+	}
+	assert.Exactly(t, testSessionKey.Key, parsed.Key)
+	assert.Exactly(t, constants.AES256, parsed.Algo)
+
+	v6SessionKey := NewSessionKeyFromToken(testSessionKey.Key[:16], "")
+	serialized, err = v6SessionKey.Serialize()
+	if err != nil {
+		t.Fatal("Expected no error while serializing the session key, got:", err)
+	}
+	assert.Exactly(t, []byte{1, 6}, serialized[:2])
+	parsed, err = NewSessionKeyFromSerialized(serialized)
+	if err != nil {
+		t.Fatal("Expected no error while parsing the session key, got:", err)
+	}
+	assert.Exactly(t, v6SessionKey.Key, parsed.Key)
+	assert.Exactly(t, "", parsed.Algo)
+
+	if _, err = NewSessionKeyFromToken(testSessionKey.Key[:16], constants.AES256).Serialize(); err == nil {
+		t.Fatal("Expected an error while serializing a session key of the wrong size, got nil")
+	}
+	for _, invalid := range [][]byte{
+		nil,
+		{2, 3, 9},
+		{1, 4, 9},
+		append([]byte{1, 3, 9}, testSessionKey.Key[:16]...),
+		append([]byte{1, 3, 1}, testSessionKey.Key[:16]...),
+		append([]byte{1, 6}, testSessionKey.Key[:20]...),
+	} {
+		if _, err = NewSessionKeyFromSerialized(invalid); err == nil {
+			t.Fatalf("Expected an error while parsing the invalid session key %x, got nil", invalid)
+		}
+	}
+}
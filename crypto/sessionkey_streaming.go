@@ -117,7 +117,7 @@ func (sk *SessionKey) encryptStream(
 	if signWriter != nil {
 		plainMessageWriter = &signAndEncryptWriteCloser{signWriter, encryptWriter}
 	} else {
-		plainMessageWriter = encryptWriter
+		plainMessageWriter = &plainMessageWriteCloser{encryptWriter}
 	}
 	return plainMessageWriter, err
 }
@@ -180,10 +180,9 @@ func decryptStreamWithSessionKeyAndContext(
 	}
 
 	return &PlainMessageReader{
-		messageDetails,
-		verifyKeyRing,
-		verifyTime,
-		false,
-		verificationContext,
+		details:             messageDetails,
+		verifyKeyRing:       verifyKeyRing,
+		verifyTime:          verifyTime,
+		verificationContext: verificationContext,
 	}, err
 }
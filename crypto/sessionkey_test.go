@@ -1,10 +1,12 @@
 package crypto
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/ProtonMail/gopenpgp/v2/constants"
@@ -408,3 +410,82 @@ func TestAEADDataPacketDecryption(t *testing.T) {
 
 	assert.Exactly(t, "hello world\n", decrypted.GetString())
 }
+
+func TestGenerateSessionKeyWithEntropy(t *testing.T) {
+	defer SetRandomSource(nil)
+	entropy := []byte("additional entropy")
+
+	SetRandomSource(bytes.NewReader(make([]byte, 64)))
+	sessionKey, err := GenerateSessionKeyWithEntropy(entropy)
+	if err != nil {
+		t.Fatal("Expected no error while generating the session key, got:", err)
+	}
+	assert.Exactly(t, constants.AES256, sessionKey.Algo)
+	assert.Len(t, sessionKey.Key, 32)
+	assert.NotEqual(t, make([]byte, 32), sessionKey.Key)
+
+	otherSessionKey, err := GenerateSessionKeyWithEntropy([]byte("other entropy"))
+	if err != nil {
+		t.Fatal("Expected no error while generating the session key, got:", err)
+	}
+	assert.NotEqual(t, sessionKey.Key, otherSessionKey.Key)
+
+	SetRandomSource(nil)
+	sessionKey, err = GenerateSessionKeyAlgoWithEntropy(constants.AES128, entropy)
+	if err != nil {
+		t.Fatal("Expected no error while generating the session key, got:", err)
+	}
+	assert.Len(t, sessionKey.Key, 16)
+
+	encrypted, err := sessionKey.Encrypt(NewPlainMessageFromString(testMessage))
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	decrypted, err := sessionKey.Decrypt(encrypted)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+
+	if _, err = GenerateSessionKeyWithEntropy(nil); err == nil {
+		t.Fatal("Expected an error while generating a session key without entropy, got nil")
+	}
+}
+
+func TestDeriveConvergentSessionKey(t *testing.T) {
+	convergenceKey := []byte("convergence key")
+	sessionKey, err := DeriveConvergentSessionKey(convergenceKey, []byte(testMessage))
+	if err != nil {
+		t.Fatal("Expected no error while deriving the session key, got:", err)
+	}
+	assert.Exactly(t, constants.AES256, sessionKey.Algo)
+	assert.Len(t, sessionKey.Key, 32)
+
+	sameSessionKey, err := DeriveConvergentSessionKeyAlgo(constants.AES256, convergenceKey, strings.NewReader(testMessage))
+	if err != nil {
+		t.Fatal("Expected no error while deriving the session key, got:", err)
+	}
+	assert.Exactly(t, sessionKey, sameSessionKey)
+
+	otherSessionKey, err := DeriveConvergentSessionKey(convergenceKey, []byte("other message"))
+	if err != nil {
+		t.Fatal("Expected no error while deriving the session key, got:", err)
+	}
+	assert.NotEqual(t, sessionKey.Key, otherSessionKey.Key)
+
+	otherSessionKey, err = DeriveConvergentSessionKey([]byte("other key"), []byte(testMessage))
+	if err != nil {
+		t.Fatal("Expected no error while deriving the session key, got:", err)
+	}
+	assert.NotEqual(t, sessionKey.Key, otherSessionKey.Key)
+
+	aes128Key, err := DeriveConvergentSessionKeyAlgo(constants.AES128, convergenceKey, strings.NewReader(testMessage))
+	if err != nil {
+		t.Fatal("Expected no error while deriving the session key, got:", err)
+	}
+	assert.Len(t, aes128Key.Key, 16)
+
+	if _, err = DeriveConvergentSessionKey(nil, []byte(testMessage)); err == nil {
+		t.Fatal("Expected an error while deriving a session key without convergence key, got nil")
+	}
+}
@@ -0,0 +1,113 @@
+package crypto
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+// TranscodeToAEAD converts a SEIPDv1 data packet, encrypted with CFB and protected by an MDC,
+// into a SEIPDv2 data packet protected by AEAD (OCB), encrypted with the same session key,
+// without parsing the encrypted message. The session key needs to be an AES key.
+func (sk *SessionKey) TranscodeToAEAD(dataPacket []byte) ([]byte, error) {
+	var transcoded bytes.Buffer
+	if err := sk.TranscodeToAEADStream(&transcoded, bytes.NewReader(dataPacket)); err != nil {
+		return nil, err
+	}
+	return transcoded.Bytes(), nil
+}
+
+// TranscodeToAEADStream is used to convert a SEIPDv1 data packet into a SEIPDv2 data packet,
+// as SessionKey.TranscodeToAEAD. It reads the SEIPDv1 packet from dataPacketReader,
+// and writes the SEIPDv2 packet to dataPacketWriter as it is read.
+// The MDC of the SEIPDv1 packet is only verified at the end: if an error is returned,
+// the data written to dataPacketWriter needs to be discarded.
+func (sk *SessionKey) TranscodeToAEADStream(dataPacketWriter Writer, dataPacketReader Reader) error {
+	cipherFunc, err := sk.aeadCipherFunc(0)
+	if err != nil {
+		return err
+	}
+	p, err := packet.Read(dataPacketReader)
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to read the data packet")
+	}
+	se, ok := p.(*packet.SymmetricallyEncrypted)
+	if !ok || !se.IntegrityProtected || se.Version != 1 {
+		return errors.New("gopenpgp: the data packet is not a SEIPDv1 packet")
+	}
+	decrypted, err := se.Decrypt(cipherFunc, sk.Key)
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to decrypt the data packet")
+	}
+
+	config := &packet.Config{Rand: getRandomSource()}
+	cipherSuite := packet.CipherSuite{Cipher: cipherFunc, Mode: packet.AEADModeOCB}
+	encryptWriter, err := packet.SerializeSymmetricallyEncrypted(dataPacketWriter, cipherFunc, true, cipherSuite, sk.Key, config)
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to encrypt the data packet")
+	}
+	if _, err = io.Copy(encryptWriter, decrypted); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to transcode the data packet")
+	}
+	if err = decrypted.Close(); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to verify the integrity of the data packet")
+	}
+	if err = encryptWriter.Close(); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to encrypt the data packet")
+	}
+	return nil
+}
+
+// TranscodeSplitMessageToNonStandardAEAD decrypts the session key of the message with the keyring, and converts
+// its SEIPDv1 data packet into a SEIPDv2 data packet, as SessionKey.TranscodeToAEAD.
+// If recipients is not nil, the session key is encrypted again to the recipients,
+// otherwise the key packets of the message are kept, and their recipients must be in the keyring.
+// All the recipients must advertise the SEIPDv2 feature, otherwise an error is returned.
+//
+// The output is NOT conformant with RFC 9580: the key packets are version 3 ones,
+// as go-crypto can't write version 6 ones, while section 5.1 requires version 6 key packets
+// before SEIPDv2 packets. The message can only be decrypted by this library
+// and by implementations accepting this pairing, and it must only be used between such peers.
+func (keyRing *KeyRing) TranscodeSplitMessageToNonStandardAEAD(message *PGPSplitMessage, recipients *KeyRing) (*PGPSplitMessage, error) {
+	var recipientEntities openpgp.EntityList
+	if recipients != nil {
+		recipientEntities = recipients.entities
+	} else {
+		keyIDs, ok := NewPGPMessage(message.GetBinaryKeyPacket()).GetEncryptionKeyIDs()
+		if !ok {
+			return nil, errors.New("gopenpgp: unable to read the recipients of the key packets")
+		}
+		for _, keyID := range keyIDs {
+			keys := keyRing.entities.KeysById(keyID)
+			if len(keys) == 0 {
+				return nil, errors.New("gopenpgp: the recipient " + keyIDToHex(keyID) +
+					" of the key packets is not in the keyring")
+			}
+			recipientEntities = append(recipientEntities, keys[0].Entity)
+		}
+	}
+	if err := checkSEIPDv2Support(recipientEntities); err != nil {
+		return nil, err
+	}
+
+	sessionKey, err := keyRing.DecryptSessionKey(message.GetBinaryKeyPacket())
+	if err != nil {
+		return nil, err
+	}
+	defer sessionKey.Clear()
+
+	dataPacket, err := sessionKey.TranscodeToAEAD(message.GetBinaryDataPacket())
+	if err != nil {
+		return nil, err
+	}
+	keyPacket := clone(message.GetBinaryKeyPacket())
+	if recipients != nil {
+		if keyPacket, err = recipients.EncryptSessionKey(sessionKey); err != nil {
+			return nil, err
+		}
+	}
+	return NewPGPSplitMessage(keyPacket, dataPacket), nil
+}
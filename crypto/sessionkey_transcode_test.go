@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscodeSplitMessageToNonStandardAEAD(t *testing.T) {
+	privateKeyRing, publicKeyRing := newSEIPDv2KeyRings(t)
+	message, err := publicKeyRing.Encrypt(NewPlainMessageFromString(testMessage), privateKeyRing)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	splitMessage, err := message.SplitMessage()
+	if err != nil {
+		t.Fatal("Expected no error while splitting the message, got:", err)
+	}
+
+	transcoded, err := privateKeyRing.TranscodeSplitMessageToNonStandardAEAD(splitMessage, nil)
+	if err != nil {
+		t.Fatal("Expected no error while transcoding, got:", err)
+	}
+	assert.Exactly(t, splitMessage.GetBinaryKeyPacket(), transcoded.GetBinaryKeyPacket())
+	p, err := packet.Read(bytes.NewReader(transcoded.GetBinaryDataPacket()))
+	if err != nil {
+		t.Fatal("Expected no error while reading the data packet, got:", err)
+	}
+	se, ok := p.(*packet.SymmetricallyEncrypted)
+	assert.True(t, ok)
+	assert.Exactly(t, 2, se.Version)
+
+	decrypted, err := privateKeyRing.Decrypt(transcoded.GetPGPMessage(), publicKeyRing, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the transcoded message, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+
+	recipientKeyRing, _ := newSEIPDv2KeyRings(t)
+	transcoded, err = privateKeyRing.TranscodeSplitMessageToNonStandardAEAD(splitMessage, recipientKeyRing)
+	if err != nil {
+		t.Fatal("Expected no error while transcoding, got:", err)
+	}
+	decrypted, err = recipientKeyRing.Decrypt(transcoded.GetPGPMessage(), nil, 0)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the transcoded message, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+}
+
+func TestTranscodeSplitMessageToNonStandardAEADWithoutSEIPDv2(t *testing.T) {
+	message, err := keyRingTestPublic.Encrypt(NewPlainMessageFromString(testMessage), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	splitMessage, err := message.SplitMessage()
+	if err != nil {
+		t.Fatal("Expected no error while splitting the message, got:", err)
+	}
+
+	_, err = keyRingTestPrivate.TranscodeSplitMessageToNonStandardAEAD(splitMessage, nil)
+	assert.Error(t, err)
+	_, err = keyRingTestPrivate.TranscodeSplitMessageToNonStandardAEAD(splitMessage, keyRingTestPublic)
+	assert.Error(t, err)
+
+	_, err = keyRingTestPrivate.TranscodeSplitMessageToNonStandardAEAD(
+		NewPGPSplitMessage(nil, splitMessage.GetBinaryDataPacket()), nil,
+	)
+	assert.Error(t, err)
+
+	// The recipients of the kept key packets must be known
+	_, publicKeyRing := newSEIPDv2KeyRings(t)
+	_, err = publicKeyRing.TranscodeSplitMessageToNonStandardAEAD(splitMessage, nil)
+	assert.Error(t, err)
+
+	_, seipdv2Recipient := newSEIPDv2KeyRings(t)
+	transcoded, err := keyRingTestPrivate.TranscodeSplitMessageToNonStandardAEAD(splitMessage, seipdv2Recipient)
+	if err != nil {
+		t.Fatal("Expected no error while transcoding to SEIPDv2 recipients, got:", err)
+	}
+	assert.NotEmpty(t, transcoded.GetBinaryDataPacket())
+}
+
+func TestTranscodeToAEADCorrupted(t *testing.T) {
+	sessionKey, err := GenerateSessionKey()
+	if err != nil {
+		t.Fatal("Expected no error while generating session key, got:", err)
+	}
+	dataPacket, err := sessionKey.Encrypt(NewPlainMessageFromString(testMessage))
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	transcoded, err := sessionKey.TranscodeToAEAD(dataPacket)
+	if err != nil {
+		t.Fatal("Expected no error while transcoding, got:", err)
+	}
+	decrypted, err := sessionKey.Decrypt(transcoded)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the transcoded packet, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+
+	dataPacket[len(dataPacket)-1] ^= 1
+	_, err = sessionKey.TranscodeToAEAD(dataPacket)
+	assert.Error(t, err)
+
+	_, err = sessionKey.TranscodeToAEAD(transcoded)
+	assert.Error(t, err)
+}
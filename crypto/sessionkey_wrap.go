@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"github.com/ProtonMail/go-crypto/openpgp/aes/keywrap"
+	"github.com/pkg/errors"
+)
+
+// Wrap encrypts the session key under a key encryption key, with the AES key wrap
+// algorithm of RFC 3394, so that it can be stored next to the ciphertext.
+// kek must be a 16, 24 or 32 bytes AES key.
+// The wrapped data is the session key as serialized by SessionKey.Serialize,
+// padded to a multiple of 8 bytes as in the ECDH key packets of RFC 6637.
+func (sk *SessionKey) Wrap(kek []byte) ([]byte, error) {
+	serialized, err := sk.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	defer clearMem(serialized)
+
+	padded := padToBlockSize(serialized, 8)
+	defer clearMem(padded)
+	wrapped, err := keywrap.Wrap(kek, padded)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to wrap session key")
+	}
+	return wrapped, nil
+}
+
+// UnwrapSessionKey decrypts a session key wrapped with SessionKey.Wrap under the given key encryption key.
+// An error is returned if the key encryption key is wrong or the wrapped data was altered.
+func UnwrapSessionKey(wrappedKey, kek []byte) (*SessionKey, error) {
+	padded, err := keywrap.Unwrap(kek, wrappedKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to unwrap session key")
+	}
+	defer clearMem(padded)
+
+	serialized, err := unpadFromBlockSize(padded, 8)
+	if err != nil {
+		return nil, err
+	}
+	return NewSessionKeyFromSerialized(serialized)
+}
+
+// padToBlockSize appends PKCS#5 padding to data, always adding at least one byte.
+func padToBlockSize(data []byte, blockSize int) []byte {
+	padding := blockSize - len(data)%blockSize
+	padded := make([]byte, len(data), len(data)+padding)
+	copy(padded, data)
+	for i := 0; i < padding; i++ {
+		padded = append(padded, byte(padding))
+	}
+	return padded
+}
+
+func unpadFromBlockSize(padded []byte, blockSize int) ([]byte, error) {
+	if len(padded) == 0 {
+		return nil, errors.New("gopenpgp: invalid wrapped session key padding")
+	}
+	padding := int(padded[len(padded)-1])
+	if padding == 0 || padding > blockSize || padding > len(padded) {
+		return nil, errors.New("gopenpgp: invalid wrapped session key padding")
+	}
+	for _, b := range padded[len(padded)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("gopenpgp: invalid wrapped session key padding")
+		}
+	}
+	return padded[:len(padded)-padding], nil
+}
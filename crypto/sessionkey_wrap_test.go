@@ -0,0 +1,76 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionKeyWrap(t *testing.T) {
+	kek, err := RandomToken(32)
+	if err != nil {
+		t.Fatal("Expected no error while generating the key encryption key, got:", err)
+	}
+
+	for _, algo := range []string{constants.AES128, constants.AES256} {
+		sk, err := GenerateSessionKeyAlgo(algo)
+		if err != nil {
+			t.Fatal("Expected no error while generating the session key, got:", err)
+		}
+		wrapped, err := sk.Wrap(kek)
+		if err != nil {
+			t.Fatal("Expected no error while wrapping the session key, got:", err)
+		}
+		assert.Zero(t, len(wrapped)%8)
+
+		unwrapped, err := UnwrapSessionKey(wrapped, kek)
+		if err != nil {
+			t.Fatal("Expected no error while unwrapping the session key, got:", err)
+		}
+		assert.Exactly(t, sk, unwrapped)
+	}
+}
+
+func TestSessionKeyWrapV6(t *testing.T) {
+	kek, _ := RandomToken(16)
+	sk := NewSessionKeyFromToken(make([]byte, 32), "")
+	wrapped, err := sk.Wrap(kek)
+	if err != nil {
+		t.Fatal("Expected no error while wrapping the session key, got:", err)
+	}
+	unwrapped, err := UnwrapSessionKey(wrapped, kek)
+	if err != nil {
+		t.Fatal("Expected no error while unwrapping the session key, got:", err)
+	}
+	assert.Exactly(t, sk, unwrapped)
+}
+
+func TestSessionKeyUnwrapTampered(t *testing.T) {
+	kek, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	sk := NewSessionKeyFromToken(make([]byte, 16), constants.AES128)
+	wrapped, err := sk.Wrap(kek)
+	if err != nil {
+		t.Fatal("Expected no error while wrapping the session key, got:", err)
+	}
+	// 3 bytes of header, 16 bytes of key, 5 bytes of padding, 8 bytes of integrity check
+	assert.Len(t, wrapped, 32)
+
+	otherKek, _ := hex.DecodeString("0f0e0d0c0b0a09080706050403020100")
+	_, err = UnwrapSessionKey(wrapped, otherKek)
+	assert.Error(t, err)
+
+	wrapped[5] ^= 1
+	_, err = UnwrapSessionKey(wrapped, kek)
+	assert.Error(t, err)
+}
+
+func TestSessionKeyWrapInvalid(t *testing.T) {
+	_, err := NewSessionKeyFromToken(make([]byte, 10), constants.AES256).Wrap(make([]byte, 32))
+	assert.Error(t, err)
+
+	sk, _ := GenerateSessionKey()
+	_, err = sk.Wrap(make([]byte, 20))
+	assert.Error(t, err)
+}
@@ -116,7 +116,9 @@ func processSignatureExpiration(md *openpgp.MessageDetails, verifyTime int64) {
 }
 
 // verifyDetailsSignature verifies signature from message details.
-func verifyDetailsSignature(md *openpgp.MessageDetails, verifierKey *KeyRing, verificationContext *VerificationContext) error {
+func verifyDetailsSignature(md *openpgp.MessageDetails, verifierKey *KeyRing, verificationContext *VerificationContext) (err error) {
+	defer func() { reportVerification(err) }()
+
 	if !md.IsSigned {
 		return newSignatureNotSigned()
 	}
@@ -134,8 +136,7 @@ func verifyDetailsSignature(md *openpgp.MessageDetails, verifierKey *KeyRing, ve
 		return newSignatureInsecure()
 	}
 	if verificationContext != nil {
-		err := verificationContext.verifyContext(md.Signature)
-		if err != nil {
+		if err := verificationContext.verifyContext(md.Signature); err != nil {
 			return newSignatureBadContext(err)
 		}
 	}
@@ -232,7 +233,9 @@ func verifySignature(
 	signature []byte,
 	verifyTime int64,
 	verificationContext *VerificationContext,
-) (*packet.Signature, error) {
+) (_ *packet.Signature, err error) {
+	defer func() { reportVerification(err) }()
+
 	config := &packet.Config{}
 	if verifyTime == 0 {
 		config.Time = func() time.Time {
@@ -307,6 +310,7 @@ func signMessageDetached(
 	config := &packet.Config{
 		DefaultHash: crypto.SHA512,
 		Time:        getTimeGenerator(),
+		Rand:        getRandomSource(),
 	}
 
 	signEntity, err := signKeyRing.getSigningEntity()
@@ -319,11 +323,13 @@ func signMessageDetached(
 	}
 
 	var outBuf bytes.Buffer
+	counter := &countedReader{Reader: messageReader}
 	if isBinary {
-		err = openpgp.DetachSign(&outBuf, signEntity, messageReader, config)
+		err = openpgp.DetachSign(&outBuf, signEntity, counter, config)
 	} else {
-		err = openpgp.DetachSignText(&outBuf, signEntity, messageReader, config)
+		err = openpgp.DetachSignText(&outBuf, signEntity, counter, config)
 	}
+	reportOperation(constants.OperationSign, counter.bytes, err)
 	if err != nil {
 		return nil, errors.Wrap(err, "gopenpgp: error in signing")
 	}
@@ -0,0 +1,118 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"hash"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+
+	"github.com/ProtonMail/gopenpgp/v2/internal"
+)
+
+// sigTypeThirdPartyConfirmation is the type of the signatures over a signature, see RFC 4880, section 5.2.1.
+const sigTypeThirdPartyConfirmation packet.SignatureType = 0x50
+
+// Countersign creates a third-party confirmation signature over an existing signature,
+// e.g. a notary or approver signing the signature of a document rather than the document itself.
+// The countersignature doesn't cover the unhashed subpackets of the signature.
+func (keyRing *KeyRing) Countersign(signature *PGPSignature) (*PGPSignature, error) {
+	raw, err := parseRawSignature(signature.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &packet.Config{
+		DefaultHash: crypto.SHA512,
+		Time:        getTimeGenerator(),
+		Rand:        getRandomSource(),
+	}
+	signEntity, err := keyRing.getSigningEntity()
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := signEntity.SigningKey(config.Now())
+	if !ok || signer.PrivateKey == nil || signer.PrivateKey.Encrypted {
+		return nil, errors.New("gopenpgp: no valid signing key found")
+	}
+
+	countersignature := &packet.Signature{
+		Version:      signer.PrivateKey.Version,
+		SigType:      sigTypeThirdPartyConfirmation,
+		PubKeyAlgo:   signer.PrivateKey.PubKeyAlgo,
+		Hash:         config.Hash(),
+		CreationTime: config.Now(),
+		IssuerKeyId:  &signer.PrivateKey.KeyId,
+	}
+	if err := countersignature.Sign(raw.confirmationHash(countersignature.Hash), signer.PrivateKey, config); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in signing")
+	}
+
+	var outBuf bytes.Buffer
+	if err := countersignature.Serialize(&outBuf); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in serializing countersignature")
+	}
+	return NewPGPSignature(outBuf.Bytes()), nil
+}
+
+// VerifyCountersignature verifies a countersignature created with Countersign over the given signature,
+// with the keys of the keyring. If verifyTime is not 0, the countersignature and its signing key
+// must be valid at that time.
+// It doesn't verify the countersigned signature itself.
+// A SignatureVerificationError is returned if the verification fails.
+func (keyRing *KeyRing) VerifyCountersignature(signature, countersignature *PGPSignature, verifyTime int64) error {
+	raw, err := parseRawSignature(signature.Data)
+	if err != nil {
+		return err
+	}
+	p, err := packet.Read(bytes.NewReader(countersignature.Data))
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to parse countersignature")
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok || sig.SigType != sigTypeThirdPartyConfirmation {
+		return errors.New("gopenpgp: not a countersignature")
+	}
+	if sig.IssuerKeyId == nil {
+		return newSignatureNoVerifier()
+	}
+
+	keys := keyRing.entities.KeysByIdUsage(*sig.IssuerKeyId, packet.KeyFlagSign)
+	if len(keys) == 0 {
+		return newSignatureNoVerifier()
+	}
+	for _, key := range keys {
+		if err = key.PublicKey.VerifySignature(raw.confirmationHash(sig.Hash), sig); err != nil {
+			continue
+		}
+		if verifyTime == 0 {
+			return nil
+		}
+		now := time.Unix(verifyTime, 0)
+		switch {
+		case sig.CreationTime.After(time.Unix(verifyTime+internal.CreationTimeOffset, 0)):
+			return newSignatureFailed(errors.New("gopenpgp: countersignature created in the future"))
+		case sig.SigLifetimeSecs != nil && *sig.SigLifetimeSecs != 0 &&
+			now.After(sig.CreationTime.Add(time.Duration(*sig.SigLifetimeSecs)*time.Second)):
+			return newSignatureFailed(errors.New("gopenpgp: countersignature expired"))
+		case key.Entity.Revoked(now) || key.PublicKey.KeyExpired(key.SelfSignature, now):
+			return newSignatureFailed(errors.New("gopenpgp: countersignature key is expired or revoked"))
+		}
+		return nil
+	}
+	return newSignatureFailed(err)
+}
+
+// confirmationHash returns the hash of the signature as hashed by a third-party confirmation signature:
+// the body of the signature without unhashed subpackets, prefixed by an old format packet header.
+func (sig *rawSignature) confirmationHash(hashFunc crypto.Hash) hash.Hash {
+	h := hashFunc.New()
+	bodyLength := len(sig.hashedPart) + 2 + len(sig.signaturePart)
+	_, _ = h.Write([]byte{0x88, byte(bodyLength >> 24), byte(bodyLength >> 16), byte(bodyLength >> 8), byte(bodyLength)})
+	_, _ = h.Write(sig.hashedPart)
+	_, _ = h.Write([]byte{0, 0})
+	_, _ = h.Write(sig.signaturePart)
+	return h
+}
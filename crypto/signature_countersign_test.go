@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	goerrors "errors"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountersignature(t *testing.T) {
+	signature, err := keyRingTestPrivate.SignDetached(NewPlainMessageFromString("approved document"))
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+
+	notaryKey, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating the notary key, got:", err)
+	}
+	notaryKeyRing, err := NewKeyRing(notaryKey)
+	if err != nil {
+		t.Fatal("Expected no error while building the notary keyring, got:", err)
+	}
+
+	countersignature, err := notaryKeyRing.Countersign(signature)
+	if err != nil {
+		t.Fatal("Expected no error while countersigning, got:", err)
+	}
+	if err = notaryKeyRing.VerifyCountersignature(signature, countersignature, GetUnixTime()); err != nil {
+		t.Fatal("Expected no error while verifying the countersignature, got:", err)
+	}
+
+	// A countersignature is not a signature of the document
+	err = notaryKeyRing.VerifyDetached(NewPlainMessageFromString("approved document"), countersignature, GetUnixTime())
+	assert.Error(t, err)
+
+	// The unhashed subpackets of the countersigned signature are not covered
+	timestamped, err := signature.AddTimestamp(&testTimestampAuthority{genTime: time.Now(), trusted: true})
+	if err != nil {
+		t.Fatal("Expected no error while timestamping the signature, got:", err)
+	}
+	assert.NoError(t, notaryKeyRing.VerifyCountersignature(timestamped, countersignature, GetUnixTime()))
+
+	otherSignature, err := keyRingTestPrivate.SignDetached(NewPlainMessageFromString("other document"))
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	err = notaryKeyRing.VerifyCountersignature(otherSignature, countersignature, GetUnixTime())
+	var sigErr SignatureVerificationError
+	assert.True(t, goerrors.As(err, &sigErr))
+	assert.Exactly(t, constants.SIGNATURE_FAILED, sigErr.Status)
+
+	err = keyRingTestPublic.VerifyCountersignature(signature, countersignature, GetUnixTime())
+	assert.True(t, goerrors.As(err, &sigErr))
+	assert.Exactly(t, constants.SIGNATURE_NO_VERIFIER, sigErr.Status)
+
+	err = notaryKeyRing.VerifyCountersignature(countersignature, signature, GetUnixTime())
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"crypto"
+	"encoding/hex"
+	goerrors "errors"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+)
+
+// SignatureDetails describes the embedded signature of a decrypted message.
+type SignatureDetails struct {
+	// Status is the verification status of the signature, one of constants.SIGNATURE_*.
+	Status int
+	// SignerKeyID is the hex key ID of the key which issued the signature.
+	SignerKeyID string
+	// SignerFingerprint is the hex fingerprint of the key, or subkey, which issued the signature,
+	// if it is in the verification keyring.
+	SignerFingerprint string
+	// CreationTime is the creation time of the signature, 0 if the signature was not parsed.
+	CreationTime int64
+	// HashAlgorithm is the name of the hash of the signature, e.g. constants.SHA256,
+	// empty if the signature was not parsed.
+	HashAlgorithm string
+}
+
+// VerifySignatureDetails verifies the embedded signature as VerifySignature,
+// and returns the details of the signature, which are nil if the message isn't signed.
+// This method needs to be called once all the data has been read.
+// The verification error, if any, is returned along with the details.
+func (msg *PlainMessageReader) VerifySignatureDetails() (*SignatureDetails, error) {
+	err := msg.VerifySignature()
+	if !msg.readAll || !msg.details.IsSigned {
+		return nil, err
+	}
+
+	details := &SignatureDetails{
+		Status:      constants.SIGNATURE_OK,
+		SignerKeyID: keyIDToHex(msg.details.SignedByKeyId),
+	}
+	if err != nil {
+		details.Status = constants.SIGNATURE_FAILED
+		var sigErr SignatureVerificationError
+		if goerrors.As(err, &sigErr) {
+			details.Status = sigErr.Status
+		}
+	}
+	if msg.details.SignedBy != nil {
+		details.SignerFingerprint = hex.EncodeToString(msg.details.SignedBy.PublicKey.Fingerprint)
+	}
+	if msg.details.Signature != nil {
+		details.CreationTime = msg.details.Signature.CreationTime.Unix()
+		details.HashAlgorithm = hashName(msg.details.Signature.Hash)
+	}
+	return details, err
+}
+
+// hashName returns the name of the hash, as in the constants for the supported hashes.
+func hashName(hash crypto.Hash) string {
+	for name, h := range plaintextHashAlgos {
+		if h == hash {
+			return name
+		}
+	}
+	return strings.ToLower(strings.ReplaceAll(hash.String(), "-", ""))
+}
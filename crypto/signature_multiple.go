@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	goerrors "errors"
+	"strconv"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+// SignDetachedWithAllKeys generates a detached PGPSignature for a given PlainMessage
+// with each unlocked private key of the keyring, instead of the first one only as SignDetached does.
+// The signature packets are concatenated in the order of the keys,
+// and can be verified with VerifyDetachedSignatures.
+func (keyRing *KeyRing) SignDetachedWithAllKeys(message *PlainMessage) (*PGPSignature, error) {
+	var signatures bytes.Buffer
+	for _, entity := range keyRing.entities {
+		if entity.PrivateKey == nil || entity.PrivateKey.Encrypted {
+			continue
+		}
+		signature, err := signMessageDetached(
+			&KeyRing{entities: openpgp.EntityList{entity}},
+			message.NewReader(),
+			message.IsBinary(),
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		signatures.Write(signature.GetBinary())
+	}
+	if signatures.Len() == 0 {
+		return nil, errors.New("gopenpgp: cannot sign message, unable to unlock signer key")
+	}
+	return NewPGPSignature(signatures.Bytes()), nil
+}
+
+// VerifyDetachedSignatures verifies each signature packet of a detached PGPSignature,
+// e.g. as generated by SignDetachedWithAllKeys, and returns the details of all the signatures,
+// in order, with their status: constants.SIGNATURE_NO_VERIFIER if the signer isn't in the keyring.
+// An error is only returned if the signature packets can't be parsed.
+func (keyRing *KeyRing) VerifyDetachedSignatures(
+	message *PlainMessage, signature *PGPSignature, verifyTime int64,
+) ([]*SignatureDetails, error) {
+	data := signature.GetBinary()
+	var allDetails []*SignatureDetails
+	for offset := 0; offset < len(data); {
+		_, length, expected, found := readPacketLayout(data[offset:])
+		if expected != "" {
+			return nil, errors.New("gopenpgp: malformed signature packet at offset " + strconv.Itoa(offset) +
+				": expected " + expected + ", found " + found)
+		}
+		rawSignature := data[offset : offset+length]
+		offset += length
+
+		p, err := packet.Read(bytes.NewReader(rawSignature))
+		if err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: unable to read the signature packet")
+		}
+		sig, ok := p.(*packet.Signature)
+		if !ok {
+			return nil, errors.New("gopenpgp: the detached signature contains a packet that is not a signature")
+		}
+		allDetails = append(allDetails, keyRing.verifySignaturePacket(message, sig, rawSignature, verifyTime))
+	}
+	if len(allDetails) == 0 {
+		return nil, errors.New("gopenpgp: the detached signature contains no signature packet")
+	}
+	return allDetails, nil
+}
+
+// verifySignaturePacket verifies a single signature packet and returns its details.
+func (keyRing *KeyRing) verifySignaturePacket(
+	message *PlainMessage, sig *packet.Signature, rawSignature []byte, verifyTime int64,
+) *SignatureDetails {
+	details := &SignatureDetails{
+		Status:        constants.SIGNATURE_OK,
+		CreationTime:  sig.CreationTime.Unix(),
+		HashAlgorithm: hashName(sig.Hash),
+	}
+	if sig.IssuerKeyId != nil {
+		details.SignerKeyID = keyIDToHex(*sig.IssuerKeyId)
+		if keys := keyRing.entities.KeysById(*sig.IssuerKeyId); len(keys) > 0 {
+			details.SignerFingerprint = hex.EncodeToString(keys[0].PublicKey.Fingerprint)
+		}
+	}
+	if details.SignerFingerprint == "" {
+		details.Status = constants.SIGNATURE_NO_VERIFIER
+		return details
+	}
+
+	_, err := verifySignature(keyRing.entities, message.NewReader(), rawSignature, verifyTime, nil)
+	if err != nil {
+		details.Status = constants.SIGNATURE_FAILED
+		var sigErr SignatureVerificationError
+		if goerrors.As(err, &sigErr) {
+			details.Status = sigErr.Status
+		}
+	}
+	return details
+}
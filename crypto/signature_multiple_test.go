@@ -0,0 +1,65 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignDetachedWithAllKeys(t *testing.T) {
+	keyRing, err := NewKeyRing(nil)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	for _, keyType := range []string{"x25519", "rsa"} {
+		key, err := GenerateKey(keyTestName, keyTestDomain, keyType, 2048)
+		if err != nil {
+			t.Fatal("Expected no error while generating key, got:", err)
+		}
+		if err = keyRing.AddKey(key); err != nil {
+			t.Fatal("Expected no error while adding key, got:", err)
+		}
+	}
+	message := NewPlainMessageFromString(testMessage)
+
+	signature, err := keyRing.SignDetachedWithAllKeys(message)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	allDetails, err := keyRing.VerifyDetachedSignatures(message, signature, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while verifying, got:", err)
+	}
+	assert.Len(t, allDetails, 2)
+	for i, details := range allDetails {
+		assert.Exactly(t, constants.SIGNATURE_OK, details.Status)
+		assert.Exactly(t, keyRing.GetKeys()[i].GetHexKeyID(), details.SignerKeyID)
+		assert.Exactly(t, keyRing.GetKeys()[i].GetFingerprint(), details.SignerFingerprint)
+	}
+
+	// Only the first key is known
+	firstKeyRing, err := NewKeyRing(keyRing.GetKeys()[0])
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	assert.NoError(t, firstKeyRing.VerifyDetached(message, signature, GetUnixTime()))
+	allDetails, err = firstKeyRing.VerifyDetachedSignatures(message, signature, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while verifying, got:", err)
+	}
+	assert.Exactly(t, constants.SIGNATURE_OK, allDetails[0].Status)
+	assert.Exactly(t, constants.SIGNATURE_NO_VERIFIER, allDetails[1].Status)
+
+	allDetails, err = keyRing.VerifyDetachedSignatures(NewPlainMessageFromString("other"), signature, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while verifying, got:", err)
+	}
+	assert.Exactly(t, constants.SIGNATURE_FAILED, allDetails[0].Status)
+	assert.Exactly(t, constants.SIGNATURE_FAILED, allDetails[1].Status)
+
+	_, err = keyRingTestPublic.SignDetachedWithAllKeys(message)
+	assert.Error(t, err)
+	_, err = keyRing.VerifyDetachedSignatures(message, NewPGPSignature([]byte{0x01}), GetUnixTime())
+	assert.Error(t, err)
+}
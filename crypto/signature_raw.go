@@ -0,0 +1,305 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+)
+
+const (
+	signaturePacketTag           = 2
+	notationDataSubpacketType    = 20
+	maxSignatureSubpacketsLength = 0xffff
+)
+
+// rawSignature is a version 4 signature packet split around its unhashed subpackets,
+// which can be modified without invalidating the signature.
+type rawSignature struct {
+	// hashedPart goes from the version to the hashed subpackets included.
+	hashedPart         []byte
+	unhashedSubpackets []byte
+	// signaturePart contains the left 16 bits of the hash and the signature itself.
+	signaturePart []byte
+}
+
+func parseRawSignature(data []byte) (*rawSignature, error) {
+	tag, body, rest, err := readRawPacket(data)
+	if err != nil {
+		return nil, err
+	}
+	if tag != signaturePacketTag || len(rest) != 0 {
+		return nil, errors.New("gopenpgp: expected a single signature packet")
+	}
+	if len(body) < 6 || body[0] != 4 {
+		return nil, errors.New("gopenpgp: unsupported signature version")
+	}
+	hashedEnd := 6 + int(binary.BigEndian.Uint16(body[4:6]))
+	if len(body) < hashedEnd+2 {
+		return nil, errors.New("gopenpgp: invalid signature packet")
+	}
+	unhashedEnd := hashedEnd + 2 + int(binary.BigEndian.Uint16(body[hashedEnd:hashedEnd+2]))
+	if len(body) < unhashedEnd {
+		return nil, errors.New("gopenpgp: invalid signature packet")
+	}
+	return &rawSignature{
+		hashedPart:         clone(body[:hashedEnd]),
+		unhashedSubpackets: clone(body[hashedEnd+2 : unhashedEnd]),
+		signaturePart:      clone(body[unhashedEnd:]),
+	}, nil
+}
+
+func (sig *rawSignature) serialize() []byte {
+	bodyLength := len(sig.hashedPart) + 2 + len(sig.unhashedSubpackets) + len(sig.signaturePart)
+	packet := make([]byte, 0, 6+bodyLength)
+	packet = append(packet, 0xc0|signaturePacketTag)
+	packet = appendRawLength(packet, bodyLength)
+	packet = append(packet, sig.hashedPart...)
+	packet = append(packet, byte(len(sig.unhashedSubpackets)>>8), byte(len(sig.unhashedSubpackets)))
+	packet = append(packet, sig.unhashedSubpackets...)
+	return append(packet, sig.signaturePart...)
+}
+
+// getUnhashedNotation returns the value of the first unhashed notation with the given name, or nil.
+func (sig *rawSignature) getUnhashedNotation(name string) ([]byte, error) {
+	var value []byte
+	err := forEachRawSubpacket(sig.unhashedSubpackets, func(subpacket []byte) bool {
+		if notationName, notationValue, ok := parseRawNotation(subpacket); ok && notationName == name {
+			value = clone(notationValue)
+			return false
+		}
+		return true
+	})
+	return value, err
+}
+
+// setUnhashedNotation replaces the unhashed notations with the given name by a binary one with the given value.
+func (sig *rawSignature) setUnhashedNotation(name string, value []byte) error {
+	if len(name) > 0xffff || len(value) > 0xffff {
+		return errors.New("gopenpgp: notation is too long")
+	}
+
+	var subpackets []byte
+	err := forEachRawSubpacket(sig.unhashedSubpackets, func(subpacket []byte) bool {
+		if notationName, _, ok := parseRawNotation(subpacket); !ok || notationName != name {
+			subpackets = appendRawLength(subpackets, len(subpacket))
+			subpackets = append(subpackets, subpacket...)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	notation := make([]byte, 0, 9+len(name)+len(value))
+	notation = append(notation, notationDataSubpacketType, 0, 0, 0, 0)
+	notation = append(notation, byte(len(name)>>8), byte(len(name)), byte(len(value)>>8), byte(len(value)))
+	notation = append(notation, name...)
+	notation = append(notation, value...)
+	subpackets = appendRawLength(subpackets, len(notation))
+	subpackets = append(subpackets, notation...)
+	if len(subpackets) > maxSignatureSubpacketsLength {
+		return errors.New("gopenpgp: signature subpackets are too long")
+	}
+	sig.unhashedSubpackets = subpackets
+	return nil
+}
+
+// forEachRawSubpacket calls f with each subpacket, type included, until f returns false.
+func forEachRawSubpacket(subpackets []byte, f func(subpacket []byte) bool) error {
+	for len(subpackets) > 0 {
+		length, headerLength, err := readRawLength(subpackets)
+		if err != nil {
+			return err
+		}
+		if length == 0 || len(subpackets) < headerLength+length {
+			return errors.New("gopenpgp: invalid signature subpacket")
+		}
+		if !f(subpackets[headerLength : headerLength+length]) {
+			return nil
+		}
+		subpackets = subpackets[headerLength+length:]
+	}
+	return nil
+}
+
+func parseRawNotation(subpacket []byte) (name string, value []byte, ok bool) {
+	if subpacket[0]&0x7f != notationDataSubpacketType || len(subpacket) < 9 {
+		return "", nil, false
+	}
+	nameLength := int(binary.BigEndian.Uint16(subpacket[5:7]))
+	valueLength := int(binary.BigEndian.Uint16(subpacket[7:9]))
+	if len(subpacket) != 9+nameLength+valueLength {
+		return "", nil, false
+	}
+	return string(subpacket[9 : 9+nameLength]), subpacket[9+nameLength:], true
+}
+
+// readRawPacket splits the first packet of data into its tag and body.
+func readRawPacket(data []byte) (tag byte, body, rest []byte, err error) {
+	if len(data) == 0 || data[0]&0x80 == 0 {
+		return 0, nil, nil, errors.New("gopenpgp: invalid packet header")
+	}
+	var length, headerLength int
+	if data[0]&0x40 != 0 {
+		// New format packet
+		tag = data[0] & 0x3f
+		if length, headerLength, err = readRawLength(data[1:]); err != nil {
+			return 0, nil, nil, err
+		}
+		headerLength++
+	} else {
+		// Old format packet
+		tag = (data[0] & 0x3f) >> 2
+		switch lengthType := data[0] & 3; lengthType {
+		case 3:
+			length, headerLength = len(data)-1, 1
+		default:
+			headerLength = 1 + 1<<lengthType
+			if len(data) < headerLength {
+				return 0, nil, nil, errors.New("gopenpgp: invalid packet header")
+			}
+			for _, b := range data[1:headerLength] {
+				length = length<<8 | int(b)
+			}
+		}
+	}
+	if length < 0 || len(data) < headerLength+length {
+		return 0, nil, nil, errors.New("gopenpgp: truncated packet")
+	}
+	return tag, data[headerLength : headerLength+length], data[headerLength+length:], nil
+}
+
+// readRawLength reads a new format packet length, or a subpacket length, partial lengths excluded.
+func readRawLength(data []byte) (length, headerLength int, err error) {
+	switch {
+	case len(data) > 0 && data[0] < 192:
+		return int(data[0]), 1, nil
+	case len(data) > 1 && data[0] < 224:
+		return (int(data[0])-192)<<8 + int(data[1]) + 192, 2, nil
+	case len(data) > 4 && data[0] == 255:
+		return int(binary.BigEndian.Uint32(data[1:5])), 5, nil
+	}
+	return 0, 0, errors.New("gopenpgp: invalid packet length")
+}
+
+// appendRawLength appends a new format packet length, or a subpacket length.
+func appendRawLength(data []byte, length int) []byte {
+	switch {
+	case length < 192:
+		return append(data, byte(length))
+	case length < 8384:
+		length -= 192
+		return append(data, byte(length>>8)+192, byte(length))
+	}
+	return append(data, 255, byte(length>>24), byte(length>>16), byte(length>>8), byte(length))
+}
+
+// signWithSubpackets signs as sig.Sign, with additional hashed subpackets which go-crypto can't build,
+// given as subpacket bodies starting with their type. newHash returns a new hash of the signed data,
+// and the returned signature is parsed back from its serialization.
+func signWithSubpackets(
+	sig *packet.Signature,
+	newHash func() hash.Hash,
+	priv *packet.PrivateKey,
+	config *packet.Config,
+	subpackets ...[]byte,
+) (*packet.Signature, error) {
+	if priv.Version != 4 {
+		return nil, errors.New("gopenpgp: unsupported signing key version")
+	}
+	raw, err := signRaw(sig, newHash(), priv, config)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPart := raw.hashedPart
+	for _, subpacket := range subpackets {
+		hashedPart = appendRawSubpacket(hashedPart, subpacket)
+	}
+	hashedLength := len(hashedPart) - 6
+	if hashedLength > maxSignatureSubpacketsLength {
+		return nil, errors.New("gopenpgp: signature subpackets are too long")
+	}
+	hashedPart[4], hashedPart[5] = byte(hashedLength>>8), byte(hashedLength)
+	raw.hashedPart = hashedPart
+
+	// The digest over the new hashed subpackets is signed as is, and only the signature values are kept
+	h := newHash()
+	raw.writeHashSuffix(h)
+	signedRaw, err := signRaw(sig, &presetDigest{Hash: h, digest: h.Sum(nil)}, priv, config)
+	if err != nil {
+		return nil, err
+	}
+	raw.signaturePart = signedRaw.signaturePart
+
+	p, err := packet.Read(bytes.NewReader(raw.serialize()))
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in reading signature")
+	}
+	signed, ok := p.(*packet.Signature)
+	if !ok {
+		return nil, errors.New("gopenpgp: error in reading signature")
+	}
+	return signed, nil
+}
+
+func signRaw(sig *packet.Signature, h hash.Hash, priv *packet.PrivateKey, config *packet.Config) (*rawSignature, error) {
+	if err := sig.Sign(h, priv, config); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in signing")
+	}
+	var serialized bytes.Buffer
+	if err := sig.Serialize(&serialized); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in serializing signature")
+	}
+	return parseRawSignature(serialized.Bytes())
+}
+
+// getHashedSubpacket returns the body of the first hashed subpacket of the given type, type excluded, or nil.
+func getHashedSubpacket(sig *packet.Signature, subpacketType byte) ([]byte, error) {
+	var serialized bytes.Buffer
+	if err := sig.Serialize(&serialized); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in serializing signature")
+	}
+	raw, err := parseRawSignature(serialized.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	var body []byte
+	err = forEachRawSubpacket(raw.hashedPart[6:], func(subpacket []byte) bool {
+		if subpacket[0]&0x7f == subpacketType {
+			body = subpacket[1:]
+			return false
+		}
+		return true
+	})
+	return body, err
+}
+
+// writeHashSuffix writes the hashed part of a version 4 signature and its trailer.
+func (sig *rawSignature) writeHashSuffix(h hash.Hash) {
+	_, _ = h.Write(sig.hashedPart)
+	length := len(sig.hashedPart)
+	_, _ = h.Write([]byte{4, 0xff, byte(length >> 24), byte(length >> 16), byte(length >> 8), byte(length)})
+}
+
+// presetDigest is a hash.Hash returning a precomputed digest, to sign data hashed beforehand.
+type presetDigest struct {
+	hash.Hash
+	digest []byte
+}
+
+func (d *presetDigest) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (d *presetDigest) Sum(b []byte) []byte {
+	return append(b, d.digest...)
+}
+
+func appendRawSubpacket(subpackets, subpacket []byte) []byte {
+	subpackets = appendRawLength(subpackets, len(subpacket))
+	return append(subpackets, subpacket...)
+}
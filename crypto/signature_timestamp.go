@@ -0,0 +1,164 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+// TimestampAuthority is the integration point with an RFC 3161 timestamping authority,
+// e.g. a client of its HTTP interface.
+type TimestampAuthority interface {
+	// Timestamp requests a timestamp token over the given SHA-256 digest,
+	// and returns the DER encoded TimeStampToken of the response.
+	Timestamp(digest []byte) ([]byte, error)
+	// VerifyToken checks the CMS signature of the DER encoded TimeStampToken,
+	// and that it was issued by a trusted authority.
+	VerifyToken(token []byte) error
+}
+
+// AddTimestamp requests a timestamp token over the signature from the timestamping authority,
+// and returns a copy of the signature with the token stored in an unhashed notation,
+// giving a long-term proof that the signature existed at the time of the token.
+// The token covers the whole signature packet except its unhashed subpackets,
+// hence the signature stays valid and any previous token is replaced.
+func (sig *PGPSignature) AddTimestamp(tsa TimestampAuthority) (*PGPSignature, error) {
+	raw, err := parseRawSignature(sig.Data)
+	if err != nil {
+		return nil, err
+	}
+	token, err := tsa.Timestamp(raw.timestampDigest())
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to get timestamp token")
+	}
+	if err := raw.setUnhashedNotation(constants.TimestampNotationName, token); err != nil {
+		return nil, err
+	}
+	return &PGPSignature{Data: raw.serialize()}, nil
+}
+
+// GetTimestampToken returns the DER encoded RFC 3161 timestamp token stored in the signature,
+// or nil if the signature doesn't have one.
+func (sig *PGPSignature) GetTimestampToken() ([]byte, error) {
+	raw, err := parseRawSignature(sig.Data)
+	if err != nil {
+		return nil, err
+	}
+	return raw.getUnhashedNotation(constants.TimestampNotationName)
+}
+
+// VerifyTimestamp checks the timestamp token stored in the signature,
+// i.e. that it was issued over this signature and is verified by the timestamping authority,
+// and returns the unix time of the token.
+// It doesn't verify the signature itself, see KeyRing.VerifyDetachedWithTimestamp.
+func (sig *PGPSignature) VerifyTimestamp(tsa TimestampAuthority) (int64, error) {
+	raw, err := parseRawSignature(sig.Data)
+	if err != nil {
+		return 0, err
+	}
+	token, err := raw.getUnhashedNotation(constants.TimestampNotationName)
+	if err != nil {
+		return 0, err
+	}
+	if token == nil {
+		return 0, errors.New("gopenpgp: the signature has no timestamp token")
+	}
+
+	info, err := parseTimestampToken(token)
+	if err != nil {
+		return 0, err
+	}
+	if !info.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) {
+		return 0, errors.New("gopenpgp: unsupported timestamp token hash algorithm")
+	}
+	if !bytes.Equal(info.MessageImprint.HashedMessage, raw.timestampDigest()) {
+		return 0, errors.New("gopenpgp: the timestamp token was not issued for this signature")
+	}
+	if err := tsa.VerifyToken(token); err != nil {
+		return 0, errors.Wrap(err, "gopenpgp: invalid timestamp token")
+	}
+	return info.GenTime.Unix(), nil
+}
+
+// VerifyDetachedWithTimestamp verifies a detached signature as VerifyDetached,
+// and its timestamp token as PGPSignature.VerifyTimestamp.
+// It returns the unix time of the timestamp token.
+func (keyRing *KeyRing) VerifyDetachedWithTimestamp(
+	message *PlainMessage, signature *PGPSignature, verifyTime int64, tsa TimestampAuthority,
+) (int64, error) {
+	if err := keyRing.VerifyDetached(message, signature, verifyTime); err != nil {
+		return 0, err
+	}
+	return signature.VerifyTimestamp(tsa)
+}
+
+// timestampDigest returns the SHA-256 digest of the signature packet without its unhashed subpackets.
+func (sig *rawSignature) timestampDigest() []byte {
+	h := sha256.New()
+	_, _ = h.Write(sig.hashedPart)
+	_, _ = h.Write(sig.signaturePart)
+	return h.Sum(nil)
+}
+
+// ------ RFC 3161 timestamp tokens -------
+
+var (
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidSHA256     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+)
+
+// timestampToken is the CMS ContentInfo of a TimeStampToken.
+type timestampToken struct {
+	ContentType asn1.ObjectIdentifier
+	SignedData  timestampSignedData `asn1:"explicit,tag:0"`
+}
+
+// timestampSignedData is the beginning of a CMS SignedData:
+// the certificates and signer infos are checked by the TimestampAuthority.
+type timestampSignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo timestampEncapContentInfo
+}
+
+type timestampEncapContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     []byte `asn1:"explicit,tag:0"`
+}
+
+// timestampInfo is the beginning of a TSTInfo, ignoring the optional fields after genTime.
+type timestampInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint timestampMessageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+}
+
+type timestampMessageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+func parseTimestampToken(token []byte) (*timestampInfo, error) {
+	var contentInfo timestampToken
+	if _, err := asn1.Unmarshal(token, &contentInfo); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to parse timestamp token")
+	}
+	if !contentInfo.ContentType.Equal(oidSignedData) ||
+		!contentInfo.SignedData.EncapContentInfo.ContentType.Equal(oidTSTInfo) {
+		return nil, errors.New("gopenpgp: the timestamp token doesn't contain a TSTInfo")
+	}
+	var info timestampInfo
+	if _, err := asn1.Unmarshal(contentInfo.SignedData.EncapContentInfo.Content, &info); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to parse timestamp token info")
+	}
+	return &info, nil
+}
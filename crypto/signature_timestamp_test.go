@@ -0,0 +1,125 @@
+package crypto
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTimestampAuthority struct {
+	genTime time.Time
+	trusted bool
+}
+
+func (tsa *testTimestampAuthority) Timestamp(digest []byte) ([]byte, error) {
+	info, err := asn1.Marshal(timestampInfo{
+		Version: 1,
+		Policy:  asn1.ObjectIdentifier{1, 2, 3, 4},
+		MessageImprint: timestampMessageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			HashedMessage: digest,
+		},
+		SerialNumber: big.NewInt(42),
+		GenTime:      tsa.genTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(timestampToken{
+		ContentType: oidSignedData,
+		SignedData: timestampSignedData{
+			Version:          3,
+			DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+			EncapContentInfo: timestampEncapContentInfo{ContentType: oidTSTInfo, Content: info},
+		},
+	})
+}
+
+func (tsa *testTimestampAuthority) VerifyToken(token []byte) error {
+	if !tsa.trusted {
+		return errors.New("untrusted authority")
+	}
+	return nil
+}
+
+func TestSignatureTimestamp(t *testing.T) {
+	message := NewPlainMessageFromString("signed document")
+	signature, err := keyRingTestPrivate.SignDetached(message)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	token, err := signature.GetTimestampToken()
+	if err != nil {
+		t.Fatal("Expected no error while getting the timestamp token, got:", err)
+	}
+	assert.Nil(t, token)
+	_, err = signature.VerifyTimestamp(&testTimestampAuthority{trusted: true})
+	assert.Error(t, err)
+
+	genTime := time.Unix(1700000000, 0).UTC()
+	tsa := &testTimestampAuthority{genTime: genTime, trusted: true}
+	timestamped, err := signature.AddTimestamp(tsa)
+	if err != nil {
+		t.Fatal("Expected no error while timestamping the signature, got:", err)
+	}
+	token, err = timestamped.GetTimestampToken()
+	if err != nil {
+		t.Fatal("Expected no error while getting the timestamp token, got:", err)
+	}
+	assert.NotEmpty(t, token)
+
+	timestampTime, err := keyRingTestPublic.VerifyDetachedWithTimestamp(message, timestamped, GetUnixTime(), tsa)
+	if err != nil {
+		t.Fatal("Expected no error while verifying the timestamped signature, got:", err)
+	}
+	assert.Exactly(t, genTime.Unix(), timestampTime)
+
+	// A new token replaces the previous one
+	tsa.genTime = genTime.Add(time.Hour)
+	retimestamped, err := timestamped.AddTimestamp(tsa)
+	if err != nil {
+		t.Fatal("Expected no error while timestamping the signature, got:", err)
+	}
+	assert.Len(t, retimestamped.GetBinary(), len(timestamped.GetBinary()))
+	timestampTime, err = retimestamped.VerifyTimestamp(tsa)
+	if err != nil {
+		t.Fatal("Expected no error while verifying the timestamp, got:", err)
+	}
+	assert.Exactly(t, genTime.Add(time.Hour).Unix(), timestampTime)
+
+	_, err = timestamped.VerifyTimestamp(&testTimestampAuthority{trusted: false})
+	assert.Error(t, err)
+}
+
+func TestSignatureTimestampOtherSignature(t *testing.T) {
+	tsa := &testTimestampAuthority{genTime: time.Unix(1700000000, 0), trusted: true}
+	signature, err := keyRingTestPrivate.SignDetached(NewPlainMessageFromString("first"))
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	otherSignature, err := keyRingTestPrivate.SignDetached(NewPlainMessageFromString("second"))
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	timestamped, err := otherSignature.AddTimestamp(tsa)
+	if err != nil {
+		t.Fatal("Expected no error while timestamping the signature, got:", err)
+	}
+	token, _ := timestamped.GetTimestampToken()
+
+	raw, err := parseRawSignature(signature.GetBinary())
+	if err != nil {
+		t.Fatal("Expected no error while parsing the signature, got:", err)
+	}
+	if err = raw.setUnhashedNotation(constants.TimestampNotationName, token); err != nil {
+		t.Fatal("Expected no error while setting the notation, got:", err)
+	}
+	_, err = NewPGPSignature(raw.serialize()).VerifyTimestamp(tsa)
+	assert.Error(t, err)
+}
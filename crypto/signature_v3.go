@@ -0,0 +1,311 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/dsa"   //nolint:staticcheck
+	_ "crypto/md5" //nolint:gosec
+	"crypto/rsa"
+	_ "crypto/sha1" //nolint:gosec
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgpErrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/pkg/errors"
+
+	"github.com/ProtonMail/gopenpgp/v2/internal"
+)
+
+// VerificationDetails reports the properties of a signature verified with a DecryptionPolicy,
+// and the insecure properties that were tolerated because of the policy.
+type VerificationDetails struct {
+	// SignatureVersion is the version of the verified signature packet.
+	SignatureVersion int
+	// Warnings describes the insecure properties of the signature that were tolerated.
+	Warnings []string
+}
+
+// GetSignatureVersion returns the version of the verified signature packet.
+func (details *VerificationDetails) GetSignatureVersion() int {
+	return details.SignatureVersion
+}
+
+// HasWarnings returns true if insecure properties of the signature were tolerated.
+func (details *VerificationDetails) HasWarnings() bool {
+	return len(details.Warnings) > 0
+}
+
+// VerifyDetachedWithPolicy verifies a PlainMessage with a detached PGPSignature
+// and returns a SignatureVerificationError if fails.
+// The verification is restricted by the given policy, and the tolerated insecure properties
+// of the signature are reported in the returned VerificationDetails.
+func (keyRing *KeyRing) VerifyDetachedWithPolicy(
+	message *PlainMessage, signature *PGPSignature, verifyTime int64, policy *DecryptionPolicy,
+) (*VerificationDetails, error) {
+	if policy == nil {
+		policy = NewDecryptionPolicy()
+	}
+
+	if err := policy.check(); err != nil {
+		return nil, err
+	}
+	if policy.isStrict() && !isSingleSignaturePacket(signature.GetBinary()) {
+		return nil, errors.New("gopenpgp: detached signature is not a single signature packet")
+	}
+
+	if v3Sig, err := readV3Signature(signature.GetBinary()); err == nil {
+		if !policy.allowV3Signatures && !policy.isInterop() {
+			return nil, newSignatureInsecure()
+		}
+		key, err := v3Sig.verify(keyRing.entities, message.NewReader(), verifyTime)
+		if err != nil {
+			return nil, err
+		}
+		details := &VerificationDetails{SignatureVersion: 3}
+		details.Warnings = append(details.Warnings, "signature is a legacy version 3 signature")
+		warnings, err := policy.checkSigningKey(key)
+		if err != nil {
+			return nil, err
+		}
+		details.Warnings = append(details.Warnings, warnings...)
+		return details, nil
+	}
+
+	sig, err := verifySignature(keyRing.entities, message.NewReader(), signature.GetBinary(), verifyTime, nil)
+	if err != nil {
+		return nil, err
+	}
+	details := &VerificationDetails{SignatureVersion: sig.Version}
+	if sig.IssuerKeyId == nil {
+		return details, nil
+	}
+	if keys := keyRing.entities.KeysById(*sig.IssuerKeyId); len(keys) > 0 {
+		warnings, err := policy.checkSigningKey(&keys[0])
+		if err != nil {
+			return nil, err
+		}
+		details.Warnings = append(details.Warnings, warnings...)
+	}
+	return details, nil
+}
+
+// v3Signature is a version 3 signature packet, see RFC 4880, section 5.2.2.
+type v3Signature struct {
+	sigType      packet.SignatureType
+	creationTime uint32
+	issuerKeyID  uint64
+	pubKeyAlgo   packet.PublicKeyAlgorithm
+	hash         crypto.Hash
+	hashPrefix   []byte
+	mpis         []*big.Int
+}
+
+var v3SignatureHashes = map[uint8]crypto.Hash{
+	1:  crypto.MD5,
+	2:  crypto.SHA1,
+	8:  crypto.SHA256,
+	9:  crypto.SHA384,
+	10: crypto.SHA512,
+	11: crypto.SHA224,
+}
+
+// readV3Signature parses a binary version 3 signature packet.
+func readV3Signature(data []byte) (*v3Signature, error) {
+	tag, body, err := parsePacket(data)
+	if err != nil {
+		return nil, err
+	}
+	// Version, hashed length, signature type, creation time, key ID,
+	// public key algorithm, hash algorithm and hash prefix
+	if tag != 2 || len(body) < 19 || body[0] != 3 || body[1] != 5 {
+		return nil, errors.New("gopenpgp: not a version 3 signature")
+	}
+	sig := &v3Signature{
+		sigType:      packet.SignatureType(body[2]),
+		creationTime: binary.BigEndian.Uint32(body[3:7]),
+		issuerKeyID:  binary.BigEndian.Uint64(body[7:15]),
+		pubKeyAlgo:   packet.PublicKeyAlgorithm(body[15]),
+		hashPrefix:   body[17:19],
+	}
+	var ok bool
+	if sig.hash, ok = v3SignatureHashes[body[16]]; !ok || !sig.hash.Available() {
+		return nil, errors.New("gopenpgp: unsupported hash function in version 3 signature")
+	}
+	if sig.sigType != packet.SigTypeBinary && sig.sigType != packet.SigTypeText {
+		return nil, errors.New("gopenpgp: unsupported version 3 signature type")
+	}
+	for rest := body[19:]; len(rest) > 0; {
+		if len(rest) < 2 {
+			return nil, errors.New("gopenpgp: malformed version 3 signature")
+		}
+		length := (int(binary.BigEndian.Uint16(rest)) + 7) / 8
+		if len(rest) < 2+length {
+			return nil, errors.New("gopenpgp: malformed version 3 signature")
+		}
+		sig.mpis = append(sig.mpis, new(big.Int).SetBytes(rest[2:2+length]))
+		rest = rest[2+length:]
+	}
+	return sig, nil
+}
+
+// verify verifies the signature over the data with the matching signing keys of the entity list,
+// that must be valid at verifyTime, and returns the key that verified it.
+func (sig *v3Signature) verify(entities openpgp.EntityList, data io.Reader, verifyTime int64) (*openpgp.Key, error) {
+	if verifyTime != 0 && int64(sig.creationTime) > verifyTime+internal.CreationTimeOffset {
+		return nil, newSignatureFailed(errors.New("gopenpgp: signature was created in the future"))
+	}
+
+	h := sig.hash.New()
+	if sig.sigType == packet.SigTypeText {
+		text, err := ioutil.ReadAll(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: error in reading data")
+		}
+		_, _ = h.Write([]byte(internal.Canonicalize(string(text))))
+	} else if _, err := io.Copy(h, data); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: error in reading data")
+	}
+	var trailer [5]byte
+	trailer[0] = byte(sig.sigType)
+	binary.BigEndian.PutUint32(trailer[1:], sig.creationTime)
+	_, _ = h.Write(trailer[:])
+	digest := h.Sum(nil)
+	if !bytes.Equal(digest[:2], sig.hashPrefix) {
+		return nil, newSignatureFailed(errors.New("gopenpgp: hash tag doesn't match"))
+	}
+
+	keys := entities.KeysByIdUsage(sig.issuerKeyID, packet.KeyFlagSign)
+	if len(keys) == 0 {
+		return nil, newSignatureNoVerifier()
+	}
+	err := newSignatureNoVerifier()
+	for i := range keys {
+		if keys[i].PublicKey.PubKeyAlgo != sig.pubKeyAlgo {
+			continue
+		}
+		if verifyErr := sig.verifyWithKey(keys[i].PublicKey, digest); verifyErr != nil {
+			err = newSignatureFailed(verifyErr)
+			continue
+		}
+		if keyErr := checkV3SigningKey(&keys[i], verifyTime); keyErr != nil {
+			err = newSignatureFailed(keyErr)
+			continue
+		}
+		return &keys[i], nil
+	}
+	return nil, err
+}
+
+// checkV3SigningKey checks that the signing key is valid at verifyTime, as for version 4 signatures:
+// the key and the primary identity must not be revoked, and the key must not be expired,
+// unless verifyTime is 0.
+func checkV3SigningKey(key *openpgp.Key, verifyTime int64) error {
+	now := time.Unix(0, 0)
+	if verifyTime != 0 {
+		now = time.Unix(verifyTime+internal.CreationTimeOffset, 0)
+	}
+	primaryIdentity := key.Entity.PrimaryIdentity()
+	signedBySubkey := key.PublicKey != key.Entity.PrimaryKey
+	if key.Entity.Revoked(now) || (signedBySubkey && key.Revoked(now)) ||
+		(primaryIdentity != nil && primaryIdentity.Revoked(now)) {
+		return pgpErrors.ErrKeyRevoked
+	}
+	if verifyTime == 0 {
+		// Expiration check disabled
+		return nil
+	}
+	now = time.Unix(verifyTime, 0)
+	if primaryIdentity != nil && key.Entity.PrimaryKey.KeyExpired(primaryIdentity.SelfSignature, now) {
+		return pgpErrors.ErrKeyExpired
+	}
+	if signedBySubkey && key.PublicKey.KeyExpired(key.SelfSignature, now) {
+		return pgpErrors.ErrKeyExpired
+	}
+	return nil
+}
+
+func (sig *v3Signature) verifyWithKey(key *packet.PublicKey, digest []byte) error {
+	switch publicKey := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if len(sig.mpis) != 1 {
+			return errors.New("gopenpgp: malformed RSA signature")
+		}
+		if len(sig.mpis[0].Bytes()) > publicKey.Size() {
+			return errors.New("gopenpgp: RSA signature is larger than the modulus")
+		}
+		signature := make([]byte, publicKey.Size())
+		sig.mpis[0].FillBytes(signature)
+		return rsa.VerifyPKCS1v15(publicKey, sig.hash, digest, signature)
+	case *dsa.PublicKey:
+		if len(sig.mpis) != 2 {
+			return errors.New("gopenpgp: malformed DSA signature")
+		}
+		subgroupSize := (publicKey.Q.BitLen() + 7) / 8
+		if len(digest) > subgroupSize {
+			digest = digest[:subgroupSize]
+		}
+		if !dsa.Verify(publicKey, digest, sig.mpis[0], sig.mpis[1]) {
+			return errors.New("gopenpgp: DSA verification failure")
+		}
+		return nil
+	}
+	return errors.New("gopenpgp: unsupported public key algorithm for version 3 signatures")
+}
+
+// isSingleSignaturePacket returns true if the data is made of exactly one signature packet.
+func isSingleSignaturePacket(data []byte) bool {
+	tag, body, err := parsePacket(data)
+	return err == nil && tag == packetTagSignature && packetLength(data, body) == len(data)
+}
+
+// packetLength returns the length of the first packet of the data, given its body returned by parsePacket.
+func packetLength(data, body []byte) int {
+	// The body is a subslice of the data, following the packet header
+	return cap(data) - cap(body) + len(body)
+}
+
+// parsePacket parses the first packet of the data, with a definite length,
+// and returns its tag and body.
+func parsePacket(data []byte) (tag byte, body []byte, err error) {
+	if len(data) < 2 || data[0]&0x80 == 0 {
+		return 0, nil, errors.New("gopenpgp: invalid packet header")
+	}
+	var length, offset int
+	if data[0]&0x40 == 0 {
+		// Old format packet
+		tag = (data[0] & 0x3f) >> 2
+		lengthType := data[0] & 3
+		if lengthType == 3 {
+			return 0, nil, errors.New("gopenpgp: indeterminate packet lengths are not supported")
+		}
+		offset = 1 + 1<<lengthType
+		if len(data) < offset {
+			return 0, nil, errors.New("gopenpgp: invalid packet header")
+		}
+		for _, b := range data[1:offset] {
+			length = length<<8 | int(b)
+		}
+	} else {
+		// New format packet
+		tag = data[0] & 0x3f
+		switch {
+		case data[1] < 192:
+			length, offset = int(data[1]), 2
+		case data[1] < 224 && len(data) >= 3:
+			length, offset = (int(data[1])-192)<<8+int(data[2])+192, 3
+		case data[1] == 255 && len(data) >= 6:
+			length, offset = int(binary.BigEndian.Uint32(data[2:6])), 6
+		default:
+			return 0, nil, errors.New("gopenpgp: unsupported packet length")
+		}
+	}
+	if length < 0 || len(data)-offset < length {
+		return 0, nil, errors.New("gopenpgp: packet is truncated")
+	}
+	return tag, data[offset : offset+length], nil
+}
@@ -0,0 +1,172 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// signV3 creates a version 3 binary signature of the data with the primary RSA key.
+func signV3(t *testing.T, key *Key, data []byte, creationTime uint32) *PGPSignature {
+	digest := v3Digest(data, creationTime)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key.entity.PrivateKey.PrivateKey.(*rsa.PrivateKey), crypto.SHA256, digest)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	return newV3SignaturePacket(key, digest, creationTime, signature)
+}
+
+// v3Digest returns the SHA256 digest of a version 3 binary signature of the data.
+func v3Digest(data []byte, creationTime uint32) []byte {
+	h := crypto.SHA256.New()
+	_, _ = h.Write(data)
+	var trailer [5]byte
+	trailer[0] = byte(packet.SigTypeBinary)
+	binary.BigEndian.PutUint32(trailer[1:], creationTime)
+	_, _ = h.Write(trailer[:])
+	return h.Sum(nil)
+}
+
+// newV3SignaturePacket serializes a version 3 binary signature with the given RSA signature value.
+func newV3SignaturePacket(key *Key, digest []byte, creationTime uint32, signature []byte) *PGPSignature {
+	var trailer [5]byte
+	binary.BigEndian.PutUint32(trailer[1:], creationTime)
+	body := []byte{3, 5, byte(packet.SigTypeBinary)}
+	body = append(body, trailer[1:]...)
+	body = append(body, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(body[7:], key.entity.PrimaryKey.KeyId)
+	body = append(body, byte(packet.PubKeyAlgoRSA), 8, digest[0], digest[1])
+	body = append(body, byte(len(signature)*8>>8), byte(len(signature)*8))
+	body = append(body, signature...)
+
+	// Old format signature packet, with a two-octet length
+	header := []byte{0x89, byte(len(body) >> 8), byte(len(body))}
+	return NewPGPSignature(append(header, body...))
+}
+
+func TestVerifyDetachedWithPolicyV3(t *testing.T) {
+	keyRing, err := NewKeyRing(keyTestRSA)
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	message := NewPlainMessageFromString(testMessage)
+	signature := signV3(t, keyTestRSA, message.GetBinary(), uint32(testTime))
+
+	if err = keyRing.VerifyDetached(message, signature, testTime); err == nil {
+		t.Fatal("Expected an error while verifying a version 3 signature with the legacy API, got nil")
+	}
+
+	_, err = keyRing.VerifyDetachedWithPolicy(message, signature, testTime, nil)
+	var sigErr SignatureVerificationError
+	if !assert.ErrorAs(t, err, &sigErr) {
+		t.FailNow()
+	}
+	assert.Exactly(t, constants.SIGNATURE_FAILED, sigErr.Status)
+
+	policy := NewDecryptionPolicy().AllowV3Signatures()
+	details, err := keyRing.VerifyDetachedWithPolicy(message, signature, testTime, policy)
+	if err != nil {
+		t.Fatal("Expected no error while verifying with version 3 signatures allowed, got:", err)
+	}
+	assert.Exactly(t, 3, details.GetSignatureVersion())
+	assert.True(t, details.HasWarnings())
+
+	_, err = keyRing.VerifyDetachedWithPolicy(NewPlainMessageFromString("tampered"), signature, testTime, policy)
+	assert.Error(t, err)
+
+	_, err = keyRingTestPublic.VerifyDetachedWithPolicy(message, signature, testTime, policy)
+	if !assert.ErrorAs(t, err, &sigErr) {
+		t.FailNow()
+	}
+	assert.Exactly(t, constants.SIGNATURE_NO_VERIFIER, sigErr.Status)
+
+	// A signature value wider than the modulus must fail without panicking
+	oversized := append([]byte{1}, make([]byte, keyTestRSA.entity.PrimaryKey.PublicKey.(*rsa.PublicKey).Size())...)
+	digest := v3Digest(message.GetBinary(), uint32(testTime))
+	_, err = keyRing.VerifyDetachedWithPolicy(message, newV3SignaturePacket(keyTestRSA, digest, uint32(testTime), oversized), testTime, policy)
+	if !assert.ErrorAs(t, err, &sigErr) {
+		t.FailNow()
+	}
+	assert.Exactly(t, constants.SIGNATURE_FAILED, sigErr.Status)
+}
+
+func TestVerifyDetachedWithPolicyV3RevokedKey(t *testing.T) {
+	revokedKey, err := keyTestRSA.Copy()
+	if err != nil {
+		t.Fatal("Expected no error while copying the key, got:", err)
+	}
+	if err = revokedKey.entity.RevokeKey(packet.KeyCompromised, "", nil); err != nil {
+		t.Fatal("Expected no error while revoking the key, got:", err)
+	}
+	keyRing, err := NewKeyRing(revokedKey)
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	message := NewPlainMessageFromString(testMessage)
+	signature := signV3(t, revokedKey, message.GetBinary(), uint32(testTime))
+
+	_, err = keyRing.VerifyDetachedWithPolicy(message, signature, testTime, NewDecryptionPolicy().AllowV3Signatures())
+	var sigErr SignatureVerificationError
+	if !assert.ErrorAs(t, err, &sigErr) {
+		t.FailNow()
+	}
+	assert.Exactly(t, constants.SIGNATURE_FAILED, sigErr.Status)
+}
+
+func TestVerifyDetachedWithPolicyV4(t *testing.T) {
+	message := NewPlainMessageFromString(testMessage)
+	signature, err := keyRingTestPrivate.SignDetached(message)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	details, err := keyRingTestPublic.VerifyDetachedWithPolicy(message, signature, testTime, nil)
+	if err != nil {
+		t.Fatal("Expected no error while verifying, got:", err)
+	}
+	assert.Exactly(t, 4, details.GetSignatureVersion())
+	assert.False(t, details.HasWarnings())
+}
+
+func TestVerifyDetachedWithPolicySHA1SelfSignature(t *testing.T) {
+	key, err := GenerateKey(keyTestName, keyTestDomain, "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating the key, got:", err)
+	}
+	for name, identity := range key.entity.Identities {
+		identity.SelfSignature.Hash = crypto.SHA1
+		err = identity.SelfSignature.SignUserId(name, key.entity.PrimaryKey, key.entity.PrivateKey, nil)
+		if err != nil {
+			t.Fatal("Expected no error while signing the user ID, got:", err)
+		}
+	}
+	keyRing, err := NewKeyRing(key)
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	message := NewPlainMessageFromString(testMessage)
+	signature, err := keyRing.SignDetached(message)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+
+	_, err = keyRing.VerifyDetachedWithPolicy(message, signature, testTime, nil)
+	assert.Error(t, err)
+
+	details, err := keyRing.VerifyDetachedWithPolicy(message, signature, testTime, NewDecryptionPolicy().AllowSHA1SelfSignatures(0))
+	if err != nil {
+		t.Fatal("Expected no error while verifying with SHA-1 self-signatures allowed, got:", err)
+	}
+	assert.True(t, details.HasWarnings())
+
+	_, err = keyRing.VerifyDetachedWithPolicy(message, signature, testTime, NewDecryptionPolicy().AllowSHA1SelfSignatures(testTime+1))
+	assert.NoError(t, err)
+
+	_, err = keyRing.VerifyDetachedWithPolicy(message, signature, testTime, NewDecryptionPolicy().AllowSHA1SelfSignatures(testTime))
+	assert.Error(t, err)
+}
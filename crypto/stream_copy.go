@@ -0,0 +1,59 @@
+package crypto
+
+import (
+	"io"
+	"sync"
+)
+
+// streamCopyBufferSize is the size of the buffer used by the io.ReaderFrom and io.WriterTo
+// implementations of the streaming types, larger than the 32 KiB buffer of io.Copy
+// to reduce the number of calls through the OpenPGP packet layers on large messages.
+const streamCopyBufferSize = 1 << 18
+
+var streamCopyBuffers = sync.Pool{
+	New: func() interface{} {
+		buffer := make([]byte, streamCopyBufferSize)
+		return &buffer
+	},
+}
+
+// ReadFrom implements io.ReaderFrom, so that io.Copy writes the plaintext by large chunks.
+func (w *plainMessageWriteCloser) ReadFrom(r io.Reader) (int64, error) {
+	return copyBuffered(w.WriteCloser, r)
+}
+
+// ReadFrom implements io.ReaderFrom, so that io.Copy writes the plaintext by large chunks.
+func (w *signAndEncryptWriteCloser) ReadFrom(r io.Reader) (int64, error) {
+	return copyBuffered(w.signWriter, r)
+}
+
+// ReadFrom implements io.ReaderFrom, so that io.Copy writes the plaintext by large chunks.
+func (res *EncryptSplitResult) ReadFrom(r io.Reader) (int64, error) {
+	return copyBuffered(res.plainMessageWriter, r)
+}
+
+// WriteTo implements io.WriterTo, so that io.Copy reads the plaintext by large chunks.
+func (msg *PlainMessageReader) WriteTo(w io.Writer) (int64, error) {
+	return copyBuffered(w, readerOnly{msg})
+}
+
+// plainMessageWriteCloser wraps the plaintext writers returned by the streaming encryption functions.
+type plainMessageWriteCloser struct {
+	WriteCloser
+}
+
+// readerOnly and writerOnly hide the io.ReaderFrom and io.WriterTo implementations
+// from io.CopyBuffer, which would otherwise call them back.
+type readerOnly struct {
+	io.Reader
+}
+
+type writerOnly struct {
+	io.Writer
+}
+
+func copyBuffered(dst io.Writer, src io.Reader) (int64, error) {
+	buffer := streamCopyBuffers.Get().(*[]byte)
+	defer streamCopyBuffers.Put(buffer)
+	return io.CopyBuffer(writerOnly{dst}, readerOnly{src}, *buffer)
+}
@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamCopyFastPaths(t *testing.T) {
+	plainData := bytes.Repeat([]byte("large file content "), 100000)
+
+	var pgpMessage bytes.Buffer
+	plainMessageWriter, err := keyRingTestPublic.EncryptStream(&pgpMessage, nil, keyRingTestPrivate)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting the stream, got:", err)
+	}
+	if _, ok := plainMessageWriter.(io.ReaderFrom); !ok {
+		t.Fatal("Expected the plaintext writer to implement io.ReaderFrom")
+	}
+	n, err := io.Copy(plainMessageWriter, bytes.NewReader(plainData))
+	if err != nil {
+		t.Fatal("Expected no error while copying the plaintext, got:", err)
+	}
+	assert.Exactly(t, int64(len(plainData)), n)
+	if err = plainMessageWriter.Close(); err != nil {
+		t.Fatal("Expected no error while closing the plaintext writer, got:", err)
+	}
+
+	plainMessageReader, err := keyRingTestPrivate.DecryptStream(&pgpMessage, keyRingTestPublic, GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the stream, got:", err)
+	}
+	var decrypted bytes.Buffer
+	n, err = io.Copy(&decrypted, plainMessageReader)
+	if err != nil {
+		t.Fatal("Expected no error while copying the plaintext, got:", err)
+	}
+	assert.Exactly(t, int64(len(plainData)), n)
+	assert.Exactly(t, plainData, decrypted.Bytes())
+	if err = plainMessageReader.VerifySignature(); err != nil {
+		t.Fatal("Expected no error while verifying the signature, got:", err)
+	}
+}
+
+func TestSessionKeyStreamCopyFastPaths(t *testing.T) {
+	plainData := bytes.Repeat([]byte("large file content "), 100000)
+
+	for _, signKeyRing := range []*KeyRing{nil, keyRingTestPrivate} {
+		var dataPacket bytes.Buffer
+		plainMessageWriter, err := testSessionKey.EncryptStream(&dataPacket, nil, signKeyRing)
+		if err != nil {
+			t.Fatal("Expected no error while encrypting the stream, got:", err)
+		}
+		if _, ok := plainMessageWriter.(io.ReaderFrom); !ok {
+			t.Fatal("Expected the plaintext writer to implement io.ReaderFrom")
+		}
+		if _, err = io.Copy(plainMessageWriter, bytes.NewReader(plainData)); err != nil {
+			t.Fatal("Expected no error while copying the plaintext, got:", err)
+		}
+		if err = plainMessageWriter.Close(); err != nil {
+			t.Fatal("Expected no error while closing the plaintext writer, got:", err)
+		}
+
+		decrypted, err := testSessionKey.Decrypt(dataPacket.Bytes())
+		if err != nil {
+			t.Fatal("Expected no error while decrypting, got:", err)
+		}
+		assert.Exactly(t, plainData, decrypted.GetBinary())
+	}
+}
@@ -0,0 +1,131 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/pkg/errors"
+)
+
+// TestVectorParameters are the fixed parameters from which test vectors are generated.
+type TestVectorParameters struct {
+	// Seed of the deterministic source of randomness, required.
+	Seed []byte
+	// UnixTime is the creation time of the key, messages and signatures, required.
+	UnixTime int64
+	// Name and Email of the user ID of the key.
+	Name, Email string
+	// KeyType and Bits of the key, as in GenerateKey.
+	KeyType string
+	Bits    int
+	// Plaintext of the messages and signatures.
+	Plaintext []byte
+}
+
+// TestVectors are sample artifacts generated by GenerateTestVectors.
+// The armored artifacts have no armor headers, to be reproducible.
+type TestVectors struct {
+	// PrivateKey is the armored, unencrypted, private key.
+	PrivateKey string
+	// PublicKey is the armored public key.
+	PublicKey string
+	// SessionKeyAlgo and SessionKey are the algorithm and hex encoded session key of the messages.
+	SessionKeyAlgo string
+	SessionKey     string
+	// Message is the armored message encrypted to and signed by the key,
+	// with a version 3 PKESK and a SEIPDv1 packet.
+	Message string
+	// Signature is the armored detached signature of the plaintext, made by the key.
+	Signature string
+	// SEIPDv2Packet is a binary SEIPDv2 packet, encrypting a literal data packet of the plaintext
+	// with the session key, in OCB mode.
+	SEIPDv2Packet []byte
+}
+
+// GenerateTestVectors generates sample keys, messages and signatures from the fixed parameters,
+// for other implementations and downstream test suites: the same parameters always produce
+// the same test vectors with the same version of the library.
+// It enables, and then disables, the reproducible mode of EnableReproducibleEncryption,
+// and must not be called concurrently with other operations.
+// NOTE: version 6 keys and signatures are not supported, the key and signatures are version 4.
+func GenerateTestVectors(params *TestVectorParameters) (vectors *TestVectors, err error) {
+	if err = EnableReproducibleEncryption(params.Seed, params.UnixTime); err != nil {
+		return nil, err
+	}
+	defer DisableReproducibleEncryption()
+
+	key, err := GenerateKey(params.Name, params.Email, params.KeyType, params.Bits)
+	if err != nil {
+		return nil, err
+	}
+	keyRing, err := NewKeyRing(key)
+	if err != nil {
+		return nil, err
+	}
+	vectors = &TestVectors{}
+	if vectors.PrivateKey, err = key.ArmorWithCustomHeaders("", ""); err != nil {
+		return nil, err
+	}
+	if vectors.PublicKey, err = key.GetArmoredPublicKeyWithCustomHeaders("", ""); err != nil {
+		return nil, err
+	}
+
+	sessionKey, err := GenerateSessionKey()
+	if err != nil {
+		return nil, err
+	}
+	vectors.SessionKeyAlgo = sessionKey.Algo
+	vectors.SessionKey = hex.EncodeToString(sessionKey.Key)
+
+	message := NewPlainMessage(params.Plaintext)
+	keyPacket, err := keyRing.EncryptSessionKey(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	dataPacket, err := sessionKey.EncryptAndSign(message, keyRing)
+	if err != nil {
+		return nil, err
+	}
+	if vectors.Message, err = NewPGPSplitMessage(keyPacket, dataPacket).GetPGPMessage().GetArmoredWithCustomHeaders("", ""); err != nil {
+		return nil, err
+	}
+
+	signature, err := keyRing.SignDetached(message)
+	if err != nil {
+		return nil, err
+	}
+	if vectors.Signature, err = armor.ArmorWithTypeAndCustomHeaders(
+		signature.GetBinary(), constants.PGPSignatureHeader, "", "",
+	); err != nil {
+		return nil, err
+	}
+
+	if vectors.SEIPDv2Packet, err = newSEIPDv2Packet(sessionKey, message); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// newSEIPDv2Packet encrypts a literal data packet of the message in a SEIPDv2 packet.
+func newSEIPDv2Packet(sessionKey *SessionKey, message *PlainMessage) ([]byte, error) {
+	var body bytes.Buffer
+	encryptWriter, err := sessionKey.EncryptAEADStream(&body, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Closing the literal data writer closes the encryption writer
+	literalWriter, err := packet.SerializeLiteral(encryptWriter, message.IsBinary(), message.Filename, message.Time)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to serialize literal data")
+	}
+	if _, err = literalWriter.Write(message.GetBinary()); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to serialize literal data")
+	}
+	if err = literalWriter.Close(); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to encrypt literal data")
+	}
+	return append(appendRawLength([]byte{aeadTag}, body.Len()), body.Bytes()...), nil
+}
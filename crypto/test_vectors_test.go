@@ -0,0 +1,85 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateTestVectors(t *testing.T) {
+	params := &TestVectorParameters{
+		Seed:      []byte("test vectors"),
+		UnixTime:  testTime,
+		Name:      keyTestName,
+		Email:     keyTestDomain,
+		KeyType:   "x25519",
+		Plaintext: []byte(testMessage),
+	}
+	vectors, err := GenerateTestVectors(params)
+	if err != nil {
+		t.Fatal("Expected no error while generating test vectors, got:", err)
+	}
+	again, err := GenerateTestVectors(params)
+	if err != nil {
+		t.Fatal("Expected no error while generating test vectors, got:", err)
+	}
+	assert.Exactly(t, vectors, again)
+
+	params.Seed = []byte("other test vectors")
+	other, err := GenerateTestVectors(params)
+	if err != nil {
+		t.Fatal("Expected no error while generating test vectors, got:", err)
+	}
+	assert.NotEqual(t, vectors.PrivateKey, other.PrivateKey)
+
+	key, err := NewKeyFromArmored(vectors.PrivateKey)
+	if err != nil {
+		t.Fatal("Expected no error while reading key, got:", err)
+	}
+	keyRing, err := NewKeyRing(key)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	message, err := NewPGPMessageFromArmored(vectors.Message)
+	if err != nil {
+		t.Fatal("Expected no error while reading message, got:", err)
+	}
+	decrypted, err := keyRing.Decrypt(message, keyRing, testTime)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting message, got:", err)
+	}
+	assert.Exactly(t, testMessage, decrypted.GetString())
+	signature, err := NewPGPSignatureFromArmored(vectors.Signature)
+	if err != nil {
+		t.Fatal("Expected no error while reading signature, got:", err)
+	}
+	assert.NoError(t, keyRing.VerifyDetached(NewPlainMessageFromString(testMessage), signature, testTime))
+
+	sessionKeyBytes, err := hex.DecodeString(vectors.SessionKey)
+	if err != nil {
+		t.Fatal("Expected no error while decoding session key, got:", err)
+	}
+	sessionKey := NewSessionKeyFromToken(sessionKeyBytes, vectors.SessionKeyAlgo)
+	tag, body, err := parsePacket(vectors.SEIPDv2Packet)
+	if err != nil {
+		t.Fatal("Expected no error while parsing SEIPDv2 packet, got:", err)
+	}
+	assert.Exactly(t, byte(18), tag)
+	literalData, err := sessionKey.DecryptAEAD(body, nil)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting SEIPDv2 packet, got:", err)
+	}
+	p, err := packet.Read(bytes.NewReader(literalData))
+	if err != nil {
+		t.Fatal("Expected no error while reading literal data, got:", err)
+	}
+	plaintext, err := ioutil.ReadAll(p.(*packet.LiteralData).Body)
+	if err != nil {
+		t.Fatal("Expected no error while reading literal data, got:", err)
+	}
+	assert.Exactly(t, testMessage, string(plaintext))
+}
@@ -39,6 +39,10 @@ func getNow() time.Time {
 	pgp.lock.RLock()
 	defer pgp.lock.RUnlock()
 
+	if pgp.fixedTime != 0 {
+		return time.Unix(pgp.fixedTime, 0)
+	}
+
 	if pgp.latestServerTime == 0 {
 		return time.Now()
 	}
@@ -56,6 +60,10 @@ func getNowKeyGenerationOffset() time.Time {
 	pgp.lock.RLock()
 	defer pgp.lock.RUnlock()
 
+	if pgp.fixedTime != 0 {
+		return time.Unix(pgp.fixedTime+pgp.generationOffset, 0)
+	}
+
 	if pgp.latestServerTime == 0 {
 		return time.Unix(time.Now().Unix()+pgp.generationOffset, 0)
 	}
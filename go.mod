@@ -9,4 +9,5 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.7.0
+	golang.org/x/sys v0.6.0
 )
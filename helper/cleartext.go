@@ -2,7 +2,6 @@ package helper
 
 import (
 	"github.com/ProtonMail/gopenpgp/v2/crypto"
-	"github.com/ProtonMail/gopenpgp/v2/internal"
 	"github.com/pkg/errors"
 )
 
@@ -49,14 +48,12 @@ func VerifyCleartextMessageArmored(publicKey, armored string, verifyTime int64)
 // SignCleartextMessage signs text given a private keyring, canonicalizes and
 // trims the newlines, and returns the PGP-compliant special armoring.
 func SignCleartextMessage(keyRing *crypto.KeyRing, text string) (string, error) {
-	message := crypto.NewPlainMessageFromString(internal.TrimEachLine(text))
-
-	signature, err := keyRing.SignDetached(message)
+	clearTextMessage, err := keyRing.SignClearText(text)
 	if err != nil {
-		return "", errors.Wrap(err, "gopenpgp: error in signing cleartext message")
+		return "", err
 	}
 
-	return crypto.NewClearTextMessage(message.GetBinary(), signature.GetBinary()).GetArmored()
+	return clearTextMessage.GetArmored()
 }
 
 // VerifyCleartextMessage verifies PGP-compliant armored signed plain text
@@ -68,9 +65,7 @@ func VerifyCleartextMessage(keyRing *crypto.KeyRing, armored string, verifyTime
 		return "", errors.Wrap(err, "gopengpp: unable to unarmor cleartext message")
 	}
 
-	message := crypto.NewPlainMessageFromString(internal.TrimEachLine(clearTextMessage.GetString()))
-	signature := crypto.NewPGPSignature(clearTextMessage.GetBinarySignature())
-	err = keyRing.VerifyDetached(message, signature, verifyTime)
+	message, err := keyRing.VerifyClearText(clearTextMessage, verifyTime)
 	if err != nil {
 		return "", errors.Wrap(err, "gopengpp: unable to verify cleartext message")
 	}
@@ -4,15 +4,22 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/sha256"
 	"io"
 
+	"github.com/ProtonMail/go-crypto/eax"
+	"github.com/ProtonMail/go-crypto/ocb"
 	"github.com/ProtonMail/go-crypto/openpgp/packet"
 	"github.com/ProtonMail/gopenpgp/v2/crypto"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
 )
 
 const AES_BLOCK_SIZE = 16
 
+// Length of the SEIPDv2 header following the version byte: cipher, mode, chunk size byte and salt.
+const seipdV2HeaderLength = 3 + 32
+
 func supported(cipher packet.CipherFunction) bool {
 	switch cipher {
 	case packet.CipherAES128, packet.CipherAES192, packet.CipherAES256:
@@ -44,10 +51,23 @@ func blockCipher(cipher packet.CipherFunction, key []byte) (cipher.Block, error)
 }
 
 // QuickCheckDecryptReader checks with high probability if the provided session key
-// can decrypt a data packet given its 24 byte long prefix.
-// The method reads up to but not exactly 24 bytes from the prefixReader.
-// NOTE: Only works for SEIPDv1 packets with AES.
+// can decrypt a data packet given its prefix.
+// For SEIPDv1 packets, the method reads up to but not exactly 24 bytes from the prefixReader.
+// For SEIPDv2 packets, the method reads the packet header and the first AEAD chunk
+// (or the whole packet, if it only contains one chunk) and authenticates that chunk,
+// without decrypting the rest of the message.
+// NOTE: SEIPDv1 packets are only supported with AES.
 func QuickCheckDecryptReader(sessionKey *crypto.SessionKey, prefixReader crypto.Reader) (bool, error) {
+	var header bytes.Buffer
+	packetParser := packet.NewReader(io.TeeReader(prefixReader, &header))
+	p, err := packetParser.Next()
+	if err != nil {
+		return false, errors.New("gopenpgp: failed to parse packet prefix")
+	}
+	if se, ok := p.(*packet.SymmetricallyEncrypted); ok && se.Version == 2 {
+		return quickCheckDecryptAEAD(sessionKey, header.Bytes(), se.Contents)
+	}
+
 	algo, err := sessionKey.GetCipherFunc()
 	if err != nil {
 		return false, errors.New("gopenpgp: cipher algorithm not found")
@@ -55,11 +75,6 @@ func QuickCheckDecryptReader(sessionKey *crypto.SessionKey, prefixReader crypto.
 	if !supported(algo) {
 		return false, errors.New("gopenpgp: cipher not supported for quick check")
 	}
-	packetParser := packet.NewReader(prefixReader)
-	_, err = packetParser.Next()
-	if err != nil {
-		return false, errors.New("gopenpgp: failed to parse packet prefix")
-	}
 
 	blockSize := blockSize(algo)
 	encryptedData := make([]byte, blockSize+2)
@@ -77,10 +92,104 @@ func QuickCheckDecryptReader(sessionKey *crypto.SessionKey, prefixReader crypto.
 		encryptedData[blockSize-1] == encryptedData[blockSize+1], nil
 }
 
+// quickCheckDecryptAEAD authenticates the first chunk of a SEIPDv2 packet.
+// header contains the bytes of the packet consumed by the parser, ending with the SEIPDv2 header,
+// and contents is positioned at the start of the first encrypted chunk.
+func quickCheckDecryptAEAD(sessionKey *crypto.SessionKey, header []byte, contents io.Reader) (bool, error) {
+	if len(header) < seipdV2HeaderLength {
+		return false, errors.New("gopenpgp: prefix is too short to check")
+	}
+	aeadHeader := header[len(header)-seipdV2HeaderLength:]
+	cipherFunc := packet.CipherFunction(aeadHeader[0])
+	mode := packet.AEADMode(aeadHeader[1])
+	chunkSizeByte := aeadHeader[2]
+	salt := aeadHeader[3:]
+	if !supported(cipherFunc) {
+		return false, errors.New("gopenpgp: cipher not supported for quick check")
+	}
+	if len(sessionKey.Key) != cipherFunc.KeySize() {
+		return false, nil
+	}
+
+	// Associated data: packet tag, version, cipher, mode and chunk size byte
+	associatedData := []byte{0xD2, 2, byte(cipherFunc), byte(mode), chunkSizeByte}
+	hkdfReader := hkdf.New(sha256.New, sessionKey.Key, salt, associatedData)
+	key := make([]byte, cipherFunc.KeySize())
+	nonce := make([]byte, mode.IvLength())
+	if _, err := io.ReadFull(hkdfReader, key); err != nil {
+		return false, errors.Wrap(err, "gopenpgp: failed to derive the message key")
+	}
+	// The last 8 bytes of the nonce are the chunk index, which is zero for the first chunk
+	if _, err := io.ReadFull(hkdfReader, nonce[:len(nonce)-8]); err != nil {
+		return false, errors.Wrap(err, "gopenpgp: failed to derive the message key")
+	}
+
+	aead, err := aeadCipher(cipherFunc, mode, key)
+	if err != nil {
+		return false, err
+	}
+
+	tagLength := mode.TagLength()
+	chunkSize := 1 << (chunkSizeByte + 6)
+	chunkLength := chunkSize + tagLength
+	// Read the first chunk and the following tag, to know if it is the last chunk
+	chunk := make([]byte, chunkLength+tagLength)
+	n, err := readFull(contents, chunk)
+	switch {
+	case n >= chunkLength:
+		// The first chunk is complete
+		n = chunkLength
+	case errors.Is(err, io.EOF):
+		// The packet only contains one chunk, followed by the final authentication tag
+		if n < 2*tagLength {
+			return false, errors.New("gopenpgp: the encrypted data is truncated")
+		}
+		n -= tagLength
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return false, errors.New("gopenpgp: prefix is too short to check")
+	case err != nil:
+		return false, errors.Wrap(err, "gopenpgp: failed to read the first chunk")
+	}
+
+	_, err = aead.Open(nil, nonce, chunk[:n], associatedData)
+	return err == nil, nil
+}
+
+// readFull works as io.ReadFull, but returns the error of the reader as is,
+// to tell the end of the packet (io.EOF) from a truncated prefix (io.ErrUnexpectedEOF).
+func readFull(reader io.Reader, buffer []byte) (n int, err error) {
+	for n < len(buffer) && err == nil {
+		var read int
+		read, err = reader.Read(buffer[n:])
+		n += read
+	}
+	if n == len(buffer) {
+		err = nil
+	}
+	return n, err
+}
+
+func aeadCipher(cipherFunc packet.CipherFunction, mode packet.AEADMode, key []byte) (cipher.AEAD, error) {
+	block, err := blockCipher(cipherFunc, key)
+	if err != nil {
+		return nil, errors.New("gopenpgp: failed to initialize the cipher")
+	}
+	switch mode {
+	case packet.AEADModeEAX:
+		return eax.NewEAX(block)
+	case packet.AEADModeOCB:
+		return ocb.NewOCB(block)
+	case packet.AEADModeGCM:
+		return cipher.NewGCM(block)
+	}
+	return nil, errors.New("gopenpgp: unknown aead mode")
+}
+
 // QuickCheckDecrypt checks with high probability if the provided session key
-// can decrypt the encrypted data packet given its 24 byte long prefix.
-// The method only considers the first 24 bytes of the prefix slice (prefix[:24]).
-// NOTE: Only works for SEIPDv1 packets with AES.
+// can decrypt the encrypted data packet given its prefix.
+// For SEIPDv1 packets, only the first 24 bytes of the prefix slice are considered (prefix[:24]).
+// For SEIPDv2 packets, the prefix needs to contain the first AEAD chunk of the packet.
+// NOTE: SEIPDv1 packets are only supported with AES.
 func QuickCheckDecrypt(sessionKey *crypto.SessionKey, prefix []byte) (bool, error) {
 	return QuickCheckDecryptReader(sessionKey, bytes.NewReader(prefix))
 }
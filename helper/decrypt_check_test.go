@@ -1,9 +1,12 @@
 package helper
 
 import (
+	"bytes"
 	"encoding/hex"
 	"testing"
 
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
 	"github.com/ProtonMail/gopenpgp/v2/crypto"
 )
 
@@ -41,3 +44,96 @@ func TestCheckDecrypt(t *testing.T) {
 		t.Error("should no be able to decrypt")
 	}
 }
+
+func TestCheckDecryptSEIPDv2(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		mode      packet.AEADMode
+		chunkSize uint64
+	}{
+		{"OCB single chunk", packet.AEADModeOCB, 0},
+		{"EAX single chunk", packet.AEADModeEAX, 0},
+		{"GCM multiple chunks", packet.AEADModeGCM, 64},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			sessionKey, err := crypto.GenerateSessionKeyAlgo(constants.AES256)
+			if err != nil {
+				t.Fatal("Expected no error while generating the session key, got:", err)
+			}
+			dataPacket := encryptSEIPDv2(t, sessionKey, test.mode, test.chunkSize)
+
+			ok, err := QuickCheckDecrypt(sessionKey, dataPacket)
+			if err != nil {
+				t.Fatal("Expected no error while checking the data packet, got:", err)
+			}
+			if !ok {
+				t.Error("should be able to decrypt")
+			}
+
+			sessionKey.Key[0] += 1
+			ok, err = QuickCheckDecrypt(sessionKey, dataPacket)
+			if err != nil {
+				t.Fatal("Expected no error while checking the data packet, got:", err)
+			}
+			if ok {
+				t.Error("should no be able to decrypt")
+			}
+		})
+	}
+}
+
+func TestCheckDecryptSEIPDv2TruncatedPrefix(t *testing.T) {
+	sessionKey, err := crypto.GenerateSessionKeyAlgo(constants.AES256)
+	if err != nil {
+		t.Fatal("Expected no error while generating the session key, got:", err)
+	}
+	dataPacket := encryptSEIPDv2(t, sessionKey, packet.AEADModeGCM, 64)
+
+	// The prefix ends in the first chunk
+	if _, err = QuickCheckDecrypt(sessionKey, dataPacket[:100]); err == nil {
+		t.Error("Expected an error while checking a prefix shorter than the first chunk, got nil")
+	}
+
+	// The prefix contains the first chunk
+	ok, err := QuickCheckDecrypt(sessionKey, dataPacket[:len(dataPacket)-20])
+	if err != nil {
+		t.Fatal("Expected no error while checking the data packet, got:", err)
+	}
+	if !ok {
+		t.Error("should be able to decrypt")
+	}
+}
+
+func encryptSEIPDv2(t *testing.T, sessionKey *crypto.SessionKey, mode packet.AEADMode, chunkSize uint64) []byte {
+	cipherFunc, err := sessionKey.GetCipherFunc()
+	if err != nil {
+		t.Fatal("Expected no error while getting the cipher, got:", err)
+	}
+	config := &packet.Config{
+		DefaultCipher: cipherFunc,
+		AEADConfig:    &packet.AEADConfig{DefaultMode: mode, ChunkSize: chunkSize},
+	}
+	var dataPacket bytes.Buffer
+	encryptWriter, err := packet.SerializeSymmetricallyEncrypted(
+		&dataPacket,
+		cipherFunc,
+		true,
+		packet.CipherSuite{Cipher: cipherFunc, Mode: mode},
+		sessionKey.Key,
+		config,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	literalWriter, err := packet.SerializeLiteral(encryptWriter, true, "", 0)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if _, err = literalWriter.Write([]byte("The quick brown fox jumps over the lazy dog, and then some more, to span chunks")); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if err = literalWriter.Close(); err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	return dataPacket.Bytes()
+}
@@ -0,0 +1,126 @@
+package helper
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// DecryptVerifyArmoredDetachedStream is the streaming version of DecryptVerifyArmoredDetached.
+// It takes a public key for verification, a private key and its passphrase for decryption,
+// and the readers for the armored ciphertext and the armored encrypted detached signature,
+// as written by EncryptSignArmoredDetachedStream.
+// The plaintext must be read from the returned reader, which verifies the signature
+// as data comes in: DecryptVerifyDetachedReader.VerifySignature gives the result
+// once all the data has been read.
+func DecryptVerifyArmoredDetachedStream(
+	publicKey, privateKey string,
+	passphrase []byte,
+	ciphertextReader, encryptedSignatureReader crypto.Reader,
+) (plaintextReader *DecryptVerifyDetachedReader, err error) {
+	return decryptVerifyDetachedStream(publicKey, privateKey, passphrase, ciphertextReader, encryptedSignatureReader, true)
+}
+
+// DecryptVerifyBinaryDetachedStream is the streaming version of DecryptVerifyBinaryDetached.
+// It works as DecryptVerifyArmoredDetachedStream, but the encrypted data read
+// from ciphertextReader is not armored.
+func DecryptVerifyBinaryDetachedStream(
+	publicKey, privateKey string,
+	passphrase []byte,
+	ciphertextReader, encryptedSignatureReader crypto.Reader,
+) (plaintextReader *DecryptVerifyDetachedReader, err error) {
+	return decryptVerifyDetachedStream(publicKey, privateKey, passphrase, ciphertextReader, encryptedSignatureReader, false)
+}
+
+// DecryptVerifyDetachedReader decrypts the data read from it and verifies
+// the detached signature at the same time, as crypto.KeyRing.DecryptStreamWithEncryptedSignature.
+// The verification runs in a separate goroutine: if the reader is dropped before
+// it has been read entirely, Close needs to be called to release it.
+type DecryptVerifyDetachedReader struct {
+	plainMessageReader *crypto.PlainMessageReader
+	readAll            bool
+}
+
+// Read is used to access the decrypted data.
+// Makes DecryptVerifyDetachedReader implement the Reader interface.
+func (r *DecryptVerifyDetachedReader) Read(b []byte) (int, error) {
+	n, err := r.plainMessageReader.Read(b)
+	if errors.Is(err, io.EOF) {
+		r.readAll = true
+	}
+	return n, err
+}
+
+// Close releases the verification if the reader is dropped before it has been read entirely.
+func (r *DecryptVerifyDetachedReader) Close() error {
+	return r.plainMessageReader.Close()
+}
+
+// VerifySignature returns an error if the detached signature is invalid.
+// This method needs to be called once all the data has been read.
+func (r *DecryptVerifyDetachedReader) VerifySignature() error {
+	if !r.readAll {
+		return errors.New("gopenpgp: can't verify the signature until the message reader has been read entirely")
+	}
+	if err := r.plainMessageReader.VerifySignature(); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to verify message")
+	}
+	return nil
+}
+
+func decryptVerifyDetachedStream(
+	publicKey, privateKey string,
+	passphrase []byte,
+	ciphertextReader, encryptedSignatureReader crypto.Reader,
+	armored bool,
+) (plaintextReader *DecryptVerifyDetachedReader, err error) {
+	publicKeyRing, err := createPublicKeyRing(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKeyObj, err := crypto.NewKeyFromArmored(privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to parse private key")
+	}
+	unlockedKeyObj, err := privateKeyObj.Unlock(passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to unlock key")
+	}
+	// The session keys are decrypted before the data is read
+	defer unlockedKeyObj.ClearPrivateParams()
+	privateKeyRing, err := crypto.NewKeyRing(unlockedKeyObj)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to create private keyring")
+	}
+
+	// The encrypted signature is small, hence it is read at once
+	encryptedSignatureArmored, err := ioutil.ReadAll(encryptedSignatureReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to read encrypted signature")
+	}
+	encryptedSignature, err := crypto.NewPGPMessageFromArmored(string(encryptedSignatureArmored))
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to parse encrypted signature")
+	}
+	var dataReader crypto.Reader = ciphertextReader
+	if armored {
+		if dataReader, _, err = armor.UnarmorStream(ciphertextReader); err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: unable to unarmor the ciphertext")
+		}
+	}
+	plainMessageReader, err := privateKeyRing.DecryptStreamWithEncryptedSignature(
+		dataReader,
+		bytes.NewReader(encryptedSignature.GetBinary()),
+		publicKeyRing,
+		crypto.GetUnixTime(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt message")
+	}
+	return &DecryptVerifyDetachedReader{plainMessageReader: plainMessageReader}, nil
+}
@@ -0,0 +1,98 @@
+package helper
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+func encryptSignDetachedForTest(t *testing.T, plainData []byte, armored bool) (publicKey, privateKey string, ciphertext, signature []byte) {
+	privateKey = readTestFile("keyring_privateKey", false)
+	privateKeyObj, err := crypto.NewKeyFromArmored(privateKey)
+	if err != nil {
+		t.Fatal("Error reading the test private key: ", err)
+	}
+	publicKey, err = privateKeyObj.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal("Error reading the test public key: ", err)
+	}
+
+	var ciphertextBuf, signatureBuf bytes.Buffer
+	var plaintextWriter crypto.WriteCloser
+	if armored {
+		plaintextWriter, err = EncryptSignArmoredDetachedStream(publicKey, privateKey, testMailboxPassword, &ciphertextBuf, &signatureBuf)
+	} else {
+		plaintextWriter, err = EncryptSignBinaryDetachedStream(publicKey, privateKey, testMailboxPassword, &ciphertextBuf, &signatureBuf)
+	}
+	if err != nil {
+		t.Fatal("Expected no error while encrypting and signing, got:", err)
+	}
+	if _, err = plaintextWriter.Write(plainData); err != nil {
+		t.Fatal("Expected no error while writing the plaintext, got:", err)
+	}
+	if err = plaintextWriter.Close(); err != nil {
+		t.Fatal("Expected no error while closing the plaintext writer, got:", err)
+	}
+	return publicKey, privateKey, ciphertextBuf.Bytes(), signatureBuf.Bytes()
+}
+
+func TestDecryptVerifyDetachedStream(t *testing.T) {
+	plainData := bytes.Repeat([]byte("Secret message "), 10000)
+
+	for _, armored := range []bool{true, false} {
+		publicKey, privateKey, ciphertext, signature := encryptSignDetachedForTest(t, plainData, armored)
+
+		var plaintextReader *DecryptVerifyDetachedReader
+		var err error
+		if armored {
+			plaintextReader, err = DecryptVerifyArmoredDetachedStream(
+				publicKey, privateKey, testMailboxPassword, bytes.NewReader(ciphertext), bytes.NewReader(signature),
+			)
+		} else {
+			plaintextReader, err = DecryptVerifyBinaryDetachedStream(
+				publicKey, privateKey, testMailboxPassword, bytes.NewReader(ciphertext), bytes.NewReader(signature),
+			)
+		}
+		if err != nil {
+			t.Fatal("Expected no error while decrypting, got:", err)
+		}
+		if err = plaintextReader.VerifySignature(); err == nil {
+			t.Fatal("Expected an error while verifying the signature before reading the data, got nil")
+		}
+		decrypted, err := ioutil.ReadAll(plaintextReader)
+		if err != nil {
+			t.Fatal("Expected no error while reading the plaintext, got:", err)
+		}
+		if !bytes.Equal(decrypted, plainData) {
+			t.Error("Decrypted is not equal to the plaintext")
+		}
+		if err = plaintextReader.VerifySignature(); err != nil {
+			t.Fatal("Expected no error while verifying the signature, got:", err)
+		}
+	}
+}
+
+func TestDecryptVerifyDetachedStreamWrongKey(t *testing.T) {
+	plainData := bytes.Repeat([]byte("Secret message "), 10000)
+	_, privateKey, ciphertext, signature := encryptSignDetachedForTest(t, plainData, true)
+
+	plaintextReader, err := DecryptVerifyArmoredDetachedStream(
+		readTestFile("mime_publicKey", false), privateKey, testMailboxPassword,
+		bytes.NewReader(ciphertext), bytes.NewReader(signature),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	decrypted, err := ioutil.ReadAll(plaintextReader)
+	if err != nil {
+		t.Fatal("Expected no error while reading the plaintext, got:", err)
+	}
+	if !bytes.Equal(decrypted, plainData) {
+		t.Error("Decrypted is not equal to the plaintext")
+	}
+	if err = plaintextReader.VerifySignature(); err == nil {
+		t.Fatal("Expected an error while verifying the signature with the wrong key, got nil")
+	}
+}
@@ -0,0 +1,171 @@
+package helper
+
+import (
+	"io"
+	"sync"
+
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// EncryptSignArmoredDetachedStream is the streaming version of EncryptSignArmoredDetached.
+// It takes a public key for encryption, a private key and its passphrase for signature,
+// and the writers for the armored ciphertext and the armored encrypted detached signature.
+// The plaintext must be written to the returned WriteCloser: the ciphertext is written
+// to ciphertextWriter as data comes in, and the encrypted signature is written
+// to encryptedSignatureWriter when the returned WriteCloser is closed.
+func EncryptSignArmoredDetachedStream(
+	publicKey, privateKey string,
+	passphrase []byte,
+	ciphertextWriter, encryptedSignatureWriter crypto.Writer,
+) (plaintextWriter crypto.WriteCloser, err error) {
+	return encryptSignDetachedStream(publicKey, privateKey, passphrase, ciphertextWriter, encryptedSignatureWriter, true)
+}
+
+// EncryptSignBinaryDetachedStream is the streaming version of EncryptSignBinaryDetached.
+// It works as EncryptSignArmoredDetachedStream, but the encrypted data written
+// to ciphertextWriter is not armored.
+func EncryptSignBinaryDetachedStream(
+	publicKey, privateKey string,
+	passphrase []byte,
+	ciphertextWriter, encryptedSignatureWriter crypto.Writer,
+) (plaintextWriter crypto.WriteCloser, err error) {
+	return encryptSignDetachedStream(publicKey, privateKey, passphrase, ciphertextWriter, encryptedSignatureWriter, false)
+}
+
+// encryptSignDetachedWriteCloser encrypts the data written to it with a session key
+// and signs it at the same time, the signature being computed in a separate goroutine.
+type encryptSignDetachedWriteCloser struct {
+	sessionKey               *crypto.SessionKey
+	keyPacket                []byte
+	unlockedKey              *crypto.Key
+	encryptWriter            crypto.WriteCloser
+	armorWriter              io.WriteCloser
+	signPipe                 *io.PipeWriter
+	encryptedSignatureWriter crypto.Writer
+
+	done      sync.WaitGroup
+	signature *crypto.PGPSignature
+	signErr   error
+}
+
+func (w *encryptSignDetachedWriteCloser) Write(b []byte) (int, error) {
+	n, err := w.encryptWriter.Write(b)
+	if err != nil {
+		return n, errors.Wrap(err, "gopenpgp: unable to encrypt message")
+	}
+	if _, err = w.signPipe.Write(b[:n]); err != nil {
+		return n, errors.Wrap(err, "gopenpgp: unable to sign message")
+	}
+	return n, nil
+}
+
+func (w *encryptSignDetachedWriteCloser) Close() error {
+	defer w.unlockedKey.ClearPrivateParams()
+
+	if err := w.encryptWriter.Close(); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to encrypt message")
+	}
+	if w.armorWriter != nil {
+		if err := w.armorWriter.Close(); err != nil {
+			return errors.Wrap(err, "gopenpgp: unable to armor the ciphertext")
+		}
+	}
+
+	if err := w.signPipe.Close(); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to sign message")
+	}
+	w.done.Wait()
+	if w.signErr != nil {
+		return errors.Wrap(w.signErr, "gopenpgp: unable to sign message")
+	}
+
+	signatureDataPacket, err := w.sessionKey.Encrypt(crypto.NewPlainMessage(w.signature.GetBinary()))
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to encrypt detached signature")
+	}
+	encryptedSignatureArmored, err := crypto.NewPGPSplitMessage(w.keyPacket, signatureDataPacket).GetArmored()
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to armor encrypted signature")
+	}
+	if _, err = w.encryptedSignatureWriter.Write([]byte(encryptedSignatureArmored)); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to write encrypted signature")
+	}
+	return nil
+}
+
+func encryptSignDetachedStream(
+	publicKey, privateKey string,
+	passphrase []byte,
+	ciphertextWriter, encryptedSignatureWriter crypto.Writer,
+	armored bool,
+) (plaintextWriter crypto.WriteCloser, err error) {
+	publicKeyRing, err := createPublicKeyRing(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKeyObj, err := crypto.NewKeyFromArmored(privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to parse private key")
+	}
+	unlockedKeyObj, err := privateKeyObj.Unlock(passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to unlock key")
+	}
+	defer func() {
+		if err != nil {
+			unlockedKeyObj.ClearPrivateParams()
+		}
+	}()
+	privateKeyRing, err := crypto.NewKeyRing(unlockedKeyObj)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to create private keyring")
+	}
+
+	sessionKey, err := crypto.GenerateSessionKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to create new session key")
+	}
+	keyPacket, err := publicKeyRing.EncryptSessionKey(sessionKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to encrypt the session key")
+	}
+
+	w := &encryptSignDetachedWriteCloser{
+		sessionKey:               sessionKey,
+		keyPacket:                keyPacket,
+		unlockedKey:              unlockedKeyObj,
+		encryptedSignatureWriter: encryptedSignatureWriter,
+	}
+
+	var dataWriter crypto.Writer = ciphertextWriter
+	if armored {
+		w.armorWriter, err = armor.ArmorWithTypeBuffered(ciphertextWriter, constants.PGPMessageHeader)
+		if err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: unable to armor the ciphertext")
+		}
+		dataWriter = w.armorWriter
+	}
+	if _, err = dataWriter.Write(keyPacket); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to write key packet")
+	}
+	w.encryptWriter, err = sessionKey.EncryptStream(dataWriter, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to encrypt message")
+	}
+
+	signReader, signWriter := io.Pipe()
+	w.signPipe = signWriter
+	w.done.Add(1)
+	go func() {
+		defer w.done.Done()
+		w.signature, w.signErr = privateKeyRing.SignDetachedStream(signReader)
+		// Unblock the writer if the signature failed before the end of the data
+		_ = signReader.CloseWithError(w.signErr)
+	}()
+
+	return w, nil
+}
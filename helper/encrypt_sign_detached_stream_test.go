@@ -0,0 +1,86 @@
+package helper
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+func TestEncryptSignDetachedStream(t *testing.T) {
+	plainData := bytes.Repeat([]byte("Secret message "), 10000)
+	privateKeyString := readTestFile("keyring_privateKey", false)
+	privateKey, err := crypto.NewKeyFromArmored(privateKeyString)
+	if err != nil {
+		t.Fatal("Error reading the test private key: ", err)
+	}
+	publicKeyString, err := privateKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal("Error reading the test public key: ", err)
+	}
+
+	for _, armored := range []bool{true, false} {
+		var ciphertext, signature bytes.Buffer
+		var plaintextWriter crypto.WriteCloser
+		if armored {
+			plaintextWriter, err = EncryptSignArmoredDetachedStream(
+				publicKeyString, privateKeyString, testMailboxPassword, &ciphertext, &signature,
+			)
+		} else {
+			plaintextWriter, err = EncryptSignBinaryDetachedStream(
+				publicKeyString, privateKeyString, testMailboxPassword, &ciphertext, &signature,
+			)
+		}
+		if err != nil {
+			t.Fatal("Expected no error while encrypting and signing, got:", err)
+		}
+		for offset := 0; offset < len(plainData); offset += 1000 {
+			if _, err = plaintextWriter.Write(plainData[offset : offset+1000]); err != nil {
+				t.Fatal("Expected no error while writing the plaintext, got:", err)
+			}
+		}
+		if signature.Len() != 0 {
+			t.Fatal("Expected the signature to be written on close only")
+		}
+		if err = plaintextWriter.Close(); err != nil {
+			t.Fatal("Expected no error while closing the plaintext writer, got:", err)
+		}
+
+		var decrypted []byte
+		if armored {
+			decrypted, err = DecryptVerifyArmoredDetached(
+				publicKeyString, privateKeyString, testMailboxPassword, ciphertext.String(), signature.String(),
+			)
+		} else {
+			decrypted, err = DecryptVerifyBinaryDetached(
+				publicKeyString, privateKeyString, testMailboxPassword, ciphertext.Bytes(), signature.String(),
+			)
+		}
+		if err != nil {
+			t.Fatal("Expected no error while decrypting and verifying, got:", err)
+		}
+		if !bytes.Equal(decrypted, plainData) {
+			t.Error("Decrypted is not equal to the plaintext")
+		}
+	}
+}
+
+func TestEncryptSignDetachedStreamWrongPassphrase(t *testing.T) {
+	privateKeyString := readTestFile("keyring_privateKey", false)
+	privateKey, err := crypto.NewKeyFromArmored(privateKeyString)
+	if err != nil {
+		t.Fatal("Error reading the test private key: ", err)
+	}
+	publicKeyString, err := privateKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal("Error reading the test public key: ", err)
+	}
+
+	var ciphertext, signature bytes.Buffer
+	_, err = EncryptSignArmoredDetachedStream(
+		publicKeyString, privateKeyString, []byte("wrong passphrase"), &ciphertext, &signature,
+	)
+	if err == nil {
+		t.Fatal("Expected an error while unlocking the key with the wrong passphrase")
+	}
+}
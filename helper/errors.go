@@ -0,0 +1,59 @@
+package helper
+
+import (
+	"context"
+	goerrors "errors"
+	"io"
+	"strings"
+
+	pgpErrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// ErrCanceled can be returned by the readers and writers given to the streaming functions
+// to cancel the operation, which is then reported as constants.ERROR_CANCELED.
+var ErrCanceled = goerrors.New("gopenpgp: operation canceled")
+
+// privateKeyChecksumFailure is the error reported by go-crypto when a private key
+// is unlocked with the wrong passphrase.
+const privateKeyChecksumFailure = "private key checksum failure"
+
+// keyIDMismatch is the prefix of the error reported by go-crypto when a session key packet
+// is decrypted with a key whose ID doesn't match the recipient's one.
+const keyIDMismatch = "cannot decrypt encrypted session key for key id"
+
+// GetErrorCode returns the code of the error, one of constants.ERROR_*,
+// since mobile callers can't inspect the errors and would otherwise need to match their messages.
+// It returns constants.ERROR_NONE if err is nil, and constants.ERROR_UNKNOWN if the error can't be classified.
+func GetErrorCode(err error) int {
+	if err == nil {
+		return constants.ERROR_NONE
+	}
+
+	var sigErr crypto.SignatureVerificationError
+	var structuralErr pgpErrors.StructuralError
+	var invalidArgumentErr pgpErrors.InvalidArgumentError
+	var aeadErr pgpErrors.AEADError
+	var signatureErr pgpErrors.SignatureError
+	var unknownPacketErr pgpErrors.UnknownPacketTypeError
+	switch {
+	case goerrors.Is(err, ErrCanceled), goerrors.Is(err, context.Canceled):
+		return constants.ERROR_CANCELED
+	case goerrors.As(err, &sigErr):
+		return constants.ERROR_BAD_SIGNATURE
+	case goerrors.Is(err, crypto.ErrNoDecryptionKey), goerrors.Is(err, pgpErrors.ErrKeyIncorrect):
+		return constants.ERROR_NO_MATCHING_KEY
+	case goerrors.As(err, &invalidArgumentErr) && strings.HasPrefix(string(invalidArgumentErr), keyIDMismatch):
+		return constants.ERROR_NO_MATCHING_KEY
+	case goerrors.As(err, &structuralErr) && string(structuralErr) == privateKeyChecksumFailure:
+		return constants.ERROR_WRONG_PASSPHRASE
+	case goerrors.As(err, &structuralErr),
+		goerrors.As(err, &aeadErr),
+		goerrors.As(err, &signatureErr),
+		goerrors.As(err, &unknownPacketErr),
+		goerrors.Is(err, io.ErrUnexpectedEOF):
+		return constants.ERROR_CORRUPTED_DATA
+	}
+	return constants.ERROR_UNKNOWN
+}
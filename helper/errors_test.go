@@ -0,0 +1,63 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetErrorCode(t *testing.T) {
+	assert.Exactly(t, constants.ERROR_NONE, GetErrorCode(nil))
+	assert.Exactly(t, constants.ERROR_UNKNOWN, GetErrorCode(errors.New("other error")))
+	assert.Exactly(t, constants.ERROR_CANCELED, GetErrorCode(errors.Wrap(ErrCanceled, "gopenpgp: wrapped")))
+
+	lockedKey, err := crypto.NewKeyFromArmored(readTestFile("keyring_privateKey", false))
+	if err != nil {
+		t.Fatal("Expected no error while parsing the key, got:", err)
+	}
+	_, err = lockedKey.Unlock([]byte("wrong passphrase"))
+	assert.Exactly(t, constants.ERROR_WRONG_PASSPHRASE, GetErrorCode(err))
+
+	pubKR, privKR, err := setUpTestKeyRing()
+	if err != nil {
+		t.Fatal("Expected no error while generating the keys, got:", err)
+	}
+	_, otherPrivKR, err := setUpTestKeyRing()
+	if err != nil {
+		t.Fatal("Expected no error while generating the keys, got:", err)
+	}
+	otherPubKR, err := otherPrivKR.GetKey(0)
+	if err != nil {
+		t.Fatal("Expected no error while getting the key, got:", err)
+	}
+	message, err := pubKR.Encrypt(crypto.NewPlainMessageFromString("hello"), otherPrivKR)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+
+	_, err = otherPrivKR.Decrypt(message, nil, 0)
+	assert.Exactly(t, constants.ERROR_NO_MATCHING_KEY, GetErrorCode(err))
+	split, err := message.SplitMessage()
+	if err != nil {
+		t.Fatal("Expected no error while splitting the message, got:", err)
+	}
+	_, err = otherPrivKR.DecryptSessionKey(split.GetBinaryKeyPacket())
+	assert.Exactly(t, constants.ERROR_NO_MATCHING_KEY, GetErrorCode(err))
+
+	_, err = privKR.Decrypt(message, pubKR, crypto.GetUnixTime())
+	assert.Exactly(t, constants.ERROR_BAD_SIGNATURE, GetErrorCode(err))
+	verifyKR, err := crypto.NewKeyRing(otherPubKR)
+	if err != nil {
+		t.Fatal("Expected no error while creating the keyring, got:", err)
+	}
+	_, err = privKR.Decrypt(message, verifyKR, crypto.GetUnixTime())
+	assert.Exactly(t, constants.ERROR_NONE, GetErrorCode(err))
+
+	data := message.GetBinary()
+	data[len(data)-5] ^= 1
+	_, err = privKR.Decrypt(crypto.NewPGPMessage(data), nil, 0)
+	assert.Exactly(t, constants.ERROR_CORRUPTED_DATA, GetErrorCode(err))
+}
@@ -53,6 +53,23 @@ func GenerateKey(name, email string, passphrase []byte, keyType string, bits int
 	return locked.Armor()
 }
 
+// GenerateKeyWithPassphrasePolicy generates a key of the given keyType ("rsa" or "x25519"),
+// encrypts it, and returns an armored string, as GenerateKey.
+// The passphrase is checked against the policy before the key is generated,
+// and a crypto.WeakPassphraseError is returned if it doesn't satisfy the policy.
+func GenerateKeyWithPassphrasePolicy(
+	name, email string,
+	passphrase []byte,
+	policy *crypto.PassphrasePolicy,
+	keyType string,
+	bits int,
+) (string, error) {
+	if err := policy.Check(passphrase); err != nil {
+		return "", err
+	}
+	return GenerateKey(name, email, passphrase, keyType, bits)
+}
+
 func GetSHA256Fingerprints(publicKey string) ([]string, error) {
 	key, err := crypto.NewKeyFromArmored(publicKey)
 	if err != nil {
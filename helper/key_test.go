@@ -1,8 +1,10 @@
 package helper
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,3 +18,29 @@ func TestGetSHA256FingerprintsV4(t *testing.T) {
 	assert.Exactly(t, "d9ac0b857da6d2c8be985b251a9e3db31e7a1d2d832d1f07ebe838a9edce9c24", sha256Fingerprints[0])
 	assert.Exactly(t, "203dfba1f8442c17e59214d9cd11985bfc5cc8721bb4a71740dd5507e58a1a0d", sha256Fingerprints[1])
 }
+
+func TestGenerateKeyWithPassphrasePolicy(t *testing.T) {
+	policy := crypto.NewPassphrasePolicy().WithMinLength(12)
+
+	_, err := GenerateKeyWithPassphrasePolicy("name", "name@example.com", []byte("short"), policy, "x25519", 0)
+	var weakErr crypto.WeakPassphraseError
+	if !errors.As(err, &weakErr) {
+		t.Fatal("Expected a weak passphrase error while generating the key, got:", err)
+	}
+
+	armored, err := GenerateKeyWithPassphrasePolicy(
+		"name", "name@example.com", []byte("a long enough passphrase"), policy, "x25519", 0,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while generating the key, got:", err)
+	}
+	key, err := crypto.NewKeyFromArmored(armored)
+	if err != nil {
+		t.Fatal("Expected no error while parsing the key, got:", err)
+	}
+	locked, err := key.IsLocked()
+	if err != nil {
+		t.Fatal("Expected no error while checking the key, got:", err)
+	}
+	assert.True(t, locked)
+}
@@ -1,8 +1,10 @@
 package helper
 
 import (
+	"bytes"
 	"encoding/json"
 	goerrors "errors"
+	"io/ioutil"
 	"runtime/debug"
 
 	"github.com/ProtonMail/gopenpgp/v2/crypto"
@@ -91,6 +93,67 @@ func newExplicitVerifyMessage(message *crypto.PlainMessage, err error) (*Explici
 	return explicitVerify, nil
 }
 
+// ExplicitVerifyResult is the result of DecryptExplicitVerifyDetails.
+// It contains the decrypted message, with its literal metadata, the signature verification error,
+// and the details of the embedded signatures.
+type ExplicitVerifyResult struct {
+	Message                    *crypto.PlainMessage
+	SignatureVerificationError *crypto.SignatureVerificationError
+	signatures                 []*crypto.SignatureDetails
+}
+
+// GetSignatureCount returns the number of verified embedded signatures,
+// since gomobile can not handle arrays.
+func (result *ExplicitVerifyResult) GetSignatureCount() int {
+	return len(result.signatures)
+}
+
+// GetSignature returns the details of the embedded signature at the given index.
+func (result *ExplicitVerifyResult) GetSignature(index int) (*crypto.SignatureDetails, error) {
+	if index < 0 || index >= len(result.signatures) {
+		return nil, errors.New("gopenpgp: signature index out of range")
+	}
+	return result.signatures[index], nil
+}
+
+// DecryptExplicitVerifyDetails decrypts a PGP message given a private keyring
+// and a public keyring to verify the embedded signature, as DecryptExplicitVerify.
+// The result also contains the details of the embedded signature,
+// i.e. its status, signer key ID and fingerprint, creation time and hash.
+// NOTE: only the first signature made by a key of the public keyring is verified,
+// hence the result contains at most one signature.
+func DecryptExplicitVerifyDetails(
+	pgpMessage *crypto.PGPMessage,
+	privateKeyRing, publicKeyRing *crypto.KeyRing,
+	verifyTime int64,
+) (*ExplicitVerifyResult, error) {
+	reader, err := privateKeyRing.DecryptStream(bytes.NewReader(pgpMessage.GetBinary()), publicKeyRing, verifyTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt message")
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt message")
+	}
+	metadata := reader.GetMetadata()
+	message := crypto.NewPlainMessageFromFile(data, metadata.Filename, uint32(metadata.ModTime))
+	message.TextType = !metadata.IsBinary
+
+	result := &ExplicitVerifyResult{Message: message}
+	signature, err := reader.VerifySignatureDetails()
+	if signature != nil {
+		result.signatures = append(result.signatures, signature)
+	}
+	if err != nil {
+		castedErr := &crypto.SignatureVerificationError{}
+		if !goerrors.As(err, castedErr) {
+			return nil, errors.Wrap(err, "gopenpgp: unable to verify message")
+		}
+		result.SignatureVerificationError = castedErr
+	}
+	return result, nil
+}
+
 // DecryptAttachment takes a keypacket and datpacket
 // and returns a decrypted PlainMessage
 // Specifically designed for attachments rather than text messages.
@@ -117,6 +180,31 @@ func EncryptAttachment(plainData []byte, filename string, keyRing *crypto.KeyRin
 	return decrypted, nil
 }
 
+// AddMessageRecipient returns the given message with an additional key packet,
+// encrypting its session key to recipientKeyRing, e.g. to share it with a newly added device.
+// The session key is decrypted with decryptionKeyRing; the data packet is left as is.
+func AddMessageRecipient(
+	message *crypto.PGPMessage,
+	decryptionKeyRing, recipientKeyRing *crypto.KeyRing,
+) (*crypto.PGPMessage, error) {
+	splitMessage, err := message.SplitMessage()
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to split message")
+	}
+	sessionKey, err := decryptionKeyRing.DecryptSessionKey(splitMessage.GetBinaryKeyPacket())
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt session key")
+	}
+	defer sessionKey.Clear()
+	keyPacket, err := recipientKeyRing.EncryptSessionKey(sessionKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to encrypt session key")
+	}
+
+	keyPackets := append(splitMessage.GetBinaryKeyPacket(), keyPacket...)
+	return crypto.NewPGPSplitMessage(keyPackets, splitMessage.GetBinaryDataPacket()).GetPGPMessage(), nil
+}
+
 // GetJsonSHA256Fingerprints returns the SHA256 fingeprints of key and subkeys,
 // encoded in JSON, since gomobile can not handle arrays.
 func GetJsonSHA256Fingerprints(publicKey string) ([]byte, error) {
@@ -128,6 +216,36 @@ func GetJsonSHA256Fingerprints(publicKey string) ([]byte, error) {
 	return json.Marshal(key.GetSHA256Fingerprints())
 }
 
+// sessionKeyJson is the JSON structure of a session key,
+// with the key encoded in base64 and the symbolic name of the algorithm.
+type sessionKeyJson struct {
+	Key       []byte `json:"key"`
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+// GetJsonSessionKey encodes the session key in JSON, e.g. {"key":"<base64>","algorithm":"aes256"},
+// since gomobile can not handle structs with byte slices easily.
+// The algorithm is omitted for session keys without algorithm, as in version 6 key packets.
+func GetJsonSessionKey(sessionKey *crypto.SessionKey) ([]byte, error) {
+	if err := sessionKey.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(sessionKeyJson{Key: sessionKey.Key, Algorithm: sessionKey.Algo})
+}
+
+// NewSessionKeyFromJson decodes and validates a session key encoded in JSON with GetJsonSessionKey.
+func NewSessionKeyFromJson(jsonSessionKey []byte) (*crypto.SessionKey, error) {
+	var decoded sessionKeyJson
+	if err := json.Unmarshal(jsonSessionKey, &decoded); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to parse session key")
+	}
+	sessionKey := crypto.NewSessionKeyFromToken(decoded.Key, decoded.Algorithm)
+	if err := sessionKey.Validate(); err != nil {
+		return nil, err
+	}
+	return sessionKey, nil
+}
+
 type EncryptSignArmoredDetachedMobileResult struct {
 	CiphertextArmored, EncryptedSignatureArmored string
 }
@@ -173,6 +291,8 @@ func EncryptSignBinaryDetachedMobile(
 // FreeOSMemory can be used to explicitly
 // call the garbage collector and
 // return the unused memory to the OS.
+// crypto.SetMemoryBudget can be used instead to bound the memory
+// used by the in-memory decryption functions.
 func FreeOSMemory() {
 	debug.FreeOSMemory()
 }
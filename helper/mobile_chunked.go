@@ -0,0 +1,64 @@
+package helper
+
+import (
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// DefaultChunkSize is the chunk size used by NewChunkedBytes when the given chunk size is not positive.
+const DefaultChunkSize = 1 << 20
+
+// ChunkedBytes exposes a large byte slice, e.g. a decrypted plaintext or a ciphertext,
+// through indexed chunks, since gomobile copies the whole slice whenever it is accessed.
+type ChunkedBytes struct {
+	data      []byte
+	chunkSize int
+}
+
+// NewChunkedBytes wraps the data to be accessed by chunks of chunkSize bytes.
+// If chunkSize is not positive, DefaultChunkSize is used.
+// The data is not copied.
+func NewChunkedBytes(data []byte, chunkSize int) *ChunkedBytes {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &ChunkedBytes{data: data, chunkSize: chunkSize}
+}
+
+// NewChunkedPlainMessage wraps the data of a plain message to be accessed by chunks, as NewChunkedBytes.
+func NewChunkedPlainMessage(message *crypto.PlainMessage, chunkSize int) *ChunkedBytes {
+	return NewChunkedBytes(message.GetBinary(), chunkSize)
+}
+
+// NewChunkedPGPMessage wraps the binary data of a PGP message to be accessed by chunks, as NewChunkedBytes.
+func NewChunkedPGPMessage(message *crypto.PGPMessage, chunkSize int) *ChunkedBytes {
+	return NewChunkedBytes(message.GetBinary(), chunkSize)
+}
+
+// GetSize returns the total size of the data, in bytes.
+func (chunked *ChunkedBytes) GetSize() int {
+	return len(chunked.data)
+}
+
+// GetChunkSize returns the size of the chunks, in bytes. The last chunk can be smaller.
+func (chunked *ChunkedBytes) GetChunkSize() int {
+	return chunked.chunkSize
+}
+
+// GetChunkCount returns the number of chunks.
+func (chunked *ChunkedBytes) GetChunkCount() int {
+	return (len(chunked.data) + chunked.chunkSize - 1) / chunked.chunkSize
+}
+
+// GetChunk returns a copy of the chunk at the given index.
+func (chunked *ChunkedBytes) GetChunk(index int) ([]byte, error) {
+	if index < 0 || index >= chunked.GetChunkCount() {
+		return nil, errors.New("gopenpgp: chunk index out of range")
+	}
+	start := index * chunked.chunkSize
+	end := start + chunked.chunkSize
+	if end > len(chunked.data) {
+		end = len(chunked.data)
+	}
+	return clone(chunked.data[start:end]), nil
+}
@@ -0,0 +1,47 @@
+package helper
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkedBytes(t *testing.T) {
+	data := []byte("Hello World!")
+	chunked := NewChunkedBytes(data, 5)
+	assert.Exactly(t, 12, chunked.GetSize())
+	assert.Exactly(t, 5, chunked.GetChunkSize())
+	assert.Exactly(t, 3, chunked.GetChunkCount())
+
+	var readData []byte
+	for i := 0; i < chunked.GetChunkCount(); i++ {
+		chunk, err := chunked.GetChunk(i)
+		if err != nil {
+			t.Fatal("Expected no error while getting the chunk, got:", err)
+		}
+		readData = append(readData, chunk...)
+	}
+	assert.Exactly(t, data, readData)
+
+	for _, index := range []int{-1, 3} {
+		if _, err := chunked.GetChunk(index); err == nil {
+			t.Fatal("Expected an error while getting a chunk out of range, got nil")
+		}
+	}
+
+	assert.Exactly(t, 0, NewChunkedBytes(nil, 5).GetChunkCount())
+	assert.Exactly(t, DefaultChunkSize, NewChunkedBytes(data, 0).GetChunkSize())
+}
+
+func TestChunkedPlainMessage(t *testing.T) {
+	data := bytes.Repeat([]byte{0x42}, 10)
+	chunked := NewChunkedPlainMessage(crypto.NewPlainMessage(data), 4)
+	assert.Exactly(t, 3, chunked.GetChunkCount())
+	chunk, err := chunked.GetChunk(2)
+	if err != nil {
+		t.Fatal("Expected no error while getting the chunk, got:", err)
+	}
+	assert.Exactly(t, data[8:], chunk)
+}
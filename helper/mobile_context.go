@@ -0,0 +1,137 @@
+package helper
+
+import (
+	goerrors "errors"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// SigningContextBuilder builds a crypto.SigningContext step by step,
+// since gomobile bindings can't use struct literals.
+type SigningContextBuilder struct {
+	value      string
+	isCritical bool
+}
+
+// NewSigningContextBuilder creates a builder for a signing context with the given value,
+// not critical by default.
+func NewSigningContextBuilder(value string) *SigningContextBuilder {
+	return &SigningContextBuilder{value: value}
+}
+
+// Critical flags the context notation as critical.
+func (builder *SigningContextBuilder) Critical() *SigningContextBuilder {
+	builder.isCritical = true
+	return builder
+}
+
+// Build returns the signing context.
+func (builder *SigningContextBuilder) Build() *crypto.SigningContext {
+	return crypto.NewSigningContext(builder.value, builder.isCritical)
+}
+
+// VerificationContextBuilder builds a crypto.VerificationContext step by step,
+// since gomobile bindings can't use struct literals.
+type VerificationContextBuilder struct {
+	value         string
+	isRequired    bool
+	requiredAfter int64
+}
+
+// NewVerificationContextBuilder creates a builder for a verification context with the given value,
+// which is not required by default: signatures without context are accepted.
+func NewVerificationContextBuilder(value string) *VerificationContextBuilder {
+	return &VerificationContextBuilder{value: value}
+}
+
+// Required rejects the signatures without context.
+func (builder *VerificationContextBuilder) Required() *VerificationContextBuilder {
+	builder.isRequired = true
+	return builder
+}
+
+// RequiredAfter rejects the signatures without context created after the given unix time,
+// and accepts the older ones.
+func (builder *VerificationContextBuilder) RequiredAfter(unixTime int64) *VerificationContextBuilder {
+	builder.isRequired = true
+	builder.requiredAfter = unixTime
+	return builder
+}
+
+// Build returns the verification context.
+func (builder *VerificationContextBuilder) Build() *crypto.VerificationContext {
+	return crypto.NewVerificationContext(builder.value, builder.isRequired, builder.requiredAfter)
+}
+
+// SignatureVerificationResult is the result of a signature verification, with getters only,
+// so that gomobile bindings can inspect it without type assertions on errors.
+type SignatureVerificationResult struct {
+	status  int
+	message string
+}
+
+// IsValid returns true if the signature is valid.
+func (result *SignatureVerificationResult) IsValid() bool {
+	return result.status == constants.SIGNATURE_OK
+}
+
+// GetStatus returns the status of the verification, one of the constants.SIGNATURE_* values.
+func (result *SignatureVerificationResult) GetStatus() int {
+	return result.status
+}
+
+// HasBadContext returns true if the signature is rejected because of its context.
+func (result *SignatureVerificationResult) HasBadContext() bool {
+	return result.status == constants.SIGNATURE_BAD_CONTEXT
+}
+
+// GetMessage returns the description of the verification failure, empty if the signature is valid.
+func (result *SignatureVerificationResult) GetMessage() string {
+	return result.message
+}
+
+// VerifyDetachedWithContext verifies the armored detached signature of the data with the keyring
+// and the verification context, which can be nil, and returns the result of the verification.
+// An error is only returned if the verification could not be run, e.g. if the signature can't be parsed.
+func VerifyDetachedWithContext(
+	data []byte,
+	armoredSignature string,
+	keyRing *crypto.KeyRing,
+	verifyTime int64,
+	verificationContext *crypto.VerificationContext,
+) (*SignatureVerificationResult, error) {
+	signature, err := crypto.NewPGPSignatureFromArmored(armoredSignature)
+	if err != nil {
+		return nil, err
+	}
+	err = keyRing.VerifyDetachedWithContext(crypto.NewPlainMessage(data), signature, verifyTime, verificationContext)
+	return newSignatureVerificationResult(err)
+}
+
+// GetSignatureVerificationResult returns the result of the signature verification
+// of the explicitly verified message, as DecryptExplicitVerifyWithContext returns.
+func (msg *ExplicitVerifyMessage) GetSignatureVerificationResult() *SignatureVerificationResult {
+	if msg.SignatureVerificationError == nil {
+		return &SignatureVerificationResult{status: constants.SIGNATURE_OK}
+	}
+	return &SignatureVerificationResult{
+		status:  msg.SignatureVerificationError.Status,
+		message: msg.SignatureVerificationError.Message,
+	}
+}
+
+func newSignatureVerificationResult(err error) (*SignatureVerificationResult, error) {
+	if err == nil {
+		return &SignatureVerificationResult{status: constants.SIGNATURE_OK}, nil
+	}
+	var verificationErr crypto.SignatureVerificationError
+	if !goerrors.As(err, &verificationErr) {
+		return nil, errors.Wrap(err, "gopenpgp: unable to verify the signature")
+	}
+	return &SignatureVerificationResult{
+		status:  verificationErr.Status,
+		message: verificationErr.Message,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextBuildersAndVerificationResult(t *testing.T) {
+	keyRing, err := createUnlockedKeyRing(readTestFile("keyring_privateKey", false), testMailboxPassword)
+	if err != nil {
+		t.Fatal("Expected no error while unlocking the test key, got:", err)
+	}
+	data := []byte("signed with context")
+
+	signingContext := NewSigningContextBuilder("test-context").Critical().Build()
+	assert.Exactly(t, crypto.NewSigningContext("test-context", true), signingContext)
+	signature, err := keyRing.SignDetachedWithContext(crypto.NewPlainMessage(data), signingContext)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	armoredSignature, err := signature.GetArmored()
+	if err != nil {
+		t.Fatal("Expected no error while armoring the signature, got:", err)
+	}
+
+	verificationContext := NewVerificationContextBuilder("test-context").Required().Build()
+	result, err := VerifyDetachedWithContext(data, armoredSignature, keyRing, 0, verificationContext)
+	if err != nil {
+		t.Fatal("Expected no error while verifying, got:", err)
+	}
+	assert.True(t, result.IsValid())
+	assert.Empty(t, result.GetMessage())
+
+	wrongContext := NewVerificationContextBuilder("other-context").RequiredAfter(1600000000).Build()
+	assert.Exactly(t, crypto.NewVerificationContext("other-context", true, 1600000000), wrongContext)
+	result, err = VerifyDetachedWithContext(data, armoredSignature, keyRing, 0, wrongContext)
+	if err != nil {
+		t.Fatal("Expected no error while verifying, got:", err)
+	}
+	assert.False(t, result.IsValid())
+	assert.True(t, result.HasBadContext())
+	assert.Exactly(t, constants.SIGNATURE_BAD_CONTEXT, result.GetStatus())
+	assert.NotEmpty(t, result.GetMessage())
+
+	result, err = VerifyDetachedWithContext([]byte("other data"), armoredSignature, keyRing, 0, nil)
+	if err != nil {
+		t.Fatal("Expected no error while verifying, got:", err)
+	}
+	assert.Exactly(t, constants.SIGNATURE_FAILED, result.GetStatus())
+
+	_, err = VerifyDetachedWithContext(data, "not a signature", keyRing, 0, nil)
+	assert.Error(t, err)
+
+	message, err := keyRing.Encrypt(crypto.NewPlainMessage(data), nil)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	explicitVerify, err := DecryptExplicitVerifyWithContext(message, keyRing, keyRing, 0, verificationContext)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	assert.Exactly(t, constants.SIGNATURE_NOT_SIGNED, explicitVerify.GetSignatureVerificationResult().GetStatus())
+}
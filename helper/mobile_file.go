@@ -0,0 +1,230 @@
+package helper
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// EncryptFile encrypts the file at inputPath with the given armored public key,
+// and writes the unarmored PGP message to outputPath.
+// The file is encrypted as a stream, without loading it in memory,
+// since passing streams across gomobile is cumbersome.
+func EncryptFile(publicKey string, inputPath, outputPath string) error {
+	publicKeyRing, err := createPublicKeyRing(publicKey)
+	if err != nil {
+		return err
+	}
+	return encryptFile(publicKeyRing, nil, inputPath, outputPath)
+}
+
+// EncryptSignFile encrypts the file at inputPath with the given armored public key,
+// signs it with the private key unlocked with the passphrase,
+// and writes the unarmored PGP message to outputPath.
+// The file is encrypted as a stream, without loading it in memory.
+func EncryptSignFile(publicKey, privateKey string, passphrase []byte, inputPath, outputPath string) error {
+	publicKeyRing, err := createPublicKeyRing(publicKey)
+	if err != nil {
+		return err
+	}
+	privateKeyRing, err := createUnlockedKeyRing(privateKey, passphrase)
+	if err != nil {
+		return err
+	}
+	defer privateKeyRing.ClearPrivateParams()
+
+	return encryptFile(publicKeyRing, privateKeyRing, inputPath, outputPath)
+}
+
+// DecryptFile decrypts the unarmored PGP message in the file at inputPath
+// with the private key unlocked with the passphrase, and writes the plaintext to outputPath.
+// The file is decrypted as a stream, without loading it in memory.
+// The output file is removed if the decryption fails.
+func DecryptFile(privateKey string, passphrase []byte, inputPath, outputPath string) error {
+	privateKeyRing, err := createUnlockedKeyRing(privateKey, passphrase)
+	if err != nil {
+		return err
+	}
+	defer privateKeyRing.ClearPrivateParams()
+
+	return decryptFile(privateKeyRing, nil, inputPath, outputPath)
+}
+
+// DecryptVerifyFile decrypts the unarmored PGP message in the file at inputPath
+// as DecryptFile, and verifies the embedded signature with the given armored public key.
+// The output file is removed if the decryption or the signature verification fails.
+func DecryptVerifyFile(publicKey, privateKey string, passphrase []byte, inputPath, outputPath string) error {
+	publicKeyRing, err := createPublicKeyRing(publicKey)
+	if err != nil {
+		return err
+	}
+	privateKeyRing, err := createUnlockedKeyRing(privateKey, passphrase)
+	if err != nil {
+		return err
+	}
+	defer privateKeyRing.ClearPrivateParams()
+
+	return decryptFile(privateKeyRing, publicKeyRing, inputPath, outputPath)
+}
+
+// DecryptAttachmentToFile decrypts the attachment with the key packet and the data packet
+// in the file at dataPacketPath with the keyring, and writes the plaintext to outputPath.
+// Unlike DecryptAttachment, neither the data packet nor the plaintext are loaded in memory:
+// the plaintext is copied to the file with a buffer of at most maxBufferSize bytes.
+// Returns the metadata of the attachment. The output file is removed if the decryption fails.
+func DecryptAttachmentToFile(
+	keyPacket []byte,
+	dataPacketPath, outputPath string,
+	keyRing *crypto.KeyRing,
+	maxBufferSize int,
+) (metadata *crypto.PlainMessageMetadata, err error) {
+	dataPacketFile, err := os.Open(filepath.Clean(dataPacketPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to open the data packet file")
+	}
+	defer func() { _ = dataPacketFile.Close() }()
+
+	outputFile, err := createOutputFile(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = closeOutputFile(outputFile, err) }()
+
+	return DecryptAttachmentToWriter(keyPacket, dataPacketFile, keyRing, outputFile, maxBufferSize)
+}
+
+// DecryptAttachmentToWriter decrypts the attachment with the key packet and the data packet
+// read from dataPacketReader with the keyring, e.g. a Mobile2GoReader, and writes the plaintext
+// to plaintextWriter, e.g. a Mobile2GoWriter, with a buffer of at most maxBufferSize bytes.
+// Returns the metadata of the attachment. If an error is returned,
+// the data written to plaintextWriter needs to be discarded.
+func DecryptAttachmentToWriter(
+	keyPacket []byte,
+	dataPacketReader crypto.Reader,
+	keyRing *crypto.KeyRing,
+	plaintextWriter crypto.Writer,
+	maxBufferSize int,
+) (*crypto.PlainMessageMetadata, error) {
+	if maxBufferSize <= 0 {
+		return nil, errors.New("gopenpgp: invalid buffer size")
+	}
+	plaintextReader, err := keyRing.DecryptSplitStream(keyPacket, dataPacketReader, nil, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt attachment")
+	}
+	// Hide WriterTo and ReaderFrom, so that the copy buffer is used
+	_, err = io.CopyBuffer(
+		struct{ io.Writer }{plaintextWriter},
+		struct{ io.Reader }{plaintextReader},
+		make([]byte, maxBufferSize),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt attachment")
+	}
+	return plaintextReader.GetMetadata(), nil
+}
+
+func createUnlockedKeyRing(privateKey string, passphrase []byte) (*crypto.KeyRing, error) {
+	privateKeyObj, err := crypto.NewKeyFromArmored(privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to parse the private key")
+	}
+
+	unlockedKeyObj, err := privateKeyObj.Unlock(passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to unlock key")
+	}
+
+	privateKeyRing, err := crypto.NewKeyRing(unlockedKeyObj)
+	if err != nil {
+		unlockedKeyObj.ClearPrivateParams()
+		return nil, errors.Wrap(err, "gopenpgp: unable to create the private key ring")
+	}
+	return privateKeyRing, nil
+}
+
+func encryptFile(publicKeyRing, signKeyRing *crypto.KeyRing, inputPath, outputPath string) (err error) {
+	inputFile, err := os.Open(filepath.Clean(inputPath))
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to open the input file")
+	}
+	defer func() { _ = inputFile.Close() }()
+
+	info, err := inputFile.Stat()
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to read the input file")
+	}
+
+	outputFile, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer func() { err = closeOutputFile(outputFile, err) }()
+
+	metadata := crypto.NewPlainMessageMetadata(true, filepath.Base(inputPath), info.ModTime().Unix())
+	plaintextWriter, err := publicKeyRing.EncryptStream(outputFile, metadata, signKeyRing)
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to encrypt the file")
+	}
+	if _, err = io.Copy(plaintextWriter, inputFile); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to encrypt the file")
+	}
+	if err = plaintextWriter.Close(); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to encrypt the file")
+	}
+	return nil
+}
+
+func decryptFile(privateKeyRing, verifyKeyRing *crypto.KeyRing, inputPath, outputPath string) (err error) {
+	inputFile, err := os.Open(filepath.Clean(inputPath))
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to open the input file")
+	}
+	defer func() { _ = inputFile.Close() }()
+
+	outputFile, err := createOutputFile(outputPath)
+	if err != nil {
+		return err
+	}
+	defer func() { err = closeOutputFile(outputFile, err) }()
+
+	plaintextReader, err := privateKeyRing.DecryptStream(inputFile, verifyKeyRing, crypto.GetUnixTime())
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to decrypt the file")
+	}
+	if _, err = io.Copy(outputFile, plaintextReader); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to decrypt the file")
+	}
+	if verifyKeyRing != nil {
+		if err = plaintextReader.VerifySignature(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createOutputFile(outputPath string) (*os.File, error) {
+	outputFile, err := os.OpenFile(filepath.Clean(outputPath), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to create the output file")
+	}
+	return outputFile, nil
+}
+
+// closeOutputFile closes the output file, and removes it if the operation failed
+// so that no partial output is left behind.
+func closeOutputFile(outputFile *os.File, err error) error {
+	closeErr := outputFile.Close()
+	if err != nil {
+		_ = os.Remove(outputFile.Name())
+		return err
+	}
+	if closeErr != nil {
+		_ = os.Remove(outputFile.Name())
+		return errors.Wrap(closeErr, "gopenpgp: unable to close the output file")
+	}
+	return nil
+}
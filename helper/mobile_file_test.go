@@ -0,0 +1,138 @@
+package helper
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+func TestEncryptDecryptFile(t *testing.T) {
+	privateKeyString := readTestFile("keyring_privateKey", false)
+	privateKey, err := crypto.NewKeyFromArmored(privateKeyString)
+	if err != nil {
+		t.Fatal("Error reading the test private key: ", err)
+	}
+	publicKeyString, err := privateKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal("Error reading the test public key: ", err)
+	}
+
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "plain.txt")
+	encryptedPath := filepath.Join(dir, "plain.txt.pgp")
+	decryptedPath := filepath.Join(dir, "decrypted.txt")
+	plainData := bytes.Repeat([]byte("Secret file content\n"), 10000)
+	if err = ioutil.WriteFile(plainPath, plainData, 0600); err != nil {
+		t.Fatal("Expected no error while writing the input file, got:", err)
+	}
+
+	if err = EncryptSignFile(publicKeyString, privateKeyString, testMailboxPassword, plainPath, encryptedPath); err != nil {
+		t.Fatal("Expected no error while encrypting the file, got:", err)
+	}
+	if err = DecryptVerifyFile(publicKeyString, privateKeyString, testMailboxPassword, encryptedPath, decryptedPath); err != nil {
+		t.Fatal("Expected no error while decrypting the file, got:", err)
+	}
+	decrypted, err := ioutil.ReadFile(decryptedPath)
+	if err != nil {
+		t.Fatal("Expected no error while reading the output file, got:", err)
+	}
+	if !bytes.Equal(decrypted, plainData) {
+		t.Error("Decrypted file is not equal to the plaintext")
+	}
+
+	if err = EncryptFile(publicKeyString, plainPath, encryptedPath); err != nil {
+		t.Fatal("Expected no error while encrypting the file, got:", err)
+	}
+	if err = DecryptFile(privateKeyString, testMailboxPassword, encryptedPath, decryptedPath); err != nil {
+		t.Fatal("Expected no error while decrypting the file, got:", err)
+	}
+	decrypted, err = ioutil.ReadFile(decryptedPath)
+	if err != nil {
+		t.Fatal("Expected no error while reading the output file, got:", err)
+	}
+	if !bytes.Equal(decrypted, plainData) {
+		t.Error("Decrypted file is not equal to the plaintext")
+	}
+
+	// The signature can't be verified for an unsigned file, the output must be removed
+	err = DecryptVerifyFile(publicKeyString, privateKeyString, testMailboxPassword, encryptedPath, decryptedPath)
+	if err == nil {
+		t.Fatal("Expected an error while verifying an unsigned file")
+	}
+	if _, err = os.Stat(decryptedPath); !os.IsNotExist(err) {
+		t.Error("Expected the output file to be removed on failure")
+	}
+}
+
+type maxWriteRecorder struct {
+	bytes.Buffer
+	maxWrite int
+}
+
+func (w *maxWriteRecorder) Write(b []byte) (int, error) {
+	if len(b) > w.maxWrite {
+		w.maxWrite = len(b)
+	}
+	return w.Buffer.Write(b)
+}
+
+func TestDecryptAttachmentToFile(t *testing.T) {
+	keyRing, err := createUnlockedKeyRing(readTestFile("keyring_privateKey", false), testMailboxPassword)
+	if err != nil {
+		t.Fatal("Expected no error while unlocking the test key, got:", err)
+	}
+	plainData := bytes.Repeat([]byte("Secret attachment content\n"), 10000)
+	split, err := keyRing.EncryptAttachment(crypto.NewPlainMessageFromFile(plainData, "attachment.txt", 1600000000), "")
+	if err != nil {
+		t.Fatal("Expected no error while encrypting the attachment, got:", err)
+	}
+
+	dir := t.TempDir()
+	dataPacketPath := filepath.Join(dir, "attachment.pgp")
+	decryptedPath := filepath.Join(dir, "attachment.txt")
+	if err = ioutil.WriteFile(dataPacketPath, split.GetBinaryDataPacket(), 0600); err != nil {
+		t.Fatal("Expected no error while writing the data packet, got:", err)
+	}
+	metadata, err := DecryptAttachmentToFile(split.GetBinaryKeyPacket(), dataPacketPath, decryptedPath, keyRing, 4096)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the attachment, got:", err)
+	}
+	if metadata.Filename != "attachment.txt" || !metadata.IsBinary {
+		t.Error("Unexpected attachment metadata:", metadata)
+	}
+	decrypted, err := ioutil.ReadFile(decryptedPath)
+	if err != nil {
+		t.Fatal("Expected no error while reading the output file, got:", err)
+	}
+	if !bytes.Equal(decrypted, plainData) {
+		t.Error("Decrypted attachment is not equal to the plaintext")
+	}
+
+	var writer maxWriteRecorder
+	_, err = DecryptAttachmentToWriter(split.GetBinaryKeyPacket(), bytes.NewReader(split.GetBinaryDataPacket()), keyRing, &writer, 1000)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting the attachment, got:", err)
+	}
+	if writer.maxWrite > 1000 {
+		t.Error("Expected writes of at most 1000 bytes, got:", writer.maxWrite)
+	}
+	if !bytes.Equal(writer.Bytes(), plainData) {
+		t.Error("Decrypted attachment is not equal to the plaintext")
+	}
+
+	corrupted := split.GetBinaryDataPacket()
+	corrupted[len(corrupted)-1] ^= 1
+	if err = ioutil.WriteFile(dataPacketPath, corrupted, 0600); err != nil {
+		t.Fatal("Expected no error while writing the data packet, got:", err)
+	}
+	if _, err = DecryptAttachmentToFile(split.GetBinaryKeyPacket(), dataPacketPath, decryptedPath, keyRing, 4096); err == nil {
+		t.Fatal("Expected an error while decrypting a corrupted attachment, got nil")
+	}
+	if _, err = os.Stat(decryptedPath); !os.IsNotExist(err) {
+		t.Error("Expected the output file to be removed, got:", err)
+	}
+}
@@ -0,0 +1,160 @@
+package helper
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// keyInfoJson is the JSON structure returned by GetJsonKeyInfo.
+type keyInfoJson struct {
+	Fingerprint    string           `json:"fingerprint"`
+	KeyID          string           `json:"keyId"`
+	Algorithm      string           `json:"algorithm"`
+	Bits           int              `json:"bits,omitempty"`
+	CreationTime   int64            `json:"creationTime"`
+	ExpirationTime int64            `json:"expirationTime,omitempty"`
+	IsPrivate      bool             `json:"isPrivate"`
+	IsExpired      bool             `json:"isExpired"`
+	IsRevoked      bool             `json:"isRevoked"`
+	UserIDs        []userIDInfoJson `json:"userIds"`
+	Subkeys        []subkeyInfoJson `json:"subkeys"`
+}
+
+type userIDInfoJson struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Comment   string `json:"comment,omitempty"`
+	IsPrimary bool   `json:"isPrimary"`
+	IsRevoked bool   `json:"isRevoked"`
+}
+
+type subkeyInfoJson struct {
+	Fingerprint    string `json:"fingerprint"`
+	KeyID          string `json:"keyId"`
+	Algorithm      string `json:"algorithm"`
+	Bits           int    `json:"bits,omitempty"`
+	CreationTime   int64  `json:"creationTime"`
+	ExpirationTime int64  `json:"expirationTime,omitempty"`
+	IsExpired      bool   `json:"isExpired"`
+	IsRevoked      bool   `json:"isRevoked"`
+	CanEncrypt     bool   `json:"canEncrypt"`
+	CanSign        bool   `json:"canSign"`
+}
+
+// GetJsonKeyInfo returns a summary of the armored key encoded in JSON, since gomobile can not handle arrays:
+// the fingerprint, key ID, algorithm, creation and expiration times, and the expiration and revocation status
+// of the primary key, its user IDs and its subkeys.
+// Times are unix timestamps, and the expiration time is omitted for keys that do not expire.
+// The status is computed at the current time, as returned by crypto.GetUnixTime.
+func GetJsonKeyInfo(armoredKey string) ([]byte, error) {
+	key, err := crypto.NewKeyFromArmored(armoredKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to parse key")
+	}
+
+	now := time.Unix(crypto.GetUnixTime(), 0)
+	entity := key.GetEntity()
+	primaryKey := entity.PrimaryKey
+	primaryIdentity := entity.PrimaryIdentity()
+
+	info := keyInfoJson{
+		Fingerprint:  hex.EncodeToString(primaryKey.Fingerprint),
+		KeyID:        fmt.Sprintf("%016x", primaryKey.KeyId),
+		Algorithm:    publicKeyAlgorithmName(primaryKey.PubKeyAlgo),
+		Bits:         publicKeyBits(primaryKey),
+		CreationTime: primaryKey.CreationTime.Unix(),
+		IsPrivate:    key.IsPrivate(),
+		IsExpired:    key.IsExpired(),
+		IsRevoked:    key.IsRevoked(),
+		UserIDs:      []userIDInfoJson{},
+		Subkeys:      []subkeyInfoJson{},
+	}
+	if primaryIdentity != nil {
+		info.ExpirationTime = keyExpirationTime(primaryKey, primaryIdentity.SelfSignature)
+	}
+
+	for _, identity := range entity.Identities {
+		info.UserIDs = append(info.UserIDs, userIDInfoJson{
+			ID:        identity.UserId.Id,
+			Name:      identity.UserId.Name,
+			Email:     identity.UserId.Email,
+			Comment:   identity.UserId.Comment,
+			IsPrimary: identity == primaryIdentity,
+			IsRevoked: identity.Revoked(now),
+		})
+	}
+	// Identities are stored in a map: sort them for a stable output
+	sort.Slice(info.UserIDs, func(i, j int) bool {
+		if info.UserIDs[i].IsPrimary != info.UserIDs[j].IsPrimary {
+			return info.UserIDs[i].IsPrimary
+		}
+		return info.UserIDs[i].ID < info.UserIDs[j].ID
+	})
+
+	for _, subkey := range entity.Subkeys {
+		info.Subkeys = append(info.Subkeys, subkeyInfoJson{
+			Fingerprint:    hex.EncodeToString(subkey.PublicKey.Fingerprint),
+			KeyID:          fmt.Sprintf("%016x", subkey.PublicKey.KeyId),
+			Algorithm:      publicKeyAlgorithmName(subkey.PublicKey.PubKeyAlgo),
+			Bits:           publicKeyBits(subkey.PublicKey),
+			CreationTime:   subkey.PublicKey.CreationTime.Unix(),
+			ExpirationTime: keyExpirationTime(subkey.PublicKey, subkey.Sig),
+			IsExpired:      subkey.PublicKey.KeyExpired(subkey.Sig, now) || subkey.Sig.SigExpired(now),
+			IsRevoked:      subkey.Revoked(now),
+			CanEncrypt:     subkey.Sig.FlagsValid && (subkey.Sig.FlagEncryptCommunications || subkey.Sig.FlagEncryptStorage),
+			CanSign:        subkey.Sig.FlagsValid && subkey.Sig.FlagSign,
+		})
+	}
+
+	return json.Marshal(info)
+}
+
+// keyExpirationTime returns the expiration time of the key as unix timestamp,
+// or 0 if the key does not expire.
+func keyExpirationTime(publicKey *packet.PublicKey, selfSignature *packet.Signature) int64 {
+	if selfSignature == nil || selfSignature.KeyLifetimeSecs == nil || *selfSignature.KeyLifetimeSecs == 0 {
+		return 0
+	}
+	return publicKey.CreationTime.Add(time.Duration(*selfSignature.KeyLifetimeSecs) * time.Second).Unix()
+}
+
+// publicKeyBits returns the size of the key in bits for RSA, DSA and ElGamal keys,
+// and 0 for elliptic curve keys, whose size depends on the curve.
+func publicKeyBits(publicKey *packet.PublicKey) int {
+	switch publicKey.PubKeyAlgo {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSAEncryptOnly, packet.PubKeyAlgoRSASignOnly,
+		packet.PubKeyAlgoDSA, packet.PubKeyAlgoElGamal:
+		bits, err := publicKey.BitLength()
+		if err != nil {
+			return 0
+		}
+		return int(bits)
+	}
+	return 0
+}
+
+func publicKeyAlgorithmName(algo packet.PublicKeyAlgorithm) string {
+	switch algo {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSAEncryptOnly, packet.PubKeyAlgoRSASignOnly:
+		return "rsa"
+	case packet.PubKeyAlgoElGamal:
+		return "elgamal"
+	case packet.PubKeyAlgoDSA:
+		return "dsa"
+	case packet.PubKeyAlgoECDH:
+		return "ecdh"
+	case packet.PubKeyAlgoECDSA:
+		return "ecdsa"
+	case packet.PubKeyAlgoEdDSA:
+		return "eddsa"
+	}
+	return fmt.Sprintf("unknown(%d)", algo)
+}
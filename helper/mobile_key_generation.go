@@ -0,0 +1,119 @@
+package helper
+
+import (
+	"sync"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// keyGenerationSteps is the number of steps reported by GenerateKeyWithCancellation:
+// the generation of the key, its encryption with the passphrase, and its armoring.
+const keyGenerationSteps = 3
+
+// KeyGenerationCancellation is a token to cancel a key generation started with GenerateKeyWithCancellation,
+// e.g. when the user leaves the screen waiting for it.
+type KeyGenerationCancellation struct {
+	once sync.Once
+	done chan struct{}
+}
+
+// NewKeyGenerationCancellation creates a new KeyGenerationCancellation, not canceled yet.
+func NewKeyGenerationCancellation() *KeyGenerationCancellation {
+	return &KeyGenerationCancellation{done: make(chan struct{})}
+}
+
+// Cancel cancels the key generation. It can be called several times, from any thread.
+func (cancellation *KeyGenerationCancellation) Cancel() {
+	cancellation.once.Do(func() { close(cancellation.done) })
+}
+
+// IsCanceled returns true if the key generation has been canceled.
+func (cancellation *KeyGenerationCancellation) IsCanceled() bool {
+	select {
+	case <-cancellation.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// GenerateKeyWithCancellation generates a key of the given keyType ("rsa" or "x25519"),
+// encrypts it, and returns an armored string, as GenerateKey.
+// The generation can be canceled with the cancellation token, in which case ErrCanceled
+// is returned at once, while the key being generated is discarded in the background.
+// The listener, if not nil, is notified after each of the coarse generation steps,
+// with the number of steps done out of the total.
+func GenerateKeyWithCancellation(
+	name, email string,
+	passphrase []byte,
+	keyType string,
+	bits int,
+	cancellation *KeyGenerationCancellation,
+	listener ProgressListener,
+) (string, error) {
+	if cancellation == nil {
+		cancellation = NewKeyGenerationCancellation()
+	}
+	step := int64(0)
+	nextStep := func() error {
+		if cancellation.IsCanceled() {
+			return ErrCanceled
+		}
+		step++
+		if listener != nil {
+			listener.OnProgress(step, keyGenerationSteps)
+		}
+		return nil
+	}
+	if cancellation.IsCanceled() {
+		return "", ErrCanceled
+	}
+
+	type generationResult struct {
+		key *crypto.Key
+		err error
+	}
+	generated := make(chan generationResult, 1)
+	go func() {
+		key, err := crypto.GenerateKey(name, email, keyType, bits)
+		generated <- generationResult{key, err}
+	}()
+
+	var key *crypto.Key
+	select {
+	case <-cancellation.done:
+		go func() {
+			if result := <-generated; result.key != nil {
+				result.key.ClearPrivateParams()
+			}
+		}()
+		return "", ErrCanceled
+	case result := <-generated:
+		if result.err != nil {
+			return "", errors.Wrap(result.err, "gopenpgp: unable to generate new key")
+		}
+		key = result.key
+	}
+	defer key.ClearPrivateParams()
+	if err := nextStep(); err != nil {
+		return "", err
+	}
+
+	locked, err := key.Lock(passphrase)
+	if err != nil {
+		return "", errors.Wrap(err, "gopenpgp: unable to lock new key")
+	}
+	if err := nextStep(); err != nil {
+		return "", err
+	}
+
+	armored, err := locked.Armor()
+	if err != nil {
+		return "", err
+	}
+	if err := nextStep(); err != nil {
+		return "", err
+	}
+	return armored, nil
+}
@@ -0,0 +1,40 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKeyWithCancellation(t *testing.T) {
+	passphrase := []byte("passphrase")
+	listener := &testProgressListener{}
+	armored, err := GenerateKeyWithCancellation(
+		"name", "name@protonmail.ch", passphrase, "x25519", 0,
+		NewKeyGenerationCancellation(), listener,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	assert.Exactly(t, 3, listener.calls)
+	assert.Exactly(t, int64(3), listener.current)
+	assert.Exactly(t, int64(3), listener.total)
+
+	key, err := crypto.NewKeyFromArmored(armored)
+	if err != nil {
+		t.Fatal("Expected no error while parsing key, got:", err)
+	}
+	_, err = key.Unlock(passphrase)
+	assert.NoError(t, err)
+
+	cancellation := NewKeyGenerationCancellation()
+	cancellation.Cancel()
+	cancellation.Cancel()
+	assert.True(t, cancellation.IsCanceled())
+	listener = &testProgressListener{}
+	_, err = GenerateKeyWithCancellation("name", "name@protonmail.ch", passphrase, "rsa", 2048, cancellation, listener)
+	assert.Exactly(t, constants.ERROR_CANCELED, GetErrorCode(err))
+	assert.Exactly(t, 0, listener.calls)
+}
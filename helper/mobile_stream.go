@@ -65,6 +65,163 @@ func (w *Mobile2GoWriterWithSHA256) GetSHA256() []byte {
 	return w.sha256.Sum(nil)
 }
 
+// Go2MobileWriter is used to wrap a native golang WriteCloser in the golang runtime,
+// e.g. the plaintext writer of a streaming encryption,
+// to be usable in the mobile app runtime (via gomobile).
+type Go2MobileWriter struct {
+	writer crypto.WriteCloser
+}
+
+// NewGo2MobileWriter wraps a native golang WriteCloser to be usable in the mobile app runtime (via gomobile).
+func NewGo2MobileWriter(writer crypto.WriteCloser) *Go2MobileWriter {
+	return &Go2MobileWriter{writer}
+}
+
+// Write writes all the data in the provided buffer in the wrapped writer.
+// It clones the provided data to prevent errors with garbage collectors.
+func (w *Go2MobileWriter) Write(b []byte) (n int, err error) {
+	bufferCopy := clone(b)
+	for n < len(bufferCopy) {
+		written, err := w.writer.Write(bufferCopy[n:])
+		if err != nil {
+			return n, err
+		}
+		n += written
+	}
+	return n, nil
+}
+
+// Close closes the wrapped writer, e.g. to finish the encryption.
+func (w *Go2MobileWriter) Close() error {
+	return w.writer.Close()
+}
+
+// EncryptStreamMobile encrypts the data written to the returned Go2MobileWriter
+// with the public keys of publicKeyRing, and signs it if signKeyRing is not nil.
+// The encrypted message is written to ciphertextWriter as it is produced, so that
+// data larger than the available memory can be encrypted.
+// The returned writer needs to be closed to finish the encryption.
+// plainMessageMetadata (optional) contains the literal metadata of the data.
+func EncryptStreamMobile(
+	publicKeyRing, signKeyRing *crypto.KeyRing,
+	ciphertextWriter crypto.Writer,
+	plainMessageMetadata *crypto.PlainMessageMetadata,
+) (*Go2MobileWriter, error) {
+	plaintextWriter, err := publicKeyRing.EncryptStream(
+		NewMobile2GoWriter(ciphertextWriter),
+		plainMessageMetadata,
+		signKeyRing,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to encrypt stream")
+	}
+	return NewGo2MobileWriter(plaintextWriter), nil
+}
+
+// DecryptStreamMobile decrypts the message read from ciphertextReader with the private keys of privateKeyRing.
+// The plaintext is decrypted as it is read from the returned reader, so that data larger than the available
+// memory can be decrypted. It can be wrapped with NewGo2AndroidReader or NewGo2IOSReader.
+// If verifyKeyRing is not nil, the embedded signature can be verified with VerifySignatureExplicit
+// once the reader has been read entirely.
+func DecryptStreamMobile(
+	privateKeyRing, verifyKeyRing *crypto.KeyRing,
+	ciphertextReader MobileReader,
+	verifyTime int64,
+) (*crypto.PlainMessageReader, error) {
+	plaintextReader, err := privateKeyRing.DecryptStream(NewMobile2GoReader(ciphertextReader), verifyKeyRing, verifyTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt stream")
+	}
+	return plaintextReader, nil
+}
+
+// ProgressListener is the interface that progress listeners in the mobile runtime must implement,
+// to be notified of the progress of the streaming functions.
+type ProgressListener interface {
+	// OnProgress is called with the number of bytes processed so far,
+	// and the total number of bytes as given by the caller, or 0 if unknown.
+	OnProgress(current, total int64)
+}
+
+// progressReader notifies a ProgressListener of the bytes read from the wrapped reader.
+type progressReader struct {
+	reader   crypto.Reader
+	listener ProgressListener
+	current  int64
+	total    int64
+}
+
+func (r *progressReader) Read(b []byte) (n int, err error) {
+	n, err = r.reader.Read(b)
+	if n > 0 {
+		r.current += int64(n)
+		r.listener.OnProgress(r.current, r.total)
+	}
+	return n, err
+}
+
+// progressWriteCloser notifies a ProgressListener of the bytes written to the wrapped writer.
+type progressWriteCloser struct {
+	writer   crypto.WriteCloser
+	listener ProgressListener
+	current  int64
+	total    int64
+}
+
+func (w *progressWriteCloser) Write(b []byte) (n int, err error) {
+	n, err = w.writer.Write(b)
+	if n > 0 {
+		w.current += int64(n)
+		w.listener.OnProgress(w.current, w.total)
+	}
+	return n, err
+}
+
+func (w *progressWriteCloser) Close() error {
+	return w.writer.Close()
+}
+
+// EncryptStreamMobileWithProgress encrypts the data written to the returned Go2MobileWriter, as EncryptStreamMobile.
+// The listener is notified of the number of plaintext bytes encrypted,
+// out of totalSize, the size of the plaintext (0 if unknown).
+func EncryptStreamMobileWithProgress(
+	publicKeyRing, signKeyRing *crypto.KeyRing,
+	ciphertextWriter crypto.Writer,
+	plainMessageMetadata *crypto.PlainMessageMetadata,
+	totalSize int64,
+	listener ProgressListener,
+) (*Go2MobileWriter, error) {
+	writer, err := EncryptStreamMobile(publicKeyRing, signKeyRing, ciphertextWriter, plainMessageMetadata)
+	if err != nil {
+		return nil, err
+	}
+	if listener != nil {
+		writer.writer = &progressWriteCloser{writer: writer.writer, listener: listener, total: totalSize}
+	}
+	return writer, nil
+}
+
+// DecryptStreamMobileWithProgress decrypts the message read from ciphertextReader, as DecryptStreamMobile.
+// The listener is notified of the number of ciphertext bytes read,
+// out of totalSize, the size of the ciphertext (0 if unknown).
+func DecryptStreamMobileWithProgress(
+	privateKeyRing, verifyKeyRing *crypto.KeyRing,
+	ciphertextReader MobileReader,
+	verifyTime int64,
+	totalSize int64,
+	listener ProgressListener,
+) (*crypto.PlainMessageReader, error) {
+	var reader crypto.Reader = NewMobile2GoReader(ciphertextReader)
+	if listener != nil {
+		reader = &progressReader{reader: reader, listener: listener, total: totalSize}
+	}
+	plaintextReader, err := privateKeyRing.DecryptStream(reader, verifyKeyRing, verifyTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt stream")
+	}
+	return plaintextReader, nil
+}
+
 // MobileReader is the interface that readers in the mobile runtime must use and implement.
 // This is a workaround to some of the gomobile limitations.
 type MobileReader interface {
@@ -344,3 +344,123 @@ func TestExplicitVerifyWrongVerifier(t *testing.T) {
 		t.Fatalf("Got an error while verifying embedded sig: %v", err)
 	}
 }
+
+func TestMobileEncryptDecryptStream(t *testing.T) {
+	pubKR, privKR, err := setUpTestKeyRing()
+	if err != nil {
+		t.Fatalf("Got an error while loading test key: %v", err)
+	}
+	defer privKR.ClearPrivateParams()
+	testData := bytes.Repeat([]byte("Hello World!"), 10000)
+
+	ciphertext := &bytes.Buffer{}
+	writer, err := EncryptStreamMobile(pubKR, privKR, ciphertext, crypto.NewPlainMessageMetadata(true, "hello.txt", 0))
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	for i := 0; i < len(testData); i += 1000 {
+		if _, err = writer.Write(testData[i : i+1000]); err != nil {
+			t.Fatal("Expected no error while writing, got:", err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal("Expected no error while closing, got:", err)
+	}
+
+	reader, err := DecryptStreamMobile(
+		privKR,
+		pubKR,
+		&testMobileReader{bytes.NewReader(ciphertext.Bytes()), false},
+		crypto.GetUnixTime(),
+	)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	iosReader := NewGo2IOSReader(reader)
+	var readData []byte
+	for reachedEnd := false; !reachedEnd; {
+		res, err := iosReader.Read(1000)
+		if err != nil {
+			t.Fatal("Expected no error while reading, got:", err)
+		}
+		reachedEnd = res.IsEOF
+		readData = append(readData, res.Data[:res.N]...)
+	}
+	if !bytes.Equal(testData, readData) {
+		t.Fatal("Expected the decrypted data to match the encrypted data")
+	}
+	if reader.GetMetadata().Filename != "hello.txt" {
+		t.Fatal("Expected the filename to be preserved")
+	}
+	sigErr, err := VerifySignatureExplicit(reader)
+	if sigErr != nil || err != nil {
+		t.Fatalf("Got an error while verifying embedded sig: %v %v", sigErr, err)
+	}
+}
+
+type testProgressListener struct {
+	current, total int64
+	calls          int
+}
+
+func (l *testProgressListener) OnProgress(current, total int64) {
+	l.current = current
+	l.total = total
+	l.calls++
+}
+
+func TestMobileEncryptDecryptStreamWithProgress(t *testing.T) {
+	pubKR, privKR, err := setUpTestKeyRing()
+	if err != nil {
+		t.Fatalf("Got an error while loading test key: %v", err)
+	}
+	defer privKR.ClearPrivateParams()
+	testData := bytes.Repeat([]byte("Hello World!"), 10000)
+
+	ciphertext := &bytes.Buffer{}
+	encryptListener := &testProgressListener{}
+	writer, err := EncryptStreamMobileWithProgress(
+		pubKR, nil, ciphertext, nil, int64(len(testData)), encryptListener,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	for i := 0; i < len(testData); i += 1000 {
+		if _, err = writer.Write(testData[i : i+1000]); err != nil {
+			t.Fatal("Expected no error while writing, got:", err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal("Expected no error while closing, got:", err)
+	}
+	if encryptListener.calls != len(testData)/1000 ||
+		encryptListener.current != int64(len(testData)) ||
+		encryptListener.total != int64(len(testData)) {
+		t.Fatalf("Unexpected encryption progress: %+v", encryptListener)
+	}
+
+	decryptListener := &testProgressListener{}
+	reader, err := DecryptStreamMobileWithProgress(
+		privKR,
+		nil,
+		&testMobileReader{bytes.NewReader(ciphertext.Bytes()), false},
+		crypto.GetUnixTime(),
+		int64(ciphertext.Len()),
+		decryptListener,
+	)
+	if err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	readData, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal("Expected no error while reading, got:", err)
+	}
+	if !bytes.Equal(testData, readData) {
+		t.Fatal("Expected the decrypted data to match the encrypted data")
+	}
+	if decryptListener.calls == 0 ||
+		decryptListener.current != int64(ciphertext.Len()) ||
+		decryptListener.total != int64(ciphertext.Len()) {
+		t.Fatalf("Unexpected decryption progress: %+v", decryptListener)
+	}
+}
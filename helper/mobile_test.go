@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/ProtonMail/gopenpgp/v2/constants"
@@ -113,3 +114,160 @@ func TestGetJsonSHA256FingerprintsV4(t *testing.T) {
 
 	assert.Exactly(t, []byte("[\"d9ac0b857da6d2c8be985b251a9e3db31e7a1d2d832d1f07ebe838a9edce9c24\",\"203dfba1f8442c17e59214d9cd11985bfc5cc8721bb4a71740dd5507e58a1a0d\"]"), sha256Fingerprints)
 }
+
+func TestJsonSessionKey(t *testing.T) {
+	sessionKey := crypto.NewSessionKeyFromToken(make([]byte, 16), constants.AES128)
+	jsonSessionKey, err := GetJsonSessionKey(sessionKey)
+	if err != nil {
+		t.Fatal("Expected no error while encoding the session key, got:", err)
+	}
+	assert.Exactly(t, []byte(`{"key":"AAAAAAAAAAAAAAAAAAAAAA==","algorithm":"aes128"}`), jsonSessionKey)
+
+	decoded, err := NewSessionKeyFromJson(jsonSessionKey)
+	if err != nil {
+		t.Fatal("Expected no error while decoding the session key, got:", err)
+	}
+	assert.Exactly(t, sessionKey.Key, decoded.Key)
+	assert.Exactly(t, sessionKey.Algo, decoded.Algo)
+
+	decoded, err = NewSessionKeyFromJson([]byte(`{"key":"AAAAAAAAAAAAAAAAAAAAAA=="}`))
+	if err != nil {
+		t.Fatal("Expected no error while decoding the session key, got:", err)
+	}
+	assert.Exactly(t, "", decoded.Algo)
+
+	for _, invalid := range []string{
+		`{"key":"AAAAAAAAAAAAAAAAAAAAAA==","algorithm":"aes256"}`,
+		`{"key":"AAAAAAAAAAAAAAAAAAAAAA==","algorithm":"unknown"}`,
+		`{"key":"AAAA"}`,
+		`not json`,
+	} {
+		if _, err = NewSessionKeyFromJson([]byte(invalid)); err == nil {
+			t.Fatal("Expected an error while decoding an invalid session key, got nil:", invalid)
+		}
+	}
+}
+
+func TestMobileDecryptExplicitVerifyDetails(t *testing.T) {
+	privateKey, _ := crypto.NewKeyFromArmored(readTestFile("keyring_privateKey", false))
+	// Password defined in base_test
+	privateKey, err := privateKey.Unlock(testMailboxPassword)
+	if err != nil {
+		t.Fatal("Expected no error unlocking privateKey, got:", err)
+	}
+	testPrivateKeyRing, _ := crypto.NewKeyRing(privateKey)
+	publicKey, _ := crypto.NewKeyFromArmored(readTestFile("keyring_publicKey", false))
+	testPublicKeyRing, _ := crypto.NewKeyRing(publicKey)
+
+	message := crypto.NewPlainMessageFromFile([]byte("hello"), "hello.txt", uint32(crypto.GetUnixTime()))
+	pgpMessage, err := testPublicKeyRing.Encrypt(message, testPrivateKeyRing)
+	if err != nil {
+		t.Fatal("Expected no error when encrypting, got:", err)
+	}
+
+	result, err := DecryptExplicitVerifyDetails(pgpMessage, testPrivateKeyRing, testPublicKeyRing, crypto.GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error when decrypting, got:", err)
+	}
+	assert.Nil(t, result.SignatureVerificationError)
+	assert.Exactly(t, message.GetBinary(), result.Message.GetBinary())
+	assert.Exactly(t, "hello.txt", result.Message.GetFilename())
+	assert.Exactly(t, message.Time, result.Message.Time)
+	assert.Exactly(t, 1, result.GetSignatureCount())
+	signature, err := result.GetSignature(0)
+	if err != nil {
+		t.Fatal("Expected no error when getting the signature, got:", err)
+	}
+	assert.Exactly(t, constants.SIGNATURE_OK, signature.Status)
+	assert.Exactly(t, privateKey.GetHexKeyID(), signature.SignerKeyID)
+	assert.Exactly(t, privateKey.GetFingerprint(), signature.SignerFingerprint)
+	assert.Exactly(t, constants.SHA256, signature.HashAlgorithm)
+	assert.NotZero(t, signature.CreationTime)
+	if _, err = result.GetSignature(1); err == nil {
+		t.Fatal("Expected an error when getting a missing signature, got nil")
+	}
+
+	pgpMessage, err = testPublicKeyRing.Encrypt(message, nil)
+	if err != nil {
+		t.Fatal("Expected no error when encrypting, got:", err)
+	}
+	result, err = DecryptExplicitVerifyDetails(pgpMessage, testPrivateKeyRing, testPublicKeyRing, crypto.GetUnixTime())
+	if err != nil {
+		t.Fatal("Expected no error when decrypting, got:", err)
+	}
+	assert.Exactly(t, constants.SIGNATURE_NOT_SIGNED, result.SignatureVerificationError.Status)
+	assert.Exactly(t, 0, result.GetSignatureCount())
+}
+
+func TestGetJsonKeyInfo(t *testing.T) {
+	armored := readTestFile("keyring_publicKey", false)
+	key, err := crypto.NewKeyFromArmored(armored)
+	if err != nil {
+		t.Fatal("Cannot unarmor key:", err)
+	}
+
+	jsonKeyInfo, err := GetJsonKeyInfo(armored)
+	if err != nil {
+		t.Fatal("Expected no error while getting the key info, got:", err)
+	}
+
+	var info keyInfoJson
+	if err = json.Unmarshal(jsonKeyInfo, &info); err != nil {
+		t.Fatal("Expected no error while decoding the key info, got:", err)
+	}
+	assert.Exactly(t, key.GetFingerprint(), info.Fingerprint)
+	assert.Exactly(t, key.GetHexKeyID(), info.KeyID)
+	assert.Exactly(t, key.GetEntity().PrimaryKey.CreationTime.Unix(), info.CreationTime)
+	assert.False(t, info.IsPrivate)
+	assert.False(t, info.IsRevoked)
+	assert.Len(t, info.UserIDs, 1)
+	assert.True(t, info.UserIDs[0].IsPrimary)
+	assert.Len(t, info.Subkeys, 1)
+	assert.True(t, info.Subkeys[0].CanEncrypt)
+	assert.False(t, info.Subkeys[0].IsRevoked)
+}
+
+func TestAddMessageRecipient(t *testing.T) {
+	privateKey, _ := crypto.NewKeyFromArmored(readTestFile("keyring_privateKey", false))
+	privateKey, err := privateKey.Unlock(testMailboxPassword)
+	if err != nil {
+		t.Fatal("Expected no error unlocking privateKey, got:", err)
+	}
+	privateKeyRing, _ := crypto.NewKeyRing(privateKey)
+
+	newDeviceKey, err := crypto.GenerateKey("device", "device@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error generating key, got:", err)
+	}
+	newDeviceKeyRing, _ := crypto.NewKeyRing(newDeviceKey)
+	newDevicePublicKey, _ := newDeviceKey.ToPublic()
+	newDevicePublicKeyRing, _ := crypto.NewKeyRing(newDevicePublicKey)
+
+	plainMessage := crypto.NewPlainMessageFromString("shared with a new device")
+	pgpMessage, err := privateKeyRing.Encrypt(plainMessage, nil)
+	if err != nil {
+		t.Fatal("Expected no error when encrypting, got:", err)
+	}
+	_, err = newDeviceKeyRing.Decrypt(pgpMessage, nil, 0)
+	assert.Error(t, err)
+
+	sharedMessage, err := AddMessageRecipient(pgpMessage, privateKeyRing, newDevicePublicKeyRing)
+	if err != nil {
+		t.Fatal("Expected no error when adding a recipient, got:", err)
+	}
+
+	for _, keyRing := range []*crypto.KeyRing{privateKeyRing, newDeviceKeyRing} {
+		decrypted, err := keyRing.Decrypt(sharedMessage, nil, 0)
+		if err != nil {
+			t.Fatal("Expected no error when decrypting, got:", err)
+		}
+		assert.Exactly(t, plainMessage.GetString(), decrypted.GetString())
+	}
+
+	keyIDs, ok := sharedMessage.GetEncryptionKeyIDs()
+	assert.True(t, ok)
+	assert.Len(t, keyIDs, 2)
+
+	_, err = AddMessageRecipient(pgpMessage, newDeviceKeyRing, newDevicePublicKeyRing)
+	assert.Error(t, err)
+}
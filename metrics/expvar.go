@@ -0,0 +1,69 @@
+// Package metrics provides a ready-made crypto.MetricsCollector publishing the operation
+// metrics of the library with expvar, from which they can also be scraped by Prometheus exporters.
+package metrics
+
+import (
+	"expvar"
+	"strconv"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+)
+
+// verificationFailureReasons are the names of the verification failure reasons.
+var verificationFailureReasons = map[int]string{
+	constants.SIGNATURE_NOT_SIGNED:  "not_signed",
+	constants.SIGNATURE_NO_VERIFIER: "no_verifier",
+	constants.SIGNATURE_FAILED:      "failed",
+	constants.SIGNATURE_BAD_CONTEXT: "bad_context",
+}
+
+// ExpvarCollector is a crypto.MetricsCollector counting the operations in expvar maps.
+type ExpvarCollector struct {
+	// Operations counts the completed operations, by operation name.
+	Operations *expvar.Map
+	// Failures counts the failed operations, by operation name.
+	Failures *expvar.Map
+	// Bytes counts the plaintext bytes processed, by operation name.
+	Bytes *expvar.Map
+	// VerificationFailures counts the failed signature verifications, by reason,
+	// e.g. "failed" for bad signatures or "no_verifier" for signatures by unknown keys.
+	VerificationFailures *expvar.Map
+}
+
+// NewExpvarCollector creates an ExpvarCollector, and publishes its maps as an expvar map with the given name.
+// As expvar.Publish, it panics if the name is already used.
+// The collector is enabled with crypto.SetMetricsCollector.
+func NewExpvarCollector(name string) *ExpvarCollector {
+	collector := &ExpvarCollector{
+		Operations:           new(expvar.Map).Init(),
+		Failures:             new(expvar.Map).Init(),
+		Bytes:                new(expvar.Map).Init(),
+		VerificationFailures: new(expvar.Map).Init(),
+	}
+	published := expvar.NewMap(name)
+	published.Set("operations", collector.Operations)
+	published.Set("failures", collector.Failures)
+	published.Set("bytes", collector.Bytes)
+	published.Set("verification_failures", collector.VerificationFailures)
+	return collector
+}
+
+// OnOperation counts a completed operation.
+func (collector *ExpvarCollector) OnOperation(operation string, bytes int64, success bool) {
+	collector.Operations.Add(operation, 1)
+	if !success {
+		collector.Failures.Add(operation, 1)
+	}
+	if bytes > 0 {
+		collector.Bytes.Add(operation, bytes)
+	}
+}
+
+// OnVerificationFailure counts a failed signature verification.
+func (collector *ExpvarCollector) OnVerificationFailure(status int) {
+	reason, ok := verificationFailureReasons[status]
+	if !ok {
+		reason = strconv.Itoa(status)
+	}
+	collector.VerificationFailures.Add(reason, 1)
+}
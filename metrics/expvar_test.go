@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpvarCollector(t *testing.T) {
+	key, err := crypto.GenerateKey("name", "name@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	keyRing, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	other, err := crypto.GenerateKey("other", "other@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	otherKeyRing, err := crypto.NewKeyRing(other)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+
+	collector := NewExpvarCollector("gopenpgp_test")
+	crypto.SetMetricsCollector(collector)
+	defer crypto.SetMetricsCollector(nil)
+
+	message := crypto.NewPlainMessageFromString("metrics")
+	ciphertext, err := keyRing.Encrypt(message, keyRing)
+	if err != nil {
+		t.Fatal("Expected no error while encrypting, got:", err)
+	}
+	if _, err = keyRing.Decrypt(ciphertext, keyRing, crypto.GetUnixTime()); err != nil {
+		t.Fatal("Expected no error while decrypting, got:", err)
+	}
+	signature, err := keyRing.SignDetached(message)
+	if err != nil {
+		t.Fatal("Expected no error while signing, got:", err)
+	}
+	assert.Error(t, otherKeyRing.VerifyDetached(message, signature, crypto.GetUnixTime()))
+
+	assert.Exactly(t, "1", collector.Operations.Get(constants.OperationEncrypt).String())
+	assert.Exactly(t, "7", collector.Bytes.Get(constants.OperationEncrypt).String())
+	assert.Exactly(t, "1", collector.Operations.Get(constants.OperationDecrypt).String())
+	assert.Exactly(t, "7", collector.Bytes.Get(constants.OperationDecrypt).String())
+	assert.Exactly(t, "1", collector.Operations.Get(constants.OperationSign).String())
+	assert.Exactly(t, "2", collector.Operations.Get(constants.OperationVerify).String())
+	assert.Exactly(t, "1", collector.Failures.Get(constants.OperationVerify).String())
+	assert.Exactly(t, "1", collector.VerificationFailures.Get("failed").String())
+	assert.NotNil(t, expvar.Get("gopenpgp_test"))
+}
@@ -0,0 +1,93 @@
+package pgphttp
+
+import (
+	"net/http"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// NewHandler returns a middleware decrypting the encrypted request bodies before calling next,
+// and encrypting the response bodies written by next if the config has an encryption keyring.
+// Requests whose body can't be decrypted are rejected with http.StatusBadRequest,
+// and unencrypted request bodies with http.StatusUnsupportedMediaType
+// if the config has a decryption or verification keyring, unless config.AllowUnencrypted is set.
+func NewHandler(next http.Handler, config *Config) http.Handler {
+	return &handler{next: next, config: *config}
+}
+
+type handler struct {
+	next   http.Handler
+	config Config
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if isEncrypted(r.Header) && r.ContentLength != 0 {
+		body, err := h.config.decryptBody(r.Body)
+		if err != nil {
+			http.Error(w, "unable to decrypt the request body", http.StatusBadRequest)
+			return
+		}
+		r = r.Clone(r.Context())
+		r.Body = body
+		r.ContentLength = -1
+		setDecryptedHeaders(r.Header)
+	} else if r.ContentLength != 0 && r.Body != http.NoBody && h.config.rejectsUnencrypted() {
+		http.Error(w, "the request body must be encrypted", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if h.config.EncryptionKeyRing == nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	ew := &encryptedResponseWriter{ResponseWriter: w, config: &h.config}
+	h.next.ServeHTTP(ew, r)
+	if err := ew.close(); err != nil {
+		// The headers and part of the body are already sent: abort the response
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// encryptedResponseWriter encrypts the response body written to it.
+type encryptedResponseWriter struct {
+	http.ResponseWriter
+	config          *Config
+	plaintextWriter crypto.WriteCloser
+	wroteHeader     bool
+}
+
+func (w *encryptedResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	setEncryptedHeaders(w.Header())
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *encryptedResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.plaintextWriter == nil {
+		var err error
+		w.plaintextWriter, err = w.config.EncryptionKeyRing.EncryptStream(w.ResponseWriter, nil, w.config.SigningKeyRing)
+		if err != nil {
+			return 0, errors.Wrap(err, "gopenpgp: unable to encrypt body")
+		}
+	}
+	return w.plaintextWriter.Write(b)
+}
+
+// close finishes the encryption of the body. Responses without body are left empty.
+func (w *encryptedResponseWriter) close() error {
+	if w.plaintextWriter == nil {
+		return nil
+	}
+	if err := w.plaintextWriter.Close(); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to encrypt body")
+	}
+	return nil
+}
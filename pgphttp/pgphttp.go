@@ -0,0 +1,117 @@
+// Package pgphttp provides net/http integration to exchange end-to-end encrypted
+// request and response bodies: a RoundTripper for clients and a middleware for servers.
+package pgphttp
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// ContentType is the content type of the encrypted bodies.
+const ContentType = "application/pgp-encrypted"
+
+// OriginalContentTypeHeader is the header carrying the content type of the plaintext body.
+const OriginalContentTypeHeader = "X-Pgp-Content-Type"
+
+// Config holds the keyrings used to encrypt, decrypt, sign and verify the bodies.
+// * EncryptionKeyRing: the public keys of the peer, the outgoing bodies are not encrypted if nil.
+// * DecryptionKeyRing: the unlocked private keys decrypting the incoming encrypted bodies.
+// * SigningKeyRing: the unlocked private keys signing the outgoing bodies, optional.
+// * VerificationKeyRing: the public keys of the peer verifying the incoming bodies, optional.
+// If set, reading an incoming body returns an error at the end of the data if the signature is invalid.
+// * AllowUnencrypted: accept the unencrypted incoming bodies, which are otherwise rejected
+// if DecryptionKeyRing or VerificationKeyRing is set. They are passed through unverified.
+type Config struct {
+	EncryptionKeyRing   *crypto.KeyRing
+	DecryptionKeyRing   *crypto.KeyRing
+	SigningKeyRing      *crypto.KeyRing
+	VerificationKeyRing *crypto.KeyRing
+	AllowUnencrypted    bool
+}
+
+// rejectsUnencrypted returns true if the unencrypted incoming bodies must be rejected.
+func (config *Config) rejectsUnencrypted() bool {
+	return (config.DecryptionKeyRing != nil || config.VerificationKeyRing != nil) && !config.AllowUnencrypted
+}
+
+// isEncrypted returns true if the headers describe an encrypted body.
+func isEncrypted(header http.Header) bool {
+	return header.Get("Content-Type") == ContentType
+}
+
+// setEncryptedHeaders replaces the content type with ContentType, keeping the original one
+// in OriginalContentTypeHeader, and removes the content length which is unknown.
+func setEncryptedHeaders(header http.Header) {
+	if contentType := header.Get("Content-Type"); contentType != "" {
+		header.Set(OriginalContentTypeHeader, contentType)
+	}
+	header.Set("Content-Type", ContentType)
+	header.Del("Content-Length")
+}
+
+// setDecryptedHeaders restores the content type of the plaintext body.
+func setDecryptedHeaders(header http.Header) {
+	header.Del("Content-Type")
+	if contentType := header.Get(OriginalContentTypeHeader); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	header.Del(OriginalContentTypeHeader)
+	header.Del("Content-Length")
+}
+
+// encryptBody returns a reader for the encrypted body, encrypted on the fly in a goroutine.
+func (config *Config) encryptBody(body io.ReadCloser) io.ReadCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		defer func() { _ = body.Close() }()
+		plaintextWriter, err := config.EncryptionKeyRing.EncryptStream(writer, nil, config.SigningKeyRing)
+		if err == nil {
+			_, err = io.Copy(plaintextWriter, body)
+		}
+		if err == nil {
+			err = plaintextWriter.Close()
+		}
+		_ = writer.CloseWithError(errors.Wrap(err, "gopenpgp: unable to encrypt body"))
+	}()
+	return reader
+}
+
+// decryptBody returns a reader for the decrypted body.
+func (config *Config) decryptBody(body io.ReadCloser) (io.ReadCloser, error) {
+	if config.DecryptionKeyRing == nil {
+		return nil, errors.New("gopenpgp: no decryption keyring to decrypt the body")
+	}
+	plainMessageReader, err := config.DecryptionKeyRing.DecryptStream(body, config.VerificationKeyRing, crypto.GetUnixTime())
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to decrypt body")
+	}
+	return &decryptedBody{
+		reader: plainMessageReader,
+		body:   body,
+		verify: config.VerificationKeyRing != nil,
+	}, nil
+}
+
+// decryptedBody reads the decrypted body, and verifies the signature at the end of the data.
+type decryptedBody struct {
+	reader *crypto.PlainMessageReader
+	body   io.Closer
+	verify bool
+}
+
+func (b *decryptedBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	if errors.Is(err, io.EOF) && b.verify {
+		if verifyErr := b.reader.VerifySignature(); verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+	return n, err
+}
+
+func (b *decryptedBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,179 @@
+package pgphttp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKeyRings(t *testing.T, name string) (publicKeyRing, privateKeyRing *crypto.KeyRing) {
+	key, err := crypto.GenerateKey(name, name+"@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating the key, got:", err)
+	}
+	publicKey, err := key.ToPublic()
+	if err != nil {
+		t.Fatal("Expected no error while extracting the public key, got:", err)
+	}
+	if privateKeyRing, err = crypto.NewKeyRing(key); err != nil {
+		t.Fatal("Expected no error while building the keyring, got:", err)
+	}
+	if publicKeyRing, err = crypto.NewKeyRing(publicKey); err != nil {
+		t.Fatal("Expected no error while building the keyring, got:", err)
+	}
+	return publicKeyRing, privateKeyRing
+}
+
+func TestTransportAndHandler(t *testing.T) {
+	clientPublic, clientPrivate := generateKeyRings(t, "client")
+	serverPublic, serverPrivate := generateKeyRings(t, "server")
+	requestBody := bytes.Repeat([]byte("request "), 10000)
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		_, _ = w.Write(append([]byte("echo: "), body...))
+	})
+	server := httptest.NewServer(NewHandler(echo, &Config{
+		EncryptionKeyRing:   clientPublic,
+		DecryptionKeyRing:   serverPrivate,
+		SigningKeyRing:      serverPrivate,
+		VerificationKeyRing: clientPublic,
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(&Config{
+		EncryptionKeyRing:   serverPublic,
+		DecryptionKeyRing:   clientPrivate,
+		SigningKeyRing:      clientPrivate,
+		VerificationKeyRing: serverPublic,
+	})}
+	resp, err := client.Post(server.URL, "text/plain", bytes.NewReader(requestBody))
+	if err != nil {
+		t.Fatal("Expected no error while sending the request, got:", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal("Expected no error while reading the response, got:", err)
+	}
+	assert.Exactly(t, http.StatusOK, resp.StatusCode)
+	assert.Exactly(t, "text/plain", resp.Header.Get("Content-Type"))
+	assert.Exactly(t, append([]byte("echo: "), requestBody...), body)
+
+	// Without the transport the response is encrypted
+	resp, err = http.Get(server.URL)
+	if err != nil {
+		t.Fatal("Expected no error while sending the request, got:", err)
+	}
+	body, err = ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal("Expected no error while reading the response, got:", err)
+	}
+	assert.Exactly(t, http.StatusOK, resp.StatusCode)
+	assert.Exactly(t, ContentType, resp.Header.Get("Content-Type"))
+	assert.False(t, bytes.Contains(body, []byte("echo: ")))
+}
+
+func TestHandlerAndTransportRejectUnencrypted(t *testing.T) {
+	serverPublic, serverPrivate := generateKeyRings(t, "server")
+	requestBody := []byte("not encrypted")
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write(body)
+	})
+	config := &Config{DecryptionKeyRing: serverPrivate}
+	server := httptest.NewServer(NewHandler(echo, config))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "text/plain", bytes.NewReader(requestBody))
+	if err != nil {
+		t.Fatal("Expected no error while sending the request, got:", err)
+	}
+	_ = resp.Body.Close()
+	assert.Exactly(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+	config.AllowUnencrypted = true
+	passthroughServer := httptest.NewServer(NewHandler(echo, config))
+	defer passthroughServer.Close()
+	resp, err = http.Post(passthroughServer.URL, "text/plain", bytes.NewReader(requestBody))
+	if err != nil {
+		t.Fatal("Expected no error while sending the request, got:", err)
+	}
+	_ = resp.Body.Close()
+	assert.Exactly(t, http.StatusOK, resp.StatusCode)
+
+	// The plaintext response of the passthrough server is rejected by the client
+	client := &http.Client{Transport: NewTransport(&Config{
+		EncryptionKeyRing: serverPublic,
+		DecryptionKeyRing: serverPrivate,
+	})}
+	_, err = client.Post(passthroughServer.URL, "text/plain", bytes.NewReader(requestBody))
+	assert.Error(t, err)
+
+	client = &http.Client{Transport: NewTransport(&Config{
+		EncryptionKeyRing: serverPublic,
+		DecryptionKeyRing: serverPrivate,
+		AllowUnencrypted:  true,
+	})}
+	resp, err = client.Post(passthroughServer.URL, "text/plain", bytes.NewReader(requestBody))
+	if err != nil {
+		t.Fatal("Expected no error while sending the request, got:", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal("Expected no error while reading the response, got:", err)
+	}
+	assert.Exactly(t, requestBody, body)
+}
+
+func TestHandlerRejectsInvalidSignature(t *testing.T) {
+	_, clientPrivate := generateKeyRings(t, "client")
+	otherPublic, _ := generateKeyRings(t, "other")
+	serverPublic, serverPrivate := generateKeyRings(t, "server")
+
+	var readErr error
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = ioutil.ReadAll(r.Body)
+	})
+	server := httptest.NewServer(NewHandler(handler, &Config{
+		DecryptionKeyRing:   serverPrivate,
+		VerificationKeyRing: otherPublic,
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(&Config{
+		EncryptionKeyRing: serverPublic,
+		SigningKeyRing:    clientPrivate,
+	})}
+	resp, err := client.Post(server.URL, "text/plain", bytes.NewReader([]byte("hello")))
+	if err != nil {
+		t.Fatal("Expected no error while sending the request, got:", err)
+	}
+	_ = resp.Body.Close()
+	assert.Error(t, readErr)
+
+	resp, err = http.Post(server.URL, ContentType, bytes.NewReader([]byte("not encrypted")))
+	if err != nil {
+		t.Fatal("Expected no error while sending the request, got:", err)
+	}
+	_ = resp.Body.Close()
+	assert.Exactly(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,64 @@
+package pgphttp
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Transport is an http.RoundTripper encrypting the request bodies,
+// and decrypting the encrypted response bodies.
+type Transport struct {
+	Config
+	// Base is the RoundTripper sending the requests, http.DefaultTransport if nil.
+	Base http.RoundTripper
+}
+
+// NewTransport returns a Transport with the given config, using http.DefaultTransport.
+func NewTransport(config *Config) *Transport {
+	return &Transport{Config: *config}
+}
+
+// RoundTrip encrypts the request body if the transport has an encryption keyring,
+// sends the request, and decrypts the response body if it is encrypted.
+// Unencrypted response bodies are rejected with an error if the transport has
+// a decryption or verification keyring, unless AllowUnencrypted is set.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && t.EncryptionKeyRing != nil {
+		req = req.Clone(req.Context())
+		req.Body = t.encryptBody(req.Body)
+		req.GetBody = nil
+		req.ContentLength = -1
+		setEncryptedHeaders(req.Header)
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ContentLength == 0 || resp.Body == http.NoBody {
+		return resp, nil
+	}
+	if !isEncrypted(resp.Header) {
+		if t.rejectsUnencrypted() {
+			_ = resp.Body.Close()
+			return nil, errors.New("gopenpgp: the response body is not encrypted")
+		}
+		return resp, nil
+	}
+
+	body, err := t.decryptBody(resp.Body)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	resp.Body = body
+	resp.ContentLength = -1
+	setDecryptedHeaders(resp.Header)
+	return resp, nil
+}
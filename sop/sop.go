@@ -0,0 +1,236 @@
+// Package sop drives an external binary implementing the Stateless OpenPGP command line interface,
+// e.g. sqop or gosop, to check at runtime that messages round-trip between it and gopenpgp.
+package sop
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// exitNoSignature is the exit code of sop verify when no signature is valid.
+const exitNoSignature = 3
+
+// ErrNoSignature is returned by Client.Verify when the SOP binary finds no valid signature.
+var ErrNoSignature = errors.New("gopenpgp: sop found no valid signature")
+
+// Client runs the commands of an external SOP binary.
+// The public keys are passed to the binary in temporary files. The secret keys need to be unlocked,
+// and are passed to the binary through pipes, with @FD: designators, so that they are not written to disk:
+// the binary needs to support them, and they are not available on Windows.
+type Client struct {
+	path string
+}
+
+// NewClient creates a client for the SOP binary at the given path, or found in the PATH.
+func NewClient(path string) *Client {
+	return &Client{path: path}
+}
+
+// Version returns the name and version of the SOP implementation.
+func (client *Client) Version() (string, error) {
+	version, err := client.run(nil, nil, "version")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(version)), nil
+}
+
+// Encrypt encrypts the plaintext to the public keys of the keyring with the SOP binary,
+// and returns the armored message.
+func (client *Client) Encrypt(plaintext []byte, keyRing *crypto.KeyRing) ([]byte, error) {
+	return client.runWithPublicKeys(plaintext, keyRing, "encrypt")
+}
+
+// Decrypt decrypts the message with the unlocked keys of the keyring with the SOP binary.
+// WARNING: the unlocked secret keys are handed to the SOP binary, which must be trusted with them.
+// They are passed through pipes, with @FD: designators, and never written to disk.
+func (client *Client) Decrypt(ciphertext []byte, keyRing *crypto.KeyRing) ([]byte, error) {
+	return client.runWithSecretKeys(ciphertext, keyRing, "decrypt")
+}
+
+// Sign signs the data with the unlocked keys of the keyring with the SOP binary,
+// and returns the armored detached signature.
+// WARNING: the unlocked secret keys are handed to the SOP binary, which must be trusted with them.
+// They are passed through pipes, with @FD: designators, and never written to disk.
+func (client *Client) Sign(data []byte, keyRing *crypto.KeyRing) ([]byte, error) {
+	return client.runWithSecretKeys(data, keyRing, "sign")
+}
+
+// Verify verifies the detached signature of the data with the public keys of the keyring
+// with the SOP binary. Returns ErrNoSignature if no signature is valid.
+func (client *Client) Verify(data, signature []byte, keyRing *crypto.KeyRing) error {
+	dir, err := ioutil.TempDir("", "gopenpgp-sop")
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to create sop directory")
+	}
+	defer os.RemoveAll(dir)
+
+	signatureFile := filepath.Join(dir, "signature")
+	if err = ioutil.WriteFile(signatureFile, signature, 0600); err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to write sop signature")
+	}
+	keyFiles, err := writePublicKeys(dir, keyRing)
+	if err != nil {
+		return err
+	}
+	_, err = client.run(data, nil, append([]string{"verify", signatureFile}, keyFiles...)...)
+	return err
+}
+
+// CheckEncryption checks that a message encrypted by gopenpgp to the keyring is decrypted
+// by the SOP binary, and that a message encrypted by the SOP binary is decrypted by gopenpgp.
+// The keys of the keyring need to be unlocked.
+func (client *Client) CheckEncryption(keyRing *crypto.KeyRing, message []byte) error {
+	encrypted, err := keyRing.Encrypt(crypto.NewPlainMessage(message), nil)
+	if err != nil {
+		return err
+	}
+	armored, err := encrypted.GetArmored()
+	if err != nil {
+		return err
+	}
+	decrypted, err := client.Decrypt([]byte(armored), keyRing)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(decrypted, message) {
+		return errors.New("gopenpgp: message decrypted by sop does not match")
+	}
+
+	armoredMessage, err := client.Encrypt(message, keyRing)
+	if err != nil {
+		return err
+	}
+	pgpMessage, err := crypto.NewPGPMessageFromArmored(string(armoredMessage))
+	if err != nil {
+		return err
+	}
+	plainMessage, err := keyRing.Decrypt(pgpMessage, nil, 0)
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: unable to decrypt message encrypted by sop")
+	}
+	if !bytes.Equal(plainMessage.GetBinary(), message) {
+		return errors.New("gopenpgp: message encrypted by sop does not match")
+	}
+	return nil
+}
+
+// CheckSignature checks that a detached signature made by gopenpgp with the keyring is verified
+// by the SOP binary, and that a detached signature made by the SOP binary is verified by gopenpgp.
+// The keys of the keyring need to be unlocked.
+func (client *Client) CheckSignature(keyRing *crypto.KeyRing, message []byte) error {
+	signature, err := keyRing.SignDetached(crypto.NewPlainMessage(message))
+	if err != nil {
+		return err
+	}
+	armored, err := signature.GetArmored()
+	if err != nil {
+		return err
+	}
+	if err = client.Verify(message, []byte(armored), keyRing); err != nil {
+		return errors.Wrap(err, "gopenpgp: signature made by gopenpgp not verified by sop")
+	}
+
+	armoredSignature, err := client.Sign(message, keyRing)
+	if err != nil {
+		return err
+	}
+	pgpSignature, err := crypto.NewPGPSignatureFromArmored(string(armoredSignature))
+	if err != nil {
+		return err
+	}
+	err = keyRing.VerifyDetached(crypto.NewPlainMessage(message), pgpSignature, crypto.GetUnixTime())
+	if err != nil {
+		return errors.Wrap(err, "gopenpgp: signature made by sop not verified")
+	}
+	return nil
+}
+
+// runWithPublicKeys runs the SOP command with the public keys of the keyring, written in temporary files.
+func (client *Client) runWithPublicKeys(stdin []byte, keyRing *crypto.KeyRing, command string) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "gopenpgp-sop")
+	if err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to create sop directory")
+	}
+	defer os.RemoveAll(dir)
+
+	keyFiles, err := writePublicKeys(dir, keyRing)
+	if err != nil {
+		return nil, err
+	}
+	return client.run(stdin, nil, append([]string{command}, keyFiles...)...)
+}
+
+// runWithSecretKeys runs the SOP command with the secret keys of the keyring,
+// each written to a pipe passed to the binary as an @FD: designator.
+func (client *Client) runWithSecretKeys(stdin []byte, keyRing *crypto.KeyRing, command string) ([]byte, error) {
+	var pipes []*os.File
+	defer func() {
+		for _, pipe := range pipes {
+			_ = pipe.Close()
+		}
+	}()
+
+	args := []string{command}
+	for _, key := range keyRing.GetKeys() {
+		armored, err := key.Armor()
+		if err != nil {
+			return nil, err
+		}
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: unable to create sop key pipe")
+		}
+		pipes = append(pipes, reader)
+		// The child process gets the pipes from file descriptor 3
+		args = append(args, "@FD:"+strconv.Itoa(2+len(pipes)))
+		go func() {
+			// The write fails once the reader is closed, if the binary doesn't read the key
+			_, _ = writer.Write([]byte(armored))
+			_ = writer.Close()
+		}()
+	}
+	return client.run(stdin, pipes, args...)
+}
+
+func (client *Client) run(stdin []byte, extraFiles []*os.File, args ...string) ([]byte, error) {
+	cmd := exec.Command(client.path, args...) //nolint:gosec
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.ExtraFiles = extraFiles
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && args[0] == "verify" && exitErr.ExitCode() == exitNoSignature {
+			return nil, ErrNoSignature
+		}
+		return nil, errors.Wrap(err, "gopenpgp: sop "+args[0]+" failed: "+strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// writePublicKeys writes each public key of the keyring in an armored file of the directory.
+func writePublicKeys(dir string, keyRing *crypto.KeyRing) ([]string, error) {
+	var keyFiles []string
+	for i, key := range keyRing.GetKeys() {
+		armored, err := key.GetArmoredPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		keyFile := filepath.Join(dir, "key"+strconv.Itoa(i))
+		if err = ioutil.WriteFile(keyFile, []byte(armored), 0600); err != nil {
+			return nil, errors.Wrap(err, "gopenpgp: unable to write sop key")
+		}
+		keyFiles = append(keyFiles, keyFile)
+	}
+	return keyFiles, nil
+}
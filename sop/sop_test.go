@@ -0,0 +1,173 @@
+package sop
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain makes the test binary act as a minimal SOP implementation, based on gopenpgp,
+// when run as a SOP binary by the tests.
+func TestMain(m *testing.M) {
+	if os.Getenv("GOPENPGP_SOP_TEST_HELPER") == "1" {
+		os.Exit(runTestSOP(os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+func runTestSOP(args []string) int {
+	if len(args) == 0 {
+		return 69
+	}
+	if args[0] == "version" {
+		fmt.Println("gopenpgp-test-sop 1.0")
+		return 0
+	}
+	input, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return 1
+	}
+	command, keyFiles := args[0], args[1:]
+	var signature *crypto.PGPSignature
+	if command == "verify" {
+		armored, err := ioutil.ReadFile(keyFiles[0])
+		if err != nil {
+			return 1
+		}
+		if signature, err = crypto.NewPGPSignatureFromArmored(string(armored)); err != nil {
+			return 1
+		}
+		keyFiles = keyFiles[1:]
+	}
+	keyRing, err := crypto.NewKeyRing(nil)
+	if err != nil {
+		return 1
+	}
+	for _, keyFile := range keyFiles {
+		key, err := readTestSOPKey(keyFile)
+		if err != nil {
+			return 1
+		}
+		if err = keyRing.AddKey(key); err != nil {
+			return 1
+		}
+	}
+
+	var output string
+	switch command {
+	case "encrypt":
+		message, err := keyRing.Encrypt(crypto.NewPlainMessage(input), nil)
+		if err == nil {
+			output, err = message.GetArmored()
+		}
+		if err != nil {
+			return 1
+		}
+	case "decrypt":
+		message, err := crypto.NewPGPMessageFromArmored(string(input))
+		if err != nil {
+			return 1
+		}
+		plainMessage, err := keyRing.Decrypt(message, nil, 0)
+		if err != nil {
+			return 1
+		}
+		output = string(plainMessage.GetBinary())
+	case "sign":
+		sig, err := keyRing.SignDetached(crypto.NewPlainMessage(input))
+		if err == nil {
+			output, err = sig.GetArmored()
+		}
+		if err != nil {
+			return 1
+		}
+	case "verify":
+		if keyRing.VerifyDetached(crypto.NewPlainMessage(input), signature, crypto.GetUnixTime()) != nil {
+			return exitNoSignature
+		}
+	default:
+		return 69
+	}
+	fmt.Print(output)
+	return 0
+}
+
+// readTestSOPKey reads a public key from a file, or a secret key from an @FD: designator,
+// as the secret keys must not be written to disk.
+func readTestSOPKey(designator string) (*crypto.Key, error) {
+	var armored []byte
+	var err error
+	if strings.HasPrefix(designator, "@FD:") {
+		fd, err := strconv.Atoi(strings.TrimPrefix(designator, "@FD:"))
+		if err != nil {
+			return nil, err
+		}
+		if armored, err = ioutil.ReadAll(os.NewFile(uintptr(fd), designator)); err != nil {
+			return nil, err
+		}
+	} else if armored, err = ioutil.ReadFile(designator); err != nil {
+		return nil, err
+	}
+	key, err := crypto.NewKeyFromArmored(string(armored))
+	if err != nil {
+		return nil, err
+	}
+	if key.IsPrivate() != strings.HasPrefix(designator, "@FD:") {
+		return nil, fmt.Errorf("unexpected key designator %s", designator)
+	}
+	return key, nil
+}
+
+func newTestClient(t *testing.T) *Client {
+	if path := os.Getenv("GOPENPGP_SOP"); path != "" {
+		return NewClient(path)
+	}
+	if err := os.Setenv("GOPENPGP_SOP_TEST_HELPER", "1"); err != nil {
+		t.Fatal("Expected no error while setting the environment, got:", err)
+	}
+	t.Cleanup(func() { _ = os.Unsetenv("GOPENPGP_SOP_TEST_HELPER") })
+	return NewClient(os.Args[0])
+}
+
+func newTestKeyRing(t *testing.T, name string) *crypto.KeyRing {
+	key, err := crypto.GenerateKey(name, name+"@protonmail.ch", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating key, got:", err)
+	}
+	keyRing, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatal("Expected no error while building keyring, got:", err)
+	}
+	return keyRing
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	client := newTestClient(t)
+	keyRing := newTestKeyRing(t, "sop")
+	message := []byte("Hello SOP")
+
+	version, err := client.Version()
+	if err != nil {
+		t.Fatal("Expected no error while getting the sop version, got:", err)
+	}
+	assert.NotEmpty(t, version)
+
+	assert.NoError(t, client.CheckEncryption(keyRing, message))
+	assert.NoError(t, client.CheckSignature(keyRing, message))
+
+	signature, err := client.Sign(message, keyRing)
+	if err != nil {
+		t.Fatal("Expected no error while signing with sop, got:", err)
+	}
+	otherKeyRing := newTestKeyRing(t, "other")
+	assert.ErrorIs(t, client.Verify(message, signature, otherKeyRing), ErrNoSignature)
+
+	_, err = client.Decrypt([]byte("not a message"), keyRing)
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package wkd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/pkg/errors"
+)
+
+// GenerateDirectory writes the Web Key Directory of the domain in rootDir,
+// the root of the web server, for the user IDs of the keys with an email address in the domain.
+// With the advanced method, the directory is .well-known/openpgpkey/<domain>/ and is served
+// by the openpgpkey subdomain, otherwise it is .well-known/openpgpkey/ and is served by the domain itself.
+// The directory contains an empty policy file, and a file named after the hashed local-part
+// of each address, with the keys of the address. The published keys are minimized:
+// they only contain their public key material, the user ID of the address and the self-signatures.
+func GenerateDirectory(rootDir, domain string, advanced bool, keys ...*crypto.Key) error {
+	domain = strings.ToLower(domain)
+	dir := filepath.Join(rootDir, ".well-known", "openpgpkey")
+	if advanced {
+		dir = filepath.Join(dir, domain)
+	}
+
+	files := make(map[string]*bytes.Buffer)
+	for _, key := range keys {
+		entity := key.GetEntity()
+		for _, identity := range entity.Identities {
+			localPart, addressDomain, ok := splitAddress(identity.UserId.Email)
+			if !ok || addressDomain != domain {
+				continue
+			}
+			name := HashLocalPart(localPart)
+			if files[name] == nil {
+				files[name] = &bytes.Buffer{}
+			}
+			if err := minimizeEntity(entity, identity).Serialize(files[name]); err != nil {
+				return errors.Wrap(err, "gopenpgp: unable to serialize key")
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "hu"), 0755); err != nil { //nolint:gosec
+		return errors.Wrap(err, "gopenpgp: unable to create the directory")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "policy"), nil, 0644); err != nil { //nolint:gosec
+		return errors.Wrap(err, "gopenpgp: unable to write the policy file")
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, "hu", name), content.Bytes(), 0644); err != nil { //nolint:gosec
+			return errors.Wrap(err, "gopenpgp: unable to write the key file")
+		}
+	}
+	return nil
+}
+
+// minimizeEntity returns the public entity with only the given identity,
+// without third-party certifications nor private key material.
+func minimizeEntity(entity *openpgp.Entity, identity *openpgp.Identity) *openpgp.Entity {
+	var signatures []*packet.Signature
+	for _, sig := range identity.Signatures {
+		if sig.CheckKeyIdOrFingerprint(entity.PrimaryKey) {
+			signatures = append(signatures, sig)
+		}
+	}
+
+	minimized := &openpgp.Entity{
+		PrimaryKey:  entity.PrimaryKey,
+		Revocations: entity.Revocations,
+		Identities: map[string]*openpgp.Identity{
+			identity.Name: {
+				Name:          identity.Name,
+				UserId:        identity.UserId,
+				SelfSignature: identity.SelfSignature,
+				Revocations:   identity.Revocations,
+				Signatures:    signatures,
+			},
+		},
+	}
+	for _, subkey := range entity.Subkeys {
+		minimized.Subkeys = append(minimized.Subkeys, openpgp.Subkey{
+			PublicKey:   subkey.PublicKey,
+			Sig:         subkey.Sig,
+			Revocations: subkey.Revocations,
+		})
+	}
+	return minimized
+}
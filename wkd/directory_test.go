@@ -0,0 +1,65 @@
+package wkd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateDirectory(t *testing.T) {
+	key, err := crypto.GenerateKey("Joe Doe", "Joe.Doe@Example.ORG", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating the key, got:", err)
+	}
+	entity := key.GetEntity()
+	if err = entity.AddUserId("Joe", "", "joe@other.org", nil); err != nil {
+		t.Fatal("Expected no error while adding the user ID, got:", err)
+	}
+	otherKey, err := crypto.GenerateKey("Other", "other@other.org", "x25519", 0)
+	if err != nil {
+		t.Fatal("Expected no error while generating the key, got:", err)
+	}
+
+	for _, advanced := range []bool{true, false} {
+		rootDir := t.TempDir()
+		if err = GenerateDirectory(rootDir, "example.org", advanced, key, otherKey); err != nil {
+			t.Fatal("Expected no error while generating the directory, got:", err)
+		}
+
+		dir := filepath.Join(rootDir, ".well-known", "openpgpkey")
+		if advanced {
+			dir = filepath.Join(dir, "example.org")
+		}
+		policy, err := ioutil.ReadFile(filepath.Join(dir, "policy"))
+		if err != nil {
+			t.Fatal("Expected no error while reading the policy file, got:", err)
+		}
+		assert.Empty(t, policy)
+
+		files, err := ioutil.ReadDir(filepath.Join(dir, "hu"))
+		if err != nil {
+			t.Fatal("Expected no error while listing the key files, got:", err)
+		}
+		assert.Len(t, files, 1)
+
+		published, err := ioutil.ReadFile(filepath.Join(dir, "hu", "iy9q119eutrkn8s1mk4r39qejnbu3n5q"))
+		if err != nil {
+			t.Fatal("Expected no error while reading the key file, got:", err)
+		}
+		entities, err := openpgp.ReadKeyRing(bytes.NewReader(published))
+		if err != nil {
+			t.Fatal("Expected no error while parsing the key file, got:", err)
+		}
+		assert.Len(t, entities, 1)
+		assert.Nil(t, entities[0].PrivateKey)
+		assert.Equal(t, entity.PrimaryKey.Fingerprint, entities[0].PrimaryKey.Fingerprint)
+		assert.Len(t, entities[0].Identities, 1)
+		assert.Contains(t, entities[0].Identities, "Joe Doe <Joe.Doe@Example.ORG>")
+		assert.Len(t, entities[0].Subkeys, 1)
+	}
+}
@@ -0,0 +1,67 @@
+package wkd
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Policy holds the flags of a Web Key Directory policy file.
+type Policy struct {
+	// MailboxOnly is set if the keys only contain user IDs with a bare email address.
+	MailboxOnly bool
+	// DaneOnly is set if the DANE method is the only one used to retrieve keys, deprecated.
+	DaneOnly bool
+	// AuthSubmit is set if the submission of keys requires an authentication.
+	AuthSubmit bool
+	// ProtocolVersion is the version of the Web Key Directory protocol, 0 if not given.
+	ProtocolVersion int
+	// SubmissionAddress is the email address of the key submission service, possibly empty.
+	SubmissionAddress string
+	// Unknown holds the keywords not defined above, with their value if any.
+	Unknown map[string]string
+}
+
+// ParsePolicy parses the content of a policy file, where each line is either
+// empty, a comment starting with '#', a keyword, or a keyword followed by a colon and a value.
+// An empty policy file is valid, and parsed as an empty policy.
+func ParsePolicy(data []byte) (*Policy, error) {
+	policy := &Policy{Unknown: make(map[string]string)}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, value := line, ""
+		if colon := strings.Index(line, ":"); colon >= 0 {
+			keyword, value = strings.TrimSpace(line[:colon]), strings.TrimSpace(line[colon+1:])
+		}
+		switch strings.ToLower(keyword) {
+		case "mailbox-only":
+			policy.MailboxOnly = true
+		case "dane-only":
+			policy.DaneOnly = true
+		case "auth-submit":
+			policy.AuthSubmit = true
+		case "protocol-version":
+			version, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, errors.Wrap(err, "gopenpgp: invalid protocol version in policy")
+			}
+			policy.ProtocolVersion = version
+		case "submission-address":
+			policy.SubmissionAddress = value
+		default:
+			policy.Unknown[keyword] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "gopenpgp: unable to read policy")
+	}
+	return policy, nil
+}
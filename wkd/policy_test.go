@@ -0,0 +1,30 @@
+package wkd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte("# Policy of example.org\n\nmailbox-only\nprotocol-version: 14\n" +
+		"submission-address: key-submission@example.org\nx-custom: value\nauth-submit\n"))
+	if err != nil {
+		t.Fatal("Expected no error while parsing the policy, got:", err)
+	}
+	assert.True(t, policy.MailboxOnly)
+	assert.True(t, policy.AuthSubmit)
+	assert.False(t, policy.DaneOnly)
+	assert.Equal(t, 14, policy.ProtocolVersion)
+	assert.Equal(t, "key-submission@example.org", policy.SubmissionAddress)
+	assert.Equal(t, map[string]string{"x-custom": "value"}, policy.Unknown)
+
+	policy, err = ParsePolicy(nil)
+	if err != nil {
+		t.Fatal("Expected no error while parsing the empty policy, got:", err)
+	}
+	assert.Equal(t, &Policy{Unknown: map[string]string{}}, policy)
+
+	_, err = ParsePolicy([]byte("protocol-version: latest"))
+	assert.Error(t, err)
+}
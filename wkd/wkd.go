@@ -0,0 +1,83 @@
+// Package wkd generates the static directory layout of a Web Key Directory,
+// used to publish OpenPGP keys on the web server of the email domain,
+// and provides the helpers to locate the keys and the policy in such a directory.
+package wkd
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// zBase32Alphabet is the z-base-32 alphabet, as defined in RFC 6189 section 5.1.6.
+const zBase32Alphabet = "ybndrfg8ejkmcpqxot1uwisza345h769"
+
+// HashLocalPart returns the z-base-32 encoded SHA-1 hash of the lowercased local-part
+// of an email address, naming the key file of the address in the directory.
+func HashLocalPart(localPart string) string {
+	hash := sha1.Sum([]byte(strings.ToLower(localPart))) //nolint:gosec
+	return zBase32Encode(hash[:])
+}
+
+func zBase32Encode(data []byte) string {
+	var encoded strings.Builder
+	encoded.Grow((len(data)*8 + 4) / 5)
+
+	var buffer, bits uint
+	for _, b := range data {
+		buffer = buffer<<8 | uint(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			encoded.WriteByte(zBase32Alphabet[(buffer>>bits)&0x1f])
+		}
+	}
+	if bits > 0 {
+		encoded.WriteByte(zBase32Alphabet[(buffer<<(5-bits))&0x1f])
+	}
+	return encoded.String()
+}
+
+// splitAddress splits the email address in its local-part and its lowercased domain.
+func splitAddress(address string) (localPart, domain string, ok bool) {
+	at := strings.LastIndex(address, "@")
+	if at <= 0 || at == len(address)-1 {
+		return "", "", false
+	}
+	return address[:at], strings.ToLower(address[at+1:]), true
+}
+
+// AdvancedURL returns the URL of the keys of the email address with the advanced method,
+// served by the openpgpkey subdomain of the address domain.
+func AdvancedURL(address string) (string, error) {
+	localPart, domain, ok := splitAddress(address)
+	if !ok {
+		return "", errors.New("gopenpgp: invalid email address")
+	}
+	return "https://openpgpkey." + domain + "/.well-known/openpgpkey/" + domain +
+		"/hu/" + HashLocalPart(localPart) + "?l=" + url.QueryEscape(localPart), nil
+}
+
+// DirectURL returns the URL of the keys of the email address with the direct method,
+// served by the address domain itself, used when the openpgpkey subdomain does not exist.
+func DirectURL(address string) (string, error) {
+	localPart, domain, ok := splitAddress(address)
+	if !ok {
+		return "", errors.New("gopenpgp: invalid email address")
+	}
+	return "https://" + domain + "/.well-known/openpgpkey/hu/" +
+		HashLocalPart(localPart) + "?l=" + url.QueryEscape(localPart), nil
+}
+
+// AdvancedPolicyURL returns the URL of the policy file of the domain with the advanced method.
+func AdvancedPolicyURL(domain string) string {
+	domain = strings.ToLower(domain)
+	return "https://openpgpkey." + domain + "/.well-known/openpgpkey/" + domain + "/policy"
+}
+
+// DirectPolicyURL returns the URL of the policy file of the domain with the direct method.
+func DirectPolicyURL(domain string) string {
+	return "https://" + strings.ToLower(domain) + "/.well-known/openpgpkey/policy"
+}
@@ -0,0 +1,42 @@
+package wkd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashLocalPart(t *testing.T) {
+	// Test vector from draft-koch-openpgp-webkey-service section 3.1
+	assert.Equal(t, "iy9q119eutrkn8s1mk4r39qejnbu3n5q", HashLocalPart("Joe.Doe"))
+}
+
+func TestZBase32Encode(t *testing.T) {
+	assert.Equal(t, "", zBase32Encode(nil))
+	assert.Equal(t, "yy", zBase32Encode([]byte{0}))
+	assert.Equal(t, "9h", zBase32Encode([]byte{0xff}))
+}
+
+func TestURLs(t *testing.T) {
+	advancedURL, err := AdvancedURL("Joe.Doe@Example.ORG")
+	if err != nil {
+		t.Fatal("Expected no error while building the URL, got:", err)
+	}
+	assert.Equal(t, "https://openpgpkey.example.org/.well-known/openpgpkey/example.org/hu/iy9q119eutrkn8s1mk4r39qejnbu3n5q?l=Joe.Doe", advancedURL)
+
+	directURL, err := DirectURL("Joe.Doe@Example.ORG")
+	if err != nil {
+		t.Fatal("Expected no error while building the URL, got:", err)
+	}
+	assert.Equal(t, "https://example.org/.well-known/openpgpkey/hu/iy9q119eutrkn8s1mk4r39qejnbu3n5q?l=Joe.Doe", directURL)
+
+	assert.Equal(t, "https://openpgpkey.example.org/.well-known/openpgpkey/example.org/policy", AdvancedPolicyURL("Example.ORG"))
+	assert.Equal(t, "https://example.org/.well-known/openpgpkey/policy", DirectPolicyURL("Example.ORG"))
+
+	for _, address := range []string{"", "joe", "@example.org", "joe@"} {
+		_, err = AdvancedURL(address)
+		assert.Error(t, err)
+		_, err = DirectURL(address)
+		assert.Error(t, err)
+	}
+}